@@ -0,0 +1,129 @@
+package fecanalysis
+
+import (
+	"fmt"
+	"math"
+)
+
+// AnnealingOptimizer searches for good masks with simulated annealing over random moves
+// of the protection matrix, single-bit flips unless another mutator is set. The objective
+// is pluggable, so deployments can optimize recovery probability, minimum loss for failure,
+// burst tolerance or their own target. Zero values of the parameters select the defaults.
+type AnnealingOptimizer struct {
+	Iterations         int         // number of proposed moves (default 2000)
+	InitialTemperature float64     // starting temperature (default: mean objective change of random moves)
+	CoolingRate        float64     // temperature multiplier per iteration (default: cools 1000x over the run)
+	Mutator            MaskMutator // proposes the moves (default FlipBitMutator)
+
+	Rand RandSource // source of randomness; nil derives one from the package seed
+}
+
+// Optimize runs simulated annealing starting from the given mask, or from a random mask
+// if start is nil, and returns the best mask visited
+func (o *AnnealingOptimizer) Optimize(N, K int, objective MaskObjective, start Mask) (OptimizationResult, error) {
+	if N <= 0 || K <= 0 {
+		return OptimizationResult{}, fmt.Errorf("invalid parameters for mask optimization: N=%d, K=%d", N, K)
+	}
+	rng := randSourceOrDefault(o.Rand)
+	mutator := o.Mutator
+	if mutator == nil {
+		mutator = FlipBitMutator{}
+	}
+
+	var current [][]bool
+	if start != nil {
+		if start.N() != N || start.K() != K {
+			return OptimizationResult{}, fmt.Errorf("start mask is N=%d, K=%d, expected N=%d, K=%d", start.N(), start.K(), N, K)
+		}
+		current = maskMatrix(start)
+	} else {
+		mask, err := RandomMask(N, K, (N+1)/2, rng)
+		if err != nil {
+			return OptimizationResult{}, err
+		}
+		current = mask.rows
+	}
+
+	cache := make(map[string]float64)
+	evaluate := func(rows [][]bool) float64 {
+		key := matrixKey(rows)
+		score, ok := cache[key]
+		if !ok {
+			mask, _ := NewMatrixMask(rows)
+			score = objective(mask)
+			cache[key] = score
+		}
+		return score
+	}
+
+	iterations := defaultInt(o.Iterations, 2000)
+	currentScore := evaluate(current)
+	temperature := o.InitialTemperature
+	if temperature <= 0 {
+		temperature = estimateTemperature(current, currentScore, evaluate, mutator, rng)
+	}
+	coolingRate := defaultFloat(o.CoolingRate, math.Pow(1e-3, 1.0/float64(iterations)))
+
+	best, bestScore := cloneRows(current), currentScore
+	for iteration := 0; iteration < iterations; iteration++ {
+		candidate := cloneRows(current)
+		if !mutator.Mutate(candidate, rng) {
+			continue
+		}
+		candidateScore := evaluate(candidate)
+
+		// Always accept improvements, accept regressions with Boltzmann probability
+		delta := candidateScore - currentScore
+		if delta >= 0 || rng.Float64() < math.Exp(delta/temperature) {
+			current, currentScore = candidate, candidateScore
+			if currentScore > bestScore {
+				best, bestScore = cloneRows(current), currentScore
+			}
+		}
+		temperature *= coolingRate
+	}
+
+	bestMask, _ := NewMatrixMask(best)
+	return OptimizationResult{
+		Mask:        bestMask,
+		Score:       bestScore,
+		Evaluations: len(cache),
+	}, nil
+}
+
+// flipRandomBit flips one random bit of the matrix, refusing moves that would empty a
+// row; it returns false if the move was refused
+func flipRandomBit(rows [][]bool, rng RandSource) bool {
+	fecIndex := rng.Intn(len(rows))
+	packetIndex := rng.Intn(len(rows[fecIndex]))
+	rows[fecIndex][packetIndex] = !rows[fecIndex][packetIndex]
+
+	for _, protected := range rows[fecIndex] {
+		if protected {
+			return true
+		}
+	}
+	rows[fecIndex][packetIndex] = true
+	return false
+}
+
+// estimateTemperature returns the mean absolute objective change of a few random moves,
+// so that early regressions of typical size are accepted with probability 1/e
+func estimateTemperature(rows [][]bool, score float64, evaluate func([][]bool) float64, mutator MaskMutator, rng RandSource) float64 {
+	const samples = 16
+	total, count := 0.0, 0
+	for i := 0; i < samples; i++ {
+		candidate := cloneRows(rows)
+		if !mutator.Mutate(candidate, rng) {
+			continue
+		}
+		if delta := math.Abs(evaluate(candidate) - score); delta > 0 {
+			total += delta
+			count++
+		}
+	}
+	if count == 0 {
+		return 1.0
+	}
+	return total / float64(count)
+}
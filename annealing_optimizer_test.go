@@ -0,0 +1,55 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnealingOptimizerObjectives(t *testing.T) {
+	N, K := 6, 3
+	start, err := (&GoogleBurstyMaskFactory{}).CreateMask(N, K)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		objective MaskObjective
+	}{
+		{"recovery probability", RecoveryProbabilityObjective(NewGilbertElliotLossModel(0.05, 0.7, 0.05, 0.2))},
+		{"min loss for failure", MinLossForFailureObjective()},
+		{"burst tolerance", BurstToleranceObjective()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			optimizer := &AnnealingOptimizer{Iterations: 300, Rand: NewRandSource(1)}
+			result, err := optimizer.Optimize(N, K, tt.objective, start)
+			require.NoError(t, err)
+
+			assert.GreaterOrEqual(t, result.Score, tt.objective(start))
+			assert.Equal(t, tt.objective(result.Mask), result.Score)
+		})
+	}
+}
+
+func TestBurstToleranceObjective(t *testing.T) {
+	// Interleaved mask with N=4, K=2 recovers any single loss but not two consecutive media losses
+	mask, err := (&InterleavedMaskFactory{}).CreateMask(4, 2)
+	require.NoError(t, err)
+
+	characteristics := CalculateRecoveryCharacteristics(mask)
+	assert.Equal(t, float64(characteristics.MinConsecutiveLostForNonRecovery), BurstToleranceObjective()(mask))
+	assert.Equal(t, float64(characteristics.MinLostPacketsForNonRecovery), MinLossForFailureObjective()(mask))
+}
+
+func TestAnnealingOptimizerRandomStart(t *testing.T) {
+	optimizer := &AnnealingOptimizer{Iterations: 100, Rand: NewRandSource(5)}
+	result, err := optimizer.Optimize(4, 2, MinLossForFailureObjective(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 4, result.Mask.N())
+	assert.GreaterOrEqual(t, result.Score, 2.0, "a 4x2 mask can always survive a single loss")
+
+	_, err = optimizer.Optimize(4, 2, MinLossForFailureObjective(), NewSimpleMask([][]bool{{true}}, 1, 1))
+	assert.Error(t, err)
+}
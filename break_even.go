@@ -0,0 +1,66 @@
+package fecanalysis
+
+import "fmt"
+
+// breakEvenTolerance is the width of the loss probability interval BreakEvenLossProbability
+// narrows the crossing down to
+const breakEvenTolerance = 1e-6
+
+// LossModelFamily maps a loss probability in [0, 1] to a loss model, so that a single
+// parameter sweeps a channel from lossless to fully lossy
+type LossModelFamily func(lossProbability float64) LossModel
+
+// RandomLossFamily is the family of independent loss models
+func RandomLossFamily(lossProbability float64) LossModel {
+	return NewRandomLossModel(lossProbability)
+}
+
+// GilbertBurstFamily returns the family of Gilbert models (every packet lost in the bad state,
+// none in the good state) with the given mean burst length in packets and the loss
+// probability as average loss. The good-to-bad transition probability p/(1-p)/length exceeds
+// 1 above length/(length+1), where it is capped and the average loss stops growing.
+func GilbertBurstFamily(meanBurstLength float64) LossModelFamily {
+	p10 := 1 / meanBurstLength
+	return func(lossProbability float64) LossModel {
+		p01 := 1.0
+		if lossProbability < 1 {
+			p01 = min(lossProbability/(1-lossProbability)*p10, 1)
+		}
+		return NewGilbertLossModel(1, p01, p10)
+	}
+}
+
+// BreakEvenLossProbability returns the loss probability of the family at which the recovery
+// probability of the mask drops to threshold, by bisection to within 1e-6. The recoverable
+// set is computed once and every step only sums the model over it. Recovery is assumed to
+// degrade monotonically as the loss probability grows; otherwise one of the crossings is
+// returned. It fails if the recovery probability stays above threshold up to a loss
+// probability of 1.
+func BreakEvenLossProbability(mask Mask, family LossModelFamily, threshold float64, mode DecodingMode) (float64, error) {
+	if threshold <= 0 || threshold >= 1 {
+		return 0, fmt.Errorf("recovery probability threshold %g is not in (0, 1)", threshold)
+	}
+	N, K := mask.N(), mask.K()
+	if err := checkEnumerable(N, K); err != nil {
+		return 0, err
+	}
+
+	set := RecoverableSet(mask, mode)
+	recoveryProbability := func(lossProbability float64) float64 {
+		return SumProbabilities(set, family(lossProbability), N+K, 0)
+	}
+	if recoveryProbability(1) >= threshold {
+		return 0, fmt.Errorf("recovery probability stays above %g for every loss probability", threshold)
+	}
+
+	low, high := 0.0, 1.0
+	for high-low > breakEvenTolerance {
+		middle := (low + high) / 2
+		if recoveryProbability(middle) >= threshold {
+			low = middle
+		} else {
+			high = middle
+		}
+	}
+	return (low + high) / 2, nil
+}
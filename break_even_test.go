@@ -0,0 +1,50 @@
+package fecanalysis
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreakEvenLossProbability(t *testing.T) {
+	parity, err := (&SingleParityMaskFactory{}).CreateMask(4, 1)
+	require.NoError(t, err)
+
+	// Single parity recovers at most one loss: (1-p)^5 + 5p(1-p)^4 = 0.9
+	p, err := BreakEvenLossProbability(parity, RandomLossFamily, 0.9, PeelingDecoding)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.9, math.Pow(1-p, 5)+5*p*math.Pow(1-p, 4), 1e-5)
+
+	// More FEC packets break even at a higher loss probability, under bursts as well
+	stronger, err := (&GoogleRandomMaskFactory{}).CreateMask(4, 3)
+	require.NoError(t, err)
+	for _, family := range []LossModelFamily{RandomLossFamily, GilbertBurstFamily(3)} {
+		weak, err := BreakEvenLossProbability(parity, family, 0.95, PeelingDecoding)
+		require.NoError(t, err)
+		strong, err := BreakEvenLossProbability(stronger, family, 0.95, PeelingDecoding)
+		require.NoError(t, err)
+		assert.Less(t, weak, strong)
+		assert.InDelta(t, 0.95, RecoveryProbability(stronger, family(strong)), 1e-4)
+	}
+
+	_, err = BreakEvenLossProbability(parity, RandomLossFamily, 1, PeelingDecoding)
+	assert.Error(t, err)
+	_, err = BreakEvenLossProbability(parity, RandomLossFamily, 0, PeelingDecoding)
+	assert.Error(t, err)
+}
+
+func TestGilbertBurstFamily(t *testing.T) {
+	family := GilbertBurstFamily(4)
+	for _, lossProbability := range []float64{0, 0.05, 0.3, 0.8} {
+		model := family(lossProbability).(*GilbertElliotLossModel)
+		assert.InDelta(t, lossProbability, model.GetAverageLossProbability(), 1e-12)
+		assert.Equal(t, 0.25, model.P10)
+	}
+
+	// Above length/(length+1) the transition probability is capped
+	assert.InDelta(t, 0.8, family(0.95).GetAverageLossProbability(), 1e-12)
+	_, err := BreakEvenLossProbability(NewSimpleMask([][]bool{{true}}, 1, 1), family, 0.1, PeelingDecoding)
+	assert.Error(t, err)
+}
@@ -0,0 +1,38 @@
+package fecanalysis
+
+import "fmt"
+
+// GenerateBurstMask constructs a mask with N media packets that recovers any single burst
+// of up to B consecutive lost packets (media followed by FEC, as in the recovery graph
+// vertex order), using the minimal K = B FEC packets: recovering B erasures needs at
+// least B repair symbols with any code. Media packet i is protected by FEC packet
+// (i - N) mod B, so B consecutive media packets hit distinct FEC packets and a burst
+// spanning the last m media packets and the first B-m FEC packets never loses the FEC
+// packet of a lost media packet. With B > N no media packet maps to the first B-N FEC
+// packets, so FEC packet f < B-N repeats media packet f mod N instead.
+//
+// The achieved guarantee is returned as the mask's recovery characteristics:
+// MinConsecutiveLostForNonRecovery is greater than B, or PerfectRecovery if no burst fails.
+func GenerateBurstMask(N, B int) (Mask, RecoveryCharacteristics, error) {
+	if N <= 0 || B <= 0 {
+		return nil, RecoveryCharacteristics{}, fmt.Errorf("invalid parameters for burst mask: N=%d, B=%d", N, B)
+	}
+
+	rows := make([][]bool, B)
+	for fecIndex := range rows {
+		rows[fecIndex] = make([]bool, N)
+	}
+	for packetIndex := 0; packetIndex < N; packetIndex++ {
+		fecIndex := ((packetIndex-N)%B + B) % B
+		rows[fecIndex][packetIndex] = true
+	}
+	for fecIndex := 0; fecIndex < B-N; fecIndex++ {
+		rows[fecIndex][fecIndex%N] = true
+	}
+
+	mask, err := NewMatrixMask(rows)
+	if err != nil {
+		return nil, RecoveryCharacteristics{}, err
+	}
+	return mask, CalculateRecoveryCharacteristics(mask), nil
+}
@@ -0,0 +1,42 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateBurstMask(t *testing.T) {
+	for N := 1; N <= 10; N++ {
+		// More FEC than media packets only for small N to keep the recovery graphs small
+		for B := 1; B <= N || (B <= 2*N+1 && N+B <= 16); B++ {
+			mask, characteristics, err := GenerateBurstMask(N, B)
+			require.NoError(t, err)
+			assert.Equal(t, B, mask.K())
+
+			burstTolerance := characteristics.MinConsecutiveLostForNonRecovery
+			if burstTolerance != PerfectRecovery {
+				assert.Greater(t, burstTolerance, LossCount(B), "N=%d B=%d", N, B)
+			}
+		}
+	}
+}
+
+func TestGenerateBurstMaskBeatsPlainInterleaving(t *testing.T) {
+	// Plain interleaving loses packet 4 and its FEC packet 0 in a burst over the block boundary
+	interleaved, err := (&InterleavedMaskFactory{}).CreateMask(5, 2)
+	require.NoError(t, err)
+	assert.Equal(t, LossCount(2), CalculateRecoveryCharacteristics(interleaved).MinConsecutiveLostForNonRecovery)
+
+	_, characteristics, err := GenerateBurstMask(5, 2)
+	require.NoError(t, err)
+	assert.Equal(t, LossCount(3), characteristics.MinConsecutiveLostForNonRecovery)
+}
+
+func TestGenerateBurstMaskValidation(t *testing.T) {
+	_, _, err := GenerateBurstMask(0, 1)
+	assert.Error(t, err)
+	_, _, err = GenerateBurstMask(3, 0)
+	assert.Error(t, err)
+}
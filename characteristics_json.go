@@ -0,0 +1,34 @@
+package fecanalysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// LossCount is a minimum loss count of RecoveryCharacteristics: a number of packets, or
+// PerfectRecovery when no pattern of its kind fails, which JSON encodes as "infinite" so
+// readers need not know the sentinel
+type LossCount int
+
+// MarshalJSON encodes the count as a number, or "infinite" for PerfectRecovery
+func (c LossCount) MarshalJSON() ([]byte, error) {
+	if c == PerfectRecovery {
+		return []byte(`"infinite"`), nil
+	}
+	return strconv.AppendInt(nil, int64(c), 10), nil
+}
+
+// UnmarshalJSON decodes a count encoded by MarshalJSON
+func (c *LossCount) UnmarshalJSON(data []byte) error {
+	if string(data) == `"infinite"` {
+		*c = PerfectRecovery
+		return nil
+	}
+	var count int
+	if err := json.Unmarshal(data, &count); err != nil || count < 0 {
+		return fmt.Errorf("invalid loss count %s, expected a non-negative number or \"infinite\"", data)
+	}
+	*c = LossCount(count)
+	return nil
+}
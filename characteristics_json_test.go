@@ -0,0 +1,60 @@
+package fecanalysis
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoveryCharacteristicsJSON(t *testing.T) {
+	characteristics := ReedSolomonConfig{DataShards: 2, ParityShards: 2}.RecoveryCharacteristics()
+	data, err := json.Marshal(characteristics)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"min_lost_packets_for_non_recovery": 3,
+		"min_consecutive_lost_for_non_recovery": 3,
+		"min_media_burst_for_non_recovery": "infinite",
+		"min_fec_burst_for_non_recovery": 2,
+		"max_guaranteed_losses": 2
+	}`, string(data))
+
+	var decoded RecoveryCharacteristics
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, characteristics, decoded)
+
+	// The encoding uses the field names of the tags
+	typ := reflect.TypeOf(RecoveryCharacteristics{})
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(data, &fields))
+	require.Len(t, fields, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		assert.Contains(t, fields, typ.Field(i).Tag.Get("json"))
+	}
+
+	for _, invalid := range []string{`{"max_guaranteed_losses": "infinite"}`, `{"min_lost_packets_for_non_recovery": -1}`, `{"min_lost_packets_for_non_recovery": "none"}`} {
+		assert.Error(t, json.Unmarshal([]byte(invalid), &decoded), invalid)
+	}
+}
+
+func TestEvaluationResultJSON(t *testing.T) {
+	mask, err := (&GoogleRandomMaskFactory{}).CreateMask(4, 2)
+	require.NoError(t, err)
+	result, err := EvaluateMask(mask, NewRandomLossModel(0.1), EvaluationOptions{})
+	require.NoError(t, err)
+
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(data, &fields))
+	assert.NotContains(t, fields, "Recoverable")
+	assert.InDelta(t, result.RecoveryProbability, fields["recovery_probability"], 1e-15)
+	assert.Contains(t, fields["characteristics"], "min_lost_packets_for_non_recovery")
+
+	var decoded EvaluationResult
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	result.Recoverable = nil
+	assert.Equal(t, result, decoded)
+}
@@ -0,0 +1,180 @@
+package fecanalysis
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// HammingMaskFactory creates masks from binary Hamming codes. With K parity packets the
+// code protects up to 2^K-K-1 media packets; media packet i takes the i-th codeword
+// position that is not a power of two, and FEC packet r protects the positions with bit r
+// set. N=4, K=3 is the textbook Hamming(7,4) code, which recovers any single loss.
+type HammingMaskFactory struct{}
+
+// CreateMask creates a Hamming mask with N media packets and K FEC packets
+func (f *HammingMaskFactory) CreateMask(N, K int) (Mask, error) {
+	if N <= 0 || K <= 1 || N > hammingDataPositions(K) {
+		return nil, fmt.Errorf("invalid parameters for Hamming mask: N=%d, K=%d", N, K)
+	}
+	return NewMatrixMask(hammingRows(N, K))
+}
+
+// ExtendedHammingMaskFactory creates masks from extended Hamming codes (SECDED): K-1
+// Hamming parity packets plus one overall parity packet. The overall parity also covers
+// the Hamming parities, which in terms of media packets means it protects exactly the
+// media packets whose codeword position has an even number of bits set.
+// N=4, K=4 is the extended Hamming(8,4) code.
+type ExtendedHammingMaskFactory struct{}
+
+// CreateMask creates an extended Hamming mask with N media packets and K FEC packets
+func (f *ExtendedHammingMaskFactory) CreateMask(N, K int) (Mask, error) {
+	if N <= 0 || K <= 2 || N > hammingDataPositions(K-1) {
+		return nil, fmt.Errorf("invalid parameters for extended Hamming mask: N=%d, K=%d", N, K)
+	}
+
+	rows := hammingRows(N, K-1)
+	overall := make([]bool, N)
+	for packetIndex, position := range hammingPositions(N) {
+		overall[packetIndex] = popcount(position)%2 == 0
+	}
+	return NewMatrixMask(append(rows, overall))
+}
+
+// SingleParityMaskFactory creates single-parity-check-per-group masks: the media packets
+// are split into K groups of consecutive packets with sizes differing by at most one, and
+// each FEC packet is the parity of one group. With K > N the groups are single packets
+// and FEC packet f repeats media packet f*N/K.
+type SingleParityMaskFactory struct{}
+
+// CreateMask creates a single-parity-per-group mask with N media packets and K FEC packets
+func (f *SingleParityMaskFactory) CreateMask(N, K int) (Mask, error) {
+	if N <= 0 || K <= 0 {
+		return nil, fmt.Errorf("invalid parameters for single parity mask: N=%d, K=%d", N, K)
+	}
+
+	rows := make([][]bool, K)
+	for fecIndex := range rows {
+		rows[fecIndex] = make([]bool, N)
+		if K > N {
+			rows[fecIndex][fecIndex*N/K] = true
+		}
+	}
+	for packetIndex := 0; K <= N && packetIndex < N; packetIndex++ {
+		rows[packetIndex*K/N][packetIndex] = true
+	}
+	return NewMatrixMask(rows)
+}
+
+// hammingDataPositions returns the number of data positions of a Hamming code with K parity bits
+func hammingDataPositions(K int) int {
+	if K >= 30 {
+		return 1 << 30
+	}
+	return (1 << K) - K - 1
+}
+
+// hammingPositions returns the codeword positions (1-based) of the first N data bits,
+// i.e. the first N positions that are not powers of two
+func hammingPositions(N int) []int {
+	positions := make([]int, 0, N)
+	for position := 3; len(positions) < N; position++ {
+		if position&(position-1) != 0 {
+			positions = append(positions, position)
+		}
+	}
+	return positions
+}
+
+// hammingRows returns the parity rows of a Hamming code with K parity bits over N data bits
+func hammingRows(N, K int) [][]bool {
+	rows := make([][]bool, K)
+	positions := hammingPositions(N)
+	for fecIndex := range rows {
+		rows[fecIndex] = make([]bool, N)
+		for packetIndex, position := range positions {
+			rows[fecIndex][packetIndex] = position&(1<<fecIndex) != 0
+		}
+	}
+	return rows
+}
+
+// CyclicMaskFactory creates masks from a generator polynomial over GF(2), like the rows
+// of a cyclic code's generator matrix: FEC packet 0 protects the media packets at the
+// nonzero coefficients of the polynomial, and FEC packet f protects the same pattern
+// cyclically shifted by f packets modulo N. A zero Generator uses x^3+x+1, the generator
+// of the cyclic Hamming(7,4) code.
+type CyclicMaskFactory struct {
+	Generator uint64 // bit i is the coefficient of x^i
+}
+
+// defaultCyclicGenerator is x^3+x+1
+const defaultCyclicGenerator = 0b1011
+
+// CreateMask creates a cyclic mask with N media packets and K FEC packets; the degree of
+// the generator must be below N
+func (f *CyclicMaskFactory) CreateMask(N, K int) (Mask, error) {
+	generator := f.Generator
+	if generator == 0 {
+		generator = defaultCyclicGenerator
+	}
+	degree := bits.Len64(generator) - 1
+	if N <= 0 || K <= 0 || degree >= N {
+		return nil, fmt.Errorf("invalid parameters for cyclic mask with generator %#b (degree %d): N=%d, K=%d",
+			generator, degree, N, K)
+	}
+
+	rows := make([][]bool, K)
+	for fecIndex := range rows {
+		rows[fecIndex] = make([]bool, N)
+		for coefficient := 0; coefficient <= degree; coefficient++ {
+			if generator&(1<<coefficient) != 0 {
+				rows[fecIndex][(coefficient+fecIndex)%N] = true
+			}
+		}
+	}
+	return NewMatrixMask(rows)
+}
+
+// ProductCodeMaskFactory creates two-dimensional product-code masks: the media packets are
+// arranged row by row in a Rows x Columns matrix, the first Rows FEC packets are the row
+// parities and the last Columns FEC packets the column parities, so K = Rows+Columns.
+// ProMPEGMaskFactory with RowFEC sends the same parities with the column FEC first.
+// If Rows and Columns are zero, the arrangement with Rows+Columns = K closest to square that
+// holds N packets is chosen; the last matrix row may be incomplete but not empty.
+type ProductCodeMaskFactory struct {
+	Rows    int
+	Columns int
+}
+
+// CreateMask creates a product-code mask with N media packets and K FEC packets
+func (f *ProductCodeMaskFactory) CreateMask(N, K int) (Mask, error) {
+	rows, columns := f.Rows, f.Columns
+	if rows == 0 && columns == 0 {
+		rows, columns = productArrangement(N, K)
+	}
+	if N <= 0 || rows <= 0 || columns <= 0 || rows+columns != K || N > rows*columns || N <= (rows-1)*columns {
+		return nil, fmt.Errorf("invalid parameters for %dx%d product-code mask: N=%d, K=%d", rows, columns, N, K)
+	}
+
+	matrix := make([][]bool, K)
+	for fecIndex := range matrix {
+		matrix[fecIndex] = make([]bool, N)
+	}
+	for packetIndex := 0; packetIndex < N; packetIndex++ {
+		matrix[packetIndex/columns][packetIndex] = true
+		matrix[rows+packetIndex%columns][packetIndex] = true
+	}
+	return NewMatrixMask(matrix)
+}
+
+// productArrangement returns the rows and columns with rows+columns = K, rows <= columns,
+// closest to square whose matrix holds N packets without an empty row, or zeros if there is none
+func productArrangement(N, K int) (int, int) {
+	for rows := K / 2; rows >= 1; rows-- {
+		columns := K - rows
+		if rows*columns >= N && N > (rows-1)*columns {
+			return rows, columns
+		}
+	}
+	return 0, 0
+}
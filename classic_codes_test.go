@@ -0,0 +1,134 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHamming74(t *testing.T) {
+	mask, err := (&HammingMaskFactory{}).CreateMask(4, 3)
+	require.NoError(t, err)
+
+	// Data positions 3, 5, 6, 7
+	assert.Equal(t, [][]bool{
+		{true, true, false, true},
+		{true, false, true, true},
+		{false, true, true, true},
+	}, maskMatrix(mask))
+
+	// Minimum distance 3: any two losses are recoverable, some triple losses are not
+	assert.Equal(t, LossCount(3), CalculateRecoveryCharacteristics(mask).MinLostPacketsForNonRecovery)
+
+	_, err = (&HammingMaskFactory{}).CreateMask(5, 3)
+	assert.Error(t, err, "Hamming code with 3 parity bits protects at most 4 packets")
+}
+
+func TestExtendedHamming84(t *testing.T) {
+	mask, err := (&ExtendedHammingMaskFactory{}).CreateMask(4, 4)
+	require.NoError(t, err)
+
+	// Overall parity covers data at positions with even weight: 3, 5, 6 (not 7)
+	assert.Equal(t, []bool{true, true, true, false}, maskMatrix(mask)[3])
+
+	// The overall parity must cover every codeword bit: it is the XOR of the data bits
+	// and of the three Hamming parities over them
+	for packetIndex := 0; packetIndex < 4; packetIndex++ {
+		coverage := 1 // the data bit itself
+		for fecIndex := 0; fecIndex < 3; fecIndex++ {
+			if mask.IsProtected(packetIndex, fecIndex) {
+				coverage++
+			}
+		}
+		assert.Equal(t, coverage%2 == 1, mask.IsProtected(packetIndex, 3))
+	}
+}
+
+func TestSingleParityMaskFactory(t *testing.T) {
+	mask, err := (&SingleParityMaskFactory{}).CreateMask(7, 3)
+	require.NoError(t, err)
+	assert.Equal(t, [][]bool{
+		{true, true, true, false, false, false, false},
+		{false, false, false, true, true, false, false},
+		{false, false, false, false, false, true, true},
+	}, maskMatrix(mask))
+
+	// More FEC than media packets: each FEC packet repeats one media packet
+	mask, err = (&SingleParityMaskFactory{}).CreateMask(2, 3)
+	require.NoError(t, err)
+	assert.Equal(t, [][]bool{{true, false}, {true, false}, {false, true}}, maskMatrix(mask))
+}
+
+func TestCyclicMaskFactory(t *testing.T) {
+	// x^3+x+1 shifted by one packet per FEC row
+	mask, err := (&CyclicMaskFactory{}).CreateMask(7, 3)
+	require.NoError(t, err)
+	assert.Equal(t, [][]bool{
+		{true, true, false, true, false, false, false},
+		{false, true, true, false, true, false, false},
+		{false, false, true, true, false, true, false},
+	}, maskMatrix(mask))
+
+	// Shifts wrap around modulo N
+	mask, err = (&CyclicMaskFactory{Generator: 0b1011}).CreateMask(4, 2)
+	require.NoError(t, err)
+	assert.Equal(t, [][]bool{
+		{true, true, false, true},
+		{true, true, true, false},
+	}, maskMatrix(mask))
+
+	// x+1 over a full cycle protects every packet twice along a ring, so any two losses
+	// are recoverable by peeling from either side
+	mask, err = (&CyclicMaskFactory{Generator: 0b11}).CreateMask(4, 4)
+	require.NoError(t, err)
+	assert.Equal(t, LossCount(3), CalculateRecoveryCharacteristics(mask).MinLostPacketsForNonRecovery)
+
+	_, err = (&CyclicMaskFactory{}).CreateMask(3, 2)
+	assert.Error(t, err, "degree 3 generator needs at least 4 media packets")
+}
+
+func TestProductCodeMaskFactory(t *testing.T) {
+	// 2x3 arrangement: row parities first, then column parities
+	mask, err := (&ProductCodeMaskFactory{Rows: 2, Columns: 3}).CreateMask(6, 5)
+	require.NoError(t, err)
+	assert.Equal(t, [][]bool{
+		{true, true, true, false, false, false},
+		{false, false, false, true, true, true},
+		{true, false, false, true, false, false},
+		{false, true, false, false, true, false},
+		{false, false, true, false, false, true},
+	}, maskMatrix(mask))
+
+	// Any two losses are recoverable, a media packet lost with both its parities is not
+	assert.Equal(t, LossCount(3), CalculateRecoveryCharacteristics(mask).MinLostPacketsForNonRecovery)
+
+	// Same parities as Pro-MPEG 2D FEC, in a different FEC order
+	proMPEG, err := (&ProMPEGMaskFactory{Columns: 3, Rows: 2, RowFEC: true}).CreateMask(6, 5)
+	require.NoError(t, err)
+	assert.True(t, EquivalentMasks(mask, proMPEG, false))
+
+	// The arrangement is derived from N and K, with an incomplete last row
+	mask, err = (&ProductCodeMaskFactory{}).CreateMask(5, 5)
+	require.NoError(t, err)
+	assert.Equal(t, [][]bool{
+		{true, true, true, false, false},
+		{false, false, false, true, true},
+		{true, false, false, true, false},
+		{false, true, false, false, true},
+		{false, false, true, false, false},
+	}, maskMatrix(mask))
+
+	// Of the 1x4 and 2x3 arrangements holding 4 packets the square one is chosen
+	mask, err = (&ProductCodeMaskFactory{}).CreateMask(4, 5)
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, true, true, false}, maskMatrix(mask)[0])
+	assert.Equal(t, LossCount(3), CalculateRecoveryCharacteristics(mask).MinLostPacketsForNonRecovery)
+
+	_, err = (&ProductCodeMaskFactory{Rows: 2, Columns: 3}).CreateMask(6, 4)
+	assert.Error(t, err, "K must be Rows+Columns")
+	_, err = (&ProductCodeMaskFactory{Rows: 3, Columns: 3}).CreateMask(6, 6)
+	assert.Error(t, err, "empty matrix row")
+	_, err = (&ProductCodeMaskFactory{}).CreateMask(12, 3)
+	assert.Error(t, err, "no arrangement holds 12 packets with 3 FEC packets")
+}
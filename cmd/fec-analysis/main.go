@@ -1,12 +1,16 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"image/color"
 	"image/png"
-	"math"
 	"os"
+	"os/signal"
 	"sort"
+	"strings"
 
 	fec "fec-analysis"
 
@@ -17,25 +21,138 @@ import (
 	"gonum.org/v1/plot/vg/vgimg"
 )
 
+// confidenceZ is the z-score of the 95% confidence intervals of sampled results
+const confidenceZ = 1.96
+
 type LossModelResult struct {
-	Name         string  // "Random" or Gilbert-Elliott variant name
-	LossProb     float64 // Average loss probability
-	RecoveryProb float64 // Recovery probability for this loss model
+	Name         string       // "Random" or Gilbert-Elliott variant name
+	LossProb     float64      // Average loss probability
+	DeliveryRate fec.Estimate // Fraction of media packets available after recovery, 1 - residual loss rate, with Samples 0 when exact
 }
 
 type ConfigResult struct {
-	N                                int
-	K                                int
-	Overhead                         float64
-	Scenarios                        int
-	LossModelResults                 []LossModelResult
-	MinLostPacketsForNonRecovery     int
-	MinConsecutiveLostForNonRecovery int
+	N                  int
+	K                  int
+	Overhead           float64
+	WireOverhead       float64 // FEC bytes over media bytes including RTP and FlexFEC headers, -1 if not representable
+	Goodput            float64 // Media payload bytes available after recovery under the first loss model over all bytes sent, -1 if not representable
+	Scenarios          uint64
+	LossModelResults   []LossModelResult
+	MDSDeliveryRate    fec.Estimate                 // Delivery rate of an ideal MDS code at the same N, K, which cannot partially recover
+	OverheadEfficiency float64                      // Recovery probability gained over no FEC per percent of overhead
+	Characteristics    *fec.RecoveryCharacteristics // nil when sampled, as they need every pattern
 }
 
 func main() {
+	lossModelName := flag.String("loss-model", "reference", "Gilbert-Elliott channel preset to analyze")
+	listLossModels := flag.Bool("list-loss-models", false, "list available channel presets and exit")
+	seed := flag.Int64("seed", fec.DefaultSeed, "seed for all stochastic components of the analysis")
+	maskNames := flag.String("masks", "", "comma-separated mask names or files to analyze instead of the default set (see -list-masks)")
+	listMasks := flag.Bool("list-masks", false, "list available mask names and exit")
+	maskFile := flag.String("mask-file", "", "also analyze a custom mask loaded from a text or JSON file")
+	importantPackets := flag.Int("important-packets", 0, "analyze Bursty and Random masks in WebRTC unequal protection mode with this many important packets")
+	importantWeight := flag.Float64("important-weight", 1, "weight of losing one of the -important-packets in the delivery rate, relative to other packets")
+	payloadSize := flag.Int("payload-size", 1200, "media payload size in bytes used for the FlexFEC wire overhead and goodput columns")
+	maxOverhead := flag.Int("max-overhead", 100, "largest FEC overhead in percent to sweep; values above 100 add K > N configurations")
+	reedSolomon := flag.String("reed-solomon", "", "comma-separated data+parity shard configs (e.g. 10+4,6+3) to evaluate as Reed-Solomon erasure codes instead of block masks")
+	rank := flag.Bool("rank", false, "rank the masks of every configuration by composite score instead of the full analysis")
+	breakEven := flag.Float64("break-even", 0, "print the loss probability at which every mask's recovery probability drops to this threshold instead of the full analysis")
+	compare := flag.String("compare", "", "compare two masks given as name,name: print the probability of the patterns only one of them recovers instead of the full analysis")
+	scoreWeights := flag.String("score-weights", "", "composite score weights for -rank as recovery,burst,minloss,overhead (default 1,0.1,0.1,0.05)")
+	windowed := flag.Bool("windowed", false, "analyze steady-state residual loss of sliding-window FEC instead of block masks")
+	decoding := flag.String("decoding", "peeling", "receiver decoding mode: peeling (iterative XOR recovery) or ml (Gaussian elimination)")
+	maxDepth := flag.Int("max-depth", 0, "only count patterns that peeling recovers within this many rounds of parallel repairs (0 = unlimited)")
+	showProgress := flag.Bool("progress", false, "show the progress of every configuration on stderr")
+	cacheDir := flag.String("cache-dir", "", "directory caching recoverable sets across runs (disabled when empty)")
+	samples := flag.Int("samples", 0, "estimate delivery rates from this many sampled loss patterns per configuration, with 95% confidence intervals, instead of enumerating all patterns (0 = exact)")
+	maxMemory := flag.Uint64("max-memory", 8192, "skip configurations whose analysis is estimated to need more than this many MiB")
+	flag.Parse()
+
+	fec.SetSeed(*seed)
+
+	if *listLossModels {
+		for _, preset := range fec.LossModelPresets() {
+			fmt.Printf("%-16s %s\n", preset.Name, preset.Description)
+		}
+		return
+	}
+
+	if *listMasks {
+		for _, name := range fec.MaskFactoryNames() {
+			fmt.Println(name)
+		}
+		return
+	}
+
+	geModel, err := fec.NewPresetLossModel(*lossModelName)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	decodingMode, err := fec.ParseDecodingMode(*decoding)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *maxDepth < 0 || *maxDepth > 0 && decodingMode != fec.PeelingDecoding {
+		fmt.Printf("Error: -max-depth must be non-negative and requires peeling decoding\n")
+		os.Exit(1)
+	}
+
+	var cache *fec.RecoverableSetCache
+	if *cacheDir != "" {
+		if cache, err = fec.NewRecoverableSetCache(*cacheDir); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		cache.Warn = func(err error) {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	if *samples < 0 {
+		fmt.Printf("Error: -samples must be non-negative\n")
+		os.Exit(1)
+	}
+
+	if *importantWeight < 0 || *importantWeight != 1 && *maxDepth > 0 {
+		fmt.Printf("Error: -important-weight must be non-negative and cannot be combined with -max-depth\n")
+		os.Exit(1)
+	}
+
+	if *windowed {
+		printWindowedAnalysis(geModel)
+		return
+	}
+
+	if *reedSolomon != "" {
+		var configs []fec.ReedSolomonConfig
+		for _, spec := range strings.Split(*reedSolomon, ",") {
+			config, err := fec.ParseReedSolomonConfig(spec)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			configs = append(configs, config)
+		}
+		printReedSolomonAnalysis(geModel, configs)
+		return
+	}
+
 	fmt.Println("FEC Recovery Graph Analysis")
 	fmt.Println("===========================")
+	fmt.Printf("Decoding: %s\n", decodingMode)
+	if *maxDepth > 0 {
+		fmt.Printf("Max recovery depth: %d\n", *maxDepth)
+	}
+	if *importantPackets > 0 && *importantWeight != 1 {
+		fmt.Printf("Important packets: %d, weighted %g\n", *importantPackets, *importantWeight)
+	}
+	if *samples > 0 {
+		fmt.Printf("Sampled: %d loss patterns per configuration, brackets give the 95%% confidence interval\n", *samples)
+		fmt.Printf("Minimum loss counts need every pattern and are not computed when sampling\n")
+	}
 	fmt.Println()
 
 	// Generate test configurations (N, K pairs) - smaller set for testing
@@ -43,8 +160,9 @@ func main() {
 		N int
 		K int
 	}
+	// Configurations are capped at MaxEnumerablePackets wire packets to keep the graphs tractable
 	for N := 1; N <= 12; N++ {
-		for K := 1; K <= N; K++ {
+		for K := 1; K*100 <= N**maxOverhead && N+K <= fec.MaxEnumerablePackets; K++ {
 			configs = append(configs, struct {
 				N int
 				K int
@@ -57,9 +175,85 @@ func main() {
 		name    string
 		factory fec.MaskFactory
 	}{
-		{"Bursty", &fec.GoogleBurstyMaskFactory{}},
-		{"Random", &fec.GoogleRandomMaskFactory{}},
+		{"Bursty", &uepMaskFactory{&fec.GoogleBurstyMaskFactory{}, *importantPackets}},
+		{"Random", &uepMaskFactory{&fec.GoogleRandomMaskFactory{}, *importantPackets}},
 		{"Interleaved", &fec.InterleavedMaskFactory{}},
+		{"Diagonal", &fec.DiagonalInterleavedMaskFactory{Offset: 1}},
+		{"Layered", &fec.LayeredMaskFactory{}},
+		{"Uniform", &fec.RandomMaskFactory{Seed: *seed}},
+		{"LDPC", &fec.LDPCMaskFactory{Seed: *seed}},
+		{"Hamming", &fec.HammingMaskFactory{}},
+		{"Single Parity", &fec.SingleParityMaskFactory{}},
+	}
+	if *maskFile != "" {
+		maskTypes = append(maskTypes, struct {
+			name    string
+			factory fec.MaskFactory
+		}{"Custom", &fec.FileMaskFactory{Path: *maskFile}})
+	}
+	plotOrder := make([]string, 0, len(maskTypes))
+	for _, maskType := range maskTypes {
+		plotOrder = append(plotOrder, maskType.name)
+	}
+
+	// Masks selected by name replace the default set
+	if *maskNames != "" {
+		maskTypes = maskTypes[:0]
+		plotOrder = nil
+		for _, name := range strings.Split(*maskNames, ",") {
+			name = strings.TrimSpace(name)
+			factory, err := fec.LookupMaskFactory(name)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			maskTypes = append(maskTypes, struct {
+				name    string
+				factory fec.MaskFactory
+			}{name, factory})
+			plotOrder = append(plotOrder, name)
+		}
+	}
+
+	if *rank {
+		weights := fec.DefaultScoreWeights()
+		if *scoreWeights != "" {
+			weights, err = fec.ParseScoreWeights(*scoreWeights)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		factories := make(map[string]fec.MaskFactory, len(maskTypes))
+		for _, maskType := range maskTypes {
+			factories[maskType.name] = maskType.factory
+		}
+		for _, config := range configs {
+			printRanking(config.N, config.K, factories, geModel, weights)
+		}
+		return
+	}
+
+	if *compare != "" {
+		names := strings.Split(*compare, ",")
+		if len(names) != 2 {
+			fmt.Printf("Error: -compare needs two comma-separated mask names\n")
+			os.Exit(1)
+		}
+		var factories [2]fec.MaskFactory
+		for i, name := range names {
+			if factories[i], err = fec.LookupMaskFactory(strings.TrimSpace(name)); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		printComparison(configs, names, factories, geModel, decodingMode)
+		return
+	}
+
+	if *breakEven != 0 {
+		printBreakEven(configs, maskTypes, *breakEven, decodingMode, 1/geModel.P10)
+		return
 	}
 
 	// Define single Gilbert-Elliott loss model
@@ -68,21 +262,31 @@ func main() {
 		model fec.LossModel
 	}{
 		// Gilbert-Elliott model
-		{"Gilbert_Elliott", fec.NewGilbertElliotLossModel(0.05, 0.7, 0.05, 0.2)},
+		{"Gilbert_Elliott", geModel},
 	}
 
+	// Interrupting a large sweep cancels the running computation, and abort then ends the
+	// progress line and reports the interruption before exiting; tables of finished mask
+	// types are already printed, the current one is dropped
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
 	// Collect all results for plotting
 	allResults := make(map[string][]ConfigResult)
 
+	// One memo for the whole sweep computes the recoverable set and characteristics once for
+	// all loss models and for masks several types share, e.g. single parity at K=1
+	memo := fec.NewEvaluationMemo()
+
 	for _, maskType := range maskTypes {
 		fmt.Printf("%s Masks:\n", maskType.name)
 
 		// Create dynamic header based on available loss models
-		header := "Overhead\tN\tK\t"
+		header := "Overhead\tWire OH\tGoodput\tN\tK\t"
 		for _, lm := range lossModels {
 			header += fmt.Sprintf("%s (P=%.2f)\t", lm.name, lm.model.GetAverageLossProbability())
 		}
-		header += "Min Lost\tMin Consec"
+		header += "MDS\tGain/%OH\tMin Lost\tMin Consec"
 		fmt.Println(header)
 
 		// Create separator line
@@ -99,62 +303,87 @@ func main() {
 			// Create mask
 			mask, err := maskType.factory.CreateMask(config.N, config.K)
 			if err != nil {
-				continue // Skip unsupported configurations
+				warnSkipped(maskType.name, config.N, config.K, err)
+				continue
 			}
 
-			// Create recovery graph
-			graph := fec.NewRecoveryGraph(mask)
-			totalPackets := config.N + config.K
-
-			// Generate "good" vertices: first N bits are 1, next K bits are any, rest are 0
-			var goodVertices []int
-			allMediaPackets := (1 << config.N) - 1 // First N bits set to 1
-
-			// Generate all combinations of FEC packet delivery states
-			for fecState := 0; fecState < (1 << config.K); fecState++ {
-				goodVertex := allMediaPackets | (fecState << config.N)
-				goodVertices = append(goodVertices, goodVertex)
+			// Refuse configurations that would not fit in memory before allocating anything
+			engine := fec.ClosureEngine
+			if *maxDepth > 0 {
+				engine = fec.DepthLimitedEngine
+			}
+			if cost, err := fec.EstimateCost(config.N, config.K, engine); *samples == 0 && err == nil && cost.Bytes > *maxMemory<<20 {
+				fmt.Fprintf(os.Stderr, "Warning: skipping %s N=%d K=%d, estimated to need %d MiB (limit %d MiB)\n",
+					maskType.name, config.N, config.K, cost.Bytes>>20, *maxMemory)
+				continue
 			}
 
-			// Run multi-source BFS from all good vertices (once per configuration)
-			reachable := fec.BFS(graph, goodVertices)
-			overhead := float64(config.K) * 100.0 / float64(config.N)
-			scenarios := graph.NumVertices()
-
-			// Calculate recovery characteristics (once per configuration)
-			characteristics := fec.CalculateRecoveryCharacteristicsFromReachable(config.N, config.K, reachable)
-
-			// Calculate recovery probabilities for all loss models
+			// Evaluate the mask under every loss model, counting packets repaired in partially
+			// recovered blocks and weighting important packets in the delivery rate
+			var progress fec.ProgressFunc
+			if *showProgress {
+				progress = progressPrinter(fmt.Sprintf("%s N=%d K=%d", maskType.name, config.N, config.K))
+			}
+			opts := fec.EvaluationOptions{Mode: decodingMode, MaxDepth: *maxDepth, Cache: cache, Progress: progress, Samples: *samples}
+			if *importantPackets > 0 && *importantWeight != 1 {
+				opts.Weights = fec.ImportanceWeights(config.N, min(*importantPackets, config.N), *importantWeight)
+			}
+			var evaluations []fec.EvaluationResult
 			var lossModelResults []LossModelResult
 			for _, lossModelConfig := range lossModels {
-				// Calculate recovery probability by summing probabilities of recovered scenarios
-				recoveryProb := 0.0
-				for _, vertex := range reachable {
-					prob := lossModelConfig.model.CalculateProbability(vertex, totalPackets)
-					recoveryProb += prob
-				}
-
-				// Normalize by taking the Nth root to account for needing all N media packets
-				if recoveryProb > 0 && config.N > 0 {
-					recoveryProb = math.Pow(recoveryProb, 1.0/float64(config.N))
+				evaluation, err := memo.EvaluateMask(ctx, mask, lossModelConfig.model, opts)
+				if err != nil {
+					abort(err)
 				}
-
+				evaluations = append(evaluations, evaluation)
 				lossModelResults = append(lossModelResults, LossModelResult{
 					Name:         lossModelConfig.name,
 					LossProb:     lossModelConfig.model.GetAverageLossProbability(),
-					RecoveryProb: recoveryProb,
+					DeliveryRate: evaluation.DeliveryRateEstimate(),
 				})
 			}
+			if *showProgress {
+				fmt.Fprint(os.Stderr, "\r\033[K")
+			}
+
+			overhead := float64(config.K) * 100.0 / float64(config.N)
+			wireOverhead, err := fec.FlexFECWireOverhead(mask, *payloadSize)
+			if err != nil {
+				wireOverhead = -1
+			} else {
+				wireOverhead *= 100.0
+			}
+			// Payload bytes only, from the (weighted) delivery rate of the first loss model
+			goodput, err := fec.FlexFECGoodput(mask, *payloadSize, evaluations[0].DeliveryRate())
+			if err != nil {
+				goodput = -1
+			}
+			// Ideal MDS code under the first loss model, sampled like the masks when enumerating
+			// every pattern is too slow
+			mdsResidual := fec.Estimate{}
+			if *samples > 0 {
+				mdsResidual, err = fec.EstimateMDSResidualLossRate(lossModels[0].model, config.N, config.K, *samples, nil)
+				if err != nil {
+					abort(err)
+				}
+			} else {
+				mdsResidual.Value = fec.MDSResidualLossRate(lossModels[0].model, config.N, config.K)
+			}
+			mdsDelivery := mdsResidual
+			mdsDelivery.Value = 1 - mdsResidual.Value
 
 			// Create single result per configuration with all loss model results
 			results = append(results, ConfigResult{
-				N:                                config.N,
-				K:                                config.K,
-				Overhead:                         overhead,
-				Scenarios:                        scenarios,
-				LossModelResults:                 lossModelResults,
-				MinLostPacketsForNonRecovery:     characteristics.MinLostPacketsForNonRecovery,
-				MinConsecutiveLostForNonRecovery: characteristics.MinConsecutiveLostForNonRecovery,
+				N:                  config.N,
+				K:                  config.K,
+				Overhead:           overhead,
+				WireOverhead:       wireOverhead,
+				Goodput:            goodput,
+				Scenarios:          evaluations[0].Recoverable.NumVertices(),
+				LossModelResults:   lossModelResults,
+				MDSDeliveryRate:    mdsDelivery,
+				OverheadEfficiency: evaluations[0].OverheadEfficiency,
+				Characteristics:    evaluations[0].Characteristics,
 			})
 		}
 
@@ -169,20 +398,38 @@ func main() {
 		// Print sorted results
 		for _, result := range results {
 			// Start with basic config info
-			fmt.Printf("%.1f%%\t\t%d\t%d\t", result.Overhead, result.N, result.K)
+			fmt.Printf("%.1f%%\t\t", result.Overhead)
+			if result.WireOverhead >= 0 {
+				fmt.Printf("%.1f%%\t", result.WireOverhead)
+			} else {
+				fmt.Printf("-\t")
+			}
+			if result.Goodput >= 0 {
+				fmt.Printf("%.1f%%\t", result.Goodput*100)
+			} else {
+				fmt.Printf("-\t")
+			}
+			fmt.Printf("%d\t%d\t", result.N, result.K)
 
-			// Print recovery probability for each loss model
+			// Print the delivery rate for each loss model
 			for _, lmResult := range result.LossModelResults {
-				fmt.Printf("%.6f\t\t", lmResult.RecoveryProb)
+				if lmResult.DeliveryRate.Samples > 0 {
+					fmt.Printf("%s\t", formatEstimate(lmResult.DeliveryRate))
+				} else {
+					fmt.Printf("%s\t\t", formatEstimate(lmResult.DeliveryRate))
+				}
 			}
+			fmt.Printf("%s\t", formatEstimate(result.MDSDeliveryRate))
+			fmt.Printf("%.6f\t", result.OverheadEfficiency)
 
 			// Print characteristics
-			if result.MinLostPacketsForNonRecovery > 0 {
-				fmt.Printf("%d\t%d\n", result.MinLostPacketsForNonRecovery, result.MinConsecutiveLostForNonRecovery)
-			} else if result.MinLostPacketsForNonRecovery == -1 {
-				fmt.Printf("∞\t∞\n")
-			} else {
+			switch {
+			case result.Characteristics == nil:
 				fmt.Printf("-\t-\n")
+			case result.Characteristics.MinLostPacketsForNonRecovery == fec.PerfectRecovery:
+				fmt.Printf("∞\t∞\n")
+			default:
+				fmt.Printf("%d\t%d\n", result.Characteristics.MinLostPacketsForNonRecovery, result.Characteristics.MinConsecutiveLostForNonRecovery)
 			}
 		}
 		fmt.Println()
@@ -192,10 +439,203 @@ func main() {
 	}
 
 	// Create combined plots with both loss models
-	createCombinedPlots(allResults)
+	createCombinedPlots(allResults, plotOrder)
+}
+
+// progressPrinter returns a ProgressFunc that rewrites a percentage line for the labeled step on stderr
+func progressPrinter(label string) fec.ProgressFunc {
+	return func(done, total uint64) {
+		if total > 0 {
+			fmt.Fprintf(os.Stderr, "\r\033[K%s: %.1f%%", label, float64(done)*100/float64(total))
+		}
+	}
+}
+
+// formatEstimate formats an exact value alone and a sampled one with its 95% confidence
+// interval, which is not symmetric near 0 and 1
+func formatEstimate(estimate fec.Estimate) string {
+	if estimate.Samples == 0 {
+		return fmt.Sprintf("%.6f", estimate.Value)
+	}
+	low, high := estimate.ConfidenceInterval(confidenceZ)
+	return fmt.Sprintf("%.6f [%.6f,%.6f]", estimate.Value, low, high)
 }
 
-func createCombinedPlots(allResults map[string][]ConfigResult) {
+// abort reports an interrupted or failed analysis and exits
+func abort(err error) {
+	fmt.Fprintln(os.Stderr)
+	if errors.Is(err, context.Canceled) {
+		fmt.Println("Analysis interrupted")
+	} else {
+		fmt.Printf("Error: %v\n", err)
+	}
+	os.Exit(1)
+}
+
+// printReedSolomonAnalysis prints the recovery of storage-style Reed-Solomon configurations
+// evaluated as ideal MDS codes
+func printReedSolomonAnalysis(model fec.LossModel, configs []fec.ReedSolomonConfig) {
+	fmt.Println("Reed-Solomon Erasure Code Analysis")
+	fmt.Println("==================================")
+	fmt.Println()
+	fmt.Printf("Loss model average loss: %.4f\n\n", model.GetAverageLossProbability())
+	fmt.Println("Overhead\tData\tParity\tRecovery Prob\tMin Lost\tMin Consec")
+	fmt.Println("────────────────────────────────────────────────────────────────────")
+
+	for _, config := range configs {
+		characteristics := config.RecoveryCharacteristics()
+		fmt.Printf("%.1f%%\t\t%d\t%d\t%.6f\t%d\t\t%d\n",
+			config.Overhead()*100, config.DataShards, config.ParityShards, config.RecoveryProbability(model),
+			characteristics.MinLostPacketsForNonRecovery, characteristics.MinConsecutiveLostForNonRecovery)
+	}
+}
+
+// printRanking prints the masks of one configuration ordered by composite score
+func printRanking(N, K int, factories map[string]fec.MaskFactory, model fec.LossModel, weights fec.ScoreWeights) {
+	masks := make(map[string]fec.Mask)
+	for name, factory := range factories {
+		mask, err := factory.CreateMask(N, K)
+		if err != nil {
+			warnSkipped(name, N, K, err)
+			continue
+		}
+		masks[name] = mask
+	}
+	if len(masks) == 0 {
+		return
+	}
+
+	fmt.Printf("N=%d, K=%d:\n", N, K)
+	for i, ranked := range fec.RankMasks(masks, model, weights) {
+		fmt.Printf("  %2d. %-16s %.6f\n", i+1, ranked.Name, ranked.Score)
+	}
+	fmt.Println()
+}
+
+// printBreakEven prints, for every mask and configuration, the random and bursty loss
+// probability at which the recovery probability drops to the threshold; bursts have the mean
+// length of the selected channel preset
+func printBreakEven(configs []struct{ N, K int }, maskTypes []struct {
+	name    string
+	factory fec.MaskFactory
+}, threshold float64, mode fec.DecodingMode, meanBurstLength float64) {
+	fmt.Printf("Break-even loss probability for recovery probability %g\n", threshold)
+	fmt.Printf("Bursty channel: Gilbert model with mean burst length %.1f\n\n", meanBurstLength)
+
+	families := []fec.LossModelFamily{fec.RandomLossFamily, fec.GilbertBurstFamily(meanBurstLength)}
+	for _, maskType := range maskTypes {
+		fmt.Printf("%s Masks:\n", maskType.name)
+		fmt.Println("N\tK\tRandom\t\tBursty")
+		for _, config := range configs {
+			mask, err := maskType.factory.CreateMask(config.N, config.K)
+			if err != nil {
+				warnSkipped(maskType.name, config.N, config.K, err)
+				continue
+			}
+			fmt.Printf("%d\t%d", config.N, config.K)
+			for _, family := range families {
+				if p, err := fec.BreakEvenLossProbability(mask, family, threshold, mode); err != nil {
+					fmt.Printf("\t%-8s", "-")
+				} else {
+					fmt.Printf("\t%.6f", p)
+				}
+			}
+			fmt.Println()
+		}
+		fmt.Println()
+	}
+}
+
+// printComparison prints, for every configuration both masks support, the probability of
+// the delivery patterns only one of the masks recovers and the most likely such pattern
+func printComparison(configs []struct{ N, K int }, names []string, factories [2]fec.MaskFactory, model fec.LossModel, mode fec.DecodingMode) {
+	fmt.Printf("Comparing %s (A) with %s (B), patterns as delivered media|FEC bits\n\n", names[0], names[1])
+	fmt.Println("N\tK\tOnly A\t\tOnly B\t\tGain\t\tTop only A\tTop only B")
+	for _, config := range configs {
+		a, errA := factories[0].CreateMask(config.N, config.K)
+		b, errB := factories[1].CreateMask(config.N, config.K)
+		if err := errors.Join(errA, errB); err != nil {
+			warnSkipped(strings.Join(names, "/"), config.N, config.K, err)
+			continue
+		}
+		comparison, err := fec.CompareMasksWithMode(a, b, model, mode)
+		if err != nil {
+			abort(err)
+		}
+		fmt.Printf("%d\t%d\t%.6f\t%.6f\t%+.6f\t%s\t%s\n", config.N, config.K,
+			comparison.OnlyAProbability, comparison.OnlyBProbability, comparison.Gain(),
+			topPattern(comparison.OnlyA, model, config.N, config.K), topPattern(comparison.OnlyB, model, config.N, config.K))
+	}
+}
+
+// topPattern formats the most likely delivery pattern of the set, or "-" for an empty set
+func topPattern(set *fec.ReachableSet, model fec.LossModel, N, K int) string {
+	top := fec.MostLikelyPatterns(set, model, N+K, 1)
+	if len(top) == 0 {
+		return "-"
+	}
+	return fec.Pattern{Delivered: uint64(top[0].Pattern), N: N, K: K}.String()
+}
+
+// warnSkipped reports a configuration a mask factory does not support
+func warnSkipped(name string, N, K int, err error) {
+	fmt.Fprintf(os.Stderr, "Warning: skipping %s N=%d K=%d: %v\n", name, N, K, err)
+}
+
+// uepMaskFactory creates the masks WebRTC uses in unequal protection mode with a fixed
+// number of important packets (capped at N); zero important packets gives regular masks.
+// WebRTC never sends more FEC than media packets, so for K > N the (N, N) mask is followed
+// by the rows FittedMaskFactory stacks for the remaining K-N FEC packets.
+type uepMaskFactory struct {
+	base interface {
+		fec.MaskFactory
+		CreateMaskUEP(N, K, numImportant int) (fec.Mask, error)
+	}
+	importantPackets int
+}
+
+func (f *uepMaskFactory) CreateMask(N, K int) (fec.Mask, error) {
+	if K <= N {
+		return f.base.CreateMaskUEP(N, K, min(f.importantPackets, N))
+	}
+	mask, err := f.base.CreateMaskUEP(N, N, min(f.importantPackets, N))
+	if err != nil {
+		return nil, err
+	}
+	rest, err := (&fec.FittedMaskFactory{Base: f.base}).CreateMask(N, K-N)
+	if err != nil {
+		return nil, err
+	}
+	return fec.Concat(mask, rest)
+}
+
+// printWindowedAnalysis prints the steady-state residual loss of sliding-window masks
+func printWindowedAnalysis(model fec.LossModel) {
+	fmt.Println("Sliding-Window FEC Steady-State Analysis")
+	fmt.Println("========================================")
+	fmt.Println()
+	fmt.Printf("Loss model average loss: %.4f\n\n", model.GetAverageLossProbability())
+	fmt.Println("Overhead\tInterval\tWindow\tResidual Loss")
+	fmt.Println("────────────────────────────────────────────────────")
+
+	for interval := 1; interval <= 4; interval++ {
+		for window := interval; window <= 3*interval; window += interval {
+			mask, err := fec.NewWindowedMask(window, interval)
+			if err != nil {
+				continue
+			}
+			// Smallest unrolled stream with at least one period clear of both stream ends
+			warmup := (window + interval - 1) / interval
+			residual, err := fec.SteadyStateResidualLoss(mask, model, 2*warmup+1)
+			if err != nil {
+				continue // Unrolled stream too long to enumerate
+			}
+			fmt.Printf("%.1f%%\t\t%d\t\t%d\t%.6f\n", mask.Overhead()*100, interval, window, residual)
+		}
+	}
+}
+
+func createCombinedPlots(allResults map[string][]ConfigResult, maskTypeOrder []string) {
 	// Group results by mask type
 	resultsByMaskType := make(map[string][]ConfigResult)
 
@@ -203,11 +643,19 @@ func createCombinedPlots(allResults map[string][]ConfigResult) {
 		resultsByMaskType[maskType] = results
 	}
 
-	// Colors for the 3 mask types (bright colors for dark background)
+	// Colors for the mask types (bright colors for dark background)
 	maskColors := map[string]color.RGBA{
 		"Bursty":      {R: 100, G: 200, B: 255, A: 255}, // Cyan/Light Blue
 		"Random":      {R: 255, G: 200, B: 100, A: 255}, // Orange/Gold
 		"Interleaved": {R: 255, G: 100, B: 150, A: 255}, // Pink/Rose
+		"Diagonal":    {R: 255, G: 140, B: 90, A: 255},  // Coral
+		"Layered":     {R: 255, G: 255, B: 120, A: 255}, // Pale Yellow
+		"Uniform":     {R: 120, G: 230, B: 210, A: 255}, // Aquamarine
+		"LDPC":        {R: 110, G: 140, B: 255, A: 255}, // Cornflower Blue
+		"Custom":      {R: 230, G: 230, B: 230, A: 255}, // Light Gray
+		// Textbook baselines
+		"Hamming":       {R: 150, G: 255, B: 120, A: 255}, // Light Green
+		"Single Parity": {R: 190, G: 150, B: 255, A: 255}, // Lavender
 	}
 
 	// Create single combined plot
@@ -215,7 +663,7 @@ func createCombinedPlots(allResults map[string][]ConfigResult) {
 
 	// Dark theme styling with bigger fonts
 	textColor := color.RGBA{R: 240, G: 240, B: 240, A: 255} // Light gray text
-	p.Title.Text = "Recovery Probability vs Overhead - Gilbert-Elliott Model"
+	p.Title.Text = "Media Delivery Rate vs Overhead - Gilbert-Elliott Model"
 	p.Title.TextStyle.Font.Size = vg.Points(24)
 	p.Title.TextStyle.Color = textColor
 
@@ -227,7 +675,7 @@ func createCombinedPlots(allResults map[string][]ConfigResult) {
 	p.X.Tick.Color = textColor
 	p.X.Color = textColor
 
-	p.Y.Label.Text = "Recovery Probability"
+	p.Y.Label.Text = "Media Delivery Rate (1 - residual loss)"
 	p.Y.Label.TextStyle.Font.Size = vg.Points(20)
 	p.Y.Label.TextStyle.Color = textColor
 	p.Y.Tick.Label.Font.Size = vg.Points(16)
@@ -242,27 +690,38 @@ func createCombinedPlots(allResults map[string][]ConfigResult) {
 	p.Legend.TextStyle.Font.Size = vg.Points(16)
 	p.Legend.TextStyle.Color = textColor
 
-	// Process each mask type
-	maskTypeOrder := []string{"Bursty", "Random", "Interleaved"}
+	// Colors for masks selected by name without a dedicated color
+	fallbackColors := []color.RGBA{
+		{R: 100, G: 200, B: 255, A: 255},
+		{R: 255, G: 200, B: 100, A: 255},
+		{R: 255, G: 100, B: 150, A: 255},
+		{R: 150, G: 255, B: 120, A: 255},
+		{R: 190, G: 150, B: 255, A: 255},
+		{R: 255, G: 255, B: 120, A: 255},
+	}
 
-	for _, maskType := range maskTypeOrder {
+	// Process each mask type
+	for index, maskType := range maskTypeOrder {
 		results, exists := resultsByMaskType[maskType]
 		if !exists || len(results) == 0 {
 			continue
 		}
 
 		points := processResultsToPoints(results)
-		if len(points) > 0 {
-			maskColor := maskColors[maskType]
+		if len(points.XYs) > 0 {
+			maskColor, ok := maskColors[maskType]
+			if !ok {
+				maskColor = fallbackColors[index%len(fallbackColors)]
+			}
 
 			// Line
-			line, err := plotter.NewLine(points)
+			line, err := plotter.NewLine(points.XYs)
 			if err == nil {
 				line.Color = maskColor
 				line.Width = vg.Points(3)
 
 				// Scatter points
-				scatter, err := plotter.NewScatter(points)
+				scatter, err := plotter.NewScatter(points.XYs)
 				if err == nil {
 					scatter.Color = maskColor
 					scatter.Radius = vg.Points(4)
@@ -271,6 +730,15 @@ func createCombinedPlots(allResults map[string][]ConfigResult) {
 					p.Legend.Add(maskType, line, scatter)
 				}
 			}
+
+			// Error bars of sampled delivery rates
+			if points.sampled {
+				if bars, err := plotter.NewYErrorBars(points); err == nil {
+					bars.Color = maskColor
+					bars.Width = vg.Points(2)
+					p.Add(bars)
+				}
+			}
 		}
 	}
 
@@ -314,43 +782,46 @@ func createCombinedPlots(allResults map[string][]ConfigResult) {
 	}
 }
 
-func processResultsToPoints(results []ConfigResult) plotter.XYs {
-	// Preprocess to keep only highest recovery for each overhead
-	overheadMap := make(map[float64]float64) // overhead -> max recovery probability
+// errorPoints are plot points with the distances of their confidence bounds
+type errorPoints struct {
+	plotter.XYs
+	plotter.YErrors
+	sampled bool // some delivery rate was estimated by sampling
+}
+
+// processResultsToPoints returns the best delivery rate at every overhead, dropping points
+// below an earlier one, with the 95% confidence intervals of sampled rates (zero when exact)
+func processResultsToPoints(results []ConfigResult) errorPoints {
+	// Preprocess to keep only highest delivery rate for each overhead
+	best := make(map[float64]LossModelResult) // overhead -> result with the max delivery rate
 	for _, result := range results {
 		if len(result.LossModelResults) > 0 {
-			recoveryProb := result.LossModelResults[0].RecoveryProb
-			if existing, exists := overheadMap[result.Overhead]; !exists || recoveryProb > existing {
-				overheadMap[result.Overhead] = recoveryProb
+			lmResult := result.LossModelResults[0]
+			if existing, exists := best[result.Overhead]; !exists || lmResult.DeliveryRate.Value > existing.DeliveryRate.Value {
+				best[result.Overhead] = lmResult
 			}
 		}
 	}
 
-	// Convert map to sorted points
-	var points plotter.XYs
-	for overhead, recoveryProb := range overheadMap {
-		points = append(points, plotter.XY{
-			X: overhead,
-			Y: recoveryProb,
-		})
+	// Convert map to points sorted by overhead for proper line plotting
+	overheads := make([]float64, 0, len(best))
+	for overhead := range best {
+		overheads = append(overheads, overhead)
 	}
-
-	// Sort points by overhead for proper line plotting
-	sort.Slice(points, func(i, j int) bool {
-		return points[i].X < points[j].X
-	})
-
-	// Post-process to ensure monotonically increasing recovery probability
-	var monotonicPoints plotter.XYs
-	if len(points) > 0 {
-		monotonicPoints = append(monotonicPoints, points[0])
-
-		for i := 1; i < len(points); i++ {
-			if points[i].Y >= monotonicPoints[len(monotonicPoints)-1].Y {
-				monotonicPoints = append(monotonicPoints, points[i])
-			}
+	sort.Float64s(overheads)
+
+	// Post-process to ensure monotonically increasing delivery rate
+	var points errorPoints
+	for _, overhead := range overheads {
+		lmResult := best[overhead]
+		rate := lmResult.DeliveryRate
+		if len(points.XYs) > 0 && rate.Value < points.XYs[len(points.XYs)-1].Y {
+			continue
 		}
+		low, high := rate.ConfidenceInterval(confidenceZ)
+		points.XYs = append(points.XYs, plotter.XY{X: overhead, Y: rate.Value})
+		points.YErrors = append(points.YErrors, struct{ Low, High float64 }{rate.Value - low, high - rate.Value})
+		points.sampled = points.sampled || rate.Samples > 0
 	}
-
-	return monotonicPoints
+	return points
 }
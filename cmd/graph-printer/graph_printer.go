@@ -14,11 +14,11 @@ func printGraph(file *os.File, graph *fec.RecoveryGraph, N, K int) {
 	fmt.Fprintf(file, "Vertices: %d\n", graph.NumVertices())
 	
 	// Collect all edges as pairs
-	var edgePairs []struct{ from, to int }
-	for vertex := 0; vertex < graph.NumVertices(); vertex++ {
+	var edgePairs []struct{ from, to uint64 }
+	for vertex := uint64(0); vertex < graph.NumVertices(); vertex++ {
 		edges := graph.GetEdges(vertex)
 		for _, edge := range edges {
-			edgePairs = append(edgePairs, struct{ from, to int }{vertex, edge})
+			edgePairs = append(edgePairs, struct{ from, to uint64 }{vertex, edge})
 		}
 	}
 	fmt.Fprintf(file, "Edges: %d\n", len(edgePairs))
@@ -29,7 +29,7 @@ func printGraph(file *os.File, graph *fec.RecoveryGraph, N, K int) {
 	fmt.Fprintf(file, "Format: [Media packets M0-M%d]|[FEC packets F0-F%d]\n", N-1, K-1)
 	fmt.Fprintf(file, "\n")
 
-	for vertex := 0; vertex < graph.NumVertices(); vertex++ {
+	for vertex := uint64(0); vertex < graph.NumVertices(); vertex++ {
 		fmt.Fprintf(file, "Vertex %3d: %s\n", vertex, formatBinaryMask(vertex, N, K))
 	}
 	fmt.Fprintf(file, "\n")
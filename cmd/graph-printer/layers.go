@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	fec "fec-analysis"
+)
+
+// printLayers prints the vertices reachable from the all-media vertices grouped by BFS
+// distance, i.e. by the number of recovery operations they need
+func printLayers(file *os.File, graph *fec.RecoveryGraph, N, K int) {
+	// Vertices are visited in increasing order, so every layer comes out sorted
+	var layers [][]uint64
+	unrecoverable := 0
+	for vertex, distance := range fec.BFSDistances(graph, fec.GoodVertices(N, K)) {
+		if distance < 0 {
+			unrecoverable++
+			continue
+		}
+		for len(layers) <= int(distance) {
+			layers = append(layers, nil)
+		}
+		layers[distance] = append(layers[distance], uint64(vertex))
+	}
+
+	fmt.Fprintf(file, "Layers (recovery operations needed):\n")
+	for distance, vertices := range layers {
+		fmt.Fprintf(file, "Layer %d (%d vertices):\n", distance, len(vertices))
+		for _, vertex := range vertices {
+			fmt.Fprintf(file, "  %s\n", formatBinaryMask(vertex, N, K))
+		}
+	}
+	fmt.Fprintf(file, "Unrecoverable: %d vertices\n", unrecoverable)
+	fmt.Fprintf(file, "\n")
+}
+
+// printRecoveryFrontier prints per number of delivered packets how many delivery patterns are
+// recoverable and how many rounds of parallel peeling repairs they need
+func printRecoveryFrontier(file *os.File, graph *fec.RecoveryGraph) {
+	fmt.Fprintf(file, "Recovery frontier (delivered: recoverable/patterns, patterns per peeling rounds needed):\n")
+	for _, layer := range fec.RecoveryGraphLayers(graph) {
+		steps := make([]string, 0, len(layer.StepCounts))
+		for rounds, count := range layer.StepCounts {
+			if count > 0 {
+				steps = append(steps, fmt.Sprintf("%d:%d", rounds, count))
+			}
+		}
+		line := fmt.Sprintf("  %2d: %d/%d", layer.Delivered, layer.Recoverable, layer.Patterns)
+		if len(steps) > 0 {
+			line += "  " + strings.Join(steps, " ")
+		}
+		fmt.Fprintf(file, "%s\n", line)
+	}
+	fmt.Fprintf(file, "\n")
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -9,6 +10,9 @@ import (
 )
 
 func main() {
+	layered := flag.Bool("layered", false, "also print the recovery frontier and the recoverable vertices grouped by BFS distance")
+	flag.Parse()
+
 	fmt.Println("FEC Graph Printer")
 	fmt.Println("=================")
 	fmt.Println()
@@ -28,6 +32,7 @@ func main() {
 		{"Bursty", &fec.GoogleBurstyMaskFactory{}},
 		{"Random", &fec.GoogleRandomMaskFactory{}},
 		{"Interleaved", &fec.InterleavedMaskFactory{}},
+		{"Layered", &fec.LayeredMaskFactory{}},
 	}
 
 	// Generate graphs for all combinations N=1..6, K=1..N (limited for reasonable output size)
@@ -62,6 +67,10 @@ func main() {
 				
 				// Print graph representation
 				printGraph(file, graph, N, K)
+				if *layered {
+					printRecoveryFrontier(file, graph)
+					printLayers(file, graph, N, K)
+				}
 				graphsGenerated++
 			}
 		}
@@ -3,7 +3,7 @@ package main
 import "fmt"
 
 // formatBinaryMask converts a vertex number to binary representation showing packet status
-func formatBinaryMask(vertex, N, K int) string {
+func formatBinaryMask(vertex uint64, N, K int) string {
 	binaryStr := ""
 	
 	// Build media packets first (bits 0 to N-1) in order M0, M1, M2, ...
@@ -31,7 +31,7 @@ func formatBinaryMask(vertex, N, K int) string {
 }
 
 // explainBits provides a human-readable explanation of which packets are present
-func explainBits(vertex, N, K int) string {
+func explainBits(vertex uint64, N, K int) string {
 	explanation := ""
 	
 	// Check media packets (bits 0 to N-1)
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"image/color"
 	"os"
@@ -14,6 +15,9 @@ import (
 )
 
 func main() {
+	lossModelName := flag.String("loss-model", "reference", "Gilbert-Elliott channel preset to compare against random loss")
+	flag.Parse()
+
 	fmt.Println("FEC Loss Models Probability Printer")
 	fmt.Println("===================================")
 	fmt.Println()
@@ -25,7 +29,11 @@ func main() {
 		return
 	}
 
-	ge := fec.NewGilbertElliotLossModel(0.05, 0.7, 0.05, 0.2)
+	ge, err := fec.NewPresetLossModel(*lossModelName)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
 	// Define loss models to compare
 	lossModels := []struct {
 		name  string
@@ -178,24 +186,18 @@ func generateProbabilityDensityAnalysis(outputDir string, lossModels []struct {
 
 		for N := 2; N <= maxN; N++ {
 			fmt.Fprintf(file, "\nPacket Length N=%d:\n", N)
-			fmt.Fprintf(file, "Lost Packets\tProbability\tCumulative\n")
-			fmt.Fprintf(file, "%s\n", repeatChar('-', 40))
-
-			// Calculate probability for each number of lost packets
-			lostPacketProbs := make([]float64, N+1) // 0 to N lost packets
+			fmt.Fprintf(file, "Lost Packets\tProbability\tCumulative\tTail (>=k)\n")
+			fmt.Fprintf(file, "%s\n", repeatChar('-', 56))
 
-			totalMasks := 1 << N
-			for mask := 0; mask < totalMasks; mask++ {
-				lostCount := countLostPackets(mask, N)
-				prob := lm.model.CalculateProbability(mask, N)
-				lostPacketProbs[lostCount] += prob
-			}
+			// Calculate probability for each number of lost packets (0 to N lost packets)
+			lostPacketProbs := fec.LossCountDistribution(lm.model, N)
 
 			// Print and accumulate for plotting
 			cumulative := 0.0
 			for lostCount := 0; lostCount <= N; lostCount++ {
 				cumulative += lostPacketProbs[lostCount]
-				fmt.Fprintf(file, "%d\t\t%.8f\t%.8f\n", lostCount, lostPacketProbs[lostCount], cumulative)
+				tail := fec.TailLossProbability(lm.model, N, lostCount)
+				fmt.Fprintf(file, "%d\t\t%.8f\t%.8f\t%.8f\n", lostCount, lostPacketProbs[lostCount], cumulative, tail)
 			}
 
 			// Store for plotting
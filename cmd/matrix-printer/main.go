@@ -1,18 +1,48 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 
 	fec "fec-analysis"
 )
 
 func main() {
+	webrtcDir := flag.String("webrtc-tables", "", "directory with WebRTC fec_private_tables_{bursty,random}.h to verify against and print instead of the built-in tables")
+	maskFile := flag.String("mask-file", "", "also print a custom mask loaded from a text or JSON file")
+	imageFormat := flag.String("image-format", "", "also render every mask as a heatmap image in this format (png or svg)")
+	cellSize := flag.Int("cell-size", 16, "size in pixels of one mask cell in rendered images")
+	flag.Parse()
+
+	if *imageFormat != "" && *imageFormat != "png" && *imageFormat != "svg" {
+		fmt.Printf("Error: unknown image format %q (expected png or svg)\n", *imageFormat)
+		return
+	}
+
 	fmt.Println("FEC Matrix Pretty Printer")
 	fmt.Println("========================")
 	fmt.Println()
 
+	burstyFactory := &fec.GoogleBurstyMaskFactory{}
+	randomFactory := &fec.GoogleRandomMaskFactory{}
+	if *webrtcDir != "" {
+		burstyTable, err := loadAndVerify(filepath.Join(*webrtcDir, "fec_private_tables_bursty.h"), fec.WebRTCBurstyTable, burstyFactory.Verify)
+		if err != nil {
+			fmt.Printf("Error loading WebRTC tables: %v\n", err)
+			return
+		}
+		randomTable, err := loadAndVerify(filepath.Join(*webrtcDir, "fec_private_tables_random.h"), fec.WebRTCRandomTable, randomFactory.Verify)
+		if err != nil {
+			fmt.Printf("Error loading WebRTC tables: %v\n", err)
+			return
+		}
+		burstyFactory.Table = burstyTable
+		randomFactory.Table = randomTable
+	}
+
 	// Create output directory if it doesn't exist
 	outputDir := "matrices"
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
@@ -25,9 +55,16 @@ func main() {
 		name    string
 		factory fec.MaskFactory
 	}{
-		{"Bursty", &fec.GoogleBurstyMaskFactory{}},
-		{"Random", &fec.GoogleRandomMaskFactory{}},
+		{"Bursty", burstyFactory},
+		{"Random", randomFactory},
 		{"Interleaved", &fec.InterleavedMaskFactory{}},
+		{"Layered", &fec.LayeredMaskFactory{}},
+	}
+	if *maskFile != "" {
+		maskTypes = append(maskTypes, struct {
+			name    string
+			factory fec.MaskFactory
+		}{"Custom", &fec.FileMaskFactory{Path: *maskFile}})
 	}
 
 	// Generate matrices for all combinations N=1..12, K=1..N
@@ -62,8 +99,16 @@ func main() {
 				fmt.Fprintf(file, "%s\n", repeatChar('-', 30))
 
 				printMatrix(file, mask, N, K)
+				printStructure(file, mask)
 				fmt.Fprintf(file, "\n")
 				matricesGenerated++
+
+				if *imageFormat != "" {
+					imageDir := filepath.Join(outputDir, "images", maskType.name)
+					if err := renderImage(imageDir, mask, *imageFormat, *cellSize); err != nil {
+						fmt.Printf("Error rendering %s N=%d, K=%d: %v\n", maskType.name, N, K, err)
+					}
+				}
 			}
 		}
 
@@ -74,6 +119,45 @@ func main() {
 	fmt.Println("\nMatrix generation complete!")
 }
 
+// loadAndVerify loads a WebRTC mask table and reports how it differs from the built-in table
+func loadAndVerify(path, kind string, verify func(fec.MaskTable) []fec.MaskTableMismatch) (fec.MaskTable, error) {
+	table, err := fec.LoadWebRTCMaskTable(path, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	mismatches := verify(table)
+	if len(mismatches) == 0 {
+		fmt.Printf("%s: %d masks match the built-in %s table\n", path, len(table), kind)
+	} else {
+		fmt.Printf("%s: %d of %d masks differ from the built-in %s table:\n", path, len(mismatches), len(table), kind)
+		for _, mismatch := range mismatches {
+			fmt.Printf("  %s\n", mismatch)
+		}
+	}
+	fmt.Println()
+	return table, nil
+}
+
+// renderImage writes the mask as a heatmap image named N<n>_K<k>.<format> into dir
+func renderImage(dir string, mask fec.Mask, format string, cellSize int) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	filename := filepath.Join(dir, fmt.Sprintf("N%d_K%d.%s", mask.N(), mask.K(), format))
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if format == "svg" {
+		return fec.WriteMaskSVG(file, mask, cellSize)
+	}
+	return fec.WriteMaskPNG(file, mask, cellSize)
+}
+
 // printMatrix pretty-prints a FEC mask matrix to the file
 func printMatrix(file *os.File, mask fec.Mask, N, K int) {
 	// Print column headers (media packet indices)
@@ -105,6 +189,48 @@ func printMatrix(file *os.File, mask fec.Mask, N, K int) {
 	}
 }
 
+// printStructure prints the structural statistics and recovery characteristics of a mask
+func printStructure(file *os.File, mask fec.Mask) {
+	structure := fec.AnalyzeMask(mask)
+	set := fec.RecoverableSet(mask, fec.PeelingDecoding)
+	characteristics, witnesses := fec.CalculateRecoveryCharacteristicsWithWitnesses(mask.N(), mask.K(), set)
+
+	fmt.Fprintf(file, "Row weights:     %v\n", structure.RowWeights)
+	fmt.Fprintf(file, "Column weights:  %v\n", structure.ColumnWeights)
+	fmt.Fprintf(file, "Max row overlap: %d\n", structure.MaxRowOverlap())
+	if len(structure.UnprotectedPackets) > 0 {
+		fmt.Fprintf(file, "Unprotected:     %v\n", structure.UnprotectedPackets)
+	}
+	fmt.Fprintf(file, "Density:         %.3f\n", structure.Density)
+	if characteristics.MinLostPacketsForNonRecovery == fec.PerfectRecovery {
+		fmt.Fprintf(file, "Min lost:        ∞ (consecutive: ∞)\n")
+	} else {
+		fmt.Fprintf(file, "Min lost:        %d (consecutive: %d)\n",
+			characteristics.MinLostPacketsForNonRecovery, characteristics.MinConsecutiveLostForNonRecovery)
+		fmt.Fprintf(file, "Failing pattern: %s\n", witnesses.MinLostPacketsForNonRecovery)
+	}
+	fmt.Fprintf(file, "Min burst:       media %s, FEC %s (with one media loss)\n",
+		formatBurst(int(characteristics.MinMediaBurstForNonRecovery)), formatBurst(int(characteristics.MinFECBurstForNonRecovery)))
+	if packetMinLost, err := fec.PacketMinLostForNonRecovery(mask, fec.PeelingDecoding); err == nil {
+		fmt.Fprintf(file, "Per-packet min:  %v\n", packetMinLost)
+	}
+	if robustness, err := fec.CalculateFECLossRobustness(mask.N(), mask.K(), set); err == nil {
+		minMediaLost := make([]string, len(robustness.MinMediaLost))
+		for lostFEC, length := range robustness.MinMediaLost {
+			minMediaLost[lostFEC] = formatBurst(length)
+		}
+		fmt.Fprintf(file, "Min lost media:  %v by lost FEC\n", minMediaLost)
+	}
+}
+
+// formatBurst formats a minimum failing burst length, ∞ when no burst fails
+func formatBurst(length int) string {
+	if length == fec.PerfectRecovery {
+		return "∞"
+	}
+	return strconv.Itoa(length)
+}
+
 // repeatChar repeats a character n times
 func repeatChar(char rune, n int) string {
 	result := make([]rune, n)
@@ -0,0 +1,54 @@
+package fecanalysis
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// FECConditionedAnalysis holds the recovery metrics of a mask when a fixed subset of its FEC
+// packets is delivered and only media packets are subject to loss
+type FECConditionedAnalysis struct {
+	DeliveredFEC                 uint64    // bit f set if FEC packet f is delivered
+	RecoverableLossPatterns      int       // media loss patterns that are recovered, including no loss
+	MinLostPacketsForNonRecovery LossCount // fewest lost media packets that defeat recovery, PerfectRecovery if none do
+	RecoveryProbability          float64   // probability that all media packets are available given the FEC delivery
+}
+
+// AnalyzeWithDeliveredFEC computes the recoverability of the mask conditioned on exactly the
+// FEC packets in deliveredFEC (bit f for FEC packet f) being delivered. Only the 2^N media
+// loss patterns are decoded, so questions like "what does losing FEC packet 2 cost" are
+// answered without the full sweep. The recovery probability is the model's probability of
+// the recovered patterns divided by the probability of the FEC delivery pattern.
+func AnalyzeWithDeliveredFEC(mask Mask, model LossModel, deliveredFEC uint64) (FECConditionedAnalysis, error) {
+	N, K := mask.N(), mask.K()
+	if err := checkEnumerable(N, K); err != nil {
+		return FECConditionedAnalysis{}, err
+	}
+	if deliveredFEC>>K != 0 {
+		return FECConditionedAnalysis{}, fmt.Errorf("FEC delivery pattern %b refers to FEC packets beyond K=%d", deliveredFEC, K)
+	}
+
+	rows := patternRows(mask)
+	allMedia := uint64(1)<<N - 1
+	analysis := FECConditionedAnalysis{DeliveredFEC: deliveredFEC, MinLostPacketsForNonRecovery: PerfectRecovery}
+	recovered, total := 0.0, 0.0
+	for media := uint64(0); media <= allMedia; media++ {
+		pattern := media | deliveredFEC<<N
+		probability := model.CalculateProbability(int(pattern), N+K)
+		total += probability
+
+		if peelingClosure(rows, N, pattern)&allMedia == allMedia {
+			analysis.RecoverableLossPatterns++
+			recovered += probability
+			continue
+		}
+		lost := LossCount(bits.OnesCount64(allMedia &^ media))
+		if analysis.MinLostPacketsForNonRecovery == PerfectRecovery || lost < analysis.MinLostPacketsForNonRecovery {
+			analysis.MinLostPacketsForNonRecovery = lost
+		}
+	}
+	if total > 0 {
+		analysis.RecoveryProbability = recovered / total
+	}
+	return analysis, nil
+}
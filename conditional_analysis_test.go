@@ -0,0 +1,58 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeWithDeliveredFEC(t *testing.T) {
+	// FEC 0 protects packets 0 and 1, FEC 1 protects packets 2 and 3
+	mask := NewSimpleMask([][]bool{
+		{true, true, false, false},
+		{false, false, true, true},
+	}, 4, 2)
+	model := NewRandomLossModel(0.1)
+
+	all, err := AnalyzeWithDeliveredFEC(mask, model, 0b11)
+	require.NoError(t, err)
+	assert.Equal(t, 9, all.RecoverableLossPatterns, "no loss, or at most one loss per group")
+	assert.Equal(t, LossCount(2), all.MinLostPacketsForNonRecovery)
+	assert.InDelta(t, 0.99*0.99, all.RecoveryProbability, 1e-12)
+
+	// Losing FEC 1 leaves packets 2 and 3 unprotected
+	withoutFEC1, err := AnalyzeWithDeliveredFEC(mask, model, 0b01)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0b01), withoutFEC1.DeliveredFEC)
+	assert.Equal(t, 3, withoutFEC1.RecoverableLossPatterns)
+	assert.Equal(t, LossCount(1), withoutFEC1.MinLostPacketsForNonRecovery)
+	assert.InDelta(t, 0.99*0.81, withoutFEC1.RecoveryProbability, 1e-12)
+
+	none, err := AnalyzeWithDeliveredFEC(mask, model, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, none.RecoverableLossPatterns)
+	assert.InDelta(t, 0.9*0.9*0.9*0.9, none.RecoveryProbability, 1e-12)
+
+	_, err = AnalyzeWithDeliveredFEC(mask, model, 0b100)
+	assert.Error(t, err)
+}
+
+func TestAnalyzeWithDeliveredFECMatchesRecoverableSet(t *testing.T) {
+	mask, err := (&GoogleRandomMaskFactory{}).CreateMask(5, 3)
+	require.NoError(t, err)
+	recoverable := recoverableSet(mask)
+
+	for deliveredFEC := uint64(0); deliveredFEC < 1<<3; deliveredFEC++ {
+		analysis, err := AnalyzeWithDeliveredFEC(mask, NewRandomLossModel(0.2), deliveredFEC)
+		require.NoError(t, err)
+
+		count := 0
+		for media := uint64(0); media < 1<<5; media++ {
+			if recoverable.Contains(media | deliveredFEC<<5) {
+				count++
+			}
+		}
+		assert.Equal(t, count, analysis.RecoverableLossPatterns, "FEC %03b", deliveredFEC)
+	}
+}
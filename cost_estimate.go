@@ -0,0 +1,135 @@
+package fecanalysis
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// AnalysisEngine is an algorithm computing the recoverable set of a mask, see EstimateCost
+type AnalysisEngine int
+
+const (
+	// ClosureEngine is RecoverableSet and FusedRecoveryAnalysis: one pass over all patterns
+	ClosureEngine AnalysisEngine = iota
+	// DepthLimitedEngine is RecoverableSetWithinDepth, which adds a byte per pattern
+	DepthLimitedEngine
+	// BFSEngine is ReachableBFS on a RecoveryGraph computing edges on demand
+	BFSEngine
+	// MaterializedBFSEngine is ReachableBFS on a RecoveryGraph after Materialize
+	MaterializedBFSEngine
+	// FECSubsetEngine is EvaluateByFECSubset, which only decodes patterns losing at most as
+	// many media packets as FEC packets were delivered
+	FECSubsetEngine
+)
+
+// Approximate single-core throughput of the engines under peeling decoding, in nanoseconds
+// per pattern; ML decoding is up to several times slower on the graph engines
+var engineNanosPerPattern = map[AnalysisEngine]float64{
+	ClosureEngine:         25,
+	DepthLimitedEngine:    50,
+	BFSEngine:             30,
+	MaterializedBFSEngine: 120,
+	FECSubsetEngine:       150,
+}
+
+// String returns the name of the engine
+func (e AnalysisEngine) String() string {
+	switch e {
+	case ClosureEngine:
+		return "closure"
+	case DepthLimitedEngine:
+		return "depth-limited"
+	case BFSEngine:
+		return "bfs"
+	case MaterializedBFSEngine:
+		return "materialized-bfs"
+	case FECSubsetEngine:
+		return "fec-subset"
+	default:
+		return fmt.Sprintf("AnalysisEngine(%d)", int(e))
+	}
+}
+
+// CostEstimate is the approximate cost of analyzing an N+K block, see EstimateCost. Values
+// that do not fit saturate at the maximum of their type.
+type CostEstimate struct {
+	Vertices uint64        // delivery patterns the engine visits
+	Edges    uint64        // recovery graph edges, at most one per pattern and delivered media packet
+	Bytes    uint64        // peak memory of the engine's data structures
+	Time     time.Duration // single-core running time
+}
+
+// EstimateCost returns the approximate cost of computing the recoverable set of an N+K block
+// with the engine, without building anything, so callers can refuse analyses that would not
+// fit in memory or finish in time. Memory and edge counts are worst-case bounds that hold
+// for every mask; the time is extrapolated from measured throughput and is only a rough guide.
+func EstimateCost(N, K int, engine AnalysisEngine) (CostEstimate, error) {
+	if N < 0 || K < 0 || N+K > MaxGraphPackets {
+		return CostEstimate{}, fmt.Errorf("invalid block of %d media and %d FEC packets", N, K)
+	}
+	nanosPerPattern, ok := engineNanosPerPattern[engine]
+	if !ok {
+		return CostEstimate{}, fmt.Errorf("unknown engine %v", engine)
+	}
+
+	vertices := math.Ldexp(1, N+K)
+	edges := vertices * float64(N) / 2
+	bitset := vertices / 8
+	visited := vertices
+	var bytes float64
+	switch engine {
+	case ClosureEngine:
+		bytes = bitset
+	case DepthLimitedEngine:
+		bytes = bitset + vertices
+	case BFSEngine:
+		// The queue may hold every vertex
+		bytes = bitset + 8*vertices
+	case MaterializedBFSEngine:
+		// CSR offsets and targets on top of the BFS
+		bytes = bitset + 8*vertices + 8*(vertices+1) + 8*edges
+	case FECSubsetEngine:
+		visited, bytes = fecSubsetPatterns(N, K)
+		edges = 0
+	}
+
+	return CostEstimate{
+		Vertices: saturateUint64(visited),
+		Edges:    saturateUint64(edges),
+		Bytes:    saturateUint64(bytes),
+		Time:     saturateDuration(visited * nanosPerPattern),
+	}, nil
+}
+
+// fecSubsetPatterns returns the number of patterns EvaluateByFECSubset decodes, the sum over
+// d of C(K, d) * sum over s <= d of C(N, s), and the bytes of the candidate and recoverable
+// loss patterns it stores, 8 bytes each
+func fecSubsetPatterns(N, K int) (patterns, bytes float64) {
+	candidates := 0.0    // loss patterns of at most delivered media packets
+	mediaBinomial := 1.0 // C(N, delivered), updated incrementally
+	fecBinomial := 1.0   // C(K, delivered), updated incrementally
+	for delivered := 0; delivered <= K; delivered++ {
+		candidates += mediaBinomial
+		patterns += fecBinomial * candidates
+		mediaBinomial = mediaBinomial * float64(max(N-delivered, 0)) / float64(delivered+1)
+		fecBinomial = fecBinomial * float64(K-delivered) / float64(delivered+1)
+	}
+	return patterns, 8 * (candidates + patterns)
+}
+
+// saturateUint64 converts a non-negative value to uint64, saturating at math.MaxUint64
+func saturateUint64(value float64) uint64 {
+	if value >= math.Ldexp(1, 64) {
+		return math.MaxUint64
+	}
+	return uint64(value)
+}
+
+// saturateDuration converts non-negative nanoseconds to a Duration, saturating at its maximum
+func saturateDuration(nanos float64) time.Duration {
+	if nanos >= math.Ldexp(1, 63) {
+		return math.MaxInt64
+	}
+	return time.Duration(nanos)
+}
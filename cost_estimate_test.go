@@ -0,0 +1,64 @@
+package fecanalysis
+
+import (
+	"math"
+	"math/bits"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateCost(t *testing.T) {
+	closure, err := EstimateCost(20, 4, ClosureEngine)
+	require.NoError(t, err)
+	assert.Equal(t, CostEstimate{
+		Vertices: 1 << 24,
+		Edges:    20 << 23,
+		Bytes:    1 << 21,
+		Time:     time.Duration(25 << 24),
+	}, closure)
+
+	// The graph engines need more memory for the same block
+	previous := closure.Bytes
+	for _, engine := range []AnalysisEngine{DepthLimitedEngine, BFSEngine, MaterializedBFSEngine} {
+		estimate, err := EstimateCost(20, 4, engine)
+		require.NoError(t, err)
+		assert.Equal(t, closure.Vertices, estimate.Vertices, "%s", engine)
+		assert.Greater(t, estimate.Bytes, previous, "%s", engine)
+		previous = estimate.Bytes
+	}
+
+	// Huge blocks saturate instead of overflowing
+	huge, err := EstimateCost(40, 23, MaterializedBFSEngine)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(math.MaxUint64), huge.Bytes)
+	assert.Equal(t, time.Duration(math.MaxInt64), huge.Time)
+
+	_, err = EstimateCost(40, 24, ClosureEngine)
+	assert.Error(t, err)
+	_, err = EstimateCost(-1, 4, ClosureEngine)
+	assert.Error(t, err)
+	_, err = EstimateCost(4, 4, AnalysisEngine(42))
+	assert.Error(t, err)
+}
+
+func TestEstimateCostFECSubset(t *testing.T) {
+	for _, config := range []struct{ N, K int }{{6, 3}, {3, 6}, {12, 4}} {
+		// Count the patterns EvaluateByFECSubset decodes
+		expected := uint64(0)
+		for deliveredFEC := 0; deliveredFEC < 1<<config.K; deliveredFEC++ {
+			for lost := 0; lost < 1<<config.N; lost++ {
+				if bits.OnesCount(uint(lost)) <= bits.OnesCount(uint(deliveredFEC)) {
+					expected++
+				}
+			}
+		}
+
+		estimate, err := EstimateCost(config.N, config.K, FECSubsetEngine)
+		require.NoError(t, err)
+		assert.Equal(t, expected, estimate.Vertices, "N=%d K=%d", config.N, config.K)
+		assert.Zero(t, estimate.Edges)
+	}
+}
@@ -0,0 +1,96 @@
+package fecanalysis
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+	"time"
+)
+
+// PacketTiming describes when the packets of a block are sent and by when the receiver
+// needs every media packet. Network delay is taken as constant, so arrival times are send
+// times shifted by the same amount and only the offsets matter.
+type PacketTiming struct {
+	MediaSpacing time.Duration   // interval between consecutive media packets, media packet 0 is sent at 0
+	FECOffsets   []time.Duration // send time of every FEC packet relative to media packet 0
+	Deadline     time.Duration   // time after its own send time by which a media packet must be available
+}
+
+// UniformPacketTiming returns the timing of a block sent at a constant spacing with the FEC
+// packets right after the last media packet, the way WebRTC sends ULPFEC
+func UniformPacketTiming(N, K int, spacing, deadline time.Duration) PacketTiming {
+	offsets := make([]time.Duration, K)
+	for fecIndex := range offsets {
+		offsets[fecIndex] = time.Duration(N+fecIndex) * spacing
+	}
+	return PacketTiming{MediaSpacing: spacing, FECOffsets: offsets, Deadline: deadline}
+}
+
+// sendTime returns the send time of a packet of a block with N media packets
+func (t PacketTiming) sendTime(N, packet int) time.Duration {
+	if packet < N {
+		return time.Duration(packet) * t.MediaSpacing
+	}
+	return t.FECOffsets[packet-N]
+}
+
+// OnTimeDeliveryRate returns the expected fraction of media packets available by their
+// deadline under the loss model: delivered, or recovered by peeling from packets that all
+// arrived by then. The receiver decodes after every arrival, so a packet is recovered as
+// soon as the last packet of some recovery chain arrives; a packet recovered after its
+// deadline is useless for real-time media like audio, but still helps recover others. With
+// an unbounded deadline this is one minus ResidualLossRate.
+func OnTimeDeliveryRate(mask Mask, model LossModel, timing PacketTiming) (float64, error) {
+	N, K := mask.N(), mask.K()
+	if err := checkEnumerable(N, K); err != nil {
+		return 0, err
+	}
+	if len(timing.FECOffsets) != K {
+		return 0, fmt.Errorf("%d FEC offsets for %d FEC packets", len(timing.FECOffsets), K)
+	}
+	if timing.MediaSpacing < 0 || timing.Deadline < 0 {
+		return 0, fmt.Errorf("media spacing %v and deadline %v must be non-negative", timing.MediaSpacing, timing.Deadline)
+	}
+	if N == 0 {
+		return 1, nil
+	}
+
+	// Packets in arrival order; simultaneous packets arrive media first
+	order := make([]int, N+K)
+	for packet := range order {
+		order[packet] = packet
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return timing.sendTime(N, order[i]) < timing.sendTime(N, order[j])
+	})
+
+	rows := patternRows(mask)
+	allMedia := uint64(1)<<N - 1
+	expectedLate := 0.0
+	VisitPatterns(model, N+K, func(pattern uint64, probability float64) {
+		if pattern&allMedia == allMedia {
+			return
+		}
+
+		// Replay the arrivals, decoding after each one
+		available := uint64(0)
+		late := 0
+		for _, packet := range order {
+			if pattern&(uint64(1)<<packet) == 0 {
+				continue // lost
+			}
+			available |= uint64(1) << packet
+			closure := peelingClosure(rows, N, available)
+			arrival := timing.sendTime(N, packet)
+			for recovered := closure &^ available; recovered != 0; recovered &= recovered - 1 {
+				if packetIndex := bits.TrailingZeros64(recovered); arrival > timing.sendTime(N, packetIndex)+timing.Deadline {
+					late++
+				}
+			}
+			available = closure
+		}
+		late += bits.OnesCount64(allMedia &^ available)
+		expectedLate += probability * float64(late)
+	})
+	return 1 - expectedLate/float64(N), nil
+}
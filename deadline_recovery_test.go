@@ -0,0 +1,54 @@
+package fecanalysis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnTimeDeliveryRate(t *testing.T) {
+	// Single parity over two packets sent 10ms apart, the FEC packet at 20ms: packet 0 is
+	// recovered 20ms after its send time, packet 1 10ms after
+	mask := NewSimpleMask([][]bool{{true, true}}, 2, 1)
+	p := 0.1
+	model := NewRandomLossModel(p)
+	residual, err := ResidualLossRate(mask, model)
+	require.NoError(t, err)
+
+	rate, err := OnTimeDeliveryRate(mask, model, UniformPacketTiming(2, 1, 10*time.Millisecond, 20*time.Millisecond))
+	require.NoError(t, err)
+	assert.InDelta(t, 1-residual, rate, 1e-12)
+	rate, err = OnTimeDeliveryRate(mask, model, UniformPacketTiming(2, 1, 10*time.Millisecond, 15*time.Millisecond))
+	require.NoError(t, err)
+	assert.InDelta(t, 1-residual-p*(1-p)*(1-p)/2, rate, 1e-12)
+	rate, err = OnTimeDeliveryRate(mask, model, UniformPacketTiming(2, 1, 10*time.Millisecond, 0))
+	require.NoError(t, err)
+	assert.InDelta(t, 1-p, rate, 1e-12, "only delivered packets are on time")
+
+	// With the FEC packet sent between the media packets, packet 1 is recovered before it is
+	// due and packet 0 right at its deadline
+	early := PacketTiming{MediaSpacing: 10 * time.Millisecond, FECOffsets: []time.Duration{5 * time.Millisecond}, Deadline: 10 * time.Millisecond}
+	rate, err = OnTimeDeliveryRate(mask, model, early)
+	require.NoError(t, err)
+	assert.InDelta(t, 1-residual, rate, 1e-12)
+
+	// An unbounded deadline gives the residual loss rate
+	bursty, err := (&GoogleBurstyMaskFactory{}).CreateMask(6, 3)
+	require.NoError(t, err)
+	geModel := NewGilbertElliotLossModel(0.01, 0.5, 0.05, 0.3)
+	residual, err = ResidualLossRate(bursty, geModel)
+	require.NoError(t, err)
+	rate, err = OnTimeDeliveryRate(bursty, geModel, UniformPacketTiming(6, 3, time.Millisecond, time.Second))
+	require.NoError(t, err)
+	assert.InDelta(t, 1-residual, rate, 1e-12)
+	tight, err := OnTimeDeliveryRate(bursty, geModel, UniformPacketTiming(6, 3, time.Millisecond, 3*time.Millisecond))
+	require.NoError(t, err)
+	assert.Less(t, tight, rate)
+
+	_, err = OnTimeDeliveryRate(bursty, geModel, UniformPacketTiming(6, 2, time.Millisecond, time.Second))
+	assert.Error(t, err)
+	_, err = OnTimeDeliveryRate(bursty, geModel, UniformPacketTiming(6, 3, time.Millisecond, -time.Second))
+	assert.Error(t, err)
+}
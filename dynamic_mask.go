@@ -0,0 +1,133 @@
+package fecanalysis
+
+import "fmt"
+
+// DynamicMask models an adaptive encoder whose protection depends on the absolute media
+// sequence number, e.g. one that sends a stronger mask for every 4th group. Groups are
+// consecutive and GroupAt maps every sequence number to the mask of its group, so the group
+// starting at sequence number s is protected by GroupAt(s) and the next one starts at
+// s + GroupAt(s).N().
+type DynamicMask interface {
+	// GroupAt returns the mask of the group containing the media packet with the given
+	// sequence number
+	GroupAt(sequenceNumber int) Mask
+}
+
+// MaskCycle is a DynamicMask that cycles through a fixed list of masks, one per group,
+// starting with the first mask at sequence number 0
+type MaskCycle struct {
+	masks       []Mask
+	cycleLength int   // media packets in one cycle
+	starts      []int // offset of every group within the cycle
+}
+
+// NewMaskCycle creates a cycle of groups protected by the given masks in order
+func NewMaskCycle(masks ...Mask) (*MaskCycle, error) {
+	if len(masks) == 0 {
+		return nil, fmt.Errorf("mask cycle needs at least one mask")
+	}
+
+	cycle := &MaskCycle{masks: append([]Mask(nil), masks...)}
+	for _, mask := range masks {
+		if mask.N() <= 0 {
+			return nil, fmt.Errorf("mask cycle contains a mask without media packets")
+		}
+		cycle.starts = append(cycle.starts, cycle.cycleLength)
+		cycle.cycleLength += mask.N()
+	}
+	return cycle, nil
+}
+
+// NewStrongEveryCycle creates the cycle of an encoder that protects every group with base
+// except every period-th group, which gets strong; both masks must have the same N
+func NewStrongEveryCycle(base, strong Mask, period int) (*MaskCycle, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("invalid period %d", period)
+	}
+	if base.N() != strong.N() {
+		return nil, fmt.Errorf("base mask has N=%d, strong mask N=%d", base.N(), strong.N())
+	}
+
+	masks := make([]Mask, period)
+	for i := range masks {
+		masks[i] = base
+	}
+	masks[period-1] = strong
+	return NewMaskCycle(masks...)
+}
+
+// GroupAt returns the mask of the group containing the given sequence number; the cycle
+// repeats every cycleLength media packets, for negative sequence numbers too
+func (c *MaskCycle) GroupAt(sequenceNumber int) Mask {
+	offset := ((sequenceNumber % c.cycleLength) + c.cycleLength) % c.cycleLength
+	group := len(c.starts) - 1
+	for group > 0 && c.starts[group] > offset {
+		group--
+	}
+	return c.masks[group]
+}
+
+// Groups returns the number of groups in one cycle
+func (c *MaskCycle) Groups() int {
+	return len(c.masks)
+}
+
+// GroupAnalysis holds the analysis of one group of a dynamic mask
+type GroupAnalysis struct {
+	SequenceNumber      int // sequence number of the first media packet of the group
+	N                   int
+	K                   int
+	RecoveryProbability float64
+	Characteristics     RecoveryCharacteristics
+}
+
+// DynamicMaskAnalysis summarizes a dynamic mask over a number of consecutive groups
+type DynamicMaskAnalysis struct {
+	Groups                       []GroupAnalysis
+	Overhead                     float64   // total FEC packets over total media packets
+	MeanRecoveryProbability      float64   // average recovery probability of a group
+	WorstRecoveryProbability     float64   // recovery probability of the weakest group
+	MinLostPacketsForNonRecovery LossCount // smallest MinLostPacketsForNonRecovery over the groups, PerfectRecovery if all recover everything
+}
+
+// AnalyzeDynamicMask analyzes the given number of consecutive groups starting at sequence
+// number start, e.g. one full cycle of a MaskCycle. Every group is evaluated independently
+// under the model, like a block mask.
+func AnalyzeDynamicMask(dynamic DynamicMask, model LossModel, start, groups int) (DynamicMaskAnalysis, error) {
+	if groups <= 0 {
+		return DynamicMaskAnalysis{}, fmt.Errorf("invalid number of groups %d", groups)
+	}
+
+	analysis := DynamicMaskAnalysis{WorstRecoveryProbability: 1, MinLostPacketsForNonRecovery: PerfectRecovery}
+	totalN, totalK := 0, 0
+	sequenceNumber := start
+	for i := 0; i < groups; i++ {
+		mask := dynamic.GroupAt(sequenceNumber)
+		if mask.N() <= 0 {
+			return DynamicMaskAnalysis{}, fmt.Errorf("group at sequence number %d has no media packets", sequenceNumber)
+		}
+
+		group := GroupAnalysis{
+			SequenceNumber:      sequenceNumber,
+			N:                   mask.N(),
+			K:                   mask.K(),
+			RecoveryProbability: RecoveryProbability(mask, model),
+			Characteristics:     CalculateRecoveryCharacteristics(mask),
+		}
+		analysis.Groups = append(analysis.Groups, group)
+
+		analysis.MeanRecoveryProbability += group.RecoveryProbability / float64(groups)
+		analysis.WorstRecoveryProbability = min(analysis.WorstRecoveryProbability, group.RecoveryProbability)
+		if minLost := group.Characteristics.MinLostPacketsForNonRecovery; minLost != PerfectRecovery &&
+			(analysis.MinLostPacketsForNonRecovery == PerfectRecovery || minLost < analysis.MinLostPacketsForNonRecovery) {
+			analysis.MinLostPacketsForNonRecovery = minLost
+		}
+
+		totalN += mask.N()
+		totalK += mask.K()
+		sequenceNumber += mask.N()
+	}
+
+	analysis.Overhead = float64(totalK) / float64(totalN)
+	return analysis, nil
+}
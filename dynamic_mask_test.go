@@ -0,0 +1,61 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaskCycle(t *testing.T) {
+	small, err := (&InterleavedMaskFactory{}).CreateMask(2, 1)
+	require.NoError(t, err)
+	large, err := (&InterleavedMaskFactory{}).CreateMask(3, 1)
+	require.NoError(t, err)
+
+	cycle, err := NewMaskCycle(small, large)
+	require.NoError(t, err)
+	assert.Equal(t, 2, cycle.Groups())
+
+	// Groups start at 0, 2, 5, 7, ...
+	for sequenceNumber, expected := range []Mask{small, small, large, large, large, small, small, large} {
+		assert.Same(t, expected, cycle.GroupAt(sequenceNumber), "sequence number %d", sequenceNumber)
+	}
+	assert.Same(t, large, cycle.GroupAt(-1))
+
+	_, err = NewMaskCycle()
+	assert.Error(t, err)
+}
+
+func TestAnalyzeDynamicMask(t *testing.T) {
+	base, err := (&GoogleRandomMaskFactory{}).CreateMask(4, 1)
+	require.NoError(t, err)
+	strong, err := (&GoogleRandomMaskFactory{}).CreateMask(4, 4)
+	require.NoError(t, err)
+
+	cycle, err := NewStrongEveryCycle(base, strong, 4)
+	require.NoError(t, err)
+
+	model := NewGilbertLossModel(0.5, 0.05, 0.5)
+	analysis, err := AnalyzeDynamicMask(cycle, model, 0, cycle.Groups())
+	require.NoError(t, err)
+
+	require.Len(t, analysis.Groups, 4)
+	assert.Equal(t, []int{0, 4, 8, 12}, []int{
+		analysis.Groups[0].SequenceNumber, analysis.Groups[1].SequenceNumber,
+		analysis.Groups[2].SequenceNumber, analysis.Groups[3].SequenceNumber,
+	})
+	assert.Equal(t, 4, analysis.Groups[3].K)
+	assert.InDelta(t, 7.0/16.0, analysis.Overhead, 1e-12)
+
+	baseProbability := RecoveryProbability(base, model)
+	strongProbability := RecoveryProbability(strong, model)
+	assert.InDelta(t, (3*baseProbability+strongProbability)/4, analysis.MeanRecoveryProbability, 1e-12)
+	assert.InDelta(t, baseProbability, analysis.WorstRecoveryProbability, 1e-12)
+	assert.Equal(t, LossCount(2), analysis.MinLostPacketsForNonRecovery)
+
+	_, err = NewStrongEveryCycle(base, NewSimpleMask([][]bool{{true}}, 1, 1), 4)
+	assert.Error(t, err)
+	_, err = AnalyzeDynamicMask(cycle, model, 0, 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,164 @@
+package fecanalysis
+
+import (
+	"fmt"
+	"sync"
+)
+
+// EmpiricalLossModel implements a loss model backed by a histogram of observed delivery
+// patterns over windows of WindowLength consecutive packets. Bit i of a pattern is set
+// if packet i of the window was delivered, matching the vertex layout of LossModel.
+//
+// Probabilities are smoothed with a parametric fallback model acting as a prior worth
+// PriorWeight pseudo-observations:
+//
+//	P(pattern) = (count(pattern) + PriorWeight * Fallback(pattern)) / (total + PriorWeight)
+//
+// so patterns never seen in the capture still get the probability of the fitted model.
+type EmpiricalLossModel struct {
+	WindowLength int         // number of packets in each observed window
+	Counts       map[int]int // observed delivery pattern -> number of windows
+	Fallback     LossModel   // parametric model used for unseen patterns
+	PriorWeight  float64     // pseudo-observation weight of the fallback model
+
+	total        int // total number of observed windows
+	lostPackets  int // total number of lost packets over all windows
+	marginals    map[int]map[int]int
+	marginalsMux sync.Mutex
+}
+
+// NewEmpiricalLossModel creates an empirical loss model from a pattern histogram.
+// If fallback is nil, a Gilbert model fitted to the loss transitions inside the
+// observed windows is used.
+func NewEmpiricalLossModel(windowLength int, counts map[int]int, fallback LossModel, priorWeight float64) (*EmpiricalLossModel, error) {
+	if windowLength <= 0 || windowLength > 62 {
+		return nil, fmt.Errorf("invalid window length for empirical loss model: %d", windowLength)
+	}
+	if priorWeight < 0 {
+		return nil, fmt.Errorf("prior weight must be non-negative, got %f", priorWeight)
+	}
+
+	model := &EmpiricalLossModel{
+		WindowLength: windowLength,
+		Counts:       make(map[int]int, len(counts)),
+		PriorWeight:  priorWeight,
+		marginals:    make(map[int]map[int]int),
+	}
+
+	allDelivered := (1 << windowLength) - 1
+	for pattern, count := range counts {
+		if pattern < 0 || pattern > allDelivered {
+			return nil, fmt.Errorf("pattern %b does not fit in window of %d packets", pattern, windowLength)
+		}
+		if count < 0 {
+			return nil, fmt.Errorf("negative count %d for pattern %b", count, pattern)
+		}
+		if count == 0 {
+			continue
+		}
+		model.Counts[pattern] += count
+		model.total += count
+		model.lostPackets += count * (windowLength - popcount(pattern))
+	}
+
+	if model.total == 0 && priorWeight == 0 {
+		return nil, fmt.Errorf("empirical loss model needs at least one observed window or a positive prior weight")
+	}
+
+	if fallback == nil {
+		fallback = fitGilbertFromWindows(windowLength, model.Counts)
+	}
+	model.Fallback = fallback
+
+	return model, nil
+}
+
+// CalculateProbability calculates the probability of a delivery pattern of N packets.
+// For N shorter than the window the histogram is marginalized over the first N packets,
+// for longer groups the fallback model is used.
+func (m *EmpiricalLossModel) CalculateProbability(vertex int, N int) float64 {
+	if N <= 0 {
+		return 0.0
+	}
+	if N > m.WindowLength {
+		return m.Fallback.CalculateProbability(vertex, N)
+	}
+
+	count := 0
+	if N == m.WindowLength {
+		count = m.Counts[vertex]
+	} else {
+		count = m.marginalCounts(N)[vertex]
+	}
+
+	prior := m.PriorWeight * m.Fallback.CalculateProbability(vertex, N)
+	return (float64(count) + prior) / (float64(m.total) + m.PriorWeight)
+}
+
+// marginalCounts returns the histogram of the first N packets of every window
+func (m *EmpiricalLossModel) marginalCounts(N int) map[int]int {
+	m.marginalsMux.Lock()
+	defer m.marginalsMux.Unlock()
+
+	if marginal, exists := m.marginals[N]; exists {
+		return marginal
+	}
+
+	prefixMask := (1 << N) - 1
+	marginal := make(map[int]int)
+	for pattern, count := range m.Counts {
+		marginal[pattern&prefixMask] += count
+	}
+	m.marginals[N] = marginal
+	return marginal
+}
+
+// GetAverageLossProbability returns the observed packet loss rate, smoothed with the fallback model
+func (m *EmpiricalLossModel) GetAverageLossProbability() float64 {
+	observedPackets := float64(m.total * m.WindowLength)
+	prior := m.PriorWeight * float64(m.WindowLength)
+	return (float64(m.lostPackets) + prior*m.Fallback.GetAverageLossProbability()) / (observedPackets + prior)
+}
+
+// TotalWindows returns the number of observed windows
+func (m *EmpiricalLossModel) TotalWindows() int {
+	return m.total
+}
+
+// fitGilbertFromWindows fits a Gilbert model (loss-free good state, loss-only bad state)
+// to the delivered/lost transitions observed inside the windows
+func fitGilbertFromWindows(windowLength int, counts map[int]int) *GilbertElliotLossModel {
+	var deliveredToLost, delivered, lostToDelivered, lost int
+	for pattern, count := range counts {
+		for i := 1; i < windowLength; i++ {
+			prevDelivered := pattern&(1<<(i-1)) != 0
+			currDelivered := pattern&(1<<i) != 0
+			if prevDelivered {
+				delivered += count
+				if !currDelivered {
+					deliveredToLost += count
+				}
+			} else {
+				lost += count
+				if currDelivered {
+					lostToDelivered += count
+				}
+			}
+		}
+	}
+	return newGilbertFromTransitions(deliveredToLost, delivered, lostToDelivered, lost)
+}
+
+// newGilbertFromTransitions creates a Gilbert model from transition counts:
+// P01 = P(lost | previous delivered), P10 = P(delivered | previous lost)
+func newGilbertFromTransitions(deliveredToLost, delivered, lostToDelivered, lost int) *GilbertElliotLossModel {
+	p01 := 0.0
+	if delivered > 0 {
+		p01 = float64(deliveredToLost) / float64(delivered)
+	}
+	p10 := 1.0 // no observed losses: leave the bad state immediately
+	if lost > 0 {
+		p10 = float64(lostToDelivered) / float64(lost)
+	}
+	return NewGilbertLossModel(1.0, p01, p10)
+}
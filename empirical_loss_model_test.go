@@ -0,0 +1,70 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmpiricalLossModelSumsToOne(t *testing.T) {
+	counts := map[int]int{
+		0b1111: 90,
+		0b1101: 5,
+		0b0011: 3,
+		0b0000: 2,
+	}
+	model, err := NewEmpiricalLossModel(4, counts, nil, 2.0)
+	require.NoError(t, err)
+	assert.Equal(t, 100, model.TotalWindows())
+
+	for N := 1; N <= 6; N++ {
+		total := 0.0
+		for vertex := 0; vertex < (1 << N); vertex++ {
+			total += model.CalculateProbability(vertex, N)
+		}
+		assert.InDelta(t, 1.0, total, 1e-9, "N=%d", N)
+	}
+}
+
+func TestEmpiricalLossModelFrequencies(t *testing.T) {
+	counts := map[int]int{0b111: 3, 0b101: 1}
+	fallback := NewRandomLossModel(0.1)
+	model, err := NewEmpiricalLossModel(3, counts, fallback, 0)
+	require.NoError(t, err)
+
+	// Without a prior the model reproduces the observed frequencies
+	assert.InDelta(t, 0.75, model.CalculateProbability(0b111, 3), 1e-12)
+	assert.InDelta(t, 0.25, model.CalculateProbability(0b101, 3), 1e-12)
+	assert.InDelta(t, 0.0, model.CalculateProbability(0b000, 3), 1e-12)
+
+	// Marginal of the first two packets: 0b11 in three windows, 0b01 in one
+	assert.InDelta(t, 0.75, model.CalculateProbability(0b11, 2), 1e-12)
+	assert.InDelta(t, 0.25, model.CalculateProbability(0b01, 2), 1e-12)
+
+	// Longer groups fall back to the parametric model
+	assert.Equal(t, fallback.CalculateProbability(0b1010, 4), model.CalculateProbability(0b1010, 4))
+
+	assert.InDelta(t, 1.0/12.0, model.GetAverageLossProbability(), 1e-12)
+}
+
+func TestEmpiricalLossModelUnseenPatternsUseFallback(t *testing.T) {
+	counts := map[int]int{0b11: 10}
+	fallback := NewRandomLossModel(0.2)
+	model, err := NewEmpiricalLossModel(2, counts, fallback, 10)
+	require.NoError(t, err)
+
+	// Unseen pattern gets half of the fallback probability (prior weight equals observations)
+	assert.InDelta(t, 0.5*fallback.CalculateProbability(0b00, 2), model.CalculateProbability(0b00, 2), 1e-12)
+}
+
+func TestEmpiricalLossModelInvalidInput(t *testing.T) {
+	_, err := NewEmpiricalLossModel(0, map[int]int{}, nil, 1)
+	assert.Error(t, err)
+
+	_, err = NewEmpiricalLossModel(2, map[int]int{0b111: 1}, nil, 1)
+	assert.Error(t, err)
+
+	_, err = NewEmpiricalLossModel(2, map[int]int{}, nil, 0)
+	assert.Error(t, err)
+}
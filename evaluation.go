@@ -0,0 +1,182 @@
+package fecanalysis
+
+import (
+	"context"
+	"fmt"
+)
+
+// EvaluationOptions configures EvaluateMask; the zero value evaluates peeling decoding
+// exactly without a cache
+type EvaluationOptions struct {
+	Mode     DecodingMode         // decoding mode of the receiver
+	MaxDepth int                  // only count patterns peeling recovers within this many rounds (0 = unlimited)
+	Weights  []float64            // importance of every media packet in the residual loss, nil for equal weights
+	Cache    *RecoverableSetCache // cache of recoverable sets across runs, nil to always compute them
+	Progress ProgressFunc         // receives the progress of every phase, may be nil
+	Samples  int                  // estimate the probabilities from this many sampled patterns instead (0 = exact)
+	Rand     RandSource           // source of the sampled patterns, nil for one derived from the package seed
+}
+
+// EvaluationResult holds the metrics of a mask under a loss model, see EvaluateMask
+type EvaluationResult struct {
+	Recoverable                 *ReachableSet            `json:"-"`                              // delivery patterns the receiver fully recovers
+	RecoveryProbability         float64                  `json:"recovery_probability"`           // probability that every media packet is available
+	ResidualLoss                float64                  `json:"residual_loss"`                  // expected (weighted) fraction of media packets still missing, partial recovery included
+	OverheadEfficiency          float64                  `json:"overhead_efficiency"`            // recovery probability gained over no FEC per percent of overhead, 0 without FEC packets
+	Characteristics             *RecoveryCharacteristics `json:"characteristics,omitempty"`      // loss counts and bursts that make recovery fail, nil when sampled
+	Samples                     int                      `json:"samples"`                        // sampled patterns, 0 when the metrics are exact
+	RecoveryProbabilityStdError float64                  `json:"recovery_probability_std_error"` // standard error of RecoveryProbability, 0 when exact
+	ResidualLossStdError        float64                  `json:"residual_loss_std_error"`        // standard error of ResidualLoss, 0 when exact
+}
+
+// DeliveryRate returns the expected fraction of media packets available after recovery
+func (r EvaluationResult) DeliveryRate() float64 {
+	return 1 - r.ResidualLoss
+}
+
+// DeliveryRateEstimate returns the delivery rate with its standard error, whose
+// ConfidenceInterval gives error bars for sampled results and a point for exact ones
+func (r EvaluationResult) DeliveryRateEstimate() Estimate {
+	return Estimate{Value: r.DeliveryRate(), StdError: r.ResidualLossStdError, Samples: r.Samples}
+}
+
+// EvaluateMask computes the recoverable set of the mask and every metric derived from it
+// under the loss model, picking the fastest engine for the options: a cached set (see
+// CachedRecoverableSet), the fused single-pass analysis for peeling under a Gilbert-Elliott model, or the closure otherwise.
+// Tools and user code share it instead of combining the engines by hand.
+//
+// With opts.Samples set, the probabilities are instead estimated by decoding sampled
+// patterns, with their standard errors, for blocks too large to enumerate; the recoverable
+// set and the characteristics, which need every pattern, are nil.
+func EvaluateMask(mask Mask, model LossModel, opts EvaluationOptions) (EvaluationResult, error) {
+	return EvaluateMaskContext(context.Background(), mask, model, opts)
+}
+
+// EvaluateMaskContext is EvaluateMask that stops with the context error once ctx is canceled
+func EvaluateMaskContext(ctx context.Context, mask Mask, model LossModel, opts EvaluationOptions) (EvaluationResult, error) {
+	return evaluateMask(ctx, mask, model, opts, nil)
+}
+
+// EvaluateMaskModels is EvaluateMaskContext under every loss model, computing the recoverable
+// set and characteristics, which do not depend on the model, only once
+func EvaluateMaskModels(ctx context.Context, mask Mask, models []LossModel, opts EvaluationOptions) ([]EvaluationResult, error) {
+	results := make([]EvaluationResult, 0, len(models))
+	for _, model := range models {
+		var known *EvaluationResult
+		if len(results) > 0 {
+			known = &results[0]
+		}
+		result, err := evaluateMask(ctx, mask, model, opts, known)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// evaluateMask is EvaluateMaskContext that reuses the recoverable set and characteristics of
+// known, which must match the mask and options, instead of computing them when not nil
+func evaluateMask(ctx context.Context, mask Mask, model LossModel, opts EvaluationOptions, known *EvaluationResult) (EvaluationResult, error) {
+	N, K := mask.N(), mask.K()
+	if err := checkGraphPackets(N, K); err != nil {
+		return EvaluationResult{}, err
+	}
+	if opts.MaxDepth < 0 || opts.MaxDepth > 0 && opts.Mode != PeelingDecoding {
+		return EvaluationResult{}, fmt.Errorf("max depth %d must be non-negative and requires peeling decoding", opts.MaxDepth)
+	}
+	if opts.MaxDepth > 0 && opts.Weights != nil {
+		return EvaluationResult{}, fmt.Errorf("importance weights cannot be combined with a max depth")
+	}
+
+	if opts.Samples > 0 {
+		return estimateMask(mask, model, opts)
+	}
+	if err := checkEnumerable(N, K); err != nil {
+		return EvaluationResult{}, err
+	}
+
+	// Recoverable set, with the probabilities of the fused analysis when it runs
+	var result EvaluationResult
+	var fused *FusedAnalysis
+	geModel, isGilbertElliott := model.(*GilbertElliotLossModel)
+	var err error
+	switch {
+	case known != nil:
+		result.Recoverable = known.Recoverable
+	case opts.MaxDepth > 0:
+		if result.Recoverable, err = RecoverableSetWithinDepthContext(ctx, mask, opts.MaxDepth, opts.Progress); err != nil {
+			return EvaluationResult{}, err
+		}
+	case opts.Cache != nil:
+		if result.Recoverable, err = CachedRecoverableSet(ctx, opts.Cache, mask, opts.Mode, opts.Progress); err != nil {
+			return EvaluationResult{}, err
+		}
+	case opts.Mode == PeelingDecoding && isGilbertElliott:
+		if fused, err = FusedRecoveryAnalysis(ctx, mask, geModel, opts.Progress); err != nil {
+			return EvaluationResult{}, err
+		}
+		result.Recoverable = fused.Recoverable
+	default:
+		if result.Recoverable, err = RecoverableSetContext(ctx, mask, opts.Mode, opts.Progress); err != nil {
+			return EvaluationResult{}, err
+		}
+	}
+	// Probabilities under the model
+	if fused != nil {
+		result.RecoveryProbability = fused.RecoveryProbability
+	} else {
+		result.RecoveryProbability = SumProbabilities(result.Recoverable, model, N+K, 0)
+	}
+	switch {
+	case opts.Weights != nil:
+		result.ResidualLoss, err = WeightedResidualLossRate(mask, model, opts.Mode, opts.Weights)
+	case fused != nil:
+		result.ResidualLoss = fused.ResidualMediaLoss
+	case opts.MaxDepth > 0:
+		result.ResidualLoss, err = ResidualLossRateWithinDepth(mask, model, opts.MaxDepth)
+	default:
+		result.ResidualLoss, err = ResidualLossRateWithMode(mask, model, opts.Mode)
+	}
+	if err != nil {
+		return EvaluationResult{}, err
+	}
+	if K > 0 && N > 0 {
+		result.OverheadEfficiency = overheadEfficiency(result.RecoveryProbability, model, N, K)
+	}
+
+	if known != nil {
+		result.Characteristics = known.Characteristics
+		return result, nil
+	}
+	characteristics, err := CalculateRecoveryCharacteristicsFromSetContext(ctx, N, K, result.Recoverable, opts.Progress)
+	if err != nil {
+		return EvaluationResult{}, err
+	}
+	result.Characteristics = &characteristics
+	return result, nil
+}
+
+// estimateMask is EvaluateMask estimating the probabilities from opts.Samples patterns
+func estimateMask(mask Mask, model LossModel, opts EvaluationOptions) (EvaluationResult, error) {
+	recover := recoveryFunc(mask, opts.Mode)
+	if opts.MaxDepth > 0 {
+		recover = depthLimitedRecoveryFunc(mask, opts.MaxDepth)
+	}
+	recovery, residual, err := estimateRecovery(mask.N(), mask.K(), model, opts.Weights, opts.Samples, opts.Rand, recover)
+	if err != nil {
+		return EvaluationResult{}, err
+	}
+
+	result := EvaluationResult{
+		RecoveryProbability:         recovery.Value,
+		ResidualLoss:                residual.Value,
+		Samples:                     opts.Samples,
+		RecoveryProbabilityStdError: recovery.StdError,
+		ResidualLossStdError:        residual.StdError,
+	}
+	if N, K := mask.N(), mask.K(); N > 0 && K > 0 {
+		result.OverheadEfficiency = overheadEfficiency(result.RecoveryProbability, model, N, K)
+	}
+	return result, nil
+}
@@ -0,0 +1,107 @@
+package fecanalysis
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sync"
+)
+
+// EvaluationMemo memoizes EvaluateMask and the recovery characteristics in memory, so
+// parameter sweeps that revisit a mask, e.g. under several loss models, compute its
+// recoverable set and characteristics once. Masks are keyed by their exact rows rather than
+// their Fingerprint, because permuting FEC packets changes burst metrics and the
+// probabilities of correlated loss models; loss models are keyed by identity. Sampled
+// evaluations are not memoized. A memo keeps every set it computed, so use one per sweep.
+// It is safe for concurrent use.
+type EvaluationMemo struct {
+	mu          sync.Mutex
+	sets        map[memoSetKey]EvaluationResult        // recoverable sets and characteristics
+	evaluations map[memoEvaluationKey]EvaluationResult // complete results
+}
+
+// memoSetKey identifies a recoverable set: the mask rows, the decoding mode and the depth
+type memoSetKey struct {
+	mask     string
+	mode     DecodingMode
+	maxDepth int
+}
+
+// memoEvaluationKey identifies an EvaluateMask result
+type memoEvaluationKey struct {
+	set     memoSetKey
+	model   LossModel
+	weights string
+}
+
+// NewEvaluationMemo returns an empty memo
+func NewEvaluationMemo() *EvaluationMemo {
+	return &EvaluationMemo{
+		sets:        make(map[memoSetKey]EvaluationResult),
+		evaluations: make(map[memoEvaluationKey]EvaluationResult),
+	}
+}
+
+// EvaluateMask is EvaluateMaskContext with memoization: the result is returned from the
+// memo if the same mask was evaluated with the same model and options, and otherwise
+// computed reusing the recoverable set and characteristics of an evaluation of the mask
+// under another model. The returned Recoverable set is shared and must not be modified.
+func (m *EvaluationMemo) EvaluateMask(ctx context.Context, mask Mask, model LossModel, opts EvaluationOptions) (EvaluationResult, error) {
+	if opts.Samples > 0 {
+		return EvaluateMaskContext(ctx, mask, model, opts)
+	}
+
+	setKey := memoSetKey{mask: memoMaskKey(mask), mode: opts.Mode, maxDepth: opts.MaxDepth}
+	key := memoEvaluationKey{set: setKey, model: model, weights: fmt.Sprint(opts.Weights)}
+	m.mu.Lock()
+	result, ok := m.evaluations[key]
+	known, setKnown := m.sets[setKey]
+	m.mu.Unlock()
+	if ok {
+		return result, nil
+	}
+
+	var err error
+	if setKnown {
+		result, err = evaluateMask(ctx, mask, model, opts, &known)
+	} else {
+		result, err = evaluateMask(ctx, mask, model, opts, nil)
+	}
+	if err != nil {
+		return EvaluationResult{}, err
+	}
+
+	m.mu.Lock()
+	m.evaluations[key] = result
+	m.sets[setKey] = EvaluationResult{Recoverable: result.Recoverable, Characteristics: result.Characteristics}
+	m.mu.Unlock()
+	return result, nil
+}
+
+// Characteristics is CalculateRecoveryCharacteristics for the decoding mode with
+// memoization, sharing the recoverable sets of EvaluateMask
+func (m *EvaluationMemo) Characteristics(mask Mask, mode DecodingMode) RecoveryCharacteristics {
+	setKey := memoSetKey{mask: memoMaskKey(mask), mode: mode}
+	m.mu.Lock()
+	known, ok := m.sets[setKey]
+	m.mu.Unlock()
+	if ok {
+		return *known.Characteristics
+	}
+
+	set := RecoverableSet(mask, mode)
+	characteristics := CalculateRecoveryCharacteristicsFromSet(mask.N(), mask.K(), set)
+	m.mu.Lock()
+	m.sets[setKey] = EvaluationResult{Recoverable: set, Characteristics: &characteristics}
+	m.mu.Unlock()
+	return characteristics
+}
+
+// memoMaskKey returns the size and exact rows of the mask
+func memoMaskKey(mask Mask) string {
+	key := fmt.Appendf(nil, "%dx%d", mask.N(), mask.K())
+	for _, row := range patternRows(mask) {
+		key = binary.LittleEndian.AppendUint64(key, row)
+	}
+	return string(key)
+}
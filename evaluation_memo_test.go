@@ -0,0 +1,81 @@
+package fecanalysis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluationMemo(t *testing.T) {
+	mask, err := (&GoogleBurstyMaskFactory{}).CreateMask(8, 4)
+	require.NoError(t, err)
+	models := []LossModel{NewGilbertElliotLossModel(0.01, 0.5, 0.05, 0.3), NewRandomLossModel(0.1)}
+	memo := NewEvaluationMemo()
+	ctx := context.Background()
+
+	for _, opts := range []EvaluationOptions{{}, {Mode: MLDecoding}, {MaxDepth: 1}, {Weights: ImportanceWeights(8, 2, 4)}} {
+		var first *ReachableSet
+		for _, model := range models {
+			direct, err := EvaluateMask(mask, model, opts)
+			require.NoError(t, err)
+			memoized, err := memo.EvaluateMask(ctx, mask, model, opts)
+			require.NoError(t, err)
+
+			assert.Equal(t, direct.Recoverable.Vertices(), memoized.Recoverable.Vertices())
+			assert.InDelta(t, direct.RecoveryProbability, memoized.RecoveryProbability, 1e-12)
+			assert.InDelta(t, direct.ResidualLoss, memoized.ResidualLoss, 1e-12)
+			assert.InDelta(t, direct.OverheadEfficiency, memoized.OverheadEfficiency, 1e-12)
+			assert.Equal(t, direct.Characteristics, memoized.Characteristics)
+
+			// The set is computed once across models
+			if first == nil {
+				first = memoized.Recoverable
+			}
+			assert.Same(t, first, memoized.Recoverable)
+
+			again, err := memo.EvaluateMask(ctx, mask, model, opts)
+			require.NoError(t, err)
+			assert.Equal(t, memoized, again)
+		}
+	}
+
+	// Characteristics share the sets of EvaluateMask and tell FEC row orders apart
+	assert.Equal(t, CalculateRecoveryCharacteristics(mask), memo.Characteristics(mask, PeelingDecoding))
+	swapped := make([][]bool, mask.K())
+	for fecIndex := range swapped {
+		swapped[fecIndex] = make([]bool, mask.N())
+		for packetIndex := range swapped[fecIndex] {
+			swapped[fecIndex][packetIndex] = mask.IsProtected(packetIndex, mask.K()-1-fecIndex)
+		}
+	}
+	reordered, err := NewMatrixMask(swapped)
+	require.NoError(t, err)
+	assert.Equal(t, CalculateRecoveryCharacteristics(reordered), memo.Characteristics(reordered, PeelingDecoding))
+}
+
+func TestEvaluationMemoSharesIdenticalMasks(t *testing.T) {
+	// With one FEC packet the bursty table and interleaving both give single parity
+	bursty, err := (&GoogleBurstyMaskFactory{}).CreateMask(6, 1)
+	require.NoError(t, err)
+	interleaved, err := (&InterleavedMaskFactory{}).CreateMask(6, 1)
+	require.NoError(t, err)
+	model := NewGilbertElliotLossModel(0.01, 0.5, 0.05, 0.3)
+	memo := NewEvaluationMemo()
+	ctx := context.Background()
+
+	first, err := memo.EvaluateMask(ctx, bursty, model, EvaluationOptions{})
+	require.NoError(t, err)
+	second, err := memo.EvaluateMask(ctx, interleaved, model, EvaluationOptions{})
+	require.NoError(t, err)
+	assert.Same(t, first.Recoverable, second.Recoverable)
+	assert.Equal(t, first, second)
+
+	// Other masks are computed separately
+	other, err := (&InterleavedMaskFactory{}).CreateMask(6, 2)
+	require.NoError(t, err)
+	third, err := memo.EvaluateMask(ctx, other, model, EvaluationOptions{})
+	require.NoError(t, err)
+	assert.NotSame(t, first.Recoverable, third.Recoverable)
+}
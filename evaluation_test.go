@@ -0,0 +1,130 @@
+package fecanalysis
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateMask(t *testing.T) {
+	mask, err := (&GoogleBurstyMaskFactory{}).CreateMask(8, 4)
+	require.NoError(t, err)
+	models := []LossModel{NewGilbertElliotLossModel(0.01, 0.5, 0.05, 0.3), NewRandomLossModel(0.1)}
+
+	// Every engine agrees with the standalone metrics
+	for _, model := range models {
+		for _, mode := range []DecodingMode{PeelingDecoding, MLDecoding} {
+			result, err := EvaluateMask(mask, model, EvaluationOptions{Mode: mode})
+			require.NoError(t, err)
+
+			recoverable := RecoverableSet(mask, mode)
+			assert.Equal(t, recoverable.Vertices(), result.Recoverable.Vertices(), "%s", mode)
+			assert.InDelta(t, SumProbabilities(recoverable, model, 12, 0), result.RecoveryProbability, 1e-12, "%s", mode)
+			residual, err := ResidualLossRateWithMode(mask, model, mode)
+			require.NoError(t, err)
+			assert.InDelta(t, residual, result.ResidualLoss, 1e-12, "%s", mode)
+			assert.InDelta(t, 1-residual, result.DeliveryRate(), 1e-12, "%s", mode)
+			efficiency, err := OverheadEfficiencyFromSet(8, 4, recoverable, model)
+			require.NoError(t, err)
+			assert.InDelta(t, efficiency, result.OverheadEfficiency, 1e-12, "%s", mode)
+			assert.Equal(t, CalculateRecoveryCharacteristicsFromSet(8, 4, recoverable), *result.Characteristics, "%s", mode)
+		}
+	}
+
+	// Depth limits and importance weights
+	model := models[0]
+	result, err := EvaluateMask(mask, model, EvaluationOptions{MaxDepth: 1})
+	require.NoError(t, err)
+	assert.Equal(t, RecoverableSetWithinDepth(mask, 1).Count(), result.Recoverable.Count())
+	residual, err := ResidualLossRateWithinDepth(mask, model, 1)
+	require.NoError(t, err)
+	assert.InDelta(t, residual, result.ResidualLoss, 1e-12)
+
+	weights := ImportanceWeights(8, 2, 4)
+	result, err = EvaluateMask(mask, model, EvaluationOptions{Weights: weights})
+	require.NoError(t, err)
+	residual, err = WeightedResidualLossRate(mask, model, PeelingDecoding, weights)
+	require.NoError(t, err)
+	assert.InDelta(t, residual, result.ResidualLoss, 1e-12)
+
+	_, err = EvaluateMask(mask, model, EvaluationOptions{Mode: MLDecoding, MaxDepth: 1})
+	assert.Error(t, err)
+	_, err = EvaluateMask(mask, model, EvaluationOptions{MaxDepth: 1, Weights: weights})
+	assert.Error(t, err)
+}
+
+func TestEvaluateMaskCache(t *testing.T) {
+	cache, err := NewRecoverableSetCache(t.TempDir())
+	require.NoError(t, err)
+	mask, err := (&GoogleRandomMaskFactory{}).CreateMask(7, 3)
+	require.NoError(t, err)
+	model := NewGilbertElliotLossModel(0.01, 0.5, 0.05, 0.3)
+
+	computed, err := EvaluateMask(mask, model, EvaluationOptions{Cache: cache})
+	require.NoError(t, err)
+	_, ok, err := cache.Load(mask, PeelingDecoding)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	loaded, err := EvaluateMask(mask, model, EvaluationOptions{Cache: cache})
+	require.NoError(t, err)
+	assert.Equal(t, computed.Recoverable.Vertices(), loaded.Recoverable.Vertices())
+	assert.InDelta(t, computed.RecoveryProbability, loaded.RecoveryProbability, 1e-12)
+	assert.InDelta(t, computed.ResidualLoss, loaded.ResidualLoss, 1e-12)
+	assert.Equal(t, computed.Characteristics, loaded.Characteristics)
+}
+
+func TestEvaluateMaskModels(t *testing.T) {
+	mask, err := (&GoogleBurstyMaskFactory{}).CreateMask(8, 4)
+	require.NoError(t, err)
+	models := []LossModel{NewGilbertElliotLossModel(0.01, 0.5, 0.05, 0.3), NewRandomLossModel(0.1)}
+
+	results, err := EvaluateMaskModels(context.Background(), mask, models, EvaluationOptions{})
+	require.NoError(t, err)
+	require.Len(t, results, len(models))
+	for i, model := range models {
+		expected, err := EvaluateMask(mask, model, EvaluationOptions{})
+		require.NoError(t, err)
+		assert.InDelta(t, expected.RecoveryProbability, results[i].RecoveryProbability, 1e-12)
+		assert.InDelta(t, expected.ResidualLoss, results[i].ResidualLoss, 1e-12)
+		assert.Equal(t, expected.Characteristics, results[i].Characteristics)
+	}
+	// The set is computed once and shared by every model
+	assert.Same(t, results[0].Recoverable, results[1].Recoverable)
+}
+
+func TestEvaluateMaskSampled(t *testing.T) {
+	mask, err := (&GoogleBurstyMaskFactory{}).CreateMask(8, 4)
+	require.NoError(t, err)
+	model := NewGilbertElliotLossModel(0.01, 0.5, 0.05, 0.3)
+
+	for _, opts := range []EvaluationOptions{{}, {Mode: MLDecoding}, {MaxDepth: 1}, {Weights: ImportanceWeights(8, 2, 4)}} {
+		exact, err := EvaluateMask(mask, model, opts)
+		require.NoError(t, err)
+		assert.Zero(t, exact.Samples)
+		assert.Zero(t, exact.ResidualLossStdError)
+
+		opts.Samples = 20000
+		opts.Rand = NewRandSource(3)
+		sampled, err := EvaluateMask(mask, model, opts)
+		require.NoError(t, err)
+		assert.Nil(t, sampled.Recoverable)
+		assert.Nil(t, sampled.Characteristics)
+		assert.Equal(t, 20000, sampled.Samples)
+		assert.Positive(t, sampled.RecoveryProbabilityStdError)
+		assert.Positive(t, sampled.ResidualLossStdError)
+		assert.InDelta(t, exact.RecoveryProbability, sampled.RecoveryProbability, 4*sampled.RecoveryProbabilityStdError)
+		assert.InDelta(t, exact.ResidualLoss, sampled.ResidualLoss, 4*sampled.ResidualLossStdError)
+
+		low, high := sampled.DeliveryRateEstimate().ConfidenceInterval(1.96)
+		assert.Less(t, low, sampled.DeliveryRate())
+		assert.Greater(t, high, sampled.DeliveryRate())
+
+		encoded, err := json.Marshal(sampled)
+		require.NoError(t, err)
+		assert.NotContains(t, string(encoded), "characteristics")
+	}
+}
@@ -0,0 +1,74 @@
+package fecanalysis
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// PatternProbability pairs a delivery pattern with its probability under a loss model
+type PatternProbability struct {
+	Pattern     int     // delivery pattern (bit i set if packet i delivered)
+	Probability float64 // probability of the pattern
+}
+
+// minProbabilityHeap is a min-heap of patterns ordered by probability
+type minProbabilityHeap []PatternProbability
+
+func (h minProbabilityHeap) Len() int           { return len(h) }
+func (h minProbabilityHeap) Less(i, j int) bool { return h[i].Probability < h[j].Probability }
+func (h minProbabilityHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *minProbabilityHeap) Push(x any)        { *h = append(*h, x.(PatternProbability)) }
+func (h *minProbabilityHeap) Pop() any {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
+
+// MostLikelyFailures returns the top-m most probable delivery patterns from which the
+// media packets cannot be recovered with the given mask, sorted by decreasing probability
+func MostLikelyFailures(mask Mask, model LossModel, m int) []PatternProbability {
+	recoverable := recoverableSet(mask)
+	return mostLikelyPatterns(model, mask.N()+mask.K(), m, func(vertex uint64) bool {
+		return !recoverable.Contains(vertex)
+	})
+}
+
+// MostLikelyPatterns returns the top-m most probable delivery patterns of the set over
+// totalPackets packets, sorted by decreasing probability
+func MostLikelyPatterns(set *ReachableSet, model LossModel, totalPackets, m int) []PatternProbability {
+	return mostLikelyPatterns(model, totalPackets, m, set.Contains)
+}
+
+// mostLikelyPatterns returns the top-m most probable delivery patterns for which include
+// returns true, sorted by decreasing probability and then by pattern
+func mostLikelyPatterns(model LossModel, totalPackets, m int, include func(vertex uint64) bool) []PatternProbability {
+	if m <= 0 {
+		return nil
+	}
+
+	// Keep the m most probable patterns seen so far in a min-heap
+	top := &minProbabilityHeap{}
+	for vertex := 0; vertex < (1 << totalPackets); vertex++ {
+		if !include(uint64(vertex)) {
+			continue
+		}
+
+		probability := model.CalculateProbability(vertex, totalPackets)
+		if top.Len() < m {
+			heap.Push(top, PatternProbability{Pattern: vertex, Probability: probability})
+		} else if probability > (*top)[0].Probability {
+			(*top)[0] = PatternProbability{Pattern: vertex, Probability: probability}
+			heap.Fix(top, 0)
+		}
+	}
+
+	patterns := []PatternProbability(*top)
+	sort.Slice(patterns, func(i, j int) bool {
+		if patterns[i].Probability != patterns[j].Probability {
+			return patterns[i].Probability > patterns[j].Probability
+		}
+		return patterns[i].Pattern < patterns[j].Pattern
+	})
+	return patterns
+}
@@ -0,0 +1,56 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMostLikelyFailures(t *testing.T) {
+	// FEC 0 protects packets 0 and 1, packet 2 is unprotected
+	mask := NewSimpleMask([][]bool{{true, true, false}}, 3, 1)
+	model := NewRandomLossModel(0.1)
+
+	failures := MostLikelyFailures(mask, model, 3)
+	require.Len(t, failures, 3)
+
+	// The most likely failure is losing only the unprotected packet 2
+	assert.Equal(t, 0b1011, failures[0].Pattern)
+	assert.InDelta(t, model.CalculateProbability(0b1011, 4), failures[0].Probability, 1e-15)
+
+	recoverable := recoverableSet(mask)
+	for i, failure := range failures {
+		assert.False(t, recoverable.Contains(uint64(failure.Pattern)), "pattern %04b should be non-recoverable", failure.Pattern)
+		if i > 0 {
+			assert.GreaterOrEqual(t, failures[i-1].Probability, failure.Probability)
+		}
+	}
+}
+
+func TestMostLikelyFailuresPerfectMask(t *testing.T) {
+	// A single media packet duplicated by one FEC packet fails only when both are lost
+	mask := NewSimpleMask([][]bool{{true}}, 1, 1)
+	failures := MostLikelyFailures(mask, NewRandomLossModel(0.2), 10)
+	require.Len(t, failures, 1)
+	assert.Equal(t, 0, failures[0].Pattern)
+	assert.Empty(t, MostLikelyFailures(mask, NewRandomLossModel(0.2), 0))
+}
+
+func TestMostLikelyPatterns(t *testing.T) {
+	set := NewReachableSet(8)
+	for _, pattern := range []uint64{0b111, 0b011, 0b000} {
+		set.Add(pattern)
+	}
+	patterns := MostLikelyPatterns(set, NewRandomLossModel(0.1), 3, 2)
+	require.Len(t, patterns, 2)
+	assert.Equal(t, 0b111, patterns[0].Pattern)
+	assert.InDelta(t, 0.729, patterns[0].Probability, 1e-12)
+	assert.Equal(t, 0b011, patterns[1].Pattern)
+	assert.Nil(t, MostLikelyPatterns(set, NewRandomLossModel(0.1), 3, 0))
+}
+
+func TestGoodVertices(t *testing.T) {
+	assert.Equal(t, []uint64{0b011, 0b111}, GoodVertices(2, 1))
+	assert.Len(t, GoodVertices(3, 2), 4)
+}
@@ -0,0 +1,50 @@
+package fecanalysis
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// NoFailureThreshold is returned by FailureThreshold when all non-recoverable patterns
+// together are not more likely than epsilon
+const NoFailureThreshold = -1
+
+// FailureThreshold returns the smallest k such that the patterns with at most k lost packets
+// the mask does not recover have a total probability above epsilon under the loss model, or
+// NoFailureThreshold if all non-recoverable patterns together are not that likely. It refines the worst case
+// MinLostPacketsForNonRecovery, which a single unlikely pattern determines: with epsilon = 0
+// and a model giving every pattern a positive probability the two are equal.
+func FailureThreshold(mask Mask, model LossModel, mode DecodingMode, epsilon float64) (int, error) {
+	N, K := mask.N(), mask.K()
+	if err := checkEnumerable(N, K); err != nil {
+		return 0, err
+	}
+	return FailureThresholdFromSet(N, K, RecoverableSet(mask, mode), model, epsilon)
+}
+
+// FailureThresholdFromSet computes the failure threshold using an existing recoverable set
+func FailureThresholdFromSet(N, K int, reachableSet *ReachableSet, model LossModel, epsilon float64) (int, error) {
+	totalPackets := N + K
+	if epsilon < 0 || epsilon >= 1 {
+		return 0, fmt.Errorf("threshold %v must be in [0, 1)", epsilon)
+	}
+	if reachableSet.NumVertices() != uint64(1)<<totalPackets {
+		return 0, fmt.Errorf("set of %d vertices does not match a block of %d packets", reachableSet.NumVertices(), totalPackets)
+	}
+
+	// Probability of the non-recoverable patterns by number of lost packets
+	failures := make([]float64, totalPackets+1)
+	VisitPatterns(model, totalPackets, func(pattern uint64, probability float64) {
+		if !reachableSet.Contains(pattern) {
+			failures[totalPackets-bits.OnesCount64(pattern)] += probability
+		}
+	})
+
+	cumulative := 0.0
+	for lost, probability := range failures {
+		if cumulative += probability; cumulative > epsilon {
+			return lost, nil
+		}
+	}
+	return NoFailureThreshold, nil
+}
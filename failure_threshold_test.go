@@ -0,0 +1,50 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailureThreshold(t *testing.T) {
+	// Single parity over 2 media packets fails on every pattern with 2 or 3 lost packets,
+	// with probabilities 3 * 0.01 * 0.9 = 0.027 and 0.001 under 10% random loss
+	parity, err := (&SingleParityMaskFactory{}).CreateMask(2, 1)
+	require.NoError(t, err)
+	model := NewRandomLossModel(0.1)
+	tests := []struct {
+		epsilon  float64
+		expected int
+	}{
+		{0, 2},
+		{0.02, 2},
+		{0.0275, 3},
+		{0.03, NoFailureThreshold},
+	}
+	for _, tt := range tests {
+		threshold, err := FailureThreshold(parity, model, PeelingDecoding, tt.epsilon)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, threshold, "epsilon %v", tt.epsilon)
+	}
+
+	// With epsilon 0 it is the worst case, and it grows with epsilon
+	mask, err := (&GoogleBurstyMaskFactory{}).CreateMask(8, 4)
+	require.NoError(t, err)
+	geModel := NewGilbertElliotLossModel(0.01, 0.5, 0.05, 0.3)
+	threshold, err := FailureThreshold(mask, geModel, PeelingDecoding, 0)
+	require.NoError(t, err)
+	assert.Equal(t, CalculateRecoveryCharacteristics(mask).MinLostPacketsForNonRecovery, LossCount(threshold))
+	previous := threshold
+	for _, epsilon := range []float64{1e-6, 1e-4, 1e-3} {
+		threshold, err := FailureThreshold(mask, geModel, PeelingDecoding, epsilon)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, threshold, previous, "epsilon %v", epsilon)
+		previous = threshold
+	}
+
+	_, err = FailureThreshold(mask, geModel, PeelingDecoding, 1)
+	assert.Error(t, err)
+	_, err = FailureThresholdFromSet(8, 4, NewReachableSet(16), geModel, 0.1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,51 @@
+package fecanalysis
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// FECLossRobustness describes how losing FEC packets weakens a mask, see
+// CalculateFECLossRobustness
+type FECLossRobustness struct {
+	// MinMediaLost holds, for every number j in 0..K of lost FEC packets, the fewest lost media
+	// packets that results in non-recovery when the worst j FEC packets are lost, or
+	// PerfectRecovery if no media loss fails. Entry 0 counts media losses alone and entry K is
+	// 1 for a block without FEC; 0 means that losing only FEC packets fails, which erasure
+	// decoding never does, so only sets from other decoders or damaged caches have it. How
+	// much the FEC packets gain over sending none under a loss model is
+	// EvaluationResult.OverheadEfficiency.
+	MinMediaLost []int `json:"min_media_lost"`
+}
+
+// CalculateFECLossRobustness computes the recovery behaviour of the recoverable set of an
+// N+K block as FEC packets get lost, from the intact media stream to the block without FEC
+func CalculateFECLossRobustness(N, K int, reachableSet *ReachableSet) (FECLossRobustness, error) {
+	totalPackets := N + K
+	if err := checkGraphPackets(N, K); err != nil {
+		return FECLossRobustness{}, err
+	}
+	if reachableSet.NumVertices() != uint64(1)<<totalPackets {
+		return FECLossRobustness{}, fmt.Errorf("set of %d vertices does not match a block of %d packets", reachableSet.NumVertices(), totalPackets)
+	}
+
+	minMediaLost := make([]int, K+1)
+	for lostFEC := range minMediaLost {
+		minMediaLost[lostFEC] = PerfectRecovery
+	}
+	allDelivered := uint64(1)<<totalPackets - 1
+	for fecLoss := uint64(0); fecLoss < uint64(1)<<K; fecLoss++ {
+		lostFEC := bits.OnesCount64(fecLoss)
+		// Media losses of increasing size, up to the smallest failure known for this many lost FEC packets
+		for numLost := 0; numLost <= N && (minMediaLost[lostFEC] == PerfectRecovery || numLost < minMediaLost[lostFEC]); numLost++ {
+			fails := generateCombinations(N, numLost, func(mediaLoss uint64) bool {
+				return !reachableSet.Contains(allDelivered ^ mediaLoss ^ fecLoss<<N)
+			})
+			if fails {
+				minMediaLost[lostFEC] = numLost
+				break
+			}
+		}
+	}
+	return FECLossRobustness{MinMediaLost: minMediaLost}, nil
+}
@@ -0,0 +1,49 @@
+package fecanalysis
+
+import (
+	"math/bits"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateFECLossRobustness(t *testing.T) {
+	// Single parity over 2 media packets recovers one media loss while its FEC packet arrives
+	parity, err := (&SingleParityMaskFactory{}).CreateMask(2, 1)
+	require.NoError(t, err)
+	robustness, err := CalculateFECLossRobustness(2, 1, RecoverableSet(parity, PeelingDecoding))
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 1}, robustness.MinMediaLost)
+
+	// Brute force over every pattern, for masks of both decoding modes
+	mask, err := (&GoogleRandomMaskFactory{}).CreateMask(8, 4)
+	require.NoError(t, err)
+	for _, mode := range []DecodingMode{PeelingDecoding, MLDecoding} {
+		set := RecoverableSet(mask, mode)
+		robustness, err := CalculateFECLossRobustness(8, 4, set)
+		require.NoError(t, err)
+
+		expected := []int{PerfectRecovery, PerfectRecovery, PerfectRecovery, PerfectRecovery, PerfectRecovery}
+		for pattern := uint64(0); pattern < 1<<12; pattern++ {
+			if set.Contains(pattern) {
+				continue
+			}
+			mediaLost := 8 - bits.OnesCount64(pattern&0xff)
+			fecLost := 4 - bits.OnesCount64(pattern>>8)
+			if expected[fecLost] == PerfectRecovery || mediaLost < expected[fecLost] {
+				expected[fecLost] = mediaLost
+			}
+		}
+		assert.Equal(t, expected, robustness.MinMediaLost, "%s", mode)
+		assert.Equal(t, 1, robustness.MinMediaLost[4], "%s", mode)
+	}
+
+	// A set missing the intact media stream fails without media losses
+	robustness, err = CalculateFECLossRobustness(2, 1, NewReachableSet(8))
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 0}, robustness.MinMediaLost)
+
+	_, err = CalculateFECLossRobustness(8, 4, NewReachableSet(8))
+	assert.Error(t, err)
+}
@@ -0,0 +1,136 @@
+package fecanalysis
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"slices"
+)
+
+// FECSubsetEvaluation holds the recoverability of a mask grouped by the 2^K FEC delivery
+// patterns. Media losses are stored rather than delivery patterns because every FEC packet
+// recovers at most one media packet, so a subset of f delivered FEC packets recovers at most
+// f lost media packets and only those few loss patterns are decoded. For K much smaller than
+// N this is a tiny fraction of the 2^(N+K) joint patterns.
+type FECSubsetEvaluation struct {
+	N, K int
+	// Recoverable[f] holds the recoverable media loss patterns (bit i set if media packet i is
+	// lost), in ascending order, when exactly the FEC packets of f are delivered
+	Recoverable [][]uint64
+	// minUnrecoverable[f] is the fewest lost media packets that defeat recovery given f, or
+	// PerfectRecovery
+	minUnrecoverable []int
+}
+
+// EvaluateByFECSubset decodes the mask conditioned on every FEC delivery pattern, enumerating
+// only the media loss patterns with at most as many losses as delivered FEC packets. The
+// candidate loss patterns of every size are generated once and shared by all FEC subsets
+// that deliver enough packets to try them.
+func EvaluateByFECSubset(mask Mask, mode DecodingMode) (*FECSubsetEvaluation, error) {
+	N, K := mask.N(), mask.K()
+	if err := checkGraphPackets(N, K); err != nil {
+		return nil, err
+	}
+	// The decoded patterns rather than N+K bound the work; 2^K FEC subsets alone would not fit
+	// in memory at around K=40
+	if patterns, _ := fecSubsetPatterns(N, K); patterns > math.Ldexp(1, MaxEnumerablePackets) {
+		return nil, fmt.Errorf("block of %d media and %d FEC packets decodes %.3g patterns, more than the limit of 2^%d", N, K, patterns, MaxEnumerablePackets)
+	}
+
+	// Media loss patterns by number of lost packets, up to K
+	candidates := make([][]uint64, min(K, N)+1)
+	for size := range candidates {
+		generateCombinations(N, size, func(lost uint64) bool {
+			candidates[size] = append(candidates[size], lost)
+			return false
+		})
+	}
+
+	rows := patternRows(mask)
+	allMedia := uint64(1)<<N - 1
+	evaluation := &FECSubsetEvaluation{
+		N:                N,
+		K:                K,
+		Recoverable:      make([][]uint64, 1<<K),
+		minUnrecoverable: make([]int, 1<<K),
+	}
+	for deliveredFEC := range evaluation.Recoverable {
+		evaluation.minUnrecoverable[deliveredFEC] = PerfectRecovery
+		delivered := bits.OnesCount(uint(deliveredFEC))
+		for size, losses := range candidates {
+			if size > delivered {
+				break
+			}
+			for _, lost := range losses {
+				pattern := allMedia&^lost | uint64(deliveredFEC)<<N
+				decodable := false
+				if mode == MLDecoding {
+					decodable = solvableByElimination(rows, N, pattern)
+				} else {
+					decodable = peelingClosure(rows, N, pattern)&allMedia == allMedia
+				}
+				if decodable {
+					evaluation.Recoverable[deliveredFEC] = append(evaluation.Recoverable[deliveredFEC], lost)
+				} else if evaluation.minUnrecoverable[deliveredFEC] == PerfectRecovery {
+					evaluation.minUnrecoverable[deliveredFEC] = size
+				}
+			}
+		}
+		// Losing more media packets than FEC packets were delivered always defeats recovery
+		if evaluation.minUnrecoverable[deliveredFEC] == PerfectRecovery && delivered < N {
+			evaluation.minUnrecoverable[deliveredFEC] = delivered + 1
+		}
+		slices.Sort(evaluation.Recoverable[deliveredFEC])
+	}
+	return evaluation, nil
+}
+
+// Contains returns true if every media packet is recovered from the delivery pattern
+func (e *FECSubsetEvaluation) Contains(pattern uint64) bool {
+	allMedia := uint64(1)<<e.N - 1
+	deliveredFEC := pattern >> e.N
+	if deliveredFEC >= uint64(len(e.Recoverable)) {
+		return false
+	}
+	_, found := slices.BinarySearch(e.Recoverable[deliveredFEC], allMedia&^pattern)
+	return found
+}
+
+// Count returns the number of recoverable delivery patterns
+func (e *FECSubsetEvaluation) Count() uint64 {
+	count := uint64(0)
+	for _, recoverable := range e.Recoverable {
+		count += uint64(len(recoverable))
+	}
+	return count
+}
+
+// RecoveryProbability returns the total probability of the recoverable delivery patterns
+// under the model, summed per FEC delivery pattern
+func (e *FECSubsetEvaluation) RecoveryProbability(model LossModel) float64 {
+	allMedia := uint64(1)<<e.N - 1
+	probability := 0.0
+	for deliveredFEC, recoverable := range e.Recoverable {
+		for _, lost := range recoverable {
+			pattern := allMedia&^lost | uint64(deliveredFEC)<<e.N
+			probability += model.CalculateProbability(int(pattern), e.N+e.K)
+		}
+	}
+	return probability
+}
+
+// MinLostPacketsForNonRecovery returns the minimum number of lost packets, media and FEC, that
+// results in non-recovery, or PerfectRecovery if every pattern is recovered
+func (e *FECSubsetEvaluation) MinLostPacketsForNonRecovery() LossCount {
+	minLost := LossCount(PerfectRecovery)
+	for deliveredFEC, mediaLost := range e.minUnrecoverable {
+		if mediaLost == PerfectRecovery {
+			continue
+		}
+		lost := LossCount(e.K - bits.OnesCount(uint(deliveredFEC)) + mediaLost)
+		if minLost == PerfectRecovery || lost < minLost {
+			minLost = lost
+		}
+	}
+	return minLost
+}
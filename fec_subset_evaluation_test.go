@@ -0,0 +1,50 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateByFECSubsetMatchesRecoverableSet(t *testing.T) {
+	model := NewGilbertElliotLossModel(0.01, 0.5, 0.05, 0.3)
+	for _, factory := range []MaskFactory{&InterleavedMaskFactory{}, &GoogleBurstyMaskFactory{}, &LDPCMaskFactory{Seed: 3}, &SingleParityMaskFactory{}} {
+		for _, mode := range []DecodingMode{PeelingDecoding, MLDecoding} {
+			mask, err := factory.CreateMask(10, 3)
+			require.NoError(t, err)
+			expected := RecoverableSet(mask, mode)
+
+			evaluation, err := EvaluateByFECSubset(mask, mode)
+			require.NoError(t, err)
+			for pattern := uint64(0); pattern < expected.NumVertices(); pattern++ {
+				require.Equal(t, expected.Contains(pattern), evaluation.Contains(pattern), "pattern %013b", pattern)
+			}
+			assert.Equal(t, expected.Count(), evaluation.Count())
+			assert.Equal(t, CalculateRecoveryCharacteristicsFromSet(10, 3, expected).MinLostPacketsForNonRecovery,
+				evaluation.MinLostPacketsForNonRecovery())
+
+			probability := 0.0
+			expected.Iterate(func(pattern uint64) {
+				probability += model.CalculateProbability(int(pattern), 13)
+			})
+			assert.InDelta(t, probability, evaluation.RecoveryProbability(model), 1e-12)
+		}
+	}
+}
+
+func TestEvaluateByFECSubsetPerfectMask(t *testing.T) {
+	// One media packet duplicated by one FEC packet only fails when both are lost
+	evaluation, err := EvaluateByFECSubset(NewSimpleMask([][]bool{{true}}, 1, 1), PeelingDecoding)
+	require.NoError(t, err)
+	assert.Equal(t, [][]uint64{{0}, {0, 1}}, evaluation.Recoverable)
+	assert.Equal(t, LossCount(2), evaluation.MinLostPacketsForNonRecovery())
+}
+
+func BenchmarkEvaluateByFECSubset(b *testing.B) {
+	mask, err := (&InterleavedMaskFactory{}).CreateMask(20, 4)
+	require.NoError(b, err)
+	for i := 0; i < b.N; i++ {
+		_, _ = EvaluateByFECSubset(mask, PeelingDecoding)
+	}
+}
@@ -0,0 +1,126 @@
+package fecanalysis
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// EncodingWindow is the range of source symbols (media packets) a repair symbol covers
+type EncodingWindow struct {
+	First int // first protected source symbol, -1 if the repair symbol protects nothing
+	Last  int // last protected source symbol, -1 if the repair symbol protects nothing
+}
+
+// Size returns the number of source symbols from First to Last
+func (w EncodingWindow) Size() int {
+	if w.First < 0 {
+		return 0
+	}
+	return w.Last - w.First + 1
+}
+
+// FECFrameDescription expresses a block mask in the vocabulary of the IETF FEC framework
+// (FECFRAME, RFC 6363) and its sliding-window extension (RFC 8680). Source symbols are
+// the media packets and repair symbols the FEC packets, sent after the source block.
+type FECFrameDescription struct {
+	SourceBlockLength int              // source symbols per block (N)
+	RepairSymbols     int              // repair symbols per block (K)
+	EncodingWindows   []EncodingWindow // source symbols covered by every repair symbol
+	MaxEncodingWindow int              // largest encoding window size (ew_max in RFC 8680)
+	// RepairWindow is the smallest number of packets after a source symbol within which
+	// every protected source symbol has at least one repair symbol, or -1 if the mask
+	// protects nothing
+	RepairWindow int
+	// UnprotectedSymbols lists the source symbols no repair symbol covers
+	UnprotectedSymbols []int
+}
+
+// DescribeFECFrame returns the FECFRAME description of the mask
+func DescribeFECFrame(mask Mask) FECFrameDescription {
+	N, K := mask.N(), mask.K()
+	description := FECFrameDescription{
+		SourceBlockLength: N,
+		RepairSymbols:     K,
+		EncodingWindows:   make([]EncodingWindow, K),
+		RepairWindow:      -1,
+	}
+
+	for fecIndex := 0; fecIndex < K; fecIndex++ {
+		window := EncodingWindow{First: -1, Last: -1}
+		for packetIndex := 0; packetIndex < N; packetIndex++ {
+			if mask.IsProtected(packetIndex, fecIndex) {
+				if window.First < 0 {
+					window.First = packetIndex
+				}
+				window.Last = packetIndex
+			}
+		}
+		description.EncodingWindows[fecIndex] = window
+		description.MaxEncodingWindow = max(description.MaxEncodingWindow, window.Size())
+	}
+
+	for packetIndex := 0; packetIndex < N; packetIndex++ {
+		// Repair symbols are sent in order, so the first one covering the packet is the closest
+		distance := -1
+		for fecIndex := 0; fecIndex < K && distance < 0; fecIndex++ {
+			if mask.IsProtected(packetIndex, fecIndex) {
+				distance = N + fecIndex - packetIndex
+			}
+		}
+		if distance < 0 {
+			description.UnprotectedSymbols = append(description.UnprotectedSymbols, packetIndex)
+			continue
+		}
+		description.RepairWindow = max(description.RepairWindow, distance)
+	}
+	return description
+}
+
+// RepairWindowRecoveryProbability returns the probability that every source symbol is
+// delivered or recovered no later than window packets after its own position, the
+// latency constraint a FECFRAME receiver imposes. Repair symbols are processed as they
+// arrive, with peeling recovery after each one; a source symbol recovered too late is
+// still used to recover others, but makes the block fail.
+func RepairWindowRecoveryProbability(mask Mask, model LossModel, window int) (float64, error) {
+	N, K := mask.N(), mask.K()
+	if err := checkEnumerable(N, K); err != nil {
+		return 0, err
+	}
+	if window < 0 {
+		return 0, fmt.Errorf("invalid repair window %d", window)
+	}
+
+	rows := patternRows(mask)
+	probability := 0.0
+	for vertex := 0; vertex < (1 << (N + K)); vertex++ {
+		if onTimeRecovery(rows, N, uint64(vertex), window) {
+			probability += model.CalculateProbability(vertex, N+K)
+		}
+	}
+	return probability, nil
+}
+
+// onTimeRecovery returns true if peeling over the repair symbols in arrival order recovers
+// every missing source symbol within window packets of its position
+func onTimeRecovery(rows []uint64, N int, vertex uint64, window int) bool {
+	allMedia := uint64(1)<<N - 1
+	available := vertex
+	for fecIndex := range rows {
+		if available&allMedia == allMedia {
+			return true
+		}
+		if vertex&(1<<(N+fecIndex)) == 0 {
+			continue // nothing new arrived
+		}
+
+		closure := peelingClosure(rows[:fecIndex+1], N, available)
+		for recovered := closure &^ available; recovered != 0; recovered &= recovered - 1 {
+			packetIndex := bits.TrailingZeros64(recovered)
+			if N+fecIndex-packetIndex > window {
+				return false
+			}
+		}
+		available = closure
+	}
+	return available&allMedia == allMedia
+}
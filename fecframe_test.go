@@ -0,0 +1,56 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribeFECFrame(t *testing.T) {
+	mask, err := (&InterleavedMaskFactory{}).CreateMask(4, 2)
+	require.NoError(t, err)
+
+	description := DescribeFECFrame(mask)
+	assert.Equal(t, 4, description.SourceBlockLength)
+	assert.Equal(t, 2, description.RepairSymbols)
+	assert.Equal(t, []EncodingWindow{{First: 0, Last: 2}, {First: 1, Last: 3}}, description.EncodingWindows)
+	assert.Equal(t, 3, description.MaxEncodingWindow)
+	// Source symbols 0 and 1 wait four packets for their repair symbol
+	assert.Equal(t, 4, description.RepairWindow)
+	assert.Empty(t, description.UnprotectedSymbols)
+
+	partial := NewSimpleMask([][]bool{{false, true, false}}, 3, 1)
+	description = DescribeFECFrame(partial)
+	assert.Equal(t, []int{0, 2}, description.UnprotectedSymbols)
+	assert.Equal(t, 2, description.RepairWindow)
+	assert.Equal(t, 1, description.MaxEncodingWindow)
+}
+
+func TestRepairWindowRecoveryProbability(t *testing.T) {
+	mask, err := (&GoogleRandomMaskFactory{}).CreateMask(4, 2)
+	require.NoError(t, err)
+	model := NewGilbertLossModel(0.5, 0.1, 0.5)
+
+	// Without a latency constraint the result is the regular recovery probability
+	unconstrained, err := RepairWindowRecoveryProbability(mask, model, 5)
+	require.NoError(t, err)
+	assert.InDelta(t, RecoveryProbability(mask, model), unconstrained, 1e-12)
+
+	// With a zero window only blocks without media loss succeed
+	immediate, err := RepairWindowRecoveryProbability(mask, model, 0)
+	require.NoError(t, err)
+	assert.InDelta(t, CumulativeLossProbability(model, 4, 0), immediate, 1e-12)
+
+	// Tightening the window never helps
+	previous := immediate
+	for window := 1; window <= 5; window++ {
+		probability, err := RepairWindowRecoveryProbability(mask, model, window)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, probability, previous-1e-12, "window %d", window)
+		previous = probability
+	}
+
+	_, err = RepairWindowRecoveryProbability(mask, model, -1)
+	assert.Error(t, err)
+}
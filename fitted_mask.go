@@ -0,0 +1,91 @@
+package fecanalysis
+
+import "fmt"
+
+// FittedMaskFactory wraps a table-based factory (GoogleBurstyMaskFactory,
+// GoogleRandomMaskFactory) and fills in the (N, K) combinations the table lacks the way
+// the WebRTC encoder does, so sweeps over N=1..48 cover every configuration:
+//
+//  1. the mask from the wrapped factory, if it has one;
+//  2. for N up to the table size, the mask of the nearest larger table entry with the
+//     same K, cropped to its first N columns (WebRTC's sub-mask fitting), provided every
+//     FEC packet still protects something;
+//  3. for larger N (up to 48), WebRTC's generated mask where media packet i is protected
+//     by FEC packet i % K.
+//
+// The WebRTC encoder never sends more FEC than media packets. For K > N the factory stacks
+// the (N, N) mask K/N times and appends the (N, K mod N) mask, so every extra FEC packet
+// adds protection as the tables would for a fresh group.
+type FittedMaskFactory struct {
+	Base MaskFactory // table-based factory to fit from
+}
+
+// CreateMask creates a mask from the wrapped factory or fits one as described above
+func (f *FittedMaskFactory) CreateMask(N, K int) (Mask, error) {
+	mask, err := f.Base.CreateMask(N, K)
+	if err == nil {
+		return mask, nil
+	}
+	if N <= 0 || K <= 0 || N > maxWebRTCMaskPackets {
+		return nil, err
+	}
+	if K > N {
+		return f.stackMask(N, K)
+	}
+
+	if N <= maxGoogleTableSize {
+		for larger := N + 1; larger <= maxGoogleTableSize; larger++ {
+			if source, sourceErr := f.Base.CreateMask(larger, K); sourceErr == nil {
+				if fitted, ok := cropMask(source, N); ok {
+					return fitted, nil
+				}
+			}
+		}
+		return nil, fmt.Errorf("no table entry to fit N=%d, K=%d from: %w", N, K, err)
+	}
+
+	return webrtcGeneratedMask(N, K), nil
+}
+
+// stackMask builds a K > N mask from stacked (N, N) masks and one (N, K mod N) mask
+func (f *FittedMaskFactory) stackMask(N, K int) (Mask, error) {
+	stacked, err := f.CreateMask(N, N)
+	if err != nil {
+		return nil, err
+	}
+	for remaining := K - N; remaining > 0; remaining -= min(remaining, N) {
+		next, err := f.CreateMask(N, min(remaining, N))
+		if err != nil {
+			return nil, err
+		}
+		if stacked, err = Concat(stacked, next); err != nil {
+			return nil, err
+		}
+	}
+	return stacked, nil
+}
+
+// cropMask keeps the first N columns of the mask; it fails if a row becomes empty
+func cropMask(mask Mask, N int) (*MatrixMask, bool) {
+	rows := maskMatrix(mask)
+	for fecIndex, row := range patternRows(mask) {
+		if row&((1<<N)-1) == 0 {
+			return nil, false
+		}
+		rows[fecIndex] = rows[fecIndex][:N]
+	}
+	cropped, err := NewMatrixMask(rows)
+	return cropped, err == nil
+}
+
+// webrtcGeneratedMask returns the mask WebRTC generates for more media packets than its
+// tables cover: media packet i is protected by FEC packet i % K, in WebRTC byte format
+func webrtcGeneratedMask(N, K int) Mask {
+	rowBytes := webrtcRowBytes(N)
+	data := make([]byte, K*rowBytes)
+	for packetIndex := 0; packetIndex < N; packetIndex++ {
+		fecIndex := packetIndex % K
+		data[fecIndex*rowBytes+packetIndex/8] |= 1 << (7 - packetIndex%8)
+	}
+	return &bitMask{data: data, n: N, k: K, rowBytes: rowBytes}
+}
@@ -0,0 +1,68 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFittedMaskFactoryCoversLargeN(t *testing.T) {
+	factory := &FittedMaskFactory{Base: &GoogleBurstyMaskFactory{}}
+
+	for N := 1; N <= maxWebRTCMaskPackets; N++ {
+		for K := 1; K <= N; K++ {
+			mask, err := factory.CreateMask(N, K)
+			require.NoError(t, err, "N=%d K=%d", N, K)
+			assert.Equal(t, N, mask.N())
+			assert.Equal(t, K, mask.K())
+		}
+	}
+
+	_, err := factory.CreateMask(49, 1)
+	assert.Error(t, err)
+}
+
+func TestFittedMaskFactoryGeneratedMask(t *testing.T) {
+	mask, err := (&FittedMaskFactory{Base: &GoogleRandomMaskFactory{}}).CreateMask(20, 3)
+	require.NoError(t, err)
+
+	for packetIndex := 0; packetIndex < 20; packetIndex++ {
+		for fecIndex := 0; fecIndex < 3; fecIndex++ {
+			assert.Equal(t, packetIndex%3 == fecIndex, mask.IsProtected(packetIndex, fecIndex))
+		}
+	}
+}
+
+func TestFittedMaskFactoryCropsLargerTable(t *testing.T) {
+	// Table with only the 4x2 entry: the 3x2 mask is fitted from it
+	table := MaskTable{MaskSize{N: 4, K: 2}: {0xc0, 0x00, 0x70, 0x00}}
+	factory := &FittedMaskFactory{Base: &GoogleBurstyMaskFactory{Table: table}}
+
+	mask, err := factory.CreateMask(3, 2)
+	require.NoError(t, err)
+	assert.Equal(t, [][]bool{{true, true, false}, {false, true, true}}, maskMatrix(mask))
+
+	// Cropping a 4x2 entry protecting {0,1} and {2,3} to two columns would leave FEC 1 empty
+	factory = &FittedMaskFactory{Base: &GoogleBurstyMaskFactory{Table: MaskTable{MaskSize{N: 4, K: 2}: {0xc0, 0x00, 0x30, 0x00}}}}
+	_, err = factory.CreateMask(2, 2)
+	assert.Error(t, err)
+}
+
+func TestFittedMaskFactoryMoreFECThanMedia(t *testing.T) {
+	base := &GoogleRandomMaskFactory{}
+	factory := &FittedMaskFactory{Base: base}
+
+	mask, err := factory.CreateMask(3, 7)
+	require.NoError(t, err)
+	assert.Equal(t, 7, mask.K())
+
+	full, err := base.CreateMask(3, 3)
+	require.NoError(t, err)
+	rest, err := base.CreateMask(3, 1)
+	require.NoError(t, err)
+	rows := maskMatrix(mask)
+	assert.Equal(t, maskMatrix(full), rows[0:3])
+	assert.Equal(t, maskMatrix(full), rows[3:6])
+	assert.Equal(t, maskMatrix(rest), rows[6:])
+}
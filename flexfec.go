@@ -0,0 +1,102 @@
+package fecanalysis
+
+import "fmt"
+
+const (
+	rtpHeaderBytes = 12 // fixed RTP header without CSRCs or extensions
+
+	// flexfecFixedHeaderBytes is the part of the RFC 8627 FlexFEC header preceding the
+	// mask: flags, PT/length/timestamp recovery fields and the sequence number base
+	flexfecFixedHeaderBytes = 10
+)
+
+// flexfecMaskFormat is a FlexFEC flexible mask size
+type flexfecMaskFormat struct {
+	bits  int // media packets the mask can represent
+	bytes int // bytes on the wire, including the k bits that signal whether the mask continues
+}
+
+// flexfecMaskFormats lists the FlexFEC flexible mask sizes from smallest to largest
+var flexfecMaskFormats = []flexfecMaskFormat{
+	{15, 2},
+	{46, 6},
+	{109, 14},
+}
+
+// FlexFECMaskBits returns, for every FEC packet of the mask, the smallest FlexFEC mask
+// size (15, 46 or 109 bits) able to represent it. A FlexFEC mask is relative to the
+// sequence number base, which is the first media packet the FEC packet protects, so the
+// size depends on the span between the first and last protected packets.
+func FlexFECMaskBits(mask Mask) ([]int, error) {
+	sizes := make([]int, mask.K())
+	for fecIndex := 0; fecIndex < mask.K(); fecIndex++ {
+		size, err := flexfecFormatForSpan(protectedSpan(mask, fecIndex))
+		if err != nil {
+			return nil, fmt.Errorf("FEC packet %d: %w", fecIndex, err)
+		}
+		sizes[fecIndex] = size.bits
+	}
+	return sizes, nil
+}
+
+// FlexFECHeaderBytes returns the FlexFEC header size in bytes of every FEC packet of the mask
+func FlexFECHeaderBytes(mask Mask) ([]int, error) {
+	headers := make([]int, mask.K())
+	for fecIndex := 0; fecIndex < mask.K(); fecIndex++ {
+		size, err := flexfecFormatForSpan(protectedSpan(mask, fecIndex))
+		if err != nil {
+			return nil, fmt.Errorf("FEC packet %d: %w", fecIndex, err)
+		}
+		headers[fecIndex] = flexfecFixedHeaderBytes + size.bytes
+	}
+	return headers, nil
+}
+
+// FlexFECWireOverhead returns the ratio of bytes sent for FEC packets to bytes sent for
+// media packets when every media packet carries payloadSize bytes of payload. FEC packets
+// carry an RTP header, their FlexFEC header and a repair payload as large as the media
+// payload, so the result exceeds K/N by the header cost.
+func FlexFECWireOverhead(mask Mask, payloadSize int) (float64, error) {
+	headers, err := FlexFECHeaderBytes(mask)
+	if err != nil {
+		return 0, err
+	}
+
+	fecBytes := 0
+	for _, header := range headers {
+		fecBytes += rtpHeaderBytes + header + payloadSize
+	}
+	mediaBytes := mask.N() * (rtpHeaderBytes + payloadSize)
+	return float64(fecBytes) / float64(mediaBytes), nil
+}
+
+// protectedSpan returns the number of sequence numbers from the first to the last media
+// packet protected by the FEC packet, or 0 if it protects nothing
+func protectedSpan(mask Mask, fecIndex int) int {
+	first, last := -1, -1
+	for packetIndex := 0; packetIndex < mask.N(); packetIndex++ {
+		if mask.IsProtected(packetIndex, fecIndex) {
+			if first < 0 {
+				first = packetIndex
+			}
+			last = packetIndex
+		}
+	}
+	if first < 0 {
+		return 0
+	}
+	return last - first + 1
+}
+
+// flexfecFormatForSpan returns the smallest FlexFEC mask size covering the span. Mask bit i
+// stands for the media packet at sequence number base+i, where the base is the first
+// protected packet.
+func flexfecFormatForSpan(span int) (flexfecMaskFormat, error) {
+	for _, format := range flexfecMaskFormats {
+		if span <= format.bits {
+			return format, nil
+		}
+	}
+	largest := flexfecMaskFormats[len(flexfecMaskFormats)-1].bits
+	return flexfecMaskFormat{}, fmt.Errorf("protected packets span %d sequence numbers, FlexFEC masks cover at most %d", span, largest)
+}
@@ -0,0 +1,68 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlexFECMaskBits(t *testing.T) {
+	tests := []struct {
+		name     string
+		N, K     int
+		expected int
+	}{
+		{"small block", 10, 2, 15},
+		{"span of exactly 15", 15, 1, 15},
+		{"medium block", 16, 1, 46},
+		{"large block", 60, 1, 109},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mask, err := (&InterleavedMaskFactory{}).CreateMask(tt.N, tt.K)
+			require.NoError(t, err)
+			bits, err := FlexFECMaskBits(mask)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, bits[0])
+		})
+	}
+
+	mask, err := (&InterleavedMaskFactory{}).CreateMask(110, 1)
+	require.NoError(t, err)
+	_, err = FlexFECMaskBits(mask)
+	assert.Error(t, err, "110 packets don't fit the largest mask")
+}
+
+func TestFlexFECMaskBitsRelativeToBase(t *testing.T) {
+	// The second FEC packet protects packets 20..30: its span is 11 despite the high indices
+	rows := make([][]bool, 2)
+	rows[0] = make([]bool, 31)
+	rows[1] = make([]bool, 31)
+	rows[0][0], rows[0][30] = true, true
+	for packetIndex := 20; packetIndex <= 30; packetIndex++ {
+		rows[1][packetIndex] = true
+	}
+	mask, err := NewMatrixMask(rows)
+	require.NoError(t, err)
+
+	bits, err := FlexFECMaskBits(mask)
+	require.NoError(t, err)
+	assert.Equal(t, []int{46, 15}, bits)
+
+	headers, err := FlexFECHeaderBytes(mask)
+	require.NoError(t, err)
+	assert.Equal(t, []int{16, 12}, headers)
+}
+
+func TestFlexFECWireOverhead(t *testing.T) {
+	mask, err := (&InterleavedMaskFactory{}).CreateMask(10, 2)
+	require.NoError(t, err)
+
+	overhead, err := FlexFECWireOverhead(mask, 1000)
+	require.NoError(t, err)
+	// Two FEC packets of 12+12+1000 bytes over ten media packets of 12+1000 bytes
+	assert.InDelta(t, 2.0*1024/(10*1012), overhead, 1e-12)
+	assert.Greater(t, overhead, 0.2)
+}
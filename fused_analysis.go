@@ -0,0 +1,58 @@
+package fecanalysis
+
+import (
+	"context"
+	"math/bits"
+)
+
+// FusedAnalysis is the result of FusedRecoveryAnalysis
+type FusedAnalysis struct {
+	Recoverable         *ReachableSet // delivery patterns that peeling fully recovers
+	RecoveryProbability float64       // probability that every media packet is available
+	ResidualMediaLoss   float64       // expected fraction of media packets still missing after peeling
+}
+
+// FusedRecoveryAnalysis computes the recoverable set of the mask under peeling decoding
+// together with its Gilbert-Elliott recovery probability and residual media loss in a single
+// walk over the delivery patterns, instead of computing the set and then evaluating the model
+// on every pattern.
+//
+// Patterns come from the walk of GilbertElliotLossModel.VisitPatterns, which tries delivery
+// before loss, so they come out in decreasing numeric order like in RecoverableSet, which
+// visits every pattern after all of its supersets, and every pattern costs one DP step instead
+// of N+K. Progress counts the visited patterns.
+func FusedRecoveryAnalysis(ctx context.Context, mask Mask, model *GilbertElliotLossModel, progress ProgressFunc) (*FusedAnalysis, error) {
+	N, K := mask.N(), mask.K()
+	if err := checkEnumerable(N, K); err != nil {
+		return nil, err
+	}
+
+	rows := patternRows(mask)
+	allMedia := uint64(1)<<N - 1
+	analysis := &FusedAnalysis{Recoverable: NewReachableSet(uint64(1) << (N + K))}
+	tracker := newProgressTracker(ctx, progress, analysis.Recoverable.NumVertices())
+	expectedLost := 0.0
+
+	err := model.walkPatterns(newGilbertElliottNode(N+K), func(pattern uint64, probability float64) error {
+		if err := tracker.step(); err != nil {
+			return err
+		}
+		if recoverableGivenSupersets(rows, N, pattern, analysis.Recoverable, PeelingDecoding) {
+			analysis.Recoverable.Add(pattern)
+			analysis.RecoveryProbability += probability
+		} else {
+			lost := allMedia &^ peelingClosure(rows, N, pattern)
+			expectedLost += probability * float64(bits.OnesCount64(lost))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	tracker.finish()
+
+	if N > 0 {
+		analysis.ResidualMediaLoss = expectedLost / float64(N)
+	}
+	return analysis, nil
+}
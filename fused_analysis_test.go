@@ -0,0 +1,52 @@
+package fecanalysis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFusedRecoveryAnalysis(t *testing.T) {
+	model := NewGilbertElliotLossModel(0.02, 0.6, 0.05, 0.3)
+	for _, factory := range []MaskFactory{&GoogleBurstyMaskFactory{}, &InterleavedMaskFactory{}, &LDPCMaskFactory{Seed: 4}} {
+		mask, err := factory.CreateMask(9, 4)
+		require.NoError(t, err)
+
+		analysis, err := FusedRecoveryAnalysis(context.Background(), mask, model, nil)
+		require.NoError(t, err)
+		assert.Equal(t, RecoverableSet(mask, PeelingDecoding).Vertices(), analysis.Recoverable.Vertices())
+		assert.InDelta(t, RecoveryProbability(mask, model), analysis.RecoveryProbability, 1e-12)
+		residual, err := ResidualLossRate(mask, model)
+		require.NoError(t, err)
+		assert.InDelta(t, residual, analysis.ResidualMediaLoss, 1e-12)
+	}
+}
+
+func TestFusedRecoveryAnalysisCanceled(t *testing.T) {
+	mask, err := (&InterleavedMaskFactory{}).CreateMask(4, 2)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = FusedRecoveryAnalysis(ctx, mask, NewGilbertLossModel(0.5, 0.1, 0.3), nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func BenchmarkFusedRecoveryAnalysis(b *testing.B) {
+	mask, err := (&GoogleRandomMaskFactory{}).CreateMask(12, 6)
+	require.NoError(b, err)
+
+	b.Run("fused", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = FusedRecoveryAnalysis(context.Background(), mask, NewGilbertLossModel(0.5, 0.1, 0.3), nil)
+		}
+	})
+	b.Run("two-phase", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			model := NewGilbertLossModel(0.5, 0.1, 0.3)
+			SumProbabilities(RecoverableSet(mask, PeelingDecoding), model, 18, 1)
+			_, _ = ResidualLossRate(mask, model)
+		}
+	})
+}
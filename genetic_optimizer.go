@@ -0,0 +1,214 @@
+package fecanalysis
+
+import (
+	"fmt"
+	"sort"
+)
+
+// OptimizationResult is the best mask found by a mask optimizer
+type OptimizationResult struct {
+	Mask        *MatrixMask // best mask found
+	Score       float64     // objective value of the best mask
+	Evaluations int         // number of distinct masks evaluated
+}
+
+// GeneticOptimizer searches for good masks with a genetic algorithm, for N, K sizes where
+// exhaustive search over all 2^(N*K) masks is infeasible. Individuals are protection
+// matrices; children take each FEC row from one of two tournament-selected parents and
+// then have individual bits flipped, or get a move of a custom mutator instead.
+// Zero values of the parameters select the defaults.
+type GeneticOptimizer struct {
+	PopulationSize int         // individuals per generation (default 32)
+	Generations    int         // number of generations (default 50)
+	CrossoverRate  float64     // probability that a child mixes rows of two parents (default 0.9)
+	MutationRate   float64     // per-bit flip probability (default 1/(N*K))
+	Elitism        int         // best individuals copied unchanged to the next generation (default 2)
+	TournamentSize int         // individuals compared when selecting a parent (default 3)
+	Mutator        MaskMutator // if set, applied once to every child instead of the bit flips
+
+	Rand RandSource // source of randomness; nil derives one from the package seed
+}
+
+// maxDuplicateAttempts bounds how often a new individual is regenerated or mutated again
+// while it duplicates a member of its generation
+const maxDuplicateAttempts = 10
+
+// individual is a candidate mask together with its fitness
+type individual struct {
+	rows  [][]bool
+	score float64
+}
+
+// Optimize runs the genetic algorithm and returns the best mask found. Seed masks (e.g.
+// the WebRTC tables) are placed in the initial population, the rest is filled with
+// random masks, so the result is never worse than the best seed.
+func (o *GeneticOptimizer) Optimize(N, K int, objective MaskObjective, seeds ...Mask) (OptimizationResult, error) {
+	if N <= 0 || K <= 0 {
+		return OptimizationResult{}, fmt.Errorf("invalid parameters for mask optimization: N=%d, K=%d", N, K)
+	}
+	for _, seed := range seeds {
+		if seed.N() != N || seed.K() != K {
+			return OptimizationResult{}, fmt.Errorf("seed mask is N=%d, K=%d, expected N=%d, K=%d", seed.N(), seed.K(), N, K)
+		}
+	}
+
+	populationSize := defaultInt(o.PopulationSize, 32)
+	generations := defaultInt(o.Generations, 50)
+	elitism := min(defaultInt(o.Elitism, 2), populationSize)
+	tournamentSize := defaultInt(o.TournamentSize, 3)
+	crossoverRate := defaultFloat(o.CrossoverRate, 0.9)
+	mutationRate := defaultFloat(o.MutationRate, 1.0/float64(N*K))
+	rng := randSourceOrDefault(o.Rand)
+
+	// Objective values are cached since elites and duplicates recur across generations
+	cache := make(map[string]float64)
+	evaluate := func(rows [][]bool) individual {
+		key := matrixKey(rows)
+		score, ok := cache[key]
+		if !ok {
+			mask, _ := NewMatrixMask(rows)
+			score = objective(mask)
+			cache[key] = score
+		}
+		return individual{rows: rows, score: score}
+	}
+
+	// Masks equal up to FEC row order are kept out of the same generation where possible,
+	// so the population doesn't collapse onto copies of one mask
+	fingerprints := make(map[string]bool)
+	isDuplicate := func(rows [][]bool) bool {
+		mask, _ := NewMatrixMask(rows)
+		fingerprint := Fingerprint(mask)
+		if fingerprints[fingerprint] {
+			return true
+		}
+		fingerprints[fingerprint] = true
+		return false
+	}
+
+	population := make([]individual, 0, populationSize)
+	for _, seed := range seeds {
+		if len(population) < populationSize && !isDuplicate(maskMatrix(seed)) {
+			population = append(population, evaluate(maskMatrix(seed)))
+		}
+	}
+	for len(population) < populationSize {
+		var rows [][]bool
+		for attempt := 0; attempt < maxDuplicateAttempts; attempt++ {
+			mask, err := RandomMask(N, K, 1+rng.Intn(N), rng)
+			if err != nil {
+				return OptimizationResult{}, err
+			}
+			rows = mask.rows
+			if !isDuplicate(rows) {
+				break
+			}
+		}
+		population = append(population, evaluate(rows))
+	}
+	sortByScore(population)
+
+	for generation := 0; generation < generations; generation++ {
+		next := make([]individual, 0, populationSize)
+		next = append(next, population[:elitism]...)
+		clear(fingerprints)
+		for _, elite := range next {
+			isDuplicate(elite.rows)
+		}
+
+		for len(next) < populationSize {
+			first := tournament(population, tournamentSize, rng)
+			child := cloneRows(first.rows)
+			if rng.Float64() < crossoverRate {
+				second := tournament(population, tournamentSize, rng)
+				for fecIndex := range child {
+					if rng.Intn(2) == 1 {
+						copy(child[fecIndex], second.rows[fecIndex])
+					}
+				}
+			}
+			mutateChild := func() {
+				if o.Mutator != nil {
+					o.Mutator.Mutate(child, rng)
+				} else {
+					mutate(child, mutationRate, rng)
+				}
+			}
+			mutateChild()
+			for attempt := 1; isDuplicate(child) && attempt < maxDuplicateAttempts; attempt++ {
+				mutateChild()
+			}
+			next = append(next, evaluate(child))
+		}
+
+		population = next
+		sortByScore(population)
+	}
+
+	best, _ := NewMatrixMask(population[0].rows)
+	return OptimizationResult{
+		Mask:        best,
+		Score:       population[0].score,
+		Evaluations: len(cache),
+	}, nil
+}
+
+// tournament returns the fittest of size randomly chosen individuals
+func tournament(population []individual, size int, rng RandSource) individual {
+	best := population[rng.Intn(len(population))]
+	for i := 1; i < size; i++ {
+		if candidate := population[rng.Intn(len(population))]; candidate.score > best.score {
+			best = candidate
+		}
+	}
+	return best
+}
+
+// mutate flips every bit with the given probability; rows left empty get one random bit
+// back, since a FEC packet protecting nothing is wasted overhead
+func mutate(rows [][]bool, rate float64, rng RandSource) {
+	for _, row := range rows {
+		empty := true
+		for packetIndex := range row {
+			if rng.Float64() < rate {
+				row[packetIndex] = !row[packetIndex]
+			}
+			empty = empty && !row[packetIndex]
+		}
+		if empty {
+			row[rng.Intn(len(row))] = true
+		}
+	}
+}
+
+// sortByScore sorts individuals by decreasing score, keeping the order of equal scores
+func sortByScore(population []individual) {
+	sort.SliceStable(population, func(i, j int) bool {
+		return population[i].score > population[j].score
+	})
+}
+
+// cloneRows returns a deep copy of a protection matrix
+func cloneRows(rows [][]bool) [][]bool {
+	cloned := make([][]bool, len(rows))
+	for i, row := range rows {
+		cloned[i] = append([]bool(nil), row...)
+	}
+	return cloned
+}
+
+// defaultInt returns value, or fallback if value is not positive
+func defaultInt(value, fallback int) int {
+	if value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+// defaultFloat returns value, or fallback if value is not positive
+func defaultFloat(value, fallback float64) float64 {
+	if value <= 0 {
+		return fallback
+	}
+	return value
+}
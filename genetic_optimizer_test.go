@@ -0,0 +1,65 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneticOptimizerImprovesOnSeed(t *testing.T) {
+	model := NewGilbertElliotLossModel(0.05, 0.7, 0.05, 0.2)
+	objective := RecoveryProbabilityObjective(model)
+	N, K := 6, 3
+
+	seed, err := (&GoogleRandomMaskFactory{}).CreateMask(N, K)
+	require.NoError(t, err)
+
+	optimizer := &GeneticOptimizer{PopulationSize: 16, Generations: 10, Rand: NewRandSource(1)}
+	result, err := optimizer.Optimize(N, K, objective, seed)
+	require.NoError(t, err)
+
+	assert.Equal(t, N, result.Mask.N())
+	assert.Equal(t, K, result.Mask.K())
+	assert.GreaterOrEqual(t, result.Score, objective(seed))
+	assert.InDelta(t, RecoveryProbability(result.Mask, model), result.Score, 1e-12)
+	assert.Greater(t, result.Evaluations, 1)
+}
+
+func TestGeneticOptimizerReproducible(t *testing.T) {
+	objective := ResidualLossObjective(NewRandomLossModel(0.1))
+
+	run := func() OptimizationResult {
+		optimizer := &GeneticOptimizer{PopulationSize: 8, Generations: 5, Rand: NewRandSource(3)}
+		result, err := optimizer.Optimize(5, 2, objective)
+		require.NoError(t, err)
+		return result
+	}
+
+	first, second := run(), run()
+	assert.Equal(t, first.Mask, second.Mask)
+	assert.Equal(t, first.Score, second.Score)
+	assert.Less(t, first.Score, 0.0)
+}
+
+func TestResidualLossObjective(t *testing.T) {
+	p := 0.1
+	model := NewRandomLossModel(p)
+
+	// A single parity packet over two media packets: a media packet stays lost when it
+	// and at least one of the other two packets are lost
+	mask := NewSimpleMask([][]bool{{true, true}}, 2, 1)
+	assert.InDelta(t, -p*(1-(1-p)*(1-p)), ResidualLossObjective(model)(mask), 1e-12)
+}
+
+func TestGeneticOptimizerValidation(t *testing.T) {
+	optimizer := &GeneticOptimizer{Rand: NewRandSource(1)}
+	objective := RecoveryProbabilityObjective(NewRandomLossModel(0.1))
+
+	_, err := optimizer.Optimize(0, 1, objective)
+	assert.Error(t, err)
+
+	seed := NewSimpleMask([][]bool{{true, true}}, 2, 1)
+	_, err = optimizer.Optimize(3, 1, objective, seed)
+	assert.Error(t, err)
+}
@@ -0,0 +1,277 @@
+package fecanalysis
+
+import (
+	"fmt"
+	"io"
+	"math/bits"
+	"strings"
+)
+
+// GF2Matrix is a dense matrix over GF(2) with every row stored as a bitset, bit c of a
+// row being column c
+type GF2Matrix struct {
+	rows    [][]uint64
+	columns int
+}
+
+// NewGF2Matrix creates a zero matrix with the given dimensions
+func NewGF2Matrix(rows, columns int) *GF2Matrix {
+	m := &GF2Matrix{rows: make([][]uint64, rows), columns: columns}
+	words := (columns + 63) / 64
+	for r := range m.rows {
+		m.rows[r] = make([]uint64, words)
+	}
+	return m
+}
+
+// ProtectionMatrix returns the K x N protection matrix of the mask: entry (f, p) is set if FEC
+// packet f protects media packet p
+func ProtectionMatrix(mask Mask) *GF2Matrix {
+	m := NewGF2Matrix(mask.K(), mask.N())
+	for fecIndex := 0; fecIndex < mask.K(); fecIndex++ {
+		for packetIndex := 0; packetIndex < mask.N(); packetIndex++ {
+			m.Set(fecIndex, packetIndex, mask.IsProtected(packetIndex, fecIndex))
+		}
+	}
+	return m
+}
+
+// ParityCheckMatrix returns the K x (N+K) parity-check matrix [A | I] of the mask, where A
+// is the protection matrix: the columns follow the recovery graph packet order (media
+// first, then FEC), and every sent block (media, FEC) satisfies H * block = 0
+func ParityCheckMatrix(mask Mask) *GF2Matrix {
+	N, K := mask.N(), mask.K()
+	m := NewGF2Matrix(K, N+K)
+	for fecIndex := 0; fecIndex < K; fecIndex++ {
+		for packetIndex := 0; packetIndex < N; packetIndex++ {
+			m.Set(fecIndex, packetIndex, mask.IsProtected(packetIndex, fecIndex))
+		}
+		m.Set(fecIndex, N+fecIndex, true)
+	}
+	return m
+}
+
+// Rows returns the number of rows
+func (m *GF2Matrix) Rows() int {
+	return len(m.rows)
+}
+
+// Columns returns the number of columns
+func (m *GF2Matrix) Columns() int {
+	return m.columns
+}
+
+// Get returns the entry at (row, column)
+func (m *GF2Matrix) Get(row, column int) bool {
+	return m.rows[row][column/64]&(1<<(column%64)) != 0
+}
+
+// Set sets the entry at (row, column)
+func (m *GF2Matrix) Set(row, column int, value bool) {
+	if value {
+		m.rows[row][column/64] |= 1 << (column % 64)
+	} else {
+		m.rows[row][column/64] &^= 1 << (column % 64)
+	}
+}
+
+// Clone returns a copy of the matrix
+func (m *GF2Matrix) Clone() *GF2Matrix {
+	clone := &GF2Matrix{rows: make([][]uint64, len(m.rows)), columns: m.columns}
+	for r, row := range m.rows {
+		clone.rows[r] = append([]uint64(nil), row...)
+	}
+	return clone
+}
+
+// Bools returns the matrix as a dense [row][column] slice
+func (m *GF2Matrix) Bools() [][]bool {
+	dense := make([][]bool, len(m.rows))
+	for r := range dense {
+		dense[r] = make([]bool, m.columns)
+		for c := range dense[r] {
+			dense[r][c] = m.Get(r, c)
+		}
+	}
+	return dense
+}
+
+// MulVector returns the product of the matrix with a column vector of Columns() entries
+func (m *GF2Matrix) MulVector(vector []bool) ([]bool, error) {
+	if len(vector) != m.columns {
+		return nil, fmt.Errorf("vector has %d entries, expected %d", len(vector), m.columns)
+	}
+	result := make([]bool, len(m.rows))
+	for r := range m.rows {
+		for c, value := range vector {
+			if value && m.Get(r, c) {
+				result[r] = !result[r]
+			}
+		}
+	}
+	return result, nil
+}
+
+// Rank returns the rank of the matrix over GF(2)
+func (m *GF2Matrix) Rank() int {
+	_, pivots := m.Clone().reduce()
+	return len(pivots)
+}
+
+// NullSpace returns a basis of the vectors x with M * x = 0, one vector of Columns()
+// entries per free column of the reduced row echelon form
+func (m *GF2Matrix) NullSpace() [][]bool {
+	reduced, pivots := m.Clone().reduce()
+	pivotColumn := make(map[int]int, len(pivots)) // column -> row of its pivot
+	for r, c := range pivots {
+		pivotColumn[c] = r
+	}
+
+	var basis [][]bool
+	for free := 0; free < m.columns; free++ {
+		if _, isPivot := pivotColumn[free]; isPivot {
+			continue
+		}
+		vector := make([]bool, m.columns)
+		vector[free] = true
+		for r, c := range pivots {
+			vector[c] = reduced.Get(r, free)
+		}
+		basis = append(basis, vector)
+	}
+	return basis
+}
+
+// reduce transforms the matrix in place into reduced row echelon form and returns it with
+// the pivot column of every nonzero row
+func (m *GF2Matrix) reduce() (*GF2Matrix, []int) {
+	var pivots []int
+	row := 0
+	for column := 0; column < m.columns && row < len(m.rows); column++ {
+		pivot := -1
+		for r := row; r < len(m.rows); r++ {
+			if m.Get(r, column) {
+				pivot = r
+				break
+			}
+		}
+		if pivot < 0 {
+			continue
+		}
+		m.rows[row], m.rows[pivot] = m.rows[pivot], m.rows[row]
+
+		for r := range m.rows {
+			if r != row && m.Get(r, column) {
+				for word := range m.rows[r] {
+					m.rows[r][word] ^= m.rows[row][word]
+				}
+			}
+		}
+		pivots = append(pivots, column)
+		row++
+	}
+	return m, pivots
+}
+
+// Weight returns the number of set entries in the row
+func (m *GF2Matrix) Weight(row int) int {
+	weight := 0
+	for _, word := range m.rows[row] {
+		weight += bits.OnesCount64(word)
+	}
+	return weight
+}
+
+// String formats the matrix as rows of 0/1 characters, the text format of ParseMaskText
+func (m *GF2Matrix) String() string {
+	var builder strings.Builder
+	for r := range m.rows {
+		for c := 0; c < m.columns; c++ {
+			if m.Get(r, c) {
+				builder.WriteByte('1')
+			} else {
+				builder.WriteByte('0')
+			}
+		}
+		builder.WriteByte('\n')
+	}
+	return builder.String()
+}
+
+// WriteTo writes the matrix in the text format of String, for external tools
+func (m *GF2Matrix) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, m.String())
+	return int64(n), err
+}
+
+// gf2Basis is an XOR basis of GF(2) vectors of up to 64 unknowns, the equations of the
+// decoders, in row echelon form indexed by the highest unknown of every vector
+type gf2Basis struct {
+	vectors [64]uint64
+	rank    int
+}
+
+// deliveredEquations returns the basis of the delivered FEC packets' equations of the
+// pattern, restricted to its missing media packets
+func deliveredEquations(rows []uint64, N int, pattern uint64) gf2Basis {
+	missing := (uint64(1)<<N - 1) &^ pattern
+	var basis gf2Basis
+	for fecIndex, row := range rows {
+		if pattern&(uint64(1)<<(N+fecIndex)) != 0 {
+			basis.insert(row & missing)
+		}
+	}
+	return basis
+}
+
+// insert adds the vector to the basis and returns true if it was independent of it
+func (b *gf2Basis) insert(vector uint64) bool {
+	vector = b.reduce(vector)
+	if vector == 0 {
+		return false
+	}
+	b.vectors[bits.Len64(vector)-1] = vector
+	b.rank++
+	return true
+}
+
+// reduce eliminates the leading unknowns of the vector with the basis vectors; the result is
+// zero exactly when the vector is in the span
+func (b *gf2Basis) reduce(vector uint64) uint64 {
+	for vector != 0 {
+		leading := b.vectors[bits.Len64(vector)-1]
+		if leading == 0 {
+			break
+		}
+		vector ^= leading
+	}
+	return vector
+}
+
+// spans returns true if the vector is the XOR of some basis vectors
+func (b *gf2Basis) spans(vector uint64) bool {
+	return b.reduce(vector) == 0
+}
+
+// determined returns the unknowns whose unit vector lies in the span, i.e. those with a
+// single-unknown row in the reduced row echelon form
+func (b *gf2Basis) determined() uint64 {
+	reduced := b.vectors
+	for pivot := range reduced {
+		if reduced[pivot] == 0 {
+			continue
+		}
+		for other := pivot + 1; other < len(reduced); other++ {
+			if reduced[other]&(uint64(1)<<pivot) != 0 {
+				reduced[other] ^= reduced[pivot]
+			}
+		}
+	}
+	determined := uint64(0)
+	for _, vector := range reduced {
+		if vector != 0 && vector&(vector-1) == 0 {
+			determined |= vector
+		}
+	}
+	return determined
+}
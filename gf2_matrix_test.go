@@ -0,0 +1,112 @@
+package fecanalysis
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGF2MatrixRank(t *testing.T) {
+	tests := []struct {
+		name string
+		rows [][]bool
+		rank int
+	}{
+		{"identity", [][]bool{{true, false}, {false, true}}, 2},
+		{"duplicate rows", [][]bool{{true, true, false}, {true, true, false}}, 1},
+		{"dependent row", [][]bool{{true, true, false}, {false, true, true}, {true, false, true}}, 2},
+		{"zero", [][]bool{{false, false}}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := ProtectionMatrix(NewSimpleMask(tt.rows, len(tt.rows[0]), len(tt.rows)))
+			assert.Equal(t, tt.rows, m.Bools())
+			assert.Equal(t, tt.rank, m.Rank())
+			assert.Len(t, m.NullSpace(), m.Columns()-tt.rank)
+		})
+	}
+}
+
+func TestParityCheckMatrixNullSpace(t *testing.T) {
+	mask, err := (&HammingMaskFactory{}).CreateMask(4, 3)
+	require.NoError(t, err)
+
+	h := ParityCheckMatrix(mask)
+	assert.Equal(t, 3, h.Rows())
+	assert.Equal(t, 7, h.Columns())
+	assert.Equal(t, 3, h.Rank(), "the identity part makes H full rank")
+
+	// The null space is the code: 2^4 codewords spanned by 4 basis vectors
+	basis := h.NullSpace()
+	require.Len(t, basis, 4)
+	for _, vector := range basis {
+		syndrome, err := h.MulVector(vector)
+		require.NoError(t, err)
+		assert.Equal(t, []bool{false, false, false}, syndrome)
+	}
+
+	// Every nonzero Hamming codeword has weight at least 3
+	for combination := 1; combination < 1<<len(basis); combination++ {
+		weight := 0
+		codeword := make([]bool, 7)
+		for i, vector := range basis {
+			if combination&(1<<i) != 0 {
+				for c := range codeword {
+					codeword[c] = codeword[c] != vector[c]
+				}
+			}
+		}
+		for _, bit := range codeword {
+			if bit {
+				weight++
+			}
+		}
+		assert.GreaterOrEqual(t, weight, 3)
+	}
+
+	_, err = h.MulVector(make([]bool, 3))
+	assert.Error(t, err)
+}
+
+func TestGF2MatrixExport(t *testing.T) {
+	mask := NewSimpleMask([][]bool{{true, false, true}, {false, true, true}}, 3, 2)
+	m := ProtectionMatrix(mask)
+	assert.Equal(t, "101\n011\n", m.String())
+	assert.Equal(t, 2, m.Weight(0))
+
+	// The export round-trips through the mask text format
+	var buf bytes.Buffer
+	_, err := m.WriteTo(&buf)
+	require.NoError(t, err)
+	parsed, err := ParseMaskText(strings.NewReader(buf.String()))
+	require.NoError(t, err)
+	assert.Equal(t, maskMatrix(mask), maskMatrix(parsed))
+
+	// Wide matrices span several words
+	wide := NewGF2Matrix(1, 130)
+	wide.Set(0, 129, true)
+	assert.True(t, wide.Get(0, 129))
+	assert.Equal(t, 1, wide.Rank())
+	wide.Set(0, 129, false)
+	assert.Equal(t, 0, wide.Rank())
+}
+
+func TestGF2Basis(t *testing.T) {
+	var basis gf2Basis
+	assert.True(t, basis.insert(0b0110))
+	assert.True(t, basis.insert(0b0011))
+	assert.False(t, basis.insert(0b0101), "XOR of the first two")
+	assert.False(t, basis.insert(0))
+	assert.Equal(t, 2, basis.rank)
+
+	assert.True(t, basis.spans(0b0101))
+	assert.False(t, basis.spans(0b0001))
+	assert.Zero(t, basis.determined(), "no unknown is determined by two equations in three unknowns")
+
+	assert.True(t, basis.insert(0b0001))
+	assert.Equal(t, uint64(0b0111), basis.determined())
+}
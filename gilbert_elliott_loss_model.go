@@ -12,9 +12,9 @@ type GilbertElliotLossModel struct {
 	P01 float64 // transition probability from good (0) to bad (1)
 	P10 float64 // transition probability from bad (1) to good (0)
 
-	// Pre-computed probability cache for dynamic programming
-	cache map[cacheKey]float64
-	mutex sync.RWMutex
+	// Pre-computed probability cache for dynamic programming, sharded so that
+	// concurrent evaluations of different patterns rarely contend on the same lock
+	shards [cacheShardCount]cacheShard
 
 	// Steady-state probabilities
 	steadyState0 float64 // steady-state probability of being in state 0
@@ -28,14 +28,32 @@ type cacheKey struct {
 	initState int // initial state (0 or 1)
 }
 
+// cacheShardCount is the number of independently locked cache shards (a power of two)
+const cacheShardCount = 1 << 6
+
+// cacheShard is one independently locked part of the probability cache
+type cacheShard struct {
+	mutex sync.RWMutex
+	cache map[cacheKey]float64
+}
+
+// shardFor returns the cache shard responsible for the given key
+func (m *GilbertElliotLossModel) shardFor(key cacheKey) *cacheShard {
+	// Fibonacci hashing spreads consecutive patterns across shards
+	hash := (uint64(key.pattern)<<8 ^ uint64(key.length)<<1 ^ uint64(key.initState)) * 0x9E3779B97F4A7C15
+	return &m.shards[hash>>(64-6)]
+}
+
 // NewGilbertElliotLossModel creates a new Gilbert-Elliott loss model
 func NewGilbertElliotLossModel(pe0, pe1, p01, p10 float64) *GilbertElliotLossModel {
 	model := &GilbertElliotLossModel{
-		Pe0:   pe0,
-		Pe1:   pe1,
-		P01:   p01,
-		P10:   p10,
-		cache: make(map[cacheKey]float64),
+		Pe0: pe0,
+		Pe1: pe1,
+		P01: p01,
+		P10: p10,
+	}
+	for i := range model.shards {
+		model.shards[i].cache = make(map[cacheKey]float64)
 	}
 
 	// Calculate steady-state probabilities
@@ -80,66 +98,136 @@ func (m *GilbertElliotLossModel) calculatePatternProbability(pattern int, length
 
 	key := cacheKey{pattern: pattern, length: length, initState: initState}
 
+	shard := m.shardFor(key)
+
 	// Check cache first
-	m.mutex.RLock()
-	if prob, exists := m.cache[key]; exists {
-		m.mutex.RUnlock()
+	shard.mutex.RLock()
+	if prob, exists := shard.cache[key]; exists {
+		shard.mutex.RUnlock()
 		return prob
 	}
-	m.mutex.RUnlock()
+	shard.mutex.RUnlock()
 
 	// Dynamic programming computation
 	prob := m.computePatternProbabilityDP(pattern, length, initState)
 
 	// Cache the result
-	m.mutex.Lock()
-	m.cache[key] = prob
-	m.mutex.Unlock()
+	shard.mutex.Lock()
+	shard.cache[key] = prob
+	shard.mutex.Unlock()
 
 	return prob
 }
 
-// computePatternProbabilityDP computes pattern probability using dynamic programming
+// computePatternProbabilityDP computes pattern probability using dynamic programming.
+// Only the previous DP row is needed, so the table is kept in two pairs of variables
+// and the computation does not allocate.
 func (m *GilbertElliotLossModel) computePatternProbabilityDP(pattern int, length int, initState int) float64 {
-	// DP table: dp[i][state] = probability of observing pattern[0..i-1] and ending in state
-	dp := make([][2]float64, length+1)
-
+	// prev0/prev1 = probability of observing pattern[0..i-1] and ending in state 0/1
 	// Base case: start with probability 1 in the initial state
-	if initState == 0 {
-		dp[0][0] = 1.0
-		dp[0][1] = 0.0
-	} else {
-		dp[0][0] = 0.0
-		dp[0][1] = 1.0
+	prev0, prev1 := 1.0, 0.0
+	if initState != 0 {
+		prev0, prev1 = 0.0, 1.0
 	}
 
-	// Fill DP table
-	for i := 1; i <= length; i++ {
-		packetIndex := i - 1
+	for packetIndex := 0; packetIndex < length; packetIndex++ {
 		packetDelivered := (pattern & (1 << packetIndex)) != 0
 
-		// Calculate probabilities for each state transition
-		// Transitions to state 0 (good)
+		// Probability of being in each state after the transition for this packet
+		in0 := prev0*(1.0-m.P01) + prev1*m.P10
+		in1 := prev0*m.P01 + prev1*(1.0-m.P10)
+
 		if packetDelivered {
 			// Packet delivered: probability (1 - Pe_state)
-			dp[i][0] = dp[i-1][0]*(1.0-m.P01)*(1.0-m.Pe0) + dp[i-1][1]*m.P10*(1.0-m.Pe0)
+			prev0, prev1 = in0*(1.0-m.Pe0), in1*(1.0-m.Pe1)
 		} else {
 			// Packet lost: probability Pe_state
-			dp[i][0] = dp[i-1][0]*(1.0-m.P01)*m.Pe0 + dp[i-1][1]*m.P10*m.Pe0
+			prev0, prev1 = in0*m.Pe0, in1*m.Pe1
 		}
+	}
 
-		// Transitions to state 1 (bad)
-		if packetDelivered {
-			// Packet delivered: probability (1 - Pe_state)
-			dp[i][1] = dp[i-1][0]*m.P01*(1.0-m.Pe1) + dp[i-1][1]*(1.0-m.P10)*(1.0-m.Pe1)
+	// Return total probability (sum over all ending states)
+	return prev0 + prev1
+}
+
+// SamplePattern draws a delivery pattern of N packets by running the Markov chain from its
+// steady state, with a transition before every packet as in CalculateProbability
+func (m *GilbertElliotLossModel) SamplePattern(N int, rng RandSource) uint64 {
+	bad := rng.Float64() < m.steadyState1
+	pattern := uint64(0)
+	for i := 0; i < N; i++ {
+		if bad {
+			bad = rng.Float64() >= m.P10
 		} else {
-			// Packet lost: probability Pe_state
-			dp[i][1] = dp[i-1][0]*m.P01*m.Pe1 + dp[i-1][1]*(1.0-m.P10)*m.Pe1
+			bad = rng.Float64() < m.P01
+		}
+		lossProbability := m.Pe0
+		if bad {
+			lossProbability = m.Pe1
+		}
+		if rng.Float64() >= lossProbability {
+			pattern |= uint64(1) << i
 		}
 	}
+	return pattern
+}
 
-	// Return total probability (sum over all ending states)
-	return dp[length][0] + dp[length][1]
+// VisitPatterns calls fn for every delivery pattern of N packets in decreasing order with its
+// probability. The patterns are the leaves of a walk from the last packet to the first that
+// carries the probabilities of the packets fixed so far for both channel states, so every
+// pattern costs one step instead of N and nothing is cached.
+func (m *GilbertElliotLossModel) VisitPatterns(N int, fn func(pattern uint64, probability float64)) {
+	m.walkPatterns(newGilbertElliottNode(N), func(pattern uint64, probability float64) error {
+		fn(pattern, probability)
+		return nil
+	})
+}
+
+// gilbertElliottNode is a node of the walk of VisitPatterns: the packets after index are fixed
+// to pattern, and good and bad are the probabilities of those packets given the channel state
+// before them. The patterns below a node are the 2^(index+1) consecutive patterns from pattern.
+type gilbertElliottNode struct {
+	index     int
+	pattern   uint64
+	good, bad float64
+}
+
+// newGilbertElliottNode returns the root of the walk over N packets, which fixes none
+func newGilbertElliottNode(N int) gilbertElliottNode {
+	return gilbertElliottNode{index: N - 1, good: 1, bad: 1}
+}
+
+// children returns the nodes fixing packet index to delivered and to lost; the channel
+// transitions before every packet, as in CalculateProbability
+func (n gilbertElliottNode) children(model *GilbertElliotLossModel) (delivered, lost gilbertElliottNode) {
+	deliveredGood, deliveredBad := (1-model.Pe0)*n.good, (1-model.Pe1)*n.bad
+	lostGood, lostBad := model.Pe0*n.good, model.Pe1*n.bad
+	delivered = gilbertElliottNode{n.index - 1, n.pattern | uint64(1)<<n.index,
+		(1-model.P01)*deliveredGood + model.P01*deliveredBad,
+		model.P10*deliveredGood + (1-model.P10)*deliveredBad}
+	lost = gilbertElliottNode{n.index - 1, n.pattern,
+		(1-model.P01)*lostGood + model.P01*lostBad,
+		model.P10*lostGood + (1-model.P10)*lostBad}
+	return delivered, lost
+}
+
+// probability returns the marginal probability of the fixed packets, which is the total
+// probability of the patterns below the node and the probability of the pattern of a leaf
+func (n gilbertElliottNode) probability(model *GilbertElliotLossModel) float64 {
+	return model.steadyState0*n.good + model.steadyState1*n.bad
+}
+
+// walkPatterns calls leaf for every pattern below the node in decreasing order with its
+// probability and stops at the first error
+func (m *GilbertElliotLossModel) walkPatterns(node gilbertElliottNode, leaf func(pattern uint64, probability float64) error) error {
+	if node.index < 0 {
+		return leaf(node.pattern, node.probability(m))
+	}
+	delivered, lost := node.children(m)
+	if err := m.walkPatterns(delivered, leaf); err != nil {
+		return err
+	}
+	return m.walkPatterns(lost, leaf)
 }
 
 // GetSteadyStateProbabilities returns the steady-state probabilities
@@ -149,12 +237,52 @@ func (m *GilbertElliotLossModel) GetSteadyStateProbabilities() (float64, float64
 
 // ClearCache clears the probability cache (useful for testing or memory management)
 func (m *GilbertElliotLossModel) ClearCache() {
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
-	m.cache = make(map[cacheKey]float64)
+	for i := range m.shards {
+		shard := &m.shards[i]
+		shard.mutex.Lock()
+		shard.cache = make(map[cacheKey]float64)
+		shard.mutex.Unlock()
+	}
 }
 
 // GetAverageLossProbability returns the steady-state average loss probability
 func (m *GilbertElliotLossModel) GetAverageLossProbability() float64 {
 	return m.steadyState0*m.Pe0 + m.steadyState1*m.Pe1
 }
+
+// LossCountDistribution returns the distribution of the number of lost packets among N
+// consecutive packets using dynamic programming over (lost count, channel state)
+func (m *GilbertElliotLossModel) LossCountDistribution(N int) []float64 {
+	if N < 0 {
+		return nil
+	}
+
+	// dp[k][state] = probability of having lost k packets so far and being in state
+	dp := make([][2]float64, N+1)
+	dp[0][0] = m.steadyState0
+	dp[0][1] = m.steadyState1
+
+	for i := 1; i <= N; i++ {
+		next := make([][2]float64, N+1)
+		for k := 0; k < i; k++ {
+			// Probability of being in each state after the transition for packet i
+			in0 := dp[k][0]*(1.0-m.P01) + dp[k][1]*m.P10
+			in1 := dp[k][0]*m.P01 + dp[k][1]*(1.0-m.P10)
+
+			// Packet delivered: loss count unchanged
+			next[k][0] += in0 * (1.0 - m.Pe0)
+			next[k][1] += in1 * (1.0 - m.Pe1)
+
+			// Packet lost: loss count increases
+			next[k+1][0] += in0 * m.Pe0
+			next[k+1][1] += in1 * m.Pe1
+		}
+		dp = next
+	}
+
+	distribution := make([]float64, N+1)
+	for k := 0; k <= N; k++ {
+		distribution[k] = dp[k][0] + dp[k][1]
+	}
+	return distribution
+}
@@ -1,6 +1,7 @@
 package fecanalysis
 
 import (
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -222,3 +223,33 @@ func BenchmarkGilbertElliotLossModel_WithCache(b *testing.B) {
 		model.CalculateProbability(pattern, N)
 	}
 }
+
+func TestGilbertElliotLossModel_ConcurrentEvaluation(t *testing.T) {
+	model := NewGilbertElliotLossModel(0.05, 0.7, 0.05, 0.2)
+	N := 10
+
+	expected := 0.0
+	for vertex := 0; vertex < (1 << N); vertex++ {
+		expected += NewGilbertElliotLossModel(0.05, 0.7, 0.05, 0.2).CalculateProbability(vertex, N)
+	}
+
+	// Evaluate all patterns from several goroutines sharing one model
+	const workers = 8
+	sums := make([]float64, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for vertex := 0; vertex < (1 << N); vertex++ {
+				sums[w] += model.CalculateProbability(vertex, N)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	for w := 0; w < workers; w++ {
+		assert.InDelta(t, expected, sums[w], 1e-12)
+	}
+	assert.InDelta(t, 1.0, expected, 1e-9)
+}
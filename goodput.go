@@ -0,0 +1,103 @@
+package fecanalysis
+
+import "fmt"
+
+// Goodput returns the expected media payload bytes available after decoding with the mode
+// under the loss model, partially recovered groups included, as a fraction of all bytes sent
+// for the block, given the payload size of every media packet and the size in bytes of every
+// media packet and every FEC packet on the wire. Unlike the delivery rate, it charges FEC
+// packets and packet headers for their size, so masks whose FEC packets cover many or large
+// media packets pay for it; see FlexFECPacketSizes for typical sizes.
+func Goodput(mask Mask, model LossModel, mode DecodingMode, payloadSizes, mediaSizes, fecSizes []int) (float64, error) {
+	if len(payloadSizes) != mask.N() {
+		return 0, fmt.Errorf("got %d payload sizes for %d media packets", len(payloadSizes), mask.N())
+	}
+	sentBytes, err := blockBytes(mask, mediaSizes, fecSizes)
+	if err != nil {
+		return 0, err
+	}
+	for packetIndex, payloadSize := range payloadSizes {
+		if payloadSize < 0 || payloadSize > mediaSizes[packetIndex] {
+			return 0, fmt.Errorf("payload size %d must be between 0 and the packet size %d", payloadSize, mediaSizes[packetIndex])
+		}
+	}
+
+	probabilities, err := PacketRecoveryProbabilities(mask, model, mode)
+	if err != nil {
+		return 0, err
+	}
+	availableBytes := 0.0
+	for packetIndex, probability := range probabilities {
+		availableBytes += probability * float64(payloadSizes[packetIndex])
+	}
+	return availableBytes / float64(sentBytes), nil
+}
+
+// FlexFECGoodput is Goodput for a mask sent as RTP with FlexFEC when every media packet
+// carries payloadSize bytes of payload and is available at the given delivery rate, such as
+// EvaluationResult.DeliveryRate, so it needs no further pass over the delivery patterns
+func FlexFECGoodput(mask Mask, payloadSize int, deliveryRate float64) (float64, error) {
+	payloadSizes := make([]int, mask.N())
+	for packetIndex := range payloadSizes {
+		payloadSizes[packetIndex] = payloadSize
+	}
+	mediaSizes, fecSizes, err := FlexFECPacketSizes(mask, payloadSizes)
+	if err != nil {
+		return 0, err
+	}
+	sentBytes, err := blockBytes(mask, mediaSizes, fecSizes)
+	if err != nil {
+		return 0, err
+	}
+	return deliveryRate * float64(mask.N()*payloadSize) / float64(sentBytes), nil
+}
+
+// blockBytes returns the bytes sent for a block of the mask with the given packet sizes
+func blockBytes(mask Mask, mediaSizes, fecSizes []int) (int, error) {
+	if len(mediaSizes) != mask.N() || len(fecSizes) != mask.K() {
+		return 0, fmt.Errorf("got %d media and %d FEC packet sizes for a %dx%d mask", len(mediaSizes), len(fecSizes), mask.N(), mask.K())
+	}
+	total := 0
+	for _, sizes := range [][]int{mediaSizes, fecSizes} {
+		for _, size := range sizes {
+			if size < 0 {
+				return 0, fmt.Errorf("packet size %d must be non-negative", size)
+			}
+			total += size
+		}
+	}
+	if total == 0 {
+		return 0, fmt.Errorf("block sends no bytes")
+	}
+	return total, nil
+}
+
+// FlexFECPacketSizes returns the wire sizes of the media and FEC packets of the mask sent as
+// RTP with FlexFEC, given the payload size of every media packet: media packets carry an RTP
+// header and FEC packets an RTP header, their FlexFEC header and a repair payload as large as
+// the largest media payload they protect
+func FlexFECPacketSizes(mask Mask, payloadSizes []int) (mediaSizes, fecSizes []int, err error) {
+	if len(payloadSizes) != mask.N() {
+		return nil, nil, fmt.Errorf("got %d payload sizes for %d media packets", len(payloadSizes), mask.N())
+	}
+	headers, err := FlexFECHeaderBytes(mask)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mediaSizes = make([]int, mask.N())
+	for packetIndex, payloadSize := range payloadSizes {
+		mediaSizes[packetIndex] = rtpHeaderBytes + payloadSize
+	}
+	fecSizes = make([]int, mask.K())
+	for fecIndex, header := range headers {
+		largest := 0
+		for packetIndex, payloadSize := range payloadSizes {
+			if mask.IsProtected(packetIndex, fecIndex) {
+				largest = max(largest, payloadSize)
+			}
+		}
+		fecSizes[fecIndex] = rtpHeaderBytes + header + largest
+	}
+	return mediaSizes, fecSizes, nil
+}
@@ -0,0 +1,97 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoodput(t *testing.T) {
+	// A media packet of single parity over 2 packets is available when delivered, or when
+	// the other two packets are: 0.9 + 0.1 * 0.81 = 0.981 under 10% random loss. Only its
+	// payload counts, not the header it is sent with.
+	parity, err := (&SingleParityMaskFactory{}).CreateMask(2, 1)
+	require.NoError(t, err)
+	model := NewRandomLossModel(0.1)
+	goodput, err := Goodput(parity, model, PeelingDecoding, []int{100, 100}, []int{100, 100}, []int{100})
+	require.NoError(t, err)
+	assert.InDelta(t, 2*0.981*100/300, goodput, 1e-12)
+	headers, err := Goodput(parity, model, PeelingDecoding, []int{88, 88}, []int{100, 100}, []int{100})
+	require.NoError(t, err)
+	assert.InDelta(t, 2*0.981*88/300, headers, 1e-12)
+
+	// Larger FEC packets lower the goodput at the same recovery
+	larger, err := Goodput(parity, model, PeelingDecoding, []int{100, 100}, []int{100, 100}, []int{200})
+	require.NoError(t, err)
+	assert.InDelta(t, 2*0.981*100/400, larger, 1e-12)
+
+	_, err = Goodput(parity, model, PeelingDecoding, []int{100, 100}, []int{100}, []int{100})
+	assert.Error(t, err)
+	_, err = Goodput(parity, model, PeelingDecoding, []int{100}, []int{100, 100}, []int{100})
+	assert.Error(t, err)
+	_, err = Goodput(parity, model, PeelingDecoding, []int{100, 101}, []int{100, 100}, []int{100})
+	assert.Error(t, err)
+	_, err = Goodput(parity, model, PeelingDecoding, []int{0, 0}, []int{100, -1}, []int{100})
+	assert.Error(t, err)
+	_, err = Goodput(parity, model, PeelingDecoding, []int{0, 0}, []int{0, 0}, []int{0})
+	assert.Error(t, err)
+}
+
+func TestFlexFECGoodput(t *testing.T) {
+	// Equal payloads make the goodput the delivery rate scaled by the payload share of the bytes sent
+	mask, err := (&GoogleBurstyMaskFactory{}).CreateMask(8, 4)
+	require.NoError(t, err)
+	model := NewGilbertElliotLossModel(0.01, 0.5, 0.05, 0.3)
+	payloads := []int{1200, 1200, 1200, 1200, 1200, 1200, 1200, 1200}
+	mediaSizes, fecSizes, err := FlexFECPacketSizes(mask, payloads)
+	require.NoError(t, err)
+	expected, err := Goodput(mask, model, PeelingDecoding, payloads, mediaSizes, fecSizes)
+	require.NoError(t, err)
+
+	evaluation, err := EvaluateMask(mask, model, EvaluationOptions{Mode: PeelingDecoding})
+	require.NoError(t, err)
+	goodput, err := FlexFECGoodput(mask, 1200, evaluation.DeliveryRate())
+	require.NoError(t, err)
+	assert.InDelta(t, expected, goodput, 1e-12)
+	assert.Less(t, goodput, evaluation.DeliveryRate()*8/12)
+}
+
+func TestFlexFECPacketSizes(t *testing.T) {
+	mask, err := (&GoogleBurstyMaskFactory{}).CreateMask(8, 4)
+	require.NoError(t, err)
+
+	// Equal payloads give the wire overhead
+	payloads := []int{1200, 1200, 1200, 1200, 1200, 1200, 1200, 1200}
+	mediaSizes, fecSizes, err := FlexFECPacketSizes(mask, payloads)
+	require.NoError(t, err)
+	overhead, err := FlexFECWireOverhead(mask, 1200)
+	require.NoError(t, err)
+	assert.InDelta(t, overhead, float64(sum(fecSizes))/float64(sum(mediaSizes)), 1e-12)
+
+	// A FEC packet is as large as the largest payload it protects
+	payloads[0] = 2000
+	_, fecSizes, err = FlexFECPacketSizes(mask, payloads)
+	require.NoError(t, err)
+	headers, err := FlexFECHeaderBytes(mask)
+	require.NoError(t, err)
+	for fecIndex, size := range fecSizes {
+		payload := 1200
+		if mask.IsProtected(0, fecIndex) {
+			payload = 2000
+		}
+		assert.Equal(t, rtpHeaderBytes+headers[fecIndex]+payload, size, "FEC packet %d", fecIndex)
+	}
+
+	_, _, err = FlexFECPacketSizes(mask, payloads[:7])
+	assert.Error(t, err)
+}
+
+// sum returns the sum of the values
+func sum(values []int) int {
+	total := 0
+	for _, value := range values {
+		total += value
+	}
+	return total
+}
@@ -6,22 +6,69 @@ import (
 )
 
 // GoogleRandomMaskFactory creates random masks using Google's predefined patterns
-type GoogleRandomMaskFactory struct{}
+type GoogleRandomMaskFactory struct {
+	// Table optionally replaces the built-in patterns, e.g. with tables loaded from
+	// WebRTC sources via LoadWebRTCMaskTable
+	Table MaskTable
+}
 
 // CreateMask creates a random mask with the specified N and K parameters
 func (f *GoogleRandomMaskFactory) CreateMask(N, K int) (Mask, error) {
-	pattern, err := f.getRandomPattern(N, K)
+	pattern, err := f.lookup(N, K)
 	if err != nil {
 		return nil, err
 	}
 
 	return &bitMask{
-		data: pattern,
-		n:    N,
-		k:    K,
+		data:     pattern,
+		n:        N,
+		k:        K,
+		rowBytes: webrtcRowBytes(N),
 	}, nil
 }
 
+// lookup returns the pattern for given N and K from Table if set, or from the built-in patterns
+func (f *GoogleRandomMaskFactory) lookup(N, K int) ([]byte, error) {
+	if f.Table == nil {
+		return f.getRandomPattern(N, K)
+	}
+	pattern, exists := f.Table[MaskSize{N: N, K: K}]
+	if !exists {
+		return nil, fmt.Errorf("no random mask pattern available for N=%d, K=%d in table", N, K)
+	}
+	return pattern, nil
+}
+
+// BuiltinTable returns the built-in random patterns as a MaskTable
+func (f *GoogleRandomMaskFactory) BuiltinTable() MaskTable {
+	return builtinMaskTable(f.getRandomPattern)
+}
+
+// RawTable returns the patterns the factory looks masks up in: Table if set, or the
+// built-in patterns
+func (f *GoogleRandomMaskFactory) RawTable() MaskTable {
+	return rawMaskTable(f.Table, f.BuiltinTable)
+}
+
+// CreateMaskUEP creates the mask the WebRTC encoder uses in unequal protection mode with
+// numImportant important packets at the start of the block. numImportant = 0 gives the
+// regular mask.
+func (f *GoogleRandomMaskFactory) CreateMaskUEP(N, K, numImportant int) (Mask, error) {
+	return webrtcUEPMask(f.lookup, N, K, numImportant, UEPModeOverlap)
+}
+
+// CreateMaskUEPMode is CreateMaskUEP with an explicit protection mode for the remaining packets
+func (f *GoogleRandomMaskFactory) CreateMaskUEPMode(N, K, numImportant int, mode UEPMode) (Mask, error) {
+	return webrtcUEPMask(f.lookup, N, K, numImportant, mode)
+}
+
+// Verify compares a table (typically parsed from WebRTC sources) against the built-in
+// patterns and returns every mismatch; an empty result means the tables are identical.
+// Masks with N above the built-in sizes (up to 12 media packets) are not compared.
+func (f *GoogleRandomMaskFactory) Verify(upstream MaskTable) []MaskTableMismatch {
+	return CompareMaskTables(upstream.builtinSizes(), f.BuiltinTable())
+}
+
 // getRandomPattern returns the predefined random pattern for given N and K
 func (f *GoogleRandomMaskFactory) getRandomPattern(N, K int) ([]byte, error) {
 	switch N {
@@ -1,69 +1,191 @@
 package fecanalysis
 
-import "container/list"
+import (
+	"context"
+	"iter"
+)
 
 // Graph represents an abstract graph interface
+// Vertices are uint64 so that recovery graphs of up to 63 packets are addressable
 type Graph interface {
 	// NumVertices returns the total number of vertices in the graph
-	NumVertices() int
+	NumVertices() uint64
 
 	// GetEdges returns a list of edges from the given vertex
 	// Each edge is represented as the destination vertex index
-	GetEdges(vertex int) []int
+	GetEdges(vertex uint64) []uint64
+
+	// VisitEdges calls fn for every edge from the given vertex, in GetEdges order, without
+	// allocating an edge list; it stops as soon as fn returns false
+	VisitEdges(vertex uint64, fn func(dst uint64) bool)
 }
 
 // BFS performs breadth-first search on the given graph starting from multiple source vertices
-// It returns a slice of all vertices reachable from any of the source vertices
-func BFS(graph Graph, sources []int) []int {
+// It returns a slice of all vertices reachable from any of the source vertices, in ascending order
+func BFS(graph Graph, sources []uint64) []uint64 {
 	if len(sources) == 0 {
 		return nil
 	}
+	return ReachableBFS(graph, sources).Vertices()
+}
 
-	// Create internal visited tracking for BFS algorithm
-	visited := make([]bool, graph.NumVertices())
-	var reachableVertices []int
+// ReachableBFS performs breadth-first search on the given graph starting from multiple source
+// vertices and returns the reachable vertices as a bitset, which is much smaller than a slice
+// of vertex indices for the 2^(N+K)-vertex recovery graphs
+func ReachableBFS(graph Graph, sources []uint64) *ReachableSet {
+	reachable, _ := traverseGraph(graph, sources, func(uint64, int32) bool { return true }, nil)
+	return reachable
+}
 
-	// Create a queue for BFS
-	queue := list.New()
+// ReachableBFSContext is ReachableBFS that stops with the context error once ctx is canceled
+// and reports the processed vertices out of NumVertices to progress, which may be nil
+func ReachableBFSContext(ctx context.Context, graph Graph, sources []uint64, progress ProgressFunc) (*ReachableSet, error) {
+	tracker := newProgressTracker(ctx, progress, graph.NumVertices())
+	reachable, err := traverseGraph(graph, sources, func(uint64, int32) bool { return true }, tracker)
+	if err != nil {
+		return nil, err
+	}
+	tracker.finish()
+	return reachable, nil
+}
 
-	// Mark all sources as visited, add to reachable list and queue
+// BFSDistances performs breadth-first search on the given graph starting from multiple source
+// vertices and returns the number of edges on the shortest path from any source to every
+// vertex, -1 for unreachable vertices
+func BFSDistances(graph Graph, sources []uint64) []int32 {
+	distances := make([]int32, graph.NumVertices())
+	for vertex := range distances {
+		distances[vertex] = -1
+	}
+	traverseGraph(graph, sources, func(vertex uint64, distance int32) bool {
+		distances[vertex] = distance
+		return true
+	}, nil)
+	return distances
+}
+
+// BFSWithMarker performs breadth-first search on the given graph starting from multiple source
+// vertices and records the reachable vertices in the marker instead of an in-memory bitset, so
+// callers choose how visited vertices are stored. The vertices of the current and the next
+// level are still kept in memory; use BFSWithFrontier when those do not fit either. Vertices
+// already marked are treated as visited and not expanded. It returns the number of newly
+// marked vertices.
+func BFSWithMarker(graph Graph, sources []uint64, marker VisitedMarker) uint64 {
+	reached := uint64(0)
+	traverseMarked(graph, sources, marker, func(uint64, int32) bool {
+		reached++
+		return true
+	}, nil)
+	return reached
+}
+
+// BFSWithFrontier is BFSWithMarker that also keeps the vertices of the current and the next
+// level in the bitsets current and next instead of a queue, so that with DiskVisitedMarkers no
+// part of the search grows with the graph in memory. Every level scans the whole current
+// bitset, which costs NumVertices/64 word reads per level. Both frontier bitsets must range
+// over the graph's vertices; their contents are overwritten.
+func BFSWithFrontier(graph Graph, sources []uint64, marker VisitedMarker, current, next BitsetVisitedMarker) uint64 {
+	mark := markFunc(graph.NumVertices(), marker)
+	current.Set().Clear()
+	next.Set().Clear()
+	reached := uint64(0)
+	reach := func(vertex uint64) bool {
+		if mark(vertex) {
+			next.MarkReachable(vertex)
+			reached++
+		}
+		return true
+	}
 	for _, source := range sources {
-		// Validate input
-		if source < 0 || source >= graph.NumVertices() {
-			continue
+		reach(source)
+	}
+
+	// Expand one level at a time until a level reaches no new vertices
+	for next.Set().Count() > 0 {
+		current, next = next, current
+		next.Set().Clear()
+		current.Set().Iterate(func(vertex uint64) {
+			graph.VisitEdges(vertex, reach)
+		})
+	}
+	return reached
+}
+
+// BFSIter returns an iterator over the vertices reachable from any of the source vertices,
+// in BFS order, so consumers can accumulate per-vertex values on the fly without
+// materializing the reachable slice. Stopping the iteration early stops the search.
+func BFSIter(graph Graph, sources []uint64) iter.Seq[uint64] {
+	return func(yield func(uint64) bool) {
+		traverseGraph(graph, sources, func(vertex uint64, _ int32) bool { return yield(vertex) }, nil)
+	}
+}
+
+// traverseGraph runs the breadth-first search on the graph with traverse and returns the
+// vertices reached until yield returned false or the tracker's context was canceled
+func traverseGraph(graph Graph, sources []uint64, yield func(vertex uint64, distance int32) bool, tracker *progressTracker) (*ReachableSet, error) {
+	reachable := NewReachableSet(graph.NumVertices())
+	err := traverseMarked(graph, sources, reachable, yield, tracker)
+	return reachable, err
+}
+
+// traverseMarked is traverseGraph that records the reached vertices in the marker, skipping
+// vertices outside the graph
+func traverseMarked(graph Graph, sources []uint64, marker VisitedMarker, yield func(vertex uint64, distance int32) bool, tracker *progressTracker) error {
+	return traverse(graph.VisitEdges, sources, markFunc(graph.NumVertices(), marker), yield, tracker)
+}
+
+// markFunc returns the mark function of traverse for the marker, which rejects vertices
+// outside 0..numVertices-1
+func markFunc(numVertices uint64, marker VisitedMarker) func(vertex uint64) bool {
+	if set, ok := marker.(*ReachableSet); ok {
+		// Bitsets test and mark in one call
+		return func(vertex uint64) bool {
+			return vertex < numVertices && set.Add(vertex)
 		}
-		if !visited[source] {
-			visited[source] = true
-			reachableVertices = append(reachableVertices, source)
-			queue.PushBack(source)
+	}
+	return func(vertex uint64) bool {
+		if vertex >= numVertices || marker.IsReachable(vertex) {
+			return false
 		}
+		marker.MarkReachable(vertex)
+		return true
 	}
+}
 
-	// Process vertices in BFS order
-	for queue.Len() > 0 {
-		// Dequeue a vertex
-		element := queue.Front()
-		queue.Remove(element)
-		current := element.Value.(int)
-
-		// Get all adjacent vertices
-		edges := graph.GetEdges(current)
-
-		// Process each adjacent vertex
-		for _, neighbor := range edges {
-			// Skip invalid vertices
-			if neighbor < 0 || neighbor >= graph.NumVertices() {
-				continue
-			}
+// traverse runs a breadth-first search over vertices of any type, one level at a time. mark
+// marks a vertex and returns true if it is valid and was not marked yet; yield is called with
+// every newly marked vertex and its distance from the sources until it returns false. The
+// search stops with an error when the tracker's context is canceled.
+func traverse[V comparable](visitEdges func(vertex V, fn func(dst V) bool), sources []V, mark func(V) bool, yield func(vertex V, distance int32) bool, tracker *progressTracker) error {
+	var level, next []V
+	distance := int32(0)
+	stopped := false
+	reach := func(vertex V) bool {
+		if mark(vertex) {
+			next = append(next, vertex)
+			stopped = !yield(vertex, distance)
+		}
+		return !stopped
+	}
+	for _, source := range sources {
+		if !reach(source) {
+			return nil
+		}
+	}
 
-			// If not yet visited, mark as visited, add to reachable list, then enqueue
-			if !visited[neighbor] {
-				visited[neighbor] = true
-				reachableVertices = append(reachableVertices, neighbor)
-				queue.PushBack(neighbor)
+	// Expand the vertices of every level in the order they were reached
+	for len(next) > 0 {
+		level, next = next, level[:0]
+		distance++
+		for _, current := range level {
+			if err := tracker.step(); err != nil {
+				return err
+			}
+			visitEdges(current, reach)
+			if stopped {
+				return nil
 			}
 		}
 	}
-
-	return reachableVertices
+	return nil
 }
@@ -1,9 +1,12 @@
 package fecanalysis
 
 import (
+	"math"
+	"math/bits"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // SimpleVisitedMarker is a basic implementation of VisitedMarker using a boolean slice
@@ -12,22 +15,22 @@ type SimpleVisitedMarker struct {
 }
 
 // NewSimpleVisitedMarker creates a new SimpleVisitedMarker for the given number of vertices
-func NewSimpleVisitedMarker(numVertices int) *SimpleVisitedMarker {
+func NewSimpleVisitedMarker(numVertices uint64) *SimpleVisitedMarker {
 	return &SimpleVisitedMarker{
 		visited: make([]bool, numVertices),
 	}
 }
 
 // MarkReachable marks the given vertex as reachable
-func (m *SimpleVisitedMarker) MarkReachable(vertex int) {
-	if vertex >= 0 && vertex < len(m.visited) {
+func (m *SimpleVisitedMarker) MarkReachable(vertex uint64) {
+	if vertex < uint64(len(m.visited)) {
 		m.visited[vertex] = true
 	}
 }
 
 // IsReachable returns true if the vertex has been marked as reachable
-func (m *SimpleVisitedMarker) IsReachable(vertex int) bool {
-	if vertex >= 0 && vertex < len(m.visited) {
+func (m *SimpleVisitedMarker) IsReachable(vertex uint64) bool {
+	if vertex < uint64(len(m.visited)) {
 		return m.visited[vertex]
 	}
 	return false
@@ -41,11 +44,11 @@ func (m *SimpleVisitedMarker) Reset() {
 }
 
 // GetReachableVertices returns a slice of all vertices marked as reachable
-func (m *SimpleVisitedMarker) GetReachableVertices() []int {
-	var reachable []int
+func (m *SimpleVisitedMarker) GetReachableVertices() []uint64 {
+	var reachable []uint64
 	for i, isReachable := range m.visited {
 		if isReachable {
-			reachable = append(reachable, i)
+			reachable = append(reachable, uint64(i))
 		}
 	}
 	return reachable
@@ -53,35 +56,44 @@ func (m *SimpleVisitedMarker) GetReachableVertices() []int {
 
 // SimpleGraph is a basic implementation of the Graph interface using an adjacency list
 type SimpleGraph struct {
-	numVertices int
-	adjList     [][]int
+	numVertices uint64
+	adjList     [][]uint64
 }
 
 // NewSimpleGraph creates a new SimpleGraph with the specified number of vertices
 func NewSimpleGraph(numVertices int) *SimpleGraph {
 	return &SimpleGraph{
-		numVertices: numVertices,
-		adjList:     make([][]int, numVertices),
+		numVertices: uint64(numVertices),
+		adjList:     make([][]uint64, numVertices),
 	}
 }
 
 // NumVertices returns the total number of vertices in the graph
-func (g *SimpleGraph) NumVertices() int {
+func (g *SimpleGraph) NumVertices() uint64 {
 	return g.numVertices
 }
 
 // GetEdges returns a list of edges from the given vertex
-func (g *SimpleGraph) GetEdges(vertex int) []int {
-	if vertex >= 0 && vertex < g.numVertices {
+func (g *SimpleGraph) GetEdges(vertex uint64) []uint64 {
+	if vertex < g.numVertices {
 		return g.adjList[vertex]
 	}
 	return nil
 }
 
+// VisitEdges calls fn for every edge from the given vertex until it returns false
+func (g *SimpleGraph) VisitEdges(vertex uint64, fn func(dst uint64) bool) {
+	for _, destination := range g.GetEdges(vertex) {
+		if !fn(destination) {
+			return
+		}
+	}
+}
+
 // AddEdge adds a directed edge from source to destination vertex
 func (g *SimpleGraph) AddEdge(source, destination int) {
-	if source >= 0 && source < g.numVertices && destination >= 0 && destination < g.numVertices {
-		g.adjList[source] = append(g.adjList[source], destination)
+	if source >= 0 && uint64(source) < g.numVertices && destination >= 0 && uint64(destination) < g.numVertices {
+		g.adjList[source] = append(g.adjList[source], uint64(destination))
 	}
 }
 
@@ -96,10 +108,10 @@ func TestSimpleGraph(t *testing.T) {
 	graph := NewSimpleGraph(5)
 
 	// Test basic properties
-	assert.Equal(t, 5, graph.NumVertices())
+	assert.Equal(t, uint64(5), graph.NumVertices())
 
 	// Initially, no edges should exist
-	for i := 0; i < 5; i++ {
+	for i := uint64(0); i < 5; i++ {
 		edges := graph.GetEdges(i)
 		assert.Empty(t, edges, "Vertex %d should have no edges initially", i)
 	}
@@ -113,18 +125,18 @@ func TestSimpleGraph(t *testing.T) {
 	// Test edges from vertex 0
 	edges0 := graph.GetEdges(0)
 	assert.Len(t, edges0, 2)
-	assert.Contains(t, edges0, 1)
-	assert.Contains(t, edges0, 2)
+	assert.Contains(t, edges0, uint64(1))
+	assert.Contains(t, edges0, uint64(2))
 
 	// Test edges from vertex 1
 	edges1 := graph.GetEdges(1)
 	assert.Len(t, edges1, 1)
-	assert.Contains(t, edges1, 3)
+	assert.Contains(t, edges1, uint64(3))
 
 	// Test edges from vertex 2
 	edges2 := graph.GetEdges(2)
 	assert.Len(t, edges2, 1)
-	assert.Contains(t, edges2, 4)
+	assert.Contains(t, edges2, uint64(4))
 
 	// Test vertices with no outgoing edges
 	edges3 := graph.GetEdges(3)
@@ -142,18 +154,18 @@ func TestSimpleGraphUndirectedEdges(t *testing.T) {
 	// Both vertices should have edges to each other
 	edges0 := graph.GetEdges(0)
 	assert.Len(t, edges0, 1)
-	assert.Contains(t, edges0, 1)
+	assert.Contains(t, edges0, uint64(1))
 
 	edges1 := graph.GetEdges(1)
 	assert.Len(t, edges1, 1)
-	assert.Contains(t, edges1, 0)
+	assert.Contains(t, edges1, uint64(0))
 }
 
 func TestSimpleGraphBoundaryConditions(t *testing.T) {
 	graph := NewSimpleGraph(3)
 
 	// Test invalid vertex indices
-	assert.Nil(t, graph.GetEdges(-1))
+	assert.Nil(t, graph.GetEdges(math.MaxUint64))
 	assert.Nil(t, graph.GetEdges(3))
 
 	// Test adding invalid edges (should not panic)
@@ -163,7 +175,7 @@ func TestSimpleGraphBoundaryConditions(t *testing.T) {
 	graph.AddEdge(0, 3)
 
 	// Graph should remain unchanged
-	for i := 0; i < 3; i++ {
+	for i := uint64(0); i < 3; i++ {
 		assert.Empty(t, graph.GetEdges(i))
 	}
 }
@@ -172,7 +184,7 @@ func TestSimpleVisitedMarker(t *testing.T) {
 	marker := NewSimpleVisitedMarker(5)
 
 	// Initially, no vertices should be marked as reachable
-	for i := 0; i < 5; i++ {
+	for i := uint64(0); i < 5; i++ {
 		assert.False(t, marker.IsReachable(i), "Vertex %d should not be reachable initially", i)
 	}
 
@@ -191,13 +203,13 @@ func TestSimpleVisitedMarker(t *testing.T) {
 	// Test GetReachableVertices
 	reachable := marker.GetReachableVertices()
 	assert.Len(t, reachable, 3)
-	assert.Contains(t, reachable, 0)
-	assert.Contains(t, reachable, 2)
-	assert.Contains(t, reachable, 4)
+	assert.Contains(t, reachable, uint64(0))
+	assert.Contains(t, reachable, uint64(2))
+	assert.Contains(t, reachable, uint64(4))
 
 	// Test reset
 	marker.Reset()
-	for i := 0; i < 5; i++ {
+	for i := uint64(0); i < 5; i++ {
 		assert.False(t, marker.IsReachable(i), "Vertex %d should not be reachable after reset", i)
 	}
 	assert.Empty(t, marker.GetReachableVertices())
@@ -207,15 +219,15 @@ func TestSimpleVisitedMarkerBoundaryConditions(t *testing.T) {
 	marker := NewSimpleVisitedMarker(3)
 
 	// Test invalid indices
-	assert.False(t, marker.IsReachable(-1))
 	assert.False(t, marker.IsReachable(3))
+	assert.False(t, marker.IsReachable(math.MaxUint64))
 
 	// Marking invalid indices should not panic
-	marker.MarkReachable(-1)
 	marker.MarkReachable(3)
+	marker.MarkReachable(math.MaxUint64)
 
 	// Should not affect valid vertices
-	for i := 0; i < 3; i++ {
+	for i := uint64(0); i < 3; i++ {
 		assert.False(t, marker.IsReachable(i))
 	}
 }
@@ -229,12 +241,12 @@ func TestBFSLinearGraph(t *testing.T) {
 	graph.AddEdge(3, 4)
 
 	// Run BFS from vertex 0
-	reachable := BFS(graph, []int{0})
+	reachable := BFS(graph, []uint64{0})
 
 	// All vertices should be reachable
 	assert.Len(t, reachable, 5)
 	for i := 0; i < 5; i++ {
-		assert.Contains(t, reachable, i, "Vertex %d should be reachable from vertex 0", i)
+		assert.Contains(t, reachable, uint64(i), "Vertex %d should be reachable from vertex 0", i)
 	}
 }
 
@@ -245,15 +257,15 @@ func TestBFSDisconnectedGraph(t *testing.T) {
 	graph.AddEdge(2, 3)
 
 	// Run BFS from vertex 0
-	reachable := BFS(graph, []int{0})
+	reachable := BFS(graph, []uint64{0})
 
 	// Only vertices 0 and 1 should be reachable
 	assert.Len(t, reachable, 2)
-	assert.Contains(t, reachable, 0)
-	assert.Contains(t, reachable, 1)
-	assert.NotContains(t, reachable, 2)
-	assert.NotContains(t, reachable, 3)
-	assert.NotContains(t, reachable, 4)
+	assert.Contains(t, reachable, uint64(0))
+	assert.Contains(t, reachable, uint64(1))
+	assert.NotContains(t, reachable, uint64(2))
+	assert.NotContains(t, reachable, uint64(3))
+	assert.NotContains(t, reachable, uint64(4))
 }
 
 func TestBFSStarGraph(t *testing.T) {
@@ -264,21 +276,21 @@ func TestBFSStarGraph(t *testing.T) {
 	}
 
 	// Run BFS from vertex 0
-	reachable := BFS(graph, []int{0})
+	reachable := BFS(graph, []uint64{0})
 
 	// All vertices should be reachable
 	assert.Len(t, reachable, 5)
 	for i := 0; i < 5; i++ {
-		assert.Contains(t, reachable, i, "Vertex %d should be reachable from center vertex 0", i)
+		assert.Contains(t, reachable, uint64(i), "Vertex %d should be reachable from center vertex 0", i)
 	}
 
 	// Run BFS from vertex 1 (leaf node)
-	reachable = BFS(graph, []int{1})
+	reachable = BFS(graph, []uint64{1})
 
 	// All vertices should still be reachable (undirected graph)
 	assert.Len(t, reachable, 5)
 	for i := 0; i < 5; i++ {
-		assert.Contains(t, reachable, i, "Vertex %d should be reachable from leaf vertex 1", i)
+		assert.Contains(t, reachable, uint64(i), "Vertex %d should be reachable from leaf vertex 1", i)
 	}
 }
 
@@ -290,12 +302,12 @@ func TestBFSCyclicGraph(t *testing.T) {
 	graph.AddEdge(2, 0)
 
 	// Run BFS from vertex 0
-	reachable := BFS(graph, []int{0})
+	reachable := BFS(graph, []uint64{0})
 
 	// All vertices should be reachable
 	assert.Len(t, reachable, 3)
 	for i := 0; i < 3; i++ {
-		assert.Contains(t, reachable, i, "Vertex %d should be reachable in cyclic graph", i)
+		assert.Contains(t, reachable, uint64(i), "Vertex %d should be reachable in cyclic graph", i)
 	}
 }
 
@@ -304,11 +316,11 @@ func TestBFSSingleVertex(t *testing.T) {
 	graph := NewSimpleGraph(1)
 
 	// Run BFS from the only vertex
-	reachable := BFS(graph, []int{0})
+	reachable := BFS(graph, []uint64{0})
 
 	// Only the source vertex should be reachable
 	assert.Len(t, reachable, 1)
-	assert.Contains(t, reachable, 0)
+	assert.Contains(t, reachable, uint64(0))
 }
 
 func TestBFSMultiSource(t *testing.T) {
@@ -318,15 +330,15 @@ func TestBFSMultiSource(t *testing.T) {
 	graph.AddEdge(2, 3)
 
 	// Run BFS from multiple sources: 0 and 2
-	reachable := BFS(graph, []int{0, 2})
+	reachable := BFS(graph, []uint64{0, 2})
 
 	// Should reach vertices 0, 1, 2, 3 but not 4
 	assert.Len(t, reachable, 4)
-	assert.Contains(t, reachable, 0)
-	assert.Contains(t, reachable, 1)
-	assert.Contains(t, reachable, 2)
-	assert.Contains(t, reachable, 3)
-	assert.NotContains(t, reachable, 4)
+	assert.Contains(t, reachable, uint64(0))
+	assert.Contains(t, reachable, uint64(1))
+	assert.Contains(t, reachable, uint64(2))
+	assert.Contains(t, reachable, uint64(3))
+	assert.NotContains(t, reachable, uint64(4))
 }
 
 func TestBFSComplexGraph(t *testing.T) {
@@ -346,20 +358,20 @@ func TestBFSComplexGraph(t *testing.T) {
 	graph.AddEdge(3, 7)
 
 	// Run BFS from root vertex 0
-	reachable := BFS(graph, []int{0})
+	reachable := BFS(graph, []uint64{0})
 
 	// All vertices should be reachable
 	assert.Len(t, reachable, 8)
 	for i := 0; i < 8; i++ {
-		assert.Contains(t, reachable, i, "Vertex %d should be reachable from root vertex 0", i)
+		assert.Contains(t, reachable, uint64(i), "Vertex %d should be reachable from root vertex 0", i)
 	}
 
 	// Run BFS from a leaf vertex
-	reachable = BFS(graph, []int{4})
+	reachable = BFS(graph, []uint64{4})
 
 	// Only vertex 4 should be reachable (no outgoing edges)
 	assert.Len(t, reachable, 1)
-	assert.Contains(t, reachable, 4)
+	assert.Contains(t, reachable, uint64(4))
 }
 
 func TestBFSWithSelfLoop(t *testing.T) {
@@ -369,12 +381,12 @@ func TestBFSWithSelfLoop(t *testing.T) {
 	graph.AddEdge(0, 1)
 
 	// Run BFS from vertex 0
-	reachable := BFS(graph, []int{0})
+	reachable := BFS(graph, []uint64{0})
 
 	// Both vertices should be reachable
 	assert.Len(t, reachable, 2)
-	assert.Contains(t, reachable, 0)
-	assert.Contains(t, reachable, 1)
+	assert.Contains(t, reachable, uint64(0))
+	assert.Contains(t, reachable, uint64(1))
 }
 
 func TestBFSMultiSourceOverlapping(t *testing.T) {
@@ -388,12 +400,12 @@ func TestBFSMultiSourceOverlapping(t *testing.T) {
 	graph.AddEdge(1, 4)
 
 	// Run BFS from sources 0 and 3 (both can reach 1, 2, 4)
-	reachable := BFS(graph, []int{0, 3})
+	reachable := BFS(graph, []uint64{0, 3})
 
 	// Should reach all vertices
 	assert.Len(t, reachable, 5)
 	for i := 0; i < 5; i++ {
-		assert.Contains(t, reachable, i, "Vertex %d should be reachable", i)
+		assert.Contains(t, reachable, uint64(i), "Vertex %d should be reachable", i)
 	}
 }
 
@@ -404,11 +416,100 @@ func TestBFSMultiSourceDuplicates(t *testing.T) {
 	graph.AddEdge(1, 2)
 
 	// Run BFS with duplicate sources
-	reachable := BFS(graph, []int{0, 0, 0})
+	reachable := BFS(graph, []uint64{0, 0, 0})
 
 	// Should still work correctly and reach all vertices
 	assert.Len(t, reachable, 3)
-	assert.Contains(t, reachable, 0)
-	assert.Contains(t, reachable, 1)
-	assert.Contains(t, reachable, 2)
+	assert.Contains(t, reachable, uint64(0))
+	assert.Contains(t, reachable, uint64(1))
+	assert.Contains(t, reachable, uint64(2))
+}
+
+func TestBFSIter(t *testing.T) {
+	// 0 -> 1 -> 2, 0 -> 3
+	graph := NewSimpleGraph(5)
+	graph.AddEdge(0, 1)
+	graph.AddEdge(1, 2)
+	graph.AddEdge(0, 3)
+
+	var visited []uint64
+	for vertex := range BFSIter(graph, []uint64{0, 0}) {
+		visited = append(visited, vertex)
+	}
+	assert.Equal(t, []uint64{0, 1, 3, 2}, visited, "vertices come in BFS order")
+
+	// Breaking out of the loop stops the search
+	visited = nil
+	for vertex := range BFSIter(graph, []uint64{0}) {
+		visited = append(visited, vertex)
+		if len(visited) == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []uint64{0, 1}, visited)
+}
+
+func TestBFSIterAccumulatesProbability(t *testing.T) {
+	mask, err := (&GoogleBurstyMaskFactory{}).CreateMask(6, 3)
+	assert.NoError(t, err)
+	model := NewRandomLossModel(0.1)
+
+	probability := 0.0
+	for vertex := range BFSIter(NewRecoveryGraph(mask), GoodVertices(6, 3)) {
+		probability += model.CalculateProbability(int(vertex), 9)
+	}
+	assert.InDelta(t, RecoveryProbability(mask, model), probability, 1e-12)
+}
+
+func TestBFSDistances(t *testing.T) {
+	// 0 -> 1 -> 2 -> 3, 0 -> 2, 4 isolated
+	graph := NewSimpleGraph(5)
+	graph.AddEdge(0, 1)
+	graph.AddEdge(1, 2)
+	graph.AddEdge(2, 3)
+	graph.AddEdge(0, 2)
+
+	assert.Equal(t, []int32{0, 1, 1, 2, -1}, BFSDistances(graph, []uint64{0}))
+	assert.Equal(t, []int32{-1, 0, 1, 2, 0}, BFSDistances(graph, []uint64{1, 4}))
+	assert.Equal(t, []int32{-1, -1, -1, -1, -1}, BFSDistances(graph, nil))
+
+	// In the recovery graph the hop count is the number of lost media packets
+	mask, err := (&GoogleBurstyMaskFactory{}).CreateMask(4, 2)
+	require.NoError(t, err)
+	for vertex, distance := range BFSDistances(NewRecoveryGraph(mask), GoodVertices(4, 2)) {
+		if distance >= 0 {
+			assert.Equal(t, int32(4-bits.OnesCount64(uint64(vertex)&0b1111)), distance)
+		}
+	}
+}
+
+func TestBFSWithMarker(t *testing.T) {
+	// 0 -> 1 -> 2 -> 3, 4 -> 3
+	graph := NewSimpleGraph(5)
+	graph.AddEdge(0, 1)
+	graph.AddEdge(1, 2)
+	graph.AddEdge(2, 3)
+	graph.AddEdge(4, 3)
+
+	marker := NewSimpleVisitedMarker(5)
+	assert.Equal(t, uint64(4), BFSWithMarker(graph, []uint64{0, 0, 7}, marker))
+	assert.Equal(t, []uint64{0, 1, 2, 3}, marker.GetReachableVertices())
+
+	// Marked vertices block the search
+	marker = NewSimpleVisitedMarker(5)
+	marker.MarkReachable(2)
+	assert.Equal(t, uint64(2), BFSWithMarker(graph, []uint64{0}, marker))
+	assert.Equal(t, []uint64{0, 1, 2}, marker.GetReachableVertices())
+
+	// Any marker yields the same vertices as ReachableBFS
+	mask, err := (&GoogleRandomMaskFactory{}).CreateMask(6, 3)
+	require.NoError(t, err)
+	recovery := NewRecoveryGraph(mask)
+	expected := ReachableBFS(recovery, GoodVertices(6, 3))
+	marker = NewSimpleVisitedMarker(recovery.NumVertices())
+	set := NewReachableSet(recovery.NumVertices())
+	assert.Equal(t, expected.Count(), BFSWithMarker(recovery, GoodVertices(6, 3), marker))
+	assert.Equal(t, expected.Count(), BFSWithMarker(recovery, GoodVertices(6, 3), set))
+	assert.Equal(t, expected.Vertices(), set.Vertices())
+	assert.Equal(t, expected.Vertices(), marker.GetReachableVertices())
 }
@@ -0,0 +1,111 @@
+package fecanalysis
+
+import "math"
+
+// Estimate is a Monte Carlo estimate of a probability together with its standard error
+type Estimate struct {
+	Value    float64 `json:"value"`     // estimated probability
+	StdError float64 `json:"std_error"` // standard error of the estimate
+	Samples  int     `json:"samples"`   // number of samples used
+}
+
+// ConfidenceInterval returns the normal-approximation interval Value ± z*StdError,
+// clamped to [0, 1] (z = 1.96 gives the usual 95% interval). When every sample agreed, e.g.
+// no sampled pattern failed, the standard error is 0 and that interval would be a point, so
+// the margin is the Wilson score bound z²/(n+z²) for n samples instead, about 3.84/n at
+// z = 1.96 like the rule of three. Exact values (no samples) stay a point.
+func (e Estimate) ConfidenceInterval(z float64) (float64, float64) {
+	margin := z * e.StdError
+	if e.StdError == 0 && e.Samples > 0 {
+		margin = z * z / (float64(e.Samples) + z*z)
+	}
+	low := math.Max(0.0, e.Value-margin)
+	high := math.Min(1.0, e.Value+margin)
+	return low, high
+}
+
+// ImportanceSampler draws delivery patterns from a biased proposal distribution where
+// every packet is lost independently with BiasedLossProbability, and weights each
+// sample by the likelihood ratio Model(pattern) / proposal(pattern).
+// Biasing toward lossy patterns makes rare non-recoverable patterns show up often
+// enough to estimate their (tiny) total probability with a reasonable sample count.
+type ImportanceSampler struct {
+	Model                 LossModel // loss model whose probabilities are estimated
+	BiasedLossProbability float64   // per-packet loss probability of the proposal distribution
+
+	rng RandSource
+}
+
+// NewImportanceSampler creates an importance sampler for the given loss model.
+// If rng is nil, a source derived from the package-level seed is used.
+func NewImportanceSampler(model LossModel, biasedLossProbability float64, rng RandSource) *ImportanceSampler {
+	return &ImportanceSampler{
+		Model:                 model,
+		BiasedLossProbability: biasedLossProbability,
+		rng:                   randSourceOrDefault(rng),
+	}
+}
+
+// SuggestBiasedLossProbability returns a proposal loss probability under which a group
+// of N packets loses targetLosses packets on average, which is a good starting point
+// when the event of interest needs at least targetLosses losses
+func SuggestBiasedLossProbability(N, targetLosses int) float64 {
+	if N <= 0 {
+		return 0.5
+	}
+	q := float64(targetLosses) / float64(N)
+	return math.Min(math.Max(q, 1.0/float64(2*N)), 1.0-1.0/float64(2*N))
+}
+
+// Sample draws a delivery pattern of N packets from the proposal distribution and
+// returns it together with its likelihood-ratio weight
+func (s *ImportanceSampler) Sample(N int) (int, float64) {
+	q := s.BiasedLossProbability
+	vertex := 0
+	lost := 0
+	for i := 0; i < N; i++ {
+		if s.rng.Float64() < q {
+			lost++
+		} else {
+			vertex |= 1 << i // packet delivered
+		}
+	}
+
+	proposal := math.Pow(q, float64(lost)) * math.Pow(1.0-q, float64(N-lost))
+	if proposal == 0 {
+		return vertex, 0.0
+	}
+	return vertex, s.Model.CalculateProbability(vertex, N) / proposal
+}
+
+// EstimateProbability estimates the probability under Model that a group of N packets
+// ends up in a delivery pattern for which event returns true
+func (s *ImportanceSampler) EstimateProbability(N, samples int, event func(vertex int) bool) Estimate {
+	if samples <= 0 {
+		return Estimate{}
+	}
+
+	sum := 0.0
+	sumSquares := 0.0
+	for i := 0; i < samples; i++ {
+		vertex, weight := s.Sample(N)
+		if !event(vertex) {
+			continue
+		}
+		sum += weight
+		sumSquares += weight * weight
+	}
+
+	n := float64(samples)
+	mean := sum / n
+	variance := 0.0
+	if samples > 1 {
+		variance = math.Max(0.0, (sumSquares-n*mean*mean)/(n-1))
+	}
+
+	return Estimate{
+		Value:    mean,
+		StdError: math.Sqrt(variance / n),
+		Samples:  samples,
+	}
+}
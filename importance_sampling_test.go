@@ -0,0 +1,56 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportanceSamplerRareEvent(t *testing.T) {
+	model := NewGilbertElliotLossModel(0.01, 0.3, 0.02, 0.5)
+	N := 16
+	minLosses := 8
+
+	// Exact probability of losing at least minLosses packets
+	exact := TailLossProbability(model, N, minLosses)
+	assert.Less(t, exact, 1e-4, "event should be rare for this test to be meaningful")
+
+	sampler := NewImportanceSampler(model, SuggestBiasedLossProbability(N, minLosses), NewRandSource(42))
+	estimate := sampler.EstimateProbability(N, 20000, func(vertex int) bool {
+		return N-popcount(vertex) >= minLosses
+	})
+
+	assert.Equal(t, 20000, estimate.Samples)
+	assert.Greater(t, estimate.StdError, 0.0)
+	low, high := estimate.ConfidenceInterval(4.0)
+	assert.True(t, low <= exact && exact <= high,
+		"exact %.3e should be within [%.3e, %.3e]", exact, low, high)
+}
+
+func TestImportanceSamplerDeterministic(t *testing.T) {
+	model := NewRandomLossModel(0.1)
+	event := func(vertex int) bool { return vertex != 0xff }
+
+	first := NewImportanceSampler(model, 0.3, NewRandSource(7)).EstimateProbability(8, 1000, event)
+	second := NewImportanceSampler(model, 0.3, NewRandSource(7)).EstimateProbability(8, 1000, event)
+	assert.Equal(t, first, second)
+}
+
+func TestEstimateConfidenceInterval(t *testing.T) {
+	low, high := Estimate{Value: 0.5, StdError: 0.1, Samples: 100}.ConfidenceInterval(2)
+	assert.InDelta(t, 0.3, low, 1e-12)
+	assert.InDelta(t, 0.7, high, 1e-12)
+
+	// No failure among the samples still bounds the failure probability
+	low, high = Estimate{Samples: 1000}.ConfidenceInterval(1.96)
+	assert.Zero(t, low)
+	assert.InDelta(t, 1.96*1.96/(1000+1.96*1.96), high, 1e-12)
+	low, high = Estimate{Value: 1, Samples: 1000}.ConfidenceInterval(1.96)
+	assert.InDelta(t, 1-1.96*1.96/(1000+1.96*1.96), low, 1e-12)
+	assert.Equal(t, 1.0, high)
+
+	// Exact values are a point
+	low, high = Estimate{Value: 0.25}.ConfidenceInterval(1.96)
+	assert.Equal(t, 0.25, low)
+	assert.Equal(t, 0.25, high)
+}
@@ -0,0 +1,47 @@
+package fecanalysis
+
+import "fmt"
+
+// LayeredMask implements triangular (layered) protection: FEC packet i protects the
+// media packets 0..ceil((i+1)*N/K)-1, so the first FEC packet covers the first layer
+// only and the last FEC packet covers the whole block. Early packets get the most
+// protection and can be recovered before the block is complete, a common unequal-delay design.
+// With K > N several consecutive FEC packets share a layer.
+type LayeredMask struct {
+	n int // number of media packets
+	k int // number of FEC packets
+}
+
+// IsProtected returns true if the packet at packetIndex is protected by FEC at fecIndex
+func (m *LayeredMask) IsProtected(packetIndex, fecIndex int) bool {
+	if packetIndex < 0 || packetIndex >= m.n || fecIndex < 0 || fecIndex >= m.k {
+		return false
+	}
+	// Layer end is ceil((fecIndex+1)*N/K)
+	return packetIndex < ((fecIndex+1)*m.n+m.k-1)/m.k
+}
+
+// N returns the number of media packets
+func (m *LayeredMask) N() int {
+	return m.n
+}
+
+// K returns the number of FEC packets
+func (m *LayeredMask) K() int {
+	return m.k
+}
+
+// LayeredMaskFactory creates triangular (layered) protection masks
+type LayeredMaskFactory struct{}
+
+// CreateMask creates a layered mask with N media packets and K FEC packets
+func (f *LayeredMaskFactory) CreateMask(N, K int) (Mask, error) {
+	if N <= 0 || K <= 0 {
+		return nil, fmt.Errorf("invalid parameters for layered mask: N=%d, K=%d", N, K)
+	}
+
+	return &LayeredMask{
+		n: N,
+		k: K,
+	}, nil
+}
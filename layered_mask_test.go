@@ -0,0 +1,58 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayeredMask(t *testing.T) {
+	tests := []struct {
+		name     string
+		N, K     int
+		expected [][]bool
+	}{
+		{
+			name: "even layers",
+			N:    4, K: 2,
+			expected: [][]bool{
+				{true, true, false, false},
+				{true, true, true, true},
+			},
+		},
+		{
+			name: "uneven layers",
+			N:    5, K: 3,
+			expected: [][]bool{
+				{true, true, false, false, false},
+				{true, true, true, true, false},
+				{true, true, true, true, true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mask, err := (&LayeredMaskFactory{}).CreateMask(tt.N, tt.K)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, maskMatrix(mask))
+		})
+	}
+
+	// More FEC than media packets: consecutive FEC packets share a layer
+	mask, err := (&LayeredMaskFactory{}).CreateMask(2, 3)
+	require.NoError(t, err)
+	assert.Equal(t, [][]bool{{true, false}, {true, true}, {true, true}}, maskMatrix(mask))
+}
+
+func TestLayeredMaskRecoversFirstLayerLosses(t *testing.T) {
+	mask, err := (&LayeredMaskFactory{}).CreateMask(6, 3)
+	require.NoError(t, err)
+
+	// Losing packets 0 and 2 is recoverable: FEC 0 recovers packet 0, then FEC 1 packet 2
+	recoverable := recoverableSet(mask)
+	assert.True(t, recoverable.Contains(0b111010|0b111<<6))
+	// Losing two packets of the last layer is not: only the last FEC packet covers them
+	assert.False(t, recoverable.Contains(0b001111|0b111<<6))
+}
@@ -0,0 +1,112 @@
+package fecanalysis
+
+import "fmt"
+
+// LDPCMaskFactory creates masks from LDPC parity-check matrices built by progressive edge
+// growth (PEG), so sparse-graph codes can be evaluated with the same peeling analysis.
+// Media packets are the variable nodes and FEC packets the check nodes of the Tanner
+// graph; PEG connects each media packet to the FEC packets farthest away in the graph
+// built so far, which maximizes the girth and avoids short stopping sets.
+type LDPCMaskFactory struct {
+	ColumnWeight  int   // FEC packets protecting each media packet for a regular code (default 2)
+	ColumnWeights []int // per-media-packet degrees for an irregular code, repeated cyclically; overrides ColumnWeight
+	Seed          int64 // seed for breaking ties between equally good FEC packets
+}
+
+// CreateMask creates a PEG LDPC mask with N media packets and K FEC packets
+func (f *LDPCMaskFactory) CreateMask(N, K int) (Mask, error) {
+	if N <= 0 || K <= 0 {
+		return nil, fmt.Errorf("invalid parameters for LDPC mask: N=%d, K=%d", N, K)
+	}
+
+	degrees := make([]int, N)
+	for packetIndex := range degrees {
+		degree := defaultInt(f.ColumnWeight, 2)
+		if len(f.ColumnWeights) > 0 {
+			degree = f.ColumnWeights[packetIndex%len(f.ColumnWeights)]
+		}
+		if degree <= 0 {
+			return nil, fmt.Errorf("column weight must be positive, got %d", degree)
+		}
+		degrees[packetIndex] = min(degree, K)
+	}
+
+	rng := NewRandSource(f.Seed*1_000_003 + int64(N)*1_009 + int64(K))
+	checks := make([]int, K)    // media packets protected by every FEC packet
+	variables := make([]int, N) // FEC packets protecting every media packet
+
+	for packetIndex, degree := range degrees {
+		for edge := 0; edge < degree; edge++ {
+			candidates := pegCandidates(checks, variables, packetIndex, K)
+			fecIndex := lowestDegreeCheck(checks, candidates, rng)
+			checks[fecIndex] |= 1 << packetIndex
+			variables[packetIndex] |= 1 << fecIndex
+		}
+	}
+
+	rows := make([][]bool, K)
+	for fecIndex := range rows {
+		rows[fecIndex] = make([]bool, N)
+		for packetIndex := range rows[fecIndex] {
+			rows[fecIndex][packetIndex] = checks[fecIndex]&(1<<packetIndex) != 0
+		}
+	}
+	return NewMatrixMask(rows)
+}
+
+// pegCandidates expands the Tanner graph breadth-first from the media packet and returns
+// the FEC packets it cannot reach, or, if it reaches all of them, those reached last
+func pegCandidates(checks, variables []int, packetIndex, K int) int {
+	allChecks := (1 << K) - 1
+	reached := variables[packetIndex]
+	if reached == 0 {
+		return allChecks
+	}
+
+	for {
+		// One step further: media packets of the reached FEC packets, then their FEC packets
+		reachedVariables := 0
+		for fecIndex := 0; fecIndex < K; fecIndex++ {
+			if reached&(1<<fecIndex) != 0 {
+				reachedVariables |= checks[fecIndex]
+			}
+		}
+		expanded := reached
+		for index, neighbors := range variables {
+			if reachedVariables&(1<<index) != 0 {
+				expanded |= neighbors
+			}
+		}
+
+		switch {
+		case expanded == allChecks && reached != allChecks:
+			return allChecks &^ reached // reached last: the farthest FEC packets
+		case expanded == reached:
+			if reached == allChecks {
+				return allChecks &^ variables[packetIndex]
+			}
+			return allChecks &^ reached // unreachable FEC packets, no cycle is created
+		}
+		reached = expanded
+	}
+}
+
+// lowestDegreeCheck returns the candidate FEC packet protecting the fewest media packets,
+// breaking ties randomly
+func lowestDegreeCheck(checks []int, candidates int, rng RandSource) int {
+	var best []int
+	bestDegree := -1
+	for fecIndex, check := range checks {
+		if candidates&(1<<fecIndex) == 0 {
+			continue
+		}
+		degree := popcount(check)
+		if bestDegree < 0 || degree < bestDegree {
+			best, bestDegree = nil, degree
+		}
+		if degree == bestDegree {
+			best = append(best, fecIndex)
+		}
+	}
+	return best[rng.Intn(len(best))]
+}
@@ -0,0 +1,67 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLDPCMaskFactoryRegular(t *testing.T) {
+	factory := &LDPCMaskFactory{ColumnWeight: 2, Seed: 1}
+
+	for _, size := range []MaskSize{{N: 6, K: 3}, {N: 12, K: 6}, {N: 20, K: 8}} {
+		mask, err := factory.CreateMask(size.N, size.K)
+		require.NoError(t, err)
+
+		structure := AnalyzeMask(mask)
+		for packetIndex, weight := range structure.ColumnWeights {
+			assert.Equal(t, 2, weight, "N=%d K=%d packet %d", size.N, size.K, packetIndex)
+		}
+
+		// PEG keeps check degrees close to the average
+		for _, weight := range structure.RowWeights {
+			assert.LessOrEqual(t, weight, (2*size.N+size.K-1)/size.K+1, "N=%d K=%d", size.N, size.K)
+		}
+
+		// No 4-cycles: two media packets never share two FEC packets when avoidable
+		if size.N <= size.K*(size.K-1)/2 {
+			assert.LessOrEqual(t, maxColumnOverlap(mask), 1, "N=%d K=%d", size.N, size.K)
+		}
+	}
+}
+
+func TestLDPCMaskFactoryIrregularAndDeterministic(t *testing.T) {
+	factory := &LDPCMaskFactory{ColumnWeights: []int{3, 2}, Seed: 4}
+
+	mask, err := factory.CreateMask(8, 4)
+	require.NoError(t, err)
+	assert.Equal(t, []int{3, 2, 3, 2, 3, 2, 3, 2}, AnalyzeMask(mask).ColumnWeights)
+
+	again, err := factory.CreateMask(8, 4)
+	require.NoError(t, err)
+	assert.Equal(t, mask, again)
+
+	// Every single loss is recoverable since every packet is protected
+	assert.Greater(t, CalculateRecoveryCharacteristics(mask).MinLostPacketsForNonRecovery, LossCount(1))
+
+	_, err = (&LDPCMaskFactory{ColumnWeights: []int{0}}).CreateMask(4, 2)
+	assert.Error(t, err)
+}
+
+// maxColumnOverlap returns the largest number of FEC packets shared by two media packets
+func maxColumnOverlap(mask Mask) int {
+	maxOverlap := 0
+	for a := 0; a < mask.N(); a++ {
+		for b := a + 1; b < mask.N(); b++ {
+			overlap := 0
+			for fecIndex := 0; fecIndex < mask.K(); fecIndex++ {
+				if mask.IsProtected(a, fecIndex) && mask.IsProtected(b, fecIndex) {
+					overlap++
+				}
+			}
+			maxOverlap = max(maxOverlap, overlap)
+		}
+	}
+	return maxOverlap
+}
@@ -1,5 +1,7 @@
 package fecanalysis
 
+import "math/bits"
+
 // LossModel represents a packet loss model that calculates scenario probabilities
 type LossModel interface {
 	// CalculateProbability calculates the probability of a given scenario (vertex)
@@ -10,3 +12,113 @@ type LossModel interface {
 	// GetAverageLossProbability returns the average loss probability for this model
 	GetAverageLossProbability() float64
 }
+
+// LossCountModel is implemented by loss models that can compute the distribution
+// of the number of lost packets without enumerating all delivery patterns
+type LossCountModel interface {
+	// LossCountDistribution returns a slice of length N+1 where element k is the
+	// probability that exactly k of the N packets are lost
+	LossCountDistribution(N int) []float64
+}
+
+// PatternSampler is implemented by loss models that can draw delivery patterns directly
+// instead of through their pattern probabilities
+type PatternSampler interface {
+	// SamplePattern draws a delivery pattern of N <= 63 packets, bit i set if packet i
+	// is delivered
+	SamplePattern(N int, rng RandSource) uint64
+}
+
+// PatternVisitor is implemented by loss models that can enumerate all delivery patterns with
+// their probabilities faster than one CalculateProbability call per pattern
+type PatternVisitor interface {
+	// VisitPatterns calls fn for every delivery pattern of N <= 63 packets, in any order
+	VisitPatterns(N int, fn func(pattern uint64, probability float64))
+}
+
+// VisitPatterns calls fn for every delivery pattern of N <= 63 packets with its probability
+// under the model. Models implementing PatternVisitor enumerate the patterns themselves, other
+// models are queried with CalculateProbability in ascending pattern order.
+func VisitPatterns(model LossModel, N int, fn func(pattern uint64, probability float64)) {
+	if visitor, ok := model.(PatternVisitor); ok {
+		visitor.VisitPatterns(N, fn)
+		return
+	}
+	for pattern := uint64(0); pattern < uint64(1)<<N; pattern++ {
+		fn(pattern, model.CalculateProbability(int(pattern), N))
+	}
+}
+
+// SamplePattern draws a delivery pattern of N <= 63 packets from the model. Models
+// implementing PatternSampler are sampled directly, other models packet by packet from the
+// conditional delivery probabilities given by the probabilities of the pattern prefixes.
+func SamplePattern(model LossModel, N int, rng RandSource) uint64 {
+	if sampler, ok := model.(PatternSampler); ok {
+		return sampler.SamplePattern(N, rng)
+	}
+
+	pattern := uint64(0)
+	prefixProbability := 1.0
+	for i := 0; i < N; i++ {
+		delivered := pattern | uint64(1)<<i
+		deliveredProbability := model.CalculateProbability(int(delivered), i+1)
+		if prefixProbability > 0 && rng.Float64()*prefixProbability < deliveredProbability {
+			pattern = delivered
+			prefixProbability = deliveredProbability
+		} else {
+			prefixProbability -= deliveredProbability
+		}
+	}
+	return pattern
+}
+
+// LossCountDistribution returns the probability of losing exactly k packets out of N
+// for every k in 0..N. Models implementing LossCountModel are queried directly,
+// other models fall back to enumerating all 2^N delivery patterns.
+func LossCountDistribution(model LossModel, N int) []float64 {
+	if N < 0 {
+		return nil
+	}
+	if countModel, ok := model.(LossCountModel); ok {
+		return countModel.LossCountDistribution(N)
+	}
+
+	distribution := make([]float64, N+1)
+	allDelivered := (1 << N) - 1
+	for vertex := 0; vertex <= allDelivered; vertex++ {
+		lost := N - popcount(vertex)
+		distribution[lost] += model.CalculateProbability(vertex, N)
+	}
+	return distribution
+}
+
+// CumulativeLossProbability returns P(lost <= k) for a group of N packets
+func CumulativeLossProbability(model LossModel, N, k int) float64 {
+	if k < 0 {
+		return 0.0
+	}
+	distribution := LossCountDistribution(model, N)
+	cumulative := 0.0
+	for lost := 0; lost <= k && lost < len(distribution); lost++ {
+		cumulative += distribution[lost]
+	}
+	return cumulative
+}
+
+// TailLossProbability returns P(lost >= k) for a group of N packets
+func TailLossProbability(model LossModel, N, k int) float64 {
+	if k <= 0 {
+		return 1.0
+	}
+	distribution := LossCountDistribution(model, N)
+	tail := 0.0
+	for lost := k; lost < len(distribution); lost++ {
+		tail += distribution[lost]
+	}
+	return tail
+}
+
+// popcount returns the number of set bits in the given pattern
+func popcount(pattern int) int {
+	return bits.OnesCount64(uint64(pattern))
+}
@@ -0,0 +1,90 @@
+package fecanalysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// LossModelPreset describes a named Gilbert-Elliott channel with representative parameters
+type LossModelPreset struct {
+	Name        string  // preset name used on the command line
+	Description string  // short human-readable description
+	Pe0         float64 // packet loss probability in good state
+	Pe1         float64 // packet loss probability in bad state
+	P01         float64 // transition probability from good to bad
+	P10         float64 // transition probability from bad to good
+}
+
+// Model creates a new Gilbert-Elliott loss model with the preset parameters
+func (p LossModelPreset) Model() *GilbertElliotLossModel {
+	return NewGilbertElliotLossModel(p.Pe0, p.Pe1, p.P01, p.P10)
+}
+
+// lossModelPresets holds the built-in channel presets keyed by name
+var lossModelPresets = map[string]LossModelPreset{
+	"reference": {
+		Name:        "reference",
+		Description: "reference bursty channel used by the analysis tools (~18% loss)",
+		Pe0:         0.05, Pe1: 0.7, P01: 0.05, P10: 0.2,
+	},
+	"wifi_indoor": {
+		Name:        "wifi_indoor",
+		Description: "indoor Wi-Fi with short interference bursts (~2.6% loss)",
+		Pe0:         0.01, Pe1: 0.5, P01: 0.01, P10: 0.3,
+	},
+	"wifi_congested": {
+		Name:        "wifi_congested",
+		Description: "congested Wi-Fi with frequent contention bursts (~10% loss)",
+		Pe0:         0.03, Pe1: 0.6, P01: 0.04, P10: 0.3,
+	},
+	"lte_mobile": {
+		Name:        "lte_mobile",
+		Description: "mobile LTE with handover-induced long bursts (~3.5% loss)",
+		Pe0:         0.005, Pe1: 0.6, P01: 0.008, P10: 0.15,
+	},
+	"dsl": {
+		Name:        "dsl",
+		Description: "wired DSL with rare short bursts (~0.2% loss)",
+		Pe0:         0.001, Pe1: 0.3, P01: 0.002, P10: 0.5,
+	},
+	"satellite": {
+		Name:        "satellite",
+		Description: "satellite link with rare but long fades (~8.4% loss)",
+		Pe0:         0.002, Pe1: 0.9, P01: 0.005, P10: 0.05,
+	},
+}
+
+// LossModelPresets returns all built-in channel presets sorted by name
+func LossModelPresets() []LossModelPreset {
+	presets := make([]LossModelPreset, 0, len(lossModelPresets))
+	for _, preset := range lossModelPresets {
+		presets = append(presets, preset)
+	}
+	sort.Slice(presets, func(i, j int) bool {
+		return presets[i].Name < presets[j].Name
+	})
+	return presets
+}
+
+// LookupLossModelPreset returns the preset with the given name
+func LookupLossModelPreset(name string) (LossModelPreset, error) {
+	preset, exists := lossModelPresets[name]
+	if !exists {
+		names := make([]string, 0, len(lossModelPresets))
+		for _, p := range LossModelPresets() {
+			names = append(names, p.Name)
+		}
+		return LossModelPreset{}, fmt.Errorf("unknown loss model preset %q (available: %s)", name, strings.Join(names, ", "))
+	}
+	return preset, nil
+}
+
+// NewPresetLossModel creates the Gilbert-Elliott loss model for the named preset
+func NewPresetLossModel(name string) (*GilbertElliotLossModel, error) {
+	preset, err := LookupLossModelPreset(name)
+	if err != nil {
+		return nil, err
+	}
+	return preset.Model(), nil
+}
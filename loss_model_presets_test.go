@@ -0,0 +1,40 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLossModelPresets(t *testing.T) {
+	presets := LossModelPresets()
+	require.NotEmpty(t, presets)
+
+	for i, preset := range presets {
+		if i > 0 {
+			assert.Less(t, presets[i-1].Name, preset.Name, "presets should be sorted by name")
+		}
+
+		model, err := NewPresetLossModel(preset.Name)
+		require.NoError(t, err)
+		loss := model.GetAverageLossProbability()
+		assert.Greater(t, loss, 0.0, "preset %s", preset.Name)
+		assert.Less(t, loss, 0.5, "preset %s", preset.Name)
+	}
+}
+
+func TestReferencePresetMatchesToolDefaults(t *testing.T) {
+	model, err := NewPresetLossModel("reference")
+	require.NoError(t, err)
+	assert.Equal(t, 0.05, model.Pe0)
+	assert.Equal(t, 0.7, model.Pe1)
+	assert.Equal(t, 0.05, model.P01)
+	assert.Equal(t, 0.2, model.P10)
+}
+
+func TestUnknownLossModelPreset(t *testing.T) {
+	_, err := NewPresetLossModel("carrier_pigeon")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "wifi_indoor")
+}
@@ -0,0 +1,109 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bruteForceLossCounts enumerates all delivery patterns to build the loss count distribution
+func bruteForceLossCounts(model LossModel, N int) []float64 {
+	distribution := make([]float64, N+1)
+	for vertex := 0; vertex < (1 << N); vertex++ {
+		distribution[N-popcount(vertex)] += model.CalculateProbability(vertex, N)
+	}
+	return distribution
+}
+
+func TestLossCountDistributionMatchesEnumeration(t *testing.T) {
+	models := []struct {
+		name  string
+		model LossModel
+	}{
+		{"random", NewRandomLossModel(0.2)},
+		{"gilbert-elliott", NewGilbertElliotLossModel(0.05, 0.7, 0.05, 0.2)},
+		{"gilbert", NewGilbertLossModel(1.0, 0.1, 0.6)},
+	}
+
+	for _, m := range models {
+		t.Run(m.name, func(t *testing.T) {
+			for N := 1; N <= 8; N++ {
+				expected := bruteForceLossCounts(m.model, N)
+				actual := LossCountDistribution(m.model, N)
+				require.Len(t, actual, N+1)
+				for k := range expected {
+					assert.InDelta(t, expected[k], actual[k], 1e-12, "N=%d, k=%d", N, k)
+				}
+			}
+		})
+	}
+}
+
+func TestTailAndCumulativeLossProbability(t *testing.T) {
+	model := NewGilbertElliotLossModel(0.05, 0.7, 0.05, 0.2)
+	N := 6
+
+	assert.InDelta(t, 1.0, TailLossProbability(model, N, 0), 1e-12)
+	assert.InDelta(t, 0.0, CumulativeLossProbability(model, N, -1), 1e-12)
+	assert.InDelta(t, 1.0, CumulativeLossProbability(model, N, N), 1e-12)
+
+	for k := 1; k <= N; k++ {
+		tail := TailLossProbability(model, N, k)
+		cumulative := CumulativeLossProbability(model, N, k-1)
+		assert.InDelta(t, 1.0, tail+cumulative, 1e-12, "P(lost >= %d) + P(lost <= %d) should be 1", k, k-1)
+	}
+
+	// The all-lost probability is the tail at k=N
+	assert.InDelta(t, model.CalculateProbability(0, N), TailLossProbability(model, N, N), 1e-12)
+}
+
+// probabilityOnlyModel hides the PatternSampler implementation of the wrapped model
+type probabilityOnlyModel struct {
+	LossModel
+}
+
+func TestSamplePattern(t *testing.T) {
+	gilbert := NewGilbertElliotLossModel(0.05, 0.7, 0.1, 0.3)
+	models := []struct {
+		name  string
+		model LossModel
+	}{
+		{"random", NewRandomLossModel(0.3)},
+		{"gilbert-elliott", gilbert},
+		{"conditional fallback", probabilityOnlyModel{gilbert}},
+	}
+
+	const N, samples = 3, 100000
+	for _, m := range models {
+		t.Run(m.name, func(t *testing.T) {
+			rng := NewRandSource(3)
+			counts := make([]int, 1<<N)
+			for i := 0; i < samples; i++ {
+				pattern := SamplePattern(m.model, N, rng)
+				require.Less(t, pattern, uint64(1<<N))
+				counts[pattern]++
+			}
+			for pattern, count := range counts {
+				assert.InDelta(t, m.model.CalculateProbability(pattern, N), float64(count)/samples, 0.01, "pattern %03b", pattern)
+			}
+		})
+	}
+}
+
+func TestVisitPatterns(t *testing.T) {
+	gilbert := NewGilbertElliotLossModel(0.05, 0.7, 0.1, 0.3)
+	for _, model := range []LossModel{gilbert, probabilityOnlyModel{gilbert}, NewRandomLossModel(0.2)} {
+		visited := make(map[uint64]float64)
+		total := 0.0
+		VisitPatterns(model, 7, func(pattern uint64, probability float64) {
+			visited[pattern] = probability
+			total += probability
+		})
+		require.Len(t, visited, 1<<7)
+		assert.InDelta(t, 1.0, total, 1e-12)
+		for pattern, probability := range visited {
+			assert.InDelta(t, model.CalculateProbability(int(pattern), 7), probability, 1e-15)
+		}
+	}
+}
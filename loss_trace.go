@@ -0,0 +1,85 @@
+package fecanalysis
+
+import "fmt"
+
+// LossTrace is a sequence of per-packet delivery flags in transmission order,
+// where true means the packet was delivered and false means it was lost
+type LossTrace []bool
+
+// Len returns the number of packets in the trace
+func (t LossTrace) Len() int {
+	return len(t)
+}
+
+// LostCount returns the number of lost packets in the trace
+func (t LossTrace) LostCount() int {
+	lost := 0
+	for _, delivered := range t {
+		if !delivered {
+			lost++
+		}
+	}
+	return lost
+}
+
+// LossRate returns the fraction of lost packets in the trace
+func (t LossTrace) LossRate() float64 {
+	if len(t) == 0 {
+		return 0.0
+	}
+	return float64(t.LostCount()) / float64(len(t))
+}
+
+// WindowHistogram cuts the trace into windows of the given length, starting a new
+// window every stride packets, and counts the delivery pattern of every window.
+// Use stride == window for non-overlapping FEC groups, stride == 1 for all offsets.
+func (t LossTrace) WindowHistogram(window, stride int) (map[int]int, error) {
+	if window <= 0 || window > 62 {
+		return nil, fmt.Errorf("invalid window length: %d", window)
+	}
+	if stride <= 0 {
+		return nil, fmt.Errorf("invalid window stride: %d", stride)
+	}
+
+	histogram := make(map[int]int)
+	for start := 0; start+window <= len(t); start += stride {
+		pattern := 0
+		for i := 0; i < window; i++ {
+			if t[start+i] {
+				pattern |= 1 << i // packet delivered
+			}
+		}
+		histogram[pattern]++
+	}
+	return histogram, nil
+}
+
+// FitGilbert fits a Gilbert model (loss-free good state, loss-only bad state) to the
+// delivered/lost transitions of the trace
+func (t LossTrace) FitGilbert() *GilbertElliotLossModel {
+	var deliveredToLost, delivered, lostToDelivered, lost int
+	for i := 1; i < len(t); i++ {
+		if t[i-1] {
+			delivered++
+			if !t[i] {
+				deliveredToLost++
+			}
+		} else {
+			lost++
+			if t[i] {
+				lostToDelivered++
+			}
+		}
+	}
+	return newGilbertFromTransitions(deliveredToLost, delivered, lostToDelivered, lost)
+}
+
+// EmpiricalLossModel builds an empirical loss model from all windows of the given length
+// in the trace, with a Gilbert model fitted to the whole trace as fallback
+func (t LossTrace) EmpiricalLossModel(window int, priorWeight float64) (*EmpiricalLossModel, error) {
+	histogram, err := t.WindowHistogram(window, 1)
+	if err != nil {
+		return nil, err
+	}
+	return NewEmpiricalLossModel(window, histogram, t.FitGilbert(), priorWeight)
+}
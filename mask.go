@@ -20,35 +20,38 @@ type MaskFactory interface {
 
 // bitMask represents a mask implementation using bit patterns
 type bitMask struct {
-	data []byte
-	n    int // number of media packets
-	k    int // number of FEC packets
+	data     []byte
+	n        int // number of media packets
+	k        int // number of FEC packets
+	rowBytes int // bytes per FEC packet row; 0 means the 2-byte WebRTC short mask
+}
+
+// bytesPerRow returns the number of bytes encoding each FEC packet row
+func (m *bitMask) bytesPerRow() int {
+	if m.rowBytes == 0 {
+		return webrtcShortMaskBytes
+	}
+	return m.rowBytes
 }
 
 // IsProtected checks if the packet at packetIndex is protected by FEC at fecIndex
 func (m *bitMask) IsProtected(packetIndex, fecIndex int) bool {
+	rowBytes := m.bytesPerRow()
+
 	// Check bounds
-	if packetIndex < 0 || packetIndex >= 16 || fecIndex < 0 {
+	if packetIndex < 0 || packetIndex >= rowBytes*8 || fecIndex < 0 {
 		return false
 	}
 
 	// Check if we have enough bytes for this FEC index
-	// Each FEC packet is 2 bytes
-	if fecIndex*2 > len(m.data) {
+	byteOffset := fecIndex * rowBytes
+	if byteOffset+rowBytes > len(m.data) {
 		return false
 	}
 
-	// Calculate byte and bit position within the FEC packet
-	byteOffset := fecIndex * 2
-	if packetIndex < 8 {
-		// First byte of the FEC packet
-		bitPos := 7 - packetIndex // MSB first
-		return (m.data[byteOffset] & (1 << bitPos)) != 0
-	} else {
-		// Second byte of the FEC packet
-		bitPos := 7 - (packetIndex - 8) // MSB first
-		return (m.data[byteOffset+1] & (1 << bitPos)) != 0
-	}
+	// Calculate byte and bit position within the FEC packet row, MSB first
+	bitPos := 7 - packetIndex%8
+	return (m.data[byteOffset+packetIndex/8] & (1 << bitPos)) != 0
 }
 
 // N returns the number of media packets
@@ -62,10 +65,14 @@ func (m *bitMask) K() int {
 }
 
 // InterleavedMask implements interleaved protection where each packet is protected by one FEC packet
-// The FEC packet index is determined by media_packet % K
+// The FEC packet index is determined by media_packet % K, or (media_packet / depth) % K when
+// runs of depth consecutive packets share a FEC packet. With more FEC than media packets
+// (K > N), FEC packet f instead protects media packet f % N, so every media packet is
+// repeated by K/N FEC packets, rounded up or down.
 type InterleavedMask struct {
-	n int // number of media packets
-	k int // number of FEC packets
+	n     int // number of media packets
+	k     int // number of FEC packets
+	depth int // consecutive media packets mapped to the same FEC packet
 }
 
 // IsProtected returns true if the packet at packetIndex is protected by FEC at fecIndex
@@ -73,8 +80,12 @@ func (m *InterleavedMask) IsProtected(packetIndex, fecIndex int) bool {
 	if packetIndex < 0 || packetIndex >= m.n || fecIndex < 0 || fecIndex >= m.k {
 		return false
 	}
-	// Each packet is protected by exactly one FEC packet: media_packet % K
-	return packetIndex%m.k == fecIndex
+	if m.k > m.n {
+		// Each FEC packet repeats exactly one media packet: fec_packet % N
+		return fecIndex%m.n == packetIndex
+	}
+	// Each packet is protected by exactly one FEC packet: (media_packet / depth) % K
+	return (packetIndex/max(m.depth, 1))%m.k == fecIndex
 }
 
 // N returns the number of media packets
@@ -87,17 +98,85 @@ func (m *InterleavedMask) K() int {
 	return m.k
 }
 
-// InterleavedMaskFactory creates interleaved protection masks
-type InterleavedMaskFactory struct{}
+// InterleavedMaskFactory creates interleaved protection masks. Depth sets how many
+// consecutive media packets map to the same FEC packet before rotating to the next one;
+// zero or one is strict modulo interleaving. A larger depth shortens the span of media
+// packets each FEC packet covers (as long as N doesn't wrap around depth*K), and so the
+// wait for its repair, at the cost of burst tolerance: two losses within a run are
+// unrecoverable.
+type InterleavedMaskFactory struct {
+	Depth int
+}
 
 // CreateMask creates an interleaved mask with N media packets and K FEC packets
 func (f *InterleavedMaskFactory) CreateMask(N, K int) (Mask, error) {
-	if N <= 0 || K <= 0 || K > N {
+	if N <= 0 || K <= 0 {
 		return nil, fmt.Errorf("invalid parameters for interleaved mask: N=%d, K=%d", N, K)
 	}
+	if err := f.validateDepth(); err != nil {
+		return nil, err
+	}
 
 	return &InterleavedMask{
-		n: N,
-		k: K,
+		n:     N,
+		k:     K,
+		depth: f.Depth,
+	}, nil
+}
+
+// validateDepth checks the interleaving depth; zero and one both mean strict modulo interleaving
+func (f *InterleavedMaskFactory) validateDepth() error {
+	if f.Depth < 0 {
+		return fmt.Errorf("invalid interleaving depth %d", f.Depth)
+	}
+	return nil
+}
+
+// DiagonalInterleavedMask implements diagonal (staggered) interleaving. The media packets
+// are laid out in rows of K, and each row is shifted by offset FEC packets relative to the
+// previous one: media packet p is protected by FEC packet (p + offset*(p/K)) % K. With
+// offset 0 this is InterleavedMask; K > N is handled the same way as there.
+type DiagonalInterleavedMask struct {
+	n      int // number of media packets
+	k      int // number of FEC packets
+	offset int // FEC index shift per row of K media packets
+}
+
+// IsProtected returns true if the packet at packetIndex is protected by FEC at fecIndex
+func (m *DiagonalInterleavedMask) IsProtected(packetIndex, fecIndex int) bool {
+	if packetIndex < 0 || packetIndex >= m.n || fecIndex < 0 || fecIndex >= m.k {
+		return false
+	}
+	if m.k > m.n {
+		return fecIndex%m.n == packetIndex
+	}
+	return (packetIndex+m.offset*(packetIndex/m.k))%m.k == fecIndex
+}
+
+// N returns the number of media packets
+func (m *DiagonalInterleavedMask) N() int {
+	return m.n
+}
+
+// K returns the number of FEC packets
+func (m *DiagonalInterleavedMask) K() int {
+	return m.k
+}
+
+// DiagonalInterleavedMaskFactory creates diagonal interleaving masks with a fixed per-row offset
+type DiagonalInterleavedMaskFactory struct {
+	Offset int // FEC index shift per row of K media packets, must not be negative
+}
+
+// CreateMask creates a diagonal interleaved mask with N media packets and K FEC packets
+func (f *DiagonalInterleavedMaskFactory) CreateMask(N, K int) (Mask, error) {
+	if N <= 0 || K <= 0 || f.Offset < 0 {
+		return nil, fmt.Errorf("invalid parameters for diagonal interleaved mask: N=%d, K=%d, offset=%d", N, K, f.Offset)
+	}
+
+	return &DiagonalInterleavedMask{
+		n:      N,
+		k:      K,
+		offset: f.Offset,
 	}, nil
 }
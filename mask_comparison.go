@@ -0,0 +1,50 @@
+package fecanalysis
+
+import "fmt"
+
+// MaskComparison is the difference between the recoverable sets of two masks of the same
+// size, see CompareMasks
+type MaskComparison struct {
+	OnlyA            *ReachableSet `json:"-"`                  // delivery patterns the first mask recovers and the second does not
+	OnlyB            *ReachableSet `json:"-"`                  // delivery patterns the second mask recovers and the first does not
+	OnlyAProbability float64       `json:"only_a_probability"` // probability of OnlyA under the loss model
+	OnlyBProbability float64       `json:"only_b_probability"` // probability of OnlyB under the loss model
+}
+
+// Gain returns the recovery probability of the first mask minus that of the second, which
+// only depends on the patterns where they differ
+func (c MaskComparison) Gain() float64 {
+	return c.OnlyAProbability - c.OnlyBProbability
+}
+
+// CompareMasks returns the delivery patterns that peeling recovers with one mask but not the
+// other, and their probability mass under the loss model, to show exactly where one mask
+// beats the other rather than only by how much. Use MostLikelyPatterns on OnlyA and OnlyB
+// for the patterns that matter most.
+func CompareMasks(a, b Mask, model LossModel) (MaskComparison, error) {
+	return CompareMasksWithMode(a, b, model, PeelingDecoding)
+}
+
+// CompareMasksWithMode is CompareMasks for the given decoding mode
+func CompareMasksWithMode(a, b Mask, model LossModel, mode DecodingMode) (MaskComparison, error) {
+	if a.N() != b.N() || a.K() != b.K() {
+		return MaskComparison{}, fmt.Errorf("cannot compare a %dx%d mask with a %dx%d mask", a.N(), a.K(), b.N(), b.K())
+	}
+	totalPackets := a.N() + a.K()
+	if err := checkEnumerable(a.N(), a.K()); err != nil {
+		return MaskComparison{}, err
+	}
+
+	onlyA := RecoverableSet(a, mode)
+	onlyB := RecoverableSet(b, mode)
+	recoverableA := onlyA.Clone()
+	onlyA.Subtract(onlyB)
+	onlyB.Subtract(recoverableA)
+
+	return MaskComparison{
+		OnlyA:            onlyA,
+		OnlyB:            onlyB,
+		OnlyAProbability: SumProbabilities(onlyA, model, totalPackets, 0),
+		OnlyBProbability: SumProbabilities(onlyB, model, totalPackets, 0),
+	}, nil
+}
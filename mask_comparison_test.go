@@ -0,0 +1,43 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareMasks(t *testing.T) {
+	// Two packets protected together or each by its own FEC packet
+	together := NewSimpleMask([][]bool{{true, true}, {true, true}}, 2, 2)
+	separate := NewSimpleMask([][]bool{{true, false}, {false, true}}, 2, 2)
+	model := NewRandomLossModel(0.1)
+
+	comparison, err := CompareMasks(together, separate, model)
+	require.NoError(t, err)
+	// Losing one media packet and the FEC packet protecting it separately
+	assert.Equal(t, []uint64{0b0101, 0b1010}, comparison.OnlyA.Vertices())
+	// Losing both media packets, which only separate FEC packets repair
+	assert.Equal(t, []uint64{0b1100}, comparison.OnlyB.Vertices())
+	assert.InDelta(t, 2*0.1*0.1*0.9*0.9, comparison.OnlyAProbability, 1e-12)
+	assert.InDelta(t, 0.1*0.1*0.9*0.9, comparison.OnlyBProbability, 1e-12)
+	assert.InDelta(t, 0.1*0.1*0.9*0.9, comparison.Gain(), 1e-12)
+
+	// The gain is the difference of the recovery probabilities
+	bursty, err := (&GoogleBurstyMaskFactory{}).CreateMask(8, 3)
+	require.NoError(t, err)
+	interleaved, err := (&InterleavedMaskFactory{}).CreateMask(8, 3)
+	require.NoError(t, err)
+	geModel := NewGilbertElliotLossModel(0.01, 0.5, 0.05, 0.3)
+	for _, mode := range []DecodingMode{PeelingDecoding, MLDecoding} {
+		comparison, err = CompareMasksWithMode(bursty, interleaved, geModel, mode)
+		require.NoError(t, err)
+		expected := SumProbabilities(RecoverableSet(bursty, mode), geModel, 11, 0) - SumProbabilities(RecoverableSet(interleaved, mode), geModel, 11, 0)
+		assert.InDelta(t, expected, comparison.Gain(), 1e-12, "%s", mode)
+		comparison.OnlyA.Intersect(RecoverableSet(interleaved, mode))
+		assert.Zero(t, comparison.OnlyA.Count(), "%s", mode)
+	}
+
+	_, err = CompareMasks(bursty, together, model)
+	assert.Error(t, err)
+}
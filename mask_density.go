@@ -0,0 +1,69 @@
+package fecanalysis
+
+import (
+	"fmt"
+	"math"
+)
+
+// ScaleDensity returns a copy of the mask with protections added or removed at random
+// until the fraction of set entries matches the target density (rounded to the nearest
+// whole entry). Coverage is preserved: a protection is only removed if its media packet
+// stays protected and its FEC packet still protects something. If rng is nil, a source
+// derived from the package-level seed is used.
+func ScaleDensity(mask Mask, targetDensity float64, rng RandSource) (*MatrixMask, error) {
+	if targetDensity < 0 || targetDensity > 1 {
+		return nil, fmt.Errorf("target density must be in [0, 1], got %v", targetDensity)
+	}
+	rng = randSourceOrDefault(rng)
+
+	N, K := mask.N(), mask.K()
+	rows := maskMatrix(mask)
+	structure := AnalyzeMask(mask)
+	rowWeights, columnWeights := structure.RowWeights, structure.ColumnWeights
+
+	current := 0
+	for _, weight := range rowWeights {
+		current += weight
+	}
+	target := int(math.Round(targetDensity * float64(N*K)))
+
+	for current < target {
+		// Add a random missing protection
+		var candidates [][2]int
+		for fecIndex, row := range rows {
+			for packetIndex, protected := range row {
+				if !protected {
+					candidates = append(candidates, [2]int{fecIndex, packetIndex})
+				}
+			}
+		}
+		chosen := candidates[rng.Intn(len(candidates))]
+		rows[chosen[0]][chosen[1]] = true
+		rowWeights[chosen[0]]++
+		columnWeights[chosen[1]]++
+		current++
+	}
+
+	for current > target {
+		// Remove a random protection that isn't the last one of its row or column
+		var candidates [][2]int
+		for fecIndex, row := range rows {
+			for packetIndex, protected := range row {
+				if protected && rowWeights[fecIndex] > 1 && columnWeights[packetIndex] > 1 {
+					candidates = append(candidates, [2]int{fecIndex, packetIndex})
+				}
+			}
+		}
+		if len(candidates) == 0 {
+			return nil, fmt.Errorf("density %.3f is below the %.3f needed to preserve coverage",
+				targetDensity, float64(current)/float64(N*K))
+		}
+		chosen := candidates[rng.Intn(len(candidates))]
+		rows[chosen[0]][chosen[1]] = false
+		rowWeights[chosen[0]]--
+		columnWeights[chosen[1]]--
+		current--
+	}
+
+	return NewMatrixMask(rows)
+}
@@ -0,0 +1,54 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScaleDensity(t *testing.T) {
+	mask, err := (&GoogleRandomMaskFactory{}).CreateMask(10, 4)
+	require.NoError(t, err)
+
+	for _, target := range []float64{0.3, 0.5, 0.8, 1.0} {
+		scaled, err := ScaleDensity(mask, target, NewRandSource(1))
+		require.NoError(t, err)
+
+		structure := AnalyzeMask(scaled)
+		assert.InDelta(t, target, structure.Density, 0.5/40, "target %.2f", target)
+		assert.Empty(t, structure.UnprotectedPackets, "target %.2f", target)
+		for fecIndex, weight := range structure.RowWeights {
+			assert.Positive(t, weight, "target %.2f row %d", target, fecIndex)
+		}
+	}
+}
+
+func TestScaleDensityKeepsExistingProtectionsWhenAdding(t *testing.T) {
+	mask := NewSimpleMask([][]bool{
+		{true, false, false, false},
+		{false, true, true, true},
+	}, 4, 2)
+
+	scaled, err := ScaleDensity(mask, 0.75, NewRandSource(2))
+	require.NoError(t, err)
+	for fecIndex, row := range maskMatrix(mask) {
+		for packetIndex, protected := range row {
+			if protected {
+				assert.True(t, scaled.IsProtected(packetIndex, fecIndex))
+			}
+		}
+	}
+	assert.InDelta(t, 0.75, AnalyzeMask(scaled).Density, 1e-12)
+}
+
+func TestScaleDensityTooSparse(t *testing.T) {
+	mask, err := (&InterleavedMaskFactory{}).CreateMask(6, 2)
+	require.NoError(t, err)
+
+	// Every media packet needs one protection: 6 of 12 entries at least
+	_, err = ScaleDensity(mask, 0.25, NewRandSource(1))
+	assert.Error(t, err)
+	_, err = ScaleDensity(mask, 1.5, NewRandSource(1))
+	assert.Error(t, err)
+}
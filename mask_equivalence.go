@@ -0,0 +1,245 @@
+package fecanalysis
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/bits"
+	"slices"
+	"sort"
+)
+
+// CanonicalForm returns a representative of the equivalence class of the mask under FEC
+// row permutations, and additionally under media column permutations if permuteColumns
+// is set. Two masks are equivalent exactly when their canonical forms are equal.
+//
+// Without column permutations the rows are sorted. With them, the canonical form is the
+// lexicographically smallest matrix (row by row) over all row orders, each with its
+// columns sorted; rows that are interchangeable by a column symmetry are explored once.
+func CanonicalForm(mask Mask, permuteColumns bool) *MatrixMask {
+	var rows [][]bool
+	if permuteColumns {
+		rows = canonicalRowsAndColumns(mask)
+	} else {
+		rows = maskMatrix(mask)
+		sort.Slice(rows, func(i, j int) bool {
+			return slices.Compare(boolsToBytes(rows[i]), boolsToBytes(rows[j])) < 0
+		})
+	}
+
+	canonical, _ := NewMatrixMask(rows)
+	return canonical
+}
+
+// EquivalentMasks returns true if the masks are equal up to FEC row permutation, and
+// additionally up to media column permutation if permuteColumns is set
+func EquivalentMasks(a, b Mask, permuteColumns bool) bool {
+	if a.N() != b.N() || a.K() != b.K() {
+		return false
+	}
+	return matrixKey(CanonicalForm(a, permuteColumns).rows) == matrixKey(CanonicalForm(b, permuteColumns).rows)
+}
+
+// DeduplicateMasks returns the masks with every mask equivalent to an earlier one removed
+func DeduplicateMasks(masks []Mask, permuteColumns bool) []Mask {
+	seen := make(map[string]bool)
+	var unique []Mask
+	for _, mask := range masks {
+		key := matrixKey(CanonicalForm(mask, permuteColumns).rows)
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, mask)
+		}
+	}
+	return unique
+}
+
+// Fingerprint returns a stable identifier of the mask up to FEC row permutation, suitable
+// as a cache key across runs: the SHA-256 of the sorted rows packed MSB first, prefixed
+// with the mask size ("12x4-..."). Masks with equal fingerprints are equivalent in the
+// sense of EquivalentMasks(a, b, false).
+func Fingerprint(mask Mask) string {
+	rows := CanonicalForm(mask, false).rows
+	rowBytes := (mask.N() + 7) / 8
+
+	encoded := make([]byte, 0, len(rows)*rowBytes)
+	for _, row := range rows {
+		packed := make([]byte, rowBytes)
+		for packetIndex, protected := range row {
+			if protected {
+				packed[packetIndex/8] |= 0x80 >> (packetIndex % 8)
+			}
+		}
+		encoded = append(encoded, packed...)
+	}
+
+	sum := sha256.Sum256(encoded)
+	return fmt.Sprintf("%dx%d-%x", mask.N(), mask.K(), sum[:16])
+}
+
+// canonicalState is a partial row order of the canonical form search. Column labels hold
+// the bits of the already chosen rows, first chosen row most significant, so columns
+// sorted by label are in the order the chosen rows put them in.
+type canonicalState struct {
+	used   int   // bitmask of chosen rows
+	labels []int // label of every column
+}
+
+// canonicalRowsAndColumns computes the canonical matrix under row and column permutations.
+// Row t of the result only depends on the first t chosen rows (it is the chosen row with
+// columns grouped by label, zeros before ones within each group), so the search extends
+// all tied row orders one row at a time and keeps those producing the smallest next row.
+func canonicalRowsAndColumns(mask Mask) [][]bool {
+	N, K := mask.N(), mask.K()
+	rows := patternRows(mask)
+
+	states := []canonicalState{{labels: make([]int, N)}}
+	result := make([][]bool, K)
+	for t := 0; t < K; t++ {
+		var best []int // number of protected columns in every group, in group order
+		var bestSizes []int
+		var next []canonicalState
+		seen := make(map[string]bool)
+
+		for _, state := range states {
+			groups, sizes := columnGroups(state.labels)
+			for _, candidate := range candidateRows(rows, state, N) {
+				counts := make([]int, len(groups))
+				for packetIndex, label := range state.labels {
+					if rows[candidate]&(1<<packetIndex) != 0 {
+						counts[groups[label]]++
+					}
+				}
+
+				comparison := -1
+				if best != nil {
+					comparison = slices.Compare(counts, best)
+				}
+				if comparison > 0 {
+					continue
+				}
+				if comparison < 0 {
+					best, bestSizes, next = counts, sizes, nil
+					seen = make(map[string]bool)
+				}
+
+				extended := canonicalState{used: state.used | 1<<candidate, labels: make([]int, N)}
+				for packetIndex, label := range state.labels {
+					extended.labels[packetIndex] = label<<1 | int(rows[candidate]>>packetIndex&1)
+				}
+				if key := stateKey(extended); !seen[key] {
+					seen[key] = true
+					next = append(next, extended)
+				}
+			}
+		}
+
+		// Expand the group counts into the row: zeros then ones within every group
+		result[t] = make([]bool, 0, N)
+		for group, size := range bestSizes {
+			for i := 0; i < size; i++ {
+				result[t] = append(result[t], i >= size-best[group])
+			}
+		}
+		states = next
+	}
+	return result
+}
+
+// columnGroups maps every distinct column label to its rank and returns the group sizes in rank order
+func columnGroups(labels []int) (map[int]int, []int) {
+	distinct := slices.Clone(labels)
+	slices.Sort(distinct)
+	distinct = slices.Compact(distinct)
+
+	groups := make(map[int]int, len(distinct))
+	for rank, label := range distinct {
+		groups[label] = rank
+	}
+	sizes := make([]int, len(distinct))
+	for _, label := range labels {
+		sizes[groups[label]]++
+	}
+	return groups, sizes
+}
+
+// candidateRows returns the unused rows worth trying next, skipping rows that are
+// interchangeable with an earlier candidate
+func candidateRows(rows []uint64, state canonicalState, N int) []int {
+	var candidates []int
+	for row := range rows {
+		if state.used&(1<<row) != 0 {
+			continue
+		}
+		duplicate := false
+		for _, other := range candidates {
+			if interchangeableRows(rows, state, N, row, other) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			candidates = append(candidates, row)
+		}
+	}
+	return candidates
+}
+
+// interchangeableRows returns true if a permutation of columns within label groups swaps
+// rows a and b while leaving every other unused row unchanged. Such a symmetry maps the
+// search subtree of one row onto the other, so only one needs to be explored. The
+// permutation exists if the columns only in a and the columns only in b have the same
+// multiset of (label, membership in the other unused rows) signatures.
+func interchangeableRows(rows []uint64, state canonicalState, N, a, b int) bool {
+	onlyA, onlyB := rows[a]&^rows[b], rows[b]&^rows[a]
+	if bits.OnesCount64(onlyA) != bits.OnesCount64(onlyB) {
+		return false
+	}
+
+	signature := func(packetIndex int) [2]int {
+		membership := 0
+		for row, protected := range rows {
+			if row != a && row != b && state.used&(1<<row) == 0 && protected&(1<<packetIndex) != 0 {
+				membership |= 1 << row
+			}
+		}
+		return [2]int{state.labels[packetIndex], membership}
+	}
+
+	balance := make(map[[2]int]int)
+	for packetIndex := 0; packetIndex < N; packetIndex++ {
+		if onlyA&(1<<packetIndex) != 0 {
+			balance[signature(packetIndex)]++
+		}
+		if onlyB&(1<<packetIndex) != 0 {
+			balance[signature(packetIndex)]--
+		}
+	}
+	for _, count := range balance {
+		if count != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// stateKey returns a string identifying a search state, for deduplication
+func stateKey(state canonicalState) string {
+	key := make([]byte, 0, 8*(len(state.labels)+1))
+	for _, value := range append([]int{state.used}, state.labels...) {
+		for shift := 0; shift < 64; shift += 8 {
+			key = append(key, byte(value>>shift))
+		}
+	}
+	return string(key)
+}
+
+// boolsToBytes converts a row to bytes for lexicographic comparison
+func boolsToBytes(row []bool) []byte {
+	converted := make([]byte, len(row))
+	for i, value := range row {
+		if value {
+			converted[i] = 1
+		}
+	}
+	return converted
+}
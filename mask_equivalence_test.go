@@ -0,0 +1,159 @@
+package fecanalysis
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// permuteMask returns the mask with rows and columns reordered
+func permuteMask(mask Mask, rowOrder, columnOrder []int) Mask {
+	matrix := make([][]bool, mask.K())
+	for fecIndex := range matrix {
+		matrix[fecIndex] = make([]bool, mask.N())
+		for packetIndex := range matrix[fecIndex] {
+			matrix[fecIndex][packetIndex] = mask.IsProtected(columnOrder[packetIndex], rowOrder[fecIndex])
+		}
+	}
+	return NewSimpleMask(matrix, mask.N(), mask.K())
+}
+
+func TestEquivalentMasksUnderPermutations(t *testing.T) {
+	rng := NewRandSource(11)
+
+	for _, factory := range []MaskFactory{&GoogleBurstyMaskFactory{}, &GoogleRandomMaskFactory{}, &InterleavedMaskFactory{}} {
+		for _, size := range []MaskSize{{N: 4, K: 2}, {N: 8, K: 3}, {N: 10, K: 6}, {N: 12, K: 12}} {
+			mask, err := factory.CreateMask(size.N, size.K)
+			require.NoError(t, err)
+
+			rowsPermuted := permuteMask(mask, rng.Perm(size.K), identityPermutation(size.N))
+			bothPermuted := permuteMask(mask, rng.Perm(size.K), rng.Perm(size.N))
+
+			assert.True(t, EquivalentMasks(mask, rowsPermuted, false), "N=%d K=%d", size.N, size.K)
+			assert.True(t, EquivalentMasks(mask, bothPermuted, true), "N=%d K=%d", size.N, size.K)
+			assert.Equal(t, CanonicalForm(mask, true), CanonicalForm(bothPermuted, true))
+		}
+	}
+}
+
+func TestEquivalentMasksDistinguishes(t *testing.T) {
+	a := NewSimpleMask([][]bool{
+		{true, true, false},
+		{false, true, true},
+	}, 3, 2)
+	// Same column permuted: equivalent only when columns may move
+	b := NewSimpleMask([][]bool{
+		{true, false, true},
+		{false, true, true},
+	}, 3, 2)
+	// Same weights, but the rows don't overlap
+	c := NewSimpleMask([][]bool{
+		{true, true, false, false},
+		{false, false, true, true},
+	}, 4, 2)
+	d := NewSimpleMask([][]bool{
+		{true, true, false, false},
+		{false, true, true, false},
+	}, 4, 2)
+
+	assert.False(t, EquivalentMasks(a, b, false))
+	assert.True(t, EquivalentMasks(a, b, true))
+	assert.False(t, EquivalentMasks(c, d, true))
+	assert.False(t, EquivalentMasks(a, c, true))
+}
+
+func TestCanonicalFormBruteForce(t *testing.T) {
+	rng := NewRandSource(5)
+	for trial := 0; trial < 50; trial++ {
+		mask, err := RandomMask(5, 3, 1+rng.Intn(5), rng)
+		require.NoError(t, err)
+		canonical := CanonicalForm(mask, true)
+
+		// The canonical form must be the smallest sorted-column matrix over all row orders
+		for _, rowOrder := range permutations(3) {
+			candidate := permuteMask(mask, rowOrder, identityPermutation(5))
+			assert.LessOrEqual(t, matrixKey(canonical.rows), matrixKey(sortColumns(maskMatrix(candidate))))
+		}
+	}
+}
+
+func TestDeduplicateMasks(t *testing.T) {
+	a := NewSimpleMask([][]bool{{true, false}, {true, true}}, 2, 2)
+	b := NewSimpleMask([][]bool{{true, true}, {false, true}}, 2, 2)
+	c := NewSimpleMask([][]bool{{true, true}, {true, true}}, 2, 2)
+
+	assert.Len(t, DeduplicateMasks([]Mask{a, b, c}, false), 3)
+	assert.Len(t, DeduplicateMasks([]Mask{a, b, c}, true), 2)
+}
+
+func TestFingerprint(t *testing.T) {
+	a := NewSimpleMask([][]bool{{true, false}, {true, true}}, 2, 2)
+	swapped := NewSimpleMask([][]bool{{true, true}, {true, false}}, 2, 2)
+	b := NewSimpleMask([][]bool{{true, true}, {false, true}}, 2, 2)
+
+	assert.Equal(t, Fingerprint(a), Fingerprint(swapped), "FEC row order is ignored")
+	assert.NotEqual(t, Fingerprint(a), Fingerprint(b), "media column order is not")
+	assert.True(t, strings.HasPrefix(Fingerprint(a), "2x2-"))
+
+	// Same rows over a wider block differ even though the packed bytes would match
+	wide := NewSimpleMask([][]bool{{true, false, false}, {true, true, false}}, 3, 2)
+	assert.NotEqual(t, Fingerprint(a), Fingerprint(wide))
+
+	// Fingerprints are cache keys across runs, so the encoding must not change
+	assert.Equal(t, "2x2-0bd8a2bd1e76882f84e7a5cc113bea06", Fingerprint(a))
+}
+
+func identityPermutation(n int) []int {
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	return order
+}
+
+func permutations(n int) [][]int {
+	if n == 0 {
+		return [][]int{{}}
+	}
+	var result [][]int
+	for _, rest := range permutations(n - 1) {
+		for position := 0; position <= len(rest); position++ {
+			permutation := append(append(append([]int{}, rest[:position]...), n-1), rest[position:]...)
+			result = append(result, permutation)
+		}
+	}
+	return result
+}
+
+// sortColumns sorts the columns of a matrix lexicographically, first row most significant
+func sortColumns(rows [][]bool) [][]bool {
+	N := len(rows[0])
+	columns := make([]string, N)
+	for packetIndex := range columns {
+		for _, row := range rows {
+			if row[packetIndex] {
+				columns[packetIndex] += "1"
+			} else {
+				columns[packetIndex] += "0"
+			}
+		}
+	}
+	sorted := append([]string(nil), columns...)
+	for i := range sorted {
+		for j := i + 1; j < len(sorted); j++ {
+			if sorted[j] < sorted[i] {
+				sorted[i], sorted[j] = sorted[j], sorted[i]
+			}
+		}
+	}
+	result := make([][]bool, len(rows))
+	for fecIndex := range result {
+		result[fecIndex] = make([]bool, N)
+		for packetIndex, column := range sorted {
+			result[fecIndex][packetIndex] = column[fecIndex] == '1'
+		}
+	}
+	return result
+}
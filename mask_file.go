@@ -0,0 +1,136 @@
+package fecanalysis
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// NewMaskFromFile loads a hand-designed mask from a file. Two formats are accepted:
+//
+//   - text: one FEC packet per line written as 0/1 characters (spaces between them are
+//     optional), blank lines and lines starting with # are ignored
+//   - JSON: an array of rows, each row either an array of 0/1 numbers or a "0101" string
+//
+// The format is detected from the first non-space character of the file.
+func NewMaskFromFile(path string) (*MatrixMask, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mask file: %w", err)
+	}
+
+	var mask *MatrixMask
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		mask, err = ParseMaskJSON(data)
+	} else {
+		mask, err = ParseMaskText(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return mask, nil
+}
+
+// ParseMaskText parses a mask written as rows of 0/1 characters
+func ParseMaskText(r io.Reader) (*MatrixMask, error) {
+	var rows [][]bool
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		row, err := parseMaskRow(strings.Join(strings.Fields(line), ""))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read mask: %w", err)
+	}
+
+	return NewMatrixMask(rows)
+}
+
+// ParseMaskJSON parses a mask written as a JSON array of rows
+func ParseMaskJSON(data []byte) (*MatrixMask, error) {
+	var rawRows []json.RawMessage
+	if err := json.Unmarshal(data, &rawRows); err != nil {
+		return nil, fmt.Errorf("failed to parse mask JSON: %w", err)
+	}
+
+	rows := make([][]bool, 0, len(rawRows))
+	for fecIndex, rawRow := range rawRows {
+		var text string
+		if err := json.Unmarshal(rawRow, &text); err == nil {
+			row, err := parseMaskRow(text)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", fecIndex, err)
+			}
+			rows = append(rows, row)
+			continue
+		}
+
+		var values []int
+		if err := json.Unmarshal(rawRow, &values); err != nil {
+			return nil, fmt.Errorf("row %d: expected an array of 0/1 or a 0/1 string", fecIndex)
+		}
+		row := make([]bool, len(values))
+		for packetIndex, value := range values {
+			if value != 0 && value != 1 {
+				return nil, fmt.Errorf("row %d: invalid value %d at column %d", fecIndex, value, packetIndex)
+			}
+			row[packetIndex] = value == 1
+		}
+		rows = append(rows, row)
+	}
+
+	return NewMatrixMask(rows)
+}
+
+// parseMaskRow parses a row of 0/1 characters
+func parseMaskRow(text string) ([]bool, error) {
+	row := make([]bool, 0, len(text))
+	for column, char := range text {
+		switch char {
+		case '0':
+			row = append(row, false)
+		case '1':
+			row = append(row, true)
+		default:
+			return nil, fmt.Errorf("invalid character %q at column %d", char, column)
+		}
+	}
+	return row, nil
+}
+
+// FileMaskFactory serves a mask loaded from a file to tools that iterate over (N, K)
+// configurations; configurations other than the file's own size are rejected
+type FileMaskFactory struct {
+	Path string
+	mask *MatrixMask
+}
+
+// CreateMask returns the mask from the file if its size is N x K
+func (f *FileMaskFactory) CreateMask(N, K int) (Mask, error) {
+	if f.mask == nil {
+		mask, err := NewMaskFromFile(f.Path)
+		if err != nil {
+			return nil, err
+		}
+		f.mask = mask
+	}
+
+	if f.mask.N() != N || f.mask.K() != K {
+		return nil, fmt.Errorf("mask in %s is N=%d, K=%d, requested N=%d, K=%d", f.Path, f.mask.N(), f.mask.K(), N, K)
+	}
+	return f.mask, nil
+}
@@ -0,0 +1,71 @@
+package fecanalysis
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMaskText(t *testing.T) {
+	mask, err := ParseMaskText(strings.NewReader(`
+# Two FEC packets over four media packets
+1 1 0 0
+0011
+`))
+	require.NoError(t, err)
+	assert.Equal(t, 4, mask.N())
+	assert.Equal(t, 2, mask.K())
+	assert.True(t, mask.IsProtected(1, 0))
+	assert.False(t, mask.IsProtected(2, 0))
+	assert.True(t, mask.IsProtected(3, 1))
+}
+
+func TestParseMaskJSON(t *testing.T) {
+	fromNumbers, err := ParseMaskJSON([]byte(`[[1,1,0],[0,1,1]]`))
+	require.NoError(t, err)
+	fromStrings, err := ParseMaskJSON([]byte(`["110", "011"]`))
+	require.NoError(t, err)
+	assert.Equal(t, fromNumbers, fromStrings)
+
+	_, err = ParseMaskJSON([]byte(`[[1,2]]`))
+	assert.Error(t, err)
+}
+
+func TestParseMaskErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty", "# nothing\n"},
+		{"ragged rows", "110\n11\n"},
+		{"invalid character", "1x0\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseMaskText(strings.NewReader(tt.input))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestFileMaskFactory(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "mask.json")
+	require.NoError(t, os.WriteFile(path, []byte(`["1100", "0011"]`), 0644))
+
+	factory := &FileMaskFactory{Path: path}
+	mask, err := factory.CreateMask(4, 2)
+	require.NoError(t, err)
+	assert.True(t, mask.IsProtected(0, 0))
+
+	_, err = factory.CreateMask(4, 1)
+	assert.Error(t, err)
+
+	_, err = NewMaskFromFile(filepath.Join(dir, "missing.txt"))
+	assert.Error(t, err)
+}
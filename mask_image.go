@@ -0,0 +1,87 @@
+package fecanalysis
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// Colors used when rendering a mask as a grid
+var (
+	maskProtectedColor   = color.RGBA{R: 0x2b, G: 0x57, B: 0x9a, A: 0xff}
+	maskUnprotectedColor = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	maskGridColor        = color.RGBA{R: 0xb0, G: 0xb0, B: 0xb0, A: 0xff}
+)
+
+// MaskImage draws the mask as a K x N grid with one cellSize x cellSize cell per
+// (FEC packet, media packet) pair. Protected cells are filled, and cells are separated
+// by one pixel grid lines, so the image is N*cellSize+1 pixels wide and K*cellSize+1 high.
+func MaskImage(mask Mask, cellSize int) (*image.RGBA, error) {
+	if cellSize < 2 {
+		return nil, fmt.Errorf("cell size must be at least 2 pixels, got %d", cellSize)
+	}
+
+	N, K := mask.N(), mask.K()
+	img := image.NewRGBA(image.Rect(0, 0, N*cellSize+1, K*cellSize+1))
+	for y := 0; y <= K*cellSize; y++ {
+		for x := 0; x <= N*cellSize; x++ {
+			if x%cellSize == 0 || y%cellSize == 0 {
+				img.SetRGBA(x, y, maskGridColor)
+			} else if mask.IsProtected(x/cellSize, y/cellSize) {
+				img.SetRGBA(x, y, maskProtectedColor)
+			} else {
+				img.SetRGBA(x, y, maskUnprotectedColor)
+			}
+		}
+	}
+	return img, nil
+}
+
+// WriteMaskPNG renders the mask with MaskImage and encodes it as PNG
+func WriteMaskPNG(w io.Writer, mask Mask, cellSize int) error {
+	img, err := MaskImage(mask, cellSize)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// WriteMaskSVG renders the mask as an SVG document with the same layout as MaskImage
+func WriteMaskSVG(w io.Writer, mask Mask, cellSize int) error {
+	if cellSize < 2 {
+		return fmt.Errorf("cell size must be at least 2 pixels, got %d", cellSize)
+	}
+
+	N, K := mask.N(), mask.K()
+	width, height := N*cellSize+1, K*cellSize+1
+	if _, err := fmt.Fprintf(w, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n",
+		width, height, width, height); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "<rect width=\"%d\" height=\"%d\" fill=\"%s\"/>\n", width, height, svgColor(maskGridColor)); err != nil {
+		return err
+	}
+
+	for fecIndex := 0; fecIndex < K; fecIndex++ {
+		for packetIndex := 0; packetIndex < N; packetIndex++ {
+			fill := maskUnprotectedColor
+			if mask.IsProtected(packetIndex, fecIndex) {
+				fill = maskProtectedColor
+			}
+			if _, err := fmt.Fprintf(w, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"%s\"/>\n",
+				packetIndex*cellSize+1, fecIndex*cellSize+1, cellSize-1, cellSize-1, svgColor(fill)); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := fmt.Fprintln(w, "</svg>")
+	return err
+}
+
+// svgColor formats an opaque color as an SVG hex color
+func svgColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
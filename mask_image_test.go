@@ -0,0 +1,73 @@
+package fecanalysis
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaskImage(t *testing.T) {
+	// F0 protects M0 and M2, F1 protects M1
+	mask, err := NewMatrixMask([][]bool{
+		{true, false, true},
+		{false, true, false},
+	})
+	require.NoError(t, err)
+
+	img, err := MaskImage(mask, 4)
+	require.NoError(t, err)
+	assert.Equal(t, 13, img.Bounds().Dx())
+	assert.Equal(t, 9, img.Bounds().Dy())
+
+	// Grid lines
+	assert.Equal(t, maskGridColor, img.RGBAAt(0, 0))
+	assert.Equal(t, maskGridColor, img.RGBAAt(4, 2))
+	assert.Equal(t, maskGridColor, img.RGBAAt(2, 8))
+
+	// Cell interiors
+	assert.Equal(t, maskProtectedColor, img.RGBAAt(2, 2))
+	assert.Equal(t, maskUnprotectedColor, img.RGBAAt(6, 2))
+	assert.Equal(t, maskProtectedColor, img.RGBAAt(10, 2))
+	assert.Equal(t, maskUnprotectedColor, img.RGBAAt(2, 6))
+	assert.Equal(t, maskProtectedColor, img.RGBAAt(6, 6))
+
+	_, err = MaskImage(mask, 1)
+	assert.Error(t, err)
+}
+
+func TestWriteMaskPNG(t *testing.T) {
+	mask, err := (&InterleavedMaskFactory{}).CreateMask(4, 2)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteMaskPNG(&buf, mask, 8))
+
+	img, err := png.Decode(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, 33, img.Bounds().Dx())
+	assert.Equal(t, 17, img.Bounds().Dy())
+}
+
+func TestWriteMaskSVG(t *testing.T) {
+	mask, err := NewMatrixMask([][]bool{
+		{true, false, true},
+		{false, true, false},
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteMaskSVG(&buf, mask, 10))
+	svg := buf.String()
+
+	assert.True(t, strings.HasPrefix(svg, "<svg "))
+	assert.Contains(t, svg, `width="31" height="21"`)
+	assert.Equal(t, 3, strings.Count(svg, `fill="`+svgColor(maskProtectedColor)+`"`))
+	assert.Equal(t, 3, strings.Count(svg, `fill="`+svgColor(maskUnprotectedColor)+`"`))
+	assert.Contains(t, svg, `<rect x="21" y="1" width="9" height="9" fill="#2b579a"/>`)
+
+	assert.Error(t, WriteMaskSVG(&buf, mask, 0))
+}
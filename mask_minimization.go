@@ -0,0 +1,79 @@
+package fecanalysis
+
+// RowMinimization is the result of MinimizeRows
+type RowMinimization struct {
+	Mask          *MatrixMask // equivalent mask without the redundant FEC packets
+	RemovedRows   []int       // FEC indices of the original mask that were removed, ascending
+	SavedOverhead float64     // overhead saved by the removal: removed FEC packets / N
+}
+
+// MinimizeRows greedily removes FEC packets that don't change the recoverable set: a row is
+// redundant if every delivery pattern of the other packets is recovered alike whether the
+// row's FEC packet arrives or is lost. A delivered row alone repairs a single loss of any
+// media packet it protects, so in practice only rows protecting no media packet are
+// removed; duplicate and linearly dependent rows are kept, since they help once another
+// FEC packet is lost. Rows are tried from the last to the first against the already
+// reduced mask, so the result has no redundant row left. At least one row is always kept,
+// so masks without FEC packets are rejected.
+func MinimizeRows(mask Mask) (RowMinimization, error) {
+	if err := checkEnumerable(mask.N(), mask.K()); err != nil {
+		return RowMinimization{}, err
+	}
+	rows := maskMatrix(mask)
+	kept := make([]int, len(rows))
+	for fecIndex := range kept {
+		kept[fecIndex] = fecIndex
+	}
+	current, err := NewMatrixMask(rows)
+	if err != nil {
+		return RowMinimization{}, err
+	}
+	currentSet := recoverableSet(current)
+
+	var removed []int
+	for position := len(kept) - 1; position >= 0 && len(kept) > 1; position-- {
+		candidateRows := make([][]bool, 0, len(kept)-1)
+		for _, fecIndex := range kept[:position] {
+			candidateRows = append(candidateRows, rows[fecIndex])
+		}
+		for _, fecIndex := range kept[position+1:] {
+			candidateRows = append(candidateRows, rows[fecIndex])
+		}
+		candidate, err := NewMatrixMask(candidateRows)
+		if err != nil {
+			return RowMinimization{}, err
+		}
+		candidateSet := recoverableSet(candidate)
+		if !sameWithoutPacket(currentSet, candidateSet, mask.N()+position) {
+			continue
+		}
+
+		removed = append([]int{kept[position]}, removed...)
+		kept = append(kept[:position], kept[position+1:]...)
+		current, currentSet = candidate, candidateSet
+	}
+
+	return RowMinimization{
+		Mask:          current,
+		RemovedRows:   removed,
+		SavedOverhead: float64(len(removed)) / float64(mask.N()),
+	}, nil
+}
+
+// sameWithoutPacket reports whether the recoverable set current equals candidate, the
+// recoverable set without the packet at bit, once that bit is projected out: the packet
+// must be irrelevant, so every pattern of the other packets is in candidate exactly when it
+// is in current with the packet both lost and delivered
+func sameWithoutPacket(current, candidate *ReachableSet, bit int) bool {
+	if current.Count() != 2*candidate.Count() {
+		return false
+	}
+	same := true
+	current.Iterate(func(vertex uint64) {
+		low := vertex & (uint64(1)<<bit - 1)
+		if !candidate.Contains(low | vertex>>(bit+1)<<bit) {
+			same = false
+		}
+	})
+	return same
+}
@@ -0,0 +1,104 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinimizeRows(t *testing.T) {
+	tests := []struct {
+		name     string
+		rows     [][]bool
+		expected [][]bool
+		removed  []int
+	}{
+		{
+			// The copy repairs the losses of the first row once that FEC packet is lost
+			name: "duplicate row",
+			rows: [][]bool{
+				{true, true, false},
+				{false, true, true},
+				{true, true, false},
+			},
+			expected: [][]bool{
+				{true, true, false},
+				{false, true, true},
+				{true, true, false},
+			},
+		},
+		{
+			name: "empty rows",
+			rows: [][]bool{
+				{false, false, false},
+				{true, true, true},
+				{false, false, false},
+			},
+			expected: [][]bool{{true, true, true}},
+			removed:  []int{0, 2},
+		},
+		{
+			// The XOR of the first two rows repairs a loss when one of them is lost
+			name: "dependent row",
+			rows: [][]bool{
+				{true, true, false},
+				{false, true, true},
+				{true, false, true},
+			},
+			expected: [][]bool{
+				{true, true, false},
+				{false, true, true},
+				{true, false, true},
+			},
+		},
+		{
+			name: "no redundant rows",
+			rows: [][]bool{
+				{true, true, false},
+				{false, true, true},
+			},
+			expected: [][]bool{
+				{true, true, false},
+				{false, true, true},
+			},
+		},
+		{
+			name:     "last row kept",
+			rows:     [][]bool{{false, false}},
+			expected: [][]bool{{false, false}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mask := NewSimpleMask(tt.rows, len(tt.rows[0]), len(tt.rows))
+			result, err := MinimizeRows(mask)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, maskMatrix(result.Mask))
+			assert.Equal(t, tt.removed, result.RemovedRows)
+			assert.InDelta(t, float64(len(tt.removed))/float64(mask.N()), result.SavedOverhead, 1e-12)
+		})
+	}
+}
+
+func TestMinimizeRowsPreservesRecovery(t *testing.T) {
+	mask, err := (&GoogleRandomMaskFactory{}).CreateMask(4, 3)
+	require.NoError(t, err)
+	result, err := MinimizeRows(mask)
+	require.NoError(t, err)
+	assert.Empty(t, result.RemovedRows)
+
+	// An empty row is removed and the rest recovers exactly the same patterns
+	rows := maskMatrix(mask)
+	padded := NewSimpleMask(append(rows[:1:1], append([][]bool{make([]bool, 4)}, rows[1:]...)...), 4, 4)
+	result, err = MinimizeRows(padded)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1}, result.RemovedRows)
+	assert.Equal(t, recoverableSet(mask).Vertices(), recoverableSet(result.Mask).Vertices())
+}
+
+func TestMinimizeRowsWithoutFEC(t *testing.T) {
+	_, err := MinimizeRows(NewSimpleMask(nil, 3, 0))
+	assert.Error(t, err)
+}
@@ -0,0 +1,120 @@
+package fecanalysis
+
+import "fmt"
+
+// MaskMutator proposes a random move on a protection matrix (rows[fecIndex][packetIndex])
+// for the mask optimizers. Implementations can encode domain knowledge, e.g. moves that
+// keep row weights or column structure, without changing the optimizers.
+type MaskMutator interface {
+	// Mutate modifies rows in place and returns true, or returns false and leaves rows
+	// unchanged if it found no valid move
+	Mutate(rows [][]bool, rng RandSource) bool
+}
+
+// FlipBitMutator flips one random bit, refusing moves that would leave a FEC packet
+// protecting nothing
+type FlipBitMutator struct{}
+
+// Mutate flips one random bit of the matrix
+func (FlipBitMutator) Mutate(rows [][]bool, rng RandSource) bool {
+	return flipRandomBit(rows, rng)
+}
+
+// SwapColumnsMutator swaps the protection of two random media packets, which keeps the
+// row weights and the multiset of columns and only changes which packets share FEC packets
+type SwapColumnsMutator struct{}
+
+// Mutate swaps two distinct media packet columns; it fails if they are identical
+func (SwapColumnsMutator) Mutate(rows [][]bool, rng RandSource) bool {
+	N := len(rows[0])
+	if N < 2 {
+		return false
+	}
+	first := rng.Intn(N)
+	second := (first + 1 + rng.Intn(N-1)) % N
+
+	same := true
+	for _, row := range rows {
+		same = same && row[first] == row[second]
+	}
+	if same {
+		return false
+	}
+	for _, row := range rows {
+		row[first], row[second] = row[second], row[first]
+	}
+	return true
+}
+
+// MoveProtectionMutator moves one protection within a FEC row from a protected media
+// packet to an unprotected one, keeping every row weight
+type MoveProtectionMutator struct{}
+
+// Mutate moves a random protection of a random row; it fails for rows that protect no or
+// all media packets
+func (MoveProtectionMutator) Mutate(rows [][]bool, rng RandSource) bool {
+	row := rows[rng.Intn(len(rows))]
+	var protected, unprotected []int
+	for packetIndex, isProtected := range row {
+		if isProtected {
+			protected = append(protected, packetIndex)
+		} else {
+			unprotected = append(unprotected, packetIndex)
+		}
+	}
+	if len(protected) == 0 || len(unprotected) == 0 {
+		return false
+	}
+
+	row[protected[rng.Intn(len(protected))]] = false
+	row[unprotected[rng.Intn(len(unprotected))]] = true
+	return true
+}
+
+// MixedMutator applies one of several mutators, chosen at random in proportion to its weight
+type MixedMutator struct {
+	mutators []MaskMutator
+	weights  []float64
+	total    float64
+}
+
+// NewMixedMutator creates a mutator choosing among mutators with the given weights; nil
+// weights choose uniformly
+func NewMixedMutator(mutators []MaskMutator, weights []float64) (*MixedMutator, error) {
+	if len(mutators) == 0 {
+		return nil, fmt.Errorf("mixed mutator needs at least one mutator")
+	}
+	if weights == nil {
+		weights = make([]float64, len(mutators))
+		for i := range weights {
+			weights[i] = 1
+		}
+	}
+	if len(weights) != len(mutators) {
+		return nil, fmt.Errorf("got %d weights for %d mutators", len(weights), len(mutators))
+	}
+
+	mixed := &MixedMutator{mutators: mutators, weights: append([]float64(nil), weights...)}
+	for _, weight := range weights {
+		if weight < 0 {
+			return nil, fmt.Errorf("negative mutator weight %g", weight)
+		}
+		mixed.total += weight
+	}
+	if mixed.total <= 0 {
+		return nil, fmt.Errorf("mutator weights sum to zero")
+	}
+	return mixed, nil
+}
+
+// Mutate applies one randomly chosen mutator
+func (m *MixedMutator) Mutate(rows [][]bool, rng RandSource) bool {
+	choice := rng.Float64() * m.total
+	for i, weight := range m.weights {
+		if choice < weight || i == len(m.weights)-1 {
+			return m.mutators[i].Mutate(rows, rng)
+		}
+		choice -= weight
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package fecanalysis
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaskMutators(t *testing.T) {
+	start := [][]bool{
+		{true, true, false, false},
+		{false, true, true, false},
+		{true, false, false, true},
+	}
+	structure := AnalyzeMask(NewSimpleMask(start, 4, 3))
+
+	tests := []struct {
+		name    string
+		mutator MaskMutator
+		check   func(t *testing.T, mutated MaskStructure)
+	}{
+		{"flip bit", FlipBitMutator{}, func(t *testing.T, mutated MaskStructure) {
+			assert.InDelta(t, 1.0/12.0, math.Abs(mutated.Density-structure.Density), 1e-12)
+		}},
+		{"swap columns", SwapColumnsMutator{}, func(t *testing.T, mutated MaskStructure) {
+			assert.Equal(t, structure.RowWeights, mutated.RowWeights)
+			assert.ElementsMatch(t, structure.ColumnWeights, mutated.ColumnWeights)
+		}},
+		{"move protection", MoveProtectionMutator{}, func(t *testing.T, mutated MaskStructure) {
+			assert.Equal(t, structure.RowWeights, mutated.RowWeights)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rng := NewRandSource(3)
+			for i := 0; i < 20; i++ {
+				rows := cloneRows(start)
+				if !tt.mutator.Mutate(rows, rng) {
+					assert.Equal(t, start, rows, "refused moves leave the matrix unchanged")
+					continue
+				}
+				assert.NotEqual(t, start, rows)
+				tt.check(t, AnalyzeMask(NewSimpleMask(rows, 4, 3)))
+			}
+		})
+	}
+}
+
+func TestMaskMutatorsRefuseInvalidMoves(t *testing.T) {
+	rng := NewRandSource(1)
+
+	single := [][]bool{{true}}
+	assert.False(t, FlipBitMutator{}.Mutate(single, rng), "a row may not become empty")
+	assert.False(t, SwapColumnsMutator{}.Mutate(single, rng))
+
+	full := [][]bool{{true, true}}
+	assert.False(t, SwapColumnsMutator{}.Mutate(full, rng), "identical columns")
+	assert.False(t, MoveProtectionMutator{}.Mutate(full, rng), "no unprotected packet")
+	assert.Equal(t, [][]bool{{true, true}}, full)
+}
+
+func TestMixedMutator(t *testing.T) {
+	// Only the weighted mutator is ever chosen
+	mixed, err := NewMixedMutator([]MaskMutator{FlipBitMutator{}, MoveProtectionMutator{}}, []float64{0, 1})
+	require.NoError(t, err)
+	rng := NewRandSource(2)
+	for i := 0; i < 20; i++ {
+		rows := [][]bool{{true, false, false}, {false, true, true}}
+		require.True(t, mixed.Mutate(rows, rng))
+		assert.Equal(t, []int{1, 2}, AnalyzeMask(NewSimpleMask(rows, 3, 2)).RowWeights)
+	}
+
+	_, err = NewMixedMutator(nil, nil)
+	assert.Error(t, err)
+	_, err = NewMixedMutator([]MaskMutator{FlipBitMutator{}}, []float64{1, 2})
+	assert.Error(t, err)
+	_, err = NewMixedMutator([]MaskMutator{FlipBitMutator{}}, []float64{0})
+	assert.Error(t, err)
+}
+
+func TestOptimizersWithMutator(t *testing.T) {
+	start, err := (&GoogleRandomMaskFactory{}).CreateMask(6, 3)
+	require.NoError(t, err)
+	objective := RecoveryProbabilityObjective(NewRandomLossModel(0.1))
+	weights := AnalyzeMask(start).RowWeights
+
+	// Moving protections keeps the row weights of the start mask
+	annealing := &AnnealingOptimizer{Iterations: 200, Mutator: MoveProtectionMutator{}, Rand: NewRandSource(4)}
+	result, err := annealing.Optimize(6, 3, objective, start)
+	require.NoError(t, err)
+	assert.Equal(t, weights, AnalyzeMask(result.Mask).RowWeights)
+	assert.GreaterOrEqual(t, result.Score, objective(start))
+
+	genetic := &GeneticOptimizer{PopulationSize: 8, Generations: 5, CrossoverRate: 0.01, Mutator: SwapColumnsMutator{}, Rand: NewRandSource(4)}
+	result, err = genetic.Optimize(6, 3, objective, start)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, result.Score, objective(start))
+}
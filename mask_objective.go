@@ -0,0 +1,58 @@
+package fecanalysis
+
+import "math"
+
+// MaskObjective scores a mask for mask optimizers; higher is better
+type MaskObjective func(mask Mask) float64
+
+// RecoveryProbability returns the probability that all media packets are available after
+// recovery, i.e. the total probability of the delivery patterns reachable in the recovery graph
+func RecoveryProbability(mask Mask, model LossModel) float64 {
+	return SumProbabilities(recoverableSet(mask), model, mask.N()+mask.K(), 0)
+}
+
+// RecoveryProbabilityObjective scores masks by their recovery probability under the model
+func RecoveryProbabilityObjective(model LossModel) MaskObjective {
+	return func(mask Mask) float64 {
+		return RecoveryProbability(mask, model)
+	}
+}
+
+// ResidualLossObjective scores masks by the negated ResidualLossRate, the expected fraction of
+// media packets still missing after recovery, so that partial recovery is rewarded too. Masks
+// too large for ResidualLossRate score -Inf.
+func ResidualLossObjective(model LossModel) MaskObjective {
+	return func(mask Mask) float64 {
+		residual, err := ResidualLossRate(mask, model)
+		if err != nil {
+			return math.Inf(-1)
+		}
+		return -residual
+	}
+}
+
+// MinLossForFailureObjective scores masks by the minimum number of lost packets that can
+// make recovery fail; masks that recover every pattern score N+K+1
+func MinLossForFailureObjective() MaskObjective {
+	return func(mask Mask) float64 {
+		characteristics := CalculateRecoveryCharacteristics(mask)
+		return characteristicScore(characteristics.MinLostPacketsForNonRecovery, mask)
+	}
+}
+
+// BurstToleranceObjective scores masks by the length of the shortest burst of consecutive
+// losses that can make recovery fail; masks that recover every burst score N+K+1
+func BurstToleranceObjective() MaskObjective {
+	return func(mask Mask) float64 {
+		characteristics := CalculateRecoveryCharacteristics(mask)
+		return characteristicScore(characteristics.MinConsecutiveLostForNonRecovery, mask)
+	}
+}
+
+// characteristicScore maps a recovery characteristic to a score, treating -1 (perfect recovery) as best
+func characteristicScore(value LossCount, mask Mask) float64 {
+	if value < 0 {
+		return float64(mask.N() + mask.K() + 1)
+	}
+	return float64(value)
+}
@@ -0,0 +1,221 @@
+package fecanalysis
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MaskFactoryConstructor builds a mask factory from the parameter following the colon in
+// a registry name ("prompeg:5x4" passes "5x4"); the parameter is empty if there is none
+type MaskFactoryConstructor func(parameter string) (MaskFactory, error)
+
+var (
+	registryMutex sync.RWMutex
+	maskRegistry  = map[string]MaskFactoryConstructor{
+		"bursty":           staticFactory(func() MaskFactory { return &GoogleBurstyMaskFactory{} }),
+		"random":           staticFactory(func() MaskFactory { return &GoogleRandomMaskFactory{} }),
+		"interleaved":      newInterleavedFactory,
+		"layered":          staticFactory(func() MaskFactory { return &LayeredMaskFactory{} }),
+		"hamming":          staticFactory(func() MaskFactory { return &HammingMaskFactory{} }),
+		"extended-hamming": staticFactory(func() MaskFactory { return &ExtendedHammingMaskFactory{} }),
+		"single-parity":    staticFactory(func() MaskFactory { return &SingleParityMaskFactory{} }),
+		"cyclic":           newCyclicFactory,
+		"diagonal":         newDiagonalFactory,
+		"product":          newProductCodeFactory,
+		// The WebRTC FlexFEC sender generates its masks from the random table, fitted
+		// beyond the table size like ULPFEC
+		"flexfec": staticFactory(func() MaskFactory {
+			return &FittedMaskFactory{Base: &GoogleRandomMaskFactory{}}
+		}),
+		"uniform": seededFactory(func(seed int64) MaskFactory { return &RandomMaskFactory{Seed: seed} }),
+		"ldpc":    seededFactory(func(seed int64) MaskFactory { return &LDPCMaskFactory{Seed: seed} }),
+		"prompeg": newProMPEGFactory,
+	}
+)
+
+// RegisterMaskFactory adds or replaces a named mask factory in the registry
+func RegisterMaskFactory(name string, constructor MaskFactoryConstructor) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	maskRegistry[strings.ToLower(name)] = constructor
+}
+
+// MaskFactoryNames returns the registered mask factory names in sorted order
+func MaskFactoryNames() []string {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	names := make([]string, 0, len(maskRegistry))
+	for name := range maskRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupMaskFactory returns the mask factory for a name such as "bursty", "flexfec" or
+// "prompeg:5x4" (case-insensitive, parameter after the colon). Names that aren't
+// registered but refer to a file (they contain a path separator, end in .txt or .json,
+// or exist on disk) load a custom mask with FileMaskFactory.
+func LookupMaskFactory(name string) (MaskFactory, error) {
+	base, parameter, _ := strings.Cut(name, ":")
+
+	registryMutex.RLock()
+	constructor, exists := maskRegistry[strings.ToLower(base)]
+	registryMutex.RUnlock()
+	if exists {
+		factory, err := constructor(parameter)
+		if err != nil {
+			return nil, fmt.Errorf("mask %q: %w", name, err)
+		}
+		return factory, nil
+	}
+
+	if isMaskFilePath(name) {
+		return &FileMaskFactory{Path: name}, nil
+	}
+	return nil, fmt.Errorf("unknown mask %q (available: %s, or a mask file path)", name, strings.Join(MaskFactoryNames(), ", "))
+}
+
+// isMaskFilePath returns true if the name looks like or is the path of a mask file
+func isMaskFilePath(name string) bool {
+	if strings.ContainsRune(name, filepath.Separator) || strings.ContainsRune(name, '/') {
+		return true
+	}
+	if extension := strings.ToLower(filepath.Ext(name)); extension == ".txt" || extension == ".json" {
+		return true
+	}
+	_, err := os.Stat(name)
+	return err == nil
+}
+
+// staticFactory adapts a parameterless factory to a registry constructor
+func staticFactory(create func() MaskFactory) MaskFactoryConstructor {
+	return func(parameter string) (MaskFactory, error) {
+		if parameter != "" {
+			return nil, fmt.Errorf("takes no parameter, got %q", parameter)
+		}
+		return create(), nil
+	}
+}
+
+// seededFactory adapts a seeded factory to a registry constructor; the optional parameter
+// is the seed, which otherwise comes from the package-level random source
+func seededFactory(create func(seed int64) MaskFactory) MaskFactoryConstructor {
+	return func(parameter string) (MaskFactory, error) {
+		if parameter == "" {
+			return create(NextRandSource().Int63()), nil
+		}
+		seed, err := strconv.ParseInt(parameter, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid seed %q", parameter)
+		}
+		return create(seed), nil
+	}
+}
+
+// newInterleavedFactory parses the optional interleaving depth of "interleaved:2"
+func newInterleavedFactory(parameter string) (MaskFactory, error) {
+	if parameter == "" {
+		return &InterleavedMaskFactory{}, nil
+	}
+	depth, err := strconv.Atoi(parameter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid interleaving depth %q", parameter)
+	}
+	factory := &InterleavedMaskFactory{Depth: depth}
+	if err := factory.validateDepth(); err != nil {
+		return nil, err
+	}
+	return factory, nil
+}
+
+// newCyclicFactory parses the optional generator polynomial of "cyclic:0b1011" with Go
+// integer literal syntax (binary, octal, hex or decimal)
+func newCyclicFactory(parameter string) (MaskFactory, error) {
+	if parameter == "" {
+		return &CyclicMaskFactory{}, nil
+	}
+	generator, err := strconv.ParseUint(parameter, 0, 64)
+	if err != nil || generator == 0 {
+		return nil, fmt.Errorf("invalid generator polynomial %q", parameter)
+	}
+	return &CyclicMaskFactory{Generator: generator}, nil
+}
+
+// newDiagonalFactory parses the optional per-row offset of "diagonal:2", which defaults to 1
+func newDiagonalFactory(parameter string) (MaskFactory, error) {
+	if parameter == "" {
+		return &DiagonalInterleavedMaskFactory{Offset: 1}, nil
+	}
+	offset, err := strconv.Atoi(parameter)
+	if err != nil || offset < 0 {
+		return nil, fmt.Errorf("invalid diagonal offset %q", parameter)
+	}
+	return &DiagonalInterleavedMaskFactory{Offset: offset}, nil
+}
+
+// newProductCodeFactory parses the optional "RxC" arrangement of "product:3x4"
+func newProductCodeFactory(parameter string) (MaskFactory, error) {
+	if parameter == "" {
+		return &ProductCodeMaskFactory{}, nil
+	}
+	rowsText, columnsText, found := strings.Cut(strings.ToLower(parameter), "x")
+	rows, rowsErr := strconv.Atoi(rowsText)
+	columns, columnsErr := strconv.Atoi(columnsText)
+	if !found || rowsErr != nil || columnsErr != nil || rows <= 0 || columns <= 0 {
+		return nil, fmt.Errorf("expected RxC dimensions, got %q", parameter)
+	}
+	return &ProductCodeMaskFactory{Rows: rows, Columns: columns}, nil
+}
+
+// ProMPEGMaskFactory creates SMPTE 2022-1 (Pro-MPEG COP3) column FEC masks: the block
+// is a matrix of Rows rows of Columns media packets in transmission order, and FEC packet
+// c protects column c, i.e. media packets c, c+Columns, ... With RowFEC set, one FEC
+// packet per row is added after the column FEC packets (2D FEC).
+type ProMPEGMaskFactory struct {
+	Columns int  // L: media packets per matrix row, one column FEC packet each
+	Rows    int  // D: matrix rows
+	RowFEC  bool // also send one FEC packet per matrix row
+}
+
+// CreateMask creates the Pro-MPEG mask; N must be Columns*Rows and K the number of FEC packets
+func (f *ProMPEGMaskFactory) CreateMask(N, K int) (Mask, error) {
+	expectedK := f.Columns
+	if f.RowFEC {
+		expectedK += f.Rows
+	}
+	if N != f.Columns*f.Rows || K != expectedK {
+		return nil, fmt.Errorf("Pro-MPEG %dx%d mask is N=%d, K=%d, requested N=%d, K=%d",
+			f.Columns, f.Rows, f.Columns*f.Rows, expectedK, N, K)
+	}
+
+	rows := make([][]bool, K)
+	for fecIndex := range rows {
+		rows[fecIndex] = make([]bool, N)
+	}
+	for packetIndex := 0; packetIndex < N; packetIndex++ {
+		rows[packetIndex%f.Columns][packetIndex] = true
+		if f.RowFEC {
+			rows[f.Columns+packetIndex/f.Columns][packetIndex] = true
+		}
+	}
+	return NewMatrixMask(rows)
+}
+
+// newProMPEGFactory parses "LxD" (column FEC) or "LxD+row" (2D FEC)
+func newProMPEGFactory(parameter string) (MaskFactory, error) {
+	dimensions, rowFEC := strings.CutSuffix(strings.ToLower(parameter), "+row")
+	columnsText, rowsText, found := strings.Cut(dimensions, "x")
+	columns, columnsErr := strconv.Atoi(columnsText)
+	rows, rowsErr := strconv.Atoi(rowsText)
+	if !found || columnsErr != nil || rowsErr != nil || columns <= 0 || rows <= 0 {
+		return nil, fmt.Errorf("expected LxD or LxD+row dimensions, got %q", parameter)
+	}
+	return &ProMPEGMaskFactory{Columns: columns, Rows: rows, RowFEC: rowFEC}, nil
+}
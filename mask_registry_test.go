@@ -0,0 +1,93 @@
+package fecanalysis
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupMaskFactoryBuiltins(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected MaskFactory
+	}{
+		{"bursty", &GoogleBurstyMaskFactory{}},
+		{"Random", &GoogleRandomMaskFactory{}},
+		{"interleaved", &InterleavedMaskFactory{}},
+		{"interleaved:3", &InterleavedMaskFactory{Depth: 3}},
+		{"interleaved:0", &InterleavedMaskFactory{}},
+		{"flexfec", &FittedMaskFactory{Base: &GoogleRandomMaskFactory{}}},
+		{"uniform:7", &RandomMaskFactory{Seed: 7}},
+		{"prompeg:5x4", &ProMPEGMaskFactory{Columns: 5, Rows: 4}},
+		{"prompeg:5x4+row", &ProMPEGMaskFactory{Columns: 5, Rows: 4, RowFEC: true}},
+		{"cyclic", &CyclicMaskFactory{}},
+		{"diagonal", &DiagonalInterleavedMaskFactory{Offset: 1}},
+		{"product", &ProductCodeMaskFactory{}},
+		{"product:2x3", &ProductCodeMaskFactory{Rows: 2, Columns: 3}},
+		{"diagonal:3", &DiagonalInterleavedMaskFactory{Offset: 3}},
+		{"cyclic:0b111", &CyclicMaskFactory{Generator: 7}},
+		{"cyclic:0x13", &CyclicMaskFactory{Generator: 0x13}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory, err := LookupMaskFactory(tt.name)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, factory)
+		})
+	}
+
+	for _, name := range []string{"unknown", "bursty:3", "prompeg:5", "prompeg:0x4", "uniform:abc", "cyclic:0", "cyclic:xyz", "diagonal:-1", "interleaved:-1", "interleaved:x", "product:3"} {
+		_, err := LookupMaskFactory(name)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestLookupMaskFactoryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "custom.txt")
+	require.NoError(t, os.WriteFile(path, []byte("110\n011\n"), 0644))
+
+	factory, err := LookupMaskFactory(path)
+	require.NoError(t, err)
+	mask, err := factory.CreateMask(3, 2)
+	require.NoError(t, err)
+	assert.True(t, mask.IsProtected(2, 1))
+}
+
+func TestRegisterMaskFactory(t *testing.T) {
+	RegisterMaskFactory("test-only", func(parameter string) (MaskFactory, error) {
+		return &LayeredMaskFactory{}, nil
+	})
+	t.Cleanup(func() {
+		registryMutex.Lock()
+		defer registryMutex.Unlock()
+		delete(maskRegistry, "test-only")
+	})
+	assert.Contains(t, MaskFactoryNames(), "test-only")
+
+	factory, err := LookupMaskFactory("TEST-ONLY")
+	require.NoError(t, err)
+	assert.Equal(t, &LayeredMaskFactory{}, factory)
+}
+
+func TestProMPEGMaskFactory(t *testing.T) {
+	factory := &ProMPEGMaskFactory{Columns: 3, Rows: 2, RowFEC: true}
+	mask, err := factory.CreateMask(6, 5)
+	require.NoError(t, err)
+	assert.Equal(t, [][]bool{
+		{true, false, false, true, false, false},
+		{false, true, false, false, true, false},
+		{false, false, true, false, false, true},
+		{true, true, true, false, false, false},
+		{false, false, false, true, true, true},
+	}, maskMatrix(mask))
+
+	// 2D FEC recovers any two losses
+	assert.Greater(t, CalculateRecoveryCharacteristics(mask).MinLostPacketsForNonRecovery, LossCount(2))
+
+	_, err = factory.CreateMask(6, 3)
+	assert.Error(t, err)
+}
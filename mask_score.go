@@ -0,0 +1,95 @@
+package fecanalysis
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ScoreWeights sets how much every metric contributes to ScoreMask. The burst tolerance
+// and minimum loss for failure are normalized by N+K+1 (their value for a mask that
+// recovers everything), so all rewarded metrics range over 0..1.
+type ScoreWeights struct {
+	RecoveryProbability float64 // weight of the recovery probability under the loss model
+	BurstTolerance      float64 // weight of the shortest burst of losses that can make recovery fail
+	MinLossForFailure   float64 // weight of the minimum number of losses that can make recovery fail
+	Overhead            float64 // penalty per unit of overhead (K/N)
+}
+
+// DefaultScoreWeights favours the recovery probability, breaking near-ties with the
+// worst-case metrics and a small overhead penalty
+func DefaultScoreWeights() ScoreWeights {
+	return ScoreWeights{
+		RecoveryProbability: 1,
+		BurstTolerance:      0.1,
+		MinLossForFailure:   0.1,
+		Overhead:            0.05,
+	}
+}
+
+// ParseScoreWeights parses "recovery,burst,minloss,overhead" weights such as "1,0.1,0.1,0.05"
+func ParseScoreWeights(spec string) (ScoreWeights, error) {
+	fields := strings.Split(spec, ",")
+	if len(fields) != 4 {
+		return ScoreWeights{}, fmt.Errorf("expected 4 comma-separated weights (recovery,burst,minloss,overhead), got %q", spec)
+	}
+
+	values := make([]float64, len(fields))
+	for i, field := range fields {
+		value, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return ScoreWeights{}, fmt.Errorf("invalid weight %q: %w", field, err)
+		}
+		values[i] = value
+	}
+	return ScoreWeights{
+		RecoveryProbability: values[0],
+		BurstTolerance:      values[1],
+		MinLossForFailure:   values[2],
+		Overhead:            values[3],
+	}, nil
+}
+
+// ScoreMask combines the recovery probability, burst tolerance, minimum loss for failure
+// and overhead of a mask into a single score; higher is better
+func ScoreMask(mask Mask, model LossModel, weights ScoreWeights) float64 {
+	characteristics := CalculateRecoveryCharacteristics(mask)
+	scale := float64(mask.N() + mask.K() + 1)
+
+	score := weights.RecoveryProbability * RecoveryProbability(mask, model)
+	score += weights.BurstTolerance * characteristicScore(characteristics.MinConsecutiveLostForNonRecovery, mask) / scale
+	score += weights.MinLossForFailure * characteristicScore(characteristics.MinLostPacketsForNonRecovery, mask) / scale
+	score -= weights.Overhead * float64(mask.K()) / float64(mask.N())
+	return score
+}
+
+// ScoreObjective scores masks with ScoreMask for the mask optimizers
+func ScoreObjective(model LossModel, weights ScoreWeights) MaskObjective {
+	return func(mask Mask) float64 {
+		return ScoreMask(mask, model, weights)
+	}
+}
+
+// RankedMask is a named mask with its ScoreMask score
+type RankedMask struct {
+	Name  string
+	Mask  Mask
+	Score float64
+}
+
+// RankMasks scores the named masks and returns them from best to worst; masks with equal
+// scores keep their order by name
+func RankMasks(masks map[string]Mask, model LossModel, weights ScoreWeights) []RankedMask {
+	ranked := make([]RankedMask, 0, len(masks))
+	for name, mask := range masks {
+		ranked = append(ranked, RankedMask{Name: name, Mask: mask, Score: ScoreMask(mask, model, weights)})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].Name < ranked[j].Name
+	})
+	return ranked
+}
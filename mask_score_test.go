@@ -0,0 +1,76 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScoreMask(t *testing.T) {
+	mask, err := (&InterleavedMaskFactory{}).CreateMask(4, 2)
+	require.NoError(t, err)
+	model := NewGilbertLossModel(0.5, 0.1, 0.5)
+	characteristics := CalculateRecoveryCharacteristics(mask)
+
+	tests := []struct {
+		name     string
+		weights  ScoreWeights
+		expected float64
+	}{
+		{"recovery only", ScoreWeights{RecoveryProbability: 1}, RecoveryProbability(mask, model)},
+		{"burst only", ScoreWeights{BurstTolerance: 1}, float64(characteristics.MinConsecutiveLostForNonRecovery) / 7},
+		{"min loss only", ScoreWeights{MinLossForFailure: 1}, float64(characteristics.MinLostPacketsForNonRecovery) / 7},
+		{"overhead only", ScoreWeights{Overhead: 1}, -0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.expected, ScoreMask(mask, model, tt.weights), 1e-12)
+		})
+	}
+
+	// Weights combine linearly
+	weights := DefaultScoreWeights()
+	expected := 0.0
+	for _, tt := range tests {
+		expected += ScoreMask(mask, model, tt.weights) * map[string]float64{
+			"recovery only": weights.RecoveryProbability,
+			"burst only":    weights.BurstTolerance,
+			"min loss only": weights.MinLossForFailure,
+			"overhead only": weights.Overhead,
+		}[tt.name]
+	}
+	assert.InDelta(t, expected, ScoreObjective(model, weights)(mask), 1e-12)
+}
+
+func TestParseScoreWeights(t *testing.T) {
+	weights, err := ParseScoreWeights("1, 0.5,0.25,0")
+	require.NoError(t, err)
+	assert.Equal(t, ScoreWeights{RecoveryProbability: 1, BurstTolerance: 0.5, MinLossForFailure: 0.25}, weights)
+
+	_, err = ParseScoreWeights("1,0.5")
+	assert.Error(t, err)
+	_, err = ParseScoreWeights("1,0.5,x,0")
+	assert.Error(t, err)
+}
+
+func TestRankMasks(t *testing.T) {
+	single, err := (&SingleParityMaskFactory{}).CreateMask(4, 1)
+	require.NoError(t, err)
+	interleaved, err := (&InterleavedMaskFactory{}).CreateMask(4, 2)
+	require.NoError(t, err)
+	random, err := (&GoogleRandomMaskFactory{}).CreateMask(4, 4)
+	require.NoError(t, err)
+
+	// Without an overhead penalty more FEC packets rank higher
+	ranked := RankMasks(map[string]Mask{
+		"single":      single,
+		"interleaved": interleaved,
+		"random":      random,
+	}, NewRandomLossModel(0.1), ScoreWeights{RecoveryProbability: 1})
+	require.Len(t, ranked, 3)
+	assert.Equal(t, []string{"random", "interleaved", "single"}, []string{ranked[0].Name, ranked[1].Name, ranked[2].Name})
+	assert.GreaterOrEqual(t, ranked[0].Score, ranked[1].Score)
+	assert.GreaterOrEqual(t, ranked[1].Score, ranked[2].Score)
+}
@@ -0,0 +1,97 @@
+package fecanalysis
+
+import "fmt"
+
+// MaskSet models D consecutive FEC groups protected by the same mask whose packets are
+// interleaved on the wire: wire position p carries packet p/D of group p%D, where the
+// packets of a group are ordered media first, then FEC, as in the recovery graph
+// vertices. A burst of B wire losses then costs each group only about B/D packets, so
+// single-block analysis understates the burst resilience of interleaved senders, while
+// bursts crossing into the next set still hit the tails and heads of neighbouring groups.
+type MaskSet struct {
+	Mask  Mask // mask of every group
+	Depth int  // number of interleaved groups D
+}
+
+// NewMaskSet creates a set of depth interleaved groups protected by the mask
+func NewMaskSet(mask Mask, depth int) (*MaskSet, error) {
+	if depth <= 0 {
+		return nil, fmt.Errorf("interleaving depth must be positive, got %d", depth)
+	}
+	return &MaskSet{Mask: mask, Depth: depth}, nil
+}
+
+// WireLength returns the number of packets the set sends on the wire
+func (s *MaskSet) WireLength() int {
+	return s.Depth * (s.Mask.N() + s.Mask.K())
+}
+
+// WirePosition returns the wire position of packet index (media first, then FEC) of a group
+func (s *MaskSet) WirePosition(group, index int) int {
+	return index*s.Depth + group
+}
+
+// GroupPatterns splits a wire delivery pattern (bit p set if wire position p was
+// delivered) into the delivery pattern of every group
+func (s *MaskSet) GroupPatterns(wirePattern int) []int {
+	patterns := make([]int, s.Depth)
+	for position := 0; position < s.WireLength(); position++ {
+		if wirePattern&(1<<position) != 0 {
+			patterns[position%s.Depth] |= 1 << (position / s.Depth)
+		}
+	}
+	return patterns
+}
+
+// MinBurstForFailure returns the length of the shortest burst of consecutive wire losses
+// that leaves some group unrecoverable, or -1 if no burst does. Bursts are placed anywhere
+// in a stream of two consecutive sets, so bursts spanning the boundary between sets are
+// covered too.
+func (s *MaskSet) MinBurstForFailure() int {
+	recoverable := recoverableSet(s.Mask)
+	groupLength := s.Mask.N() + s.Mask.K()
+	allDelivered := (1 << groupLength) - 1
+	setLength := s.WireLength()
+
+	for burst := 1; burst <= 2*setLength; burst++ {
+		for start := 0; start+burst <= 2*setLength; start++ {
+			// Lost packets of every group of both sets
+			lost := make([]int, 2*s.Depth)
+			for position := start; position < start+burst; position++ {
+				set, offset := position/setLength, position%setLength
+				lost[set*s.Depth+offset%s.Depth] |= 1 << (offset / s.Depth)
+			}
+			for _, groupLost := range lost {
+				if groupLost != 0 && !recoverable.Contains(uint64(allDelivered&^groupLost)) {
+					return burst
+				}
+			}
+		}
+	}
+	return -1
+}
+
+// RecoveryProbability returns the probability that every group of the set recovers all
+// its media packets, with the loss model applied to the packets in wire order
+func (s *MaskSet) RecoveryProbability(model LossModel) (float64, error) {
+	length := s.WireLength()
+	if length > MaxEnumerablePackets {
+		return 0, fmt.Errorf("mask set of %d wire packets exceeds the limit of %d", length, MaxEnumerablePackets)
+	}
+
+	recoverable := recoverableSet(s.Mask)
+	probability := 0.0
+	for wirePattern := 0; wirePattern < (1 << length); wirePattern++ {
+		allRecovered := true
+		for _, pattern := range s.GroupPatterns(wirePattern) {
+			if !recoverable.Contains(uint64(pattern)) {
+				allRecovered = false
+				break
+			}
+		}
+		if allRecovered {
+			probability += model.CalculateProbability(wirePattern, length)
+		}
+	}
+	return probability, nil
+}
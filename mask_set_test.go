@@ -0,0 +1,55 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaskSetWireLayout(t *testing.T) {
+	mask := NewSimpleMask([][]bool{{true, true}}, 2, 1)
+	set, err := NewMaskSet(mask, 2)
+	require.NoError(t, err)
+
+	assert.Equal(t, 6, set.WireLength())
+	assert.Equal(t, 3, set.WirePosition(1, 1))
+
+	// Wire positions 0..5 alternate between groups: lose positions 2 and 3
+	groups := set.GroupPatterns(0b110011)
+	assert.Equal(t, []int{0b101, 0b101}, groups)
+}
+
+func TestMaskSetBurstTolerance(t *testing.T) {
+	// A single parity packet recovers one loss per group
+	mask := NewSimpleMask([][]bool{{true, true, true}}, 3, 1)
+	assert.Equal(t, LossCount(2), CalculateRecoveryCharacteristics(mask).MinConsecutiveLostForNonRecovery)
+
+	for depth := 1; depth <= 4; depth++ {
+		set, err := NewMaskSet(mask, depth)
+		require.NoError(t, err)
+		// Any burst of up to depth wire losses hits every group at most once
+		assert.Equal(t, depth+1, set.MinBurstForFailure(), "depth %d", depth)
+	}
+
+	_, err := NewMaskSet(mask, 0)
+	assert.Error(t, err)
+}
+
+func TestMaskSetRecoveryProbability(t *testing.T) {
+	mask := NewSimpleMask([][]bool{{true, true}}, 2, 1)
+	model := NewRandomLossModel(0.1)
+
+	// With independent losses, interleaving doesn't matter: groups recover independently
+	single := RecoveryProbability(mask, model)
+	set, err := NewMaskSet(mask, 3)
+	require.NoError(t, err)
+	probability, err := set.RecoveryProbability(model)
+	require.NoError(t, err)
+	assert.InDelta(t, single*single*single, probability, 1e-12)
+
+	tooLong, err := NewMaskSet(mask, 9)
+	require.NoError(t, err)
+	_, err = tooLong.RecoveryProbability(model)
+	assert.Error(t, err)
+}
@@ -0,0 +1,63 @@
+package fecanalysis
+
+import "math/bits"
+
+// MaskStructure holds structural statistics of a mask, independent of any loss model
+type MaskStructure struct {
+	RowWeights         []int   // number of media packets protected by each FEC packet
+	ColumnWeights      []int   // number of FEC packets protecting each media packet
+	RowOverlap         [][]int // RowOverlap[i][j] is the number of media packets protected by both FEC packets i and j
+	UnprotectedPackets []int   // media packets not protected by any FEC packet (coverage gaps)
+	Density            float64 // fraction of set entries in the K x N protection matrix
+}
+
+// AnalyzeMask computes the structural statistics of a mask
+func AnalyzeMask(mask Mask) MaskStructure {
+	N, K := mask.N(), mask.K()
+	rows := patternRows(mask)
+
+	structure := MaskStructure{
+		RowWeights:    make([]int, K),
+		ColumnWeights: make([]int, N),
+		RowOverlap:    make([][]int, K),
+	}
+
+	set := 0
+	for fecIndex, row := range rows {
+		structure.RowWeights[fecIndex] = bits.OnesCount64(row)
+		set += structure.RowWeights[fecIndex]
+
+		structure.RowOverlap[fecIndex] = make([]int, K)
+		for otherIndex, other := range rows {
+			structure.RowOverlap[fecIndex][otherIndex] = bits.OnesCount64(row & other)
+		}
+		for packetIndex := 0; packetIndex < N; packetIndex++ {
+			if row&(1<<packetIndex) != 0 {
+				structure.ColumnWeights[packetIndex]++
+			}
+		}
+	}
+
+	for packetIndex, weight := range structure.ColumnWeights {
+		if weight == 0 {
+			structure.UnprotectedPackets = append(structure.UnprotectedPackets, packetIndex)
+		}
+	}
+	if N > 0 && K > 0 {
+		structure.Density = float64(set) / float64(N*K)
+	}
+	return structure
+}
+
+// MaxRowOverlap returns the largest number of media packets shared by two distinct FEC packets
+func (s MaskStructure) MaxRowOverlap() int {
+	maxOverlap := 0
+	for i, overlaps := range s.RowOverlap {
+		for j, overlap := range overlaps {
+			if i != j && overlap > maxOverlap {
+				maxOverlap = overlap
+			}
+		}
+	}
+	return maxOverlap
+}
@@ -0,0 +1,40 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyzeMask(t *testing.T) {
+	mask := NewSimpleMask([][]bool{
+		{true, true, true, false, false},
+		{false, true, true, false, false},
+		{false, false, false, true, false},
+	}, 5, 3)
+
+	structure := AnalyzeMask(mask)
+
+	assert.Equal(t, []int{3, 2, 1}, structure.RowWeights)
+	assert.Equal(t, []int{1, 2, 2, 1, 0}, structure.ColumnWeights)
+	assert.Equal(t, [][]int{
+		{3, 2, 0},
+		{2, 2, 0},
+		{0, 0, 1},
+	}, structure.RowOverlap)
+	assert.Equal(t, []int{4}, structure.UnprotectedPackets)
+	assert.InDelta(t, 6.0/15.0, structure.Density, 1e-12)
+	assert.Equal(t, 2, structure.MaxRowOverlap())
+}
+
+func TestAnalyzeMaskInterleaved(t *testing.T) {
+	mask, err := (&InterleavedMaskFactory{}).CreateMask(6, 3)
+	assert.NoError(t, err)
+
+	structure := AnalyzeMask(mask)
+	assert.Equal(t, []int{2, 2, 2}, structure.RowWeights)
+	assert.Equal(t, []int{1, 1, 1, 1, 1, 1}, structure.ColumnWeights)
+	assert.Empty(t, structure.UnprotectedPackets)
+	assert.Equal(t, 0, structure.MaxRowOverlap())
+	assert.InDelta(t, 1.0/3.0, structure.Density, 1e-12)
+}
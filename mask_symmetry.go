@@ -0,0 +1,506 @@
+package fecanalysis
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+	"sort"
+)
+
+// maxPatternOrbits is the largest number of orbits PatternOrbits and
+// SymmetricRecoveryProbability enumerate, as many as the patterns of an enumerable block
+const maxPatternOrbits = 1 << MaxEnumerablePackets
+
+// Automorphism is a symmetry of a mask: media packet i maps to Columns[i] and FEC packet j to
+// Rows[j], and FEC packet j protects media packet i exactly when FEC packet Rows[j] protects
+// media packet Columns[i]. Recoverability of delivery patterns is invariant under it.
+type Automorphism struct {
+	Columns []int
+	Rows    []int
+}
+
+// Apply maps a delivery pattern (media bits first, then FEC bits) through the automorphism
+func (a Automorphism) Apply(pattern uint64) uint64 {
+	N := len(a.Columns)
+	image := uint64(0)
+	for i, column := range a.Columns {
+		image |= (pattern >> i & 1) << column
+	}
+	for j, row := range a.Rows {
+		image |= (pattern >> (N + j) & 1) << (N + row)
+	}
+	return image
+}
+
+// PatternOrbit is a class of delivery patterns mapped onto each other by mask automorphisms,
+// all of which are recovered or lost together
+type PatternOrbit struct {
+	Representative uint64 // a pattern of the orbit
+	Size           uint64 // number of patterns in the orbit
+}
+
+// MaskAutomorphisms returns generators of the automorphism group of the mask: a swap of every
+// pair of consecutive identical columns, and for every generator of the induced permutation
+// group of the FEC packets one automorphism realizing it
+func MaskAutomorphisms(mask Mask) []Automorphism {
+	N, K := mask.N(), mask.K()
+	signatures := columnSignatures(mask)
+
+	var generators []Automorphism
+	for _, twins := range twinColumns(signatures) {
+		for i := 1; i < len(twins); i++ {
+			swap := identityAutomorphism(N, K)
+			swap.Columns[twins[i-1]], swap.Columns[twins[i]] = twins[i], twins[i-1]
+			generators = append(generators, swap)
+		}
+	}
+	for _, rows := range rowPermutationGenerators(signatures, K) {
+		generators = append(generators, Automorphism{Columns: liftRowPermutation(signatures, rows), Rows: rows})
+	}
+	return generators
+}
+
+// AutomorphismGroupOrder returns the number of automorphisms of the mask, the identity included
+func AutomorphismGroupOrder(mask Mask) *big.Int {
+	signatures := columnSignatures(mask)
+	order := big.NewInt(1)
+	for _, twins := range twinColumns(signatures) {
+		order.Mul(order, new(big.Int).MulRange(1, int64(len(twins))))
+	}
+
+	// The row group order is the product of the basic orbit sizes of its stabilizer chain
+	generators := rowPermutationGenerators(signatures, mask.K())
+	for level := 0; level < mask.K(); level++ {
+		orbit := rowOrbit(generators, level)
+		order.Mul(order, big.NewInt(int64(len(orbit))))
+	}
+	return order
+}
+
+// PatternOrbits partitions the delivery patterns of the mask into orbits under the
+// symmetries that can be enumerated without visiting every pattern, ordered by
+// representative: swaps of identical columns, and swaps of identical connected components of
+// the mask (blocks of FEC packets and the media packets they protect, in packet order). For
+// masks built from repeated blocks, like Interleaved, these generate every automorphism and
+// the orbits are exact, e.g. 126 instead of 4096 for the 8x4 interleaved mask; for other
+// masks an orbit of MaskAutomorphisms may be split into several. Either way, recoverability
+// and the probability under random loss are the same within an orbit, so per-pattern work
+// such as decoding only has to be done once per orbit.
+func PatternOrbits(mask Mask) ([]PatternOrbit, error) {
+	var orbits []PatternOrbit
+	err := visitPatternOrbits(mask, func(orbit PatternOrbit) {
+		orbits = append(orbits, orbit)
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(orbits, func(i, j int) bool {
+		return orbits[i].Representative < orbits[j].Representative
+	})
+	return orbits, nil
+}
+
+// SymmetricRecoveryProbability returns the recovery probability of the mask under random loss
+// with the given loss probability, decoding one pattern per orbit of PatternOrbits. Random
+// loss assigns the same probability to every pattern of an orbit, which is what makes the
+// reduction exact; correlated loss models are not invariant under the symmetries.
+func SymmetricRecoveryProbability(mask Mask, lossProbability float64, mode DecodingMode) (float64, error) {
+	N, K := mask.N(), mask.K()
+	rows := patternRows(mask)
+	allMedia := uint64(1)<<N - 1
+	model := NewRandomLossModel(lossProbability)
+	probability := 0.0
+	err := visitPatternOrbits(mask, func(orbit PatternOrbit) {
+		decodable := false
+		if mode == MLDecoding {
+			decodable = solvableByElimination(rows, N, orbit.Representative)
+		} else {
+			decodable = peelingClosure(rows, N, orbit.Representative)&allMedia == allMedia
+		}
+		if decodable {
+			probability += float64(orbit.Size) * model.CalculateProbability(int(orbit.Representative), N+K)
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+	return probability, nil
+}
+
+// orbitBlock is a connected component of the mask: FEC packets and the media packets they
+// protect, none of which shares a FEC packet with another block
+type orbitBlock struct {
+	columns []int // media packets, ascending
+	rows    []int // FEC packets, ascending
+}
+
+// blockState is a class of delivery patterns of a block under swaps of its identical
+// columns, with packets numbered by their position in the block
+type blockState struct {
+	media, fec uint64 // delivered columns and rows of the block
+	size       uint64 // number of patterns in the class
+}
+
+// blockGroup is a set of identical blocks and the states each of them can be in
+type blockGroup struct {
+	blocks []orbitBlock
+	states []blockState
+}
+
+// visitPatternOrbits calls visit for every orbit of PatternOrbits, in no particular order.
+// An orbit assigns a state to every block, and identical blocks are interchangeable, so the
+// orbits are the products over groups of identical blocks of the multisets of their states.
+func visitPatternOrbits(mask Mask, visit func(orbit PatternOrbit)) error {
+	N, K := mask.N(), mask.K()
+	if err := checkGraphPackets(N, K); err != nil {
+		return err
+	}
+	groups := identicalBlocks(mask)
+
+	// Count the orbits before enumerating anything
+	orbitCount := big.NewInt(1)
+	for _, group := range groups {
+		states := blockStateCount(mask, group.blocks[0])
+		if states > maxPatternOrbits {
+			return fmt.Errorf("mask block has %d pattern orbits, more than the limit of %d", states, maxPatternOrbits)
+		}
+		multisets := new(big.Int).Binomial(states+int64(len(group.blocks))-1, int64(len(group.blocks)))
+		orbitCount.Mul(orbitCount, multisets)
+	}
+	if orbitCount.Cmp(big.NewInt(maxPatternOrbits)) > 0 {
+		return fmt.Errorf("mask has %v pattern orbits, more than the limit of %d", orbitCount, maxPatternOrbits)
+	}
+	for index := range groups {
+		groups[index].states = blockStates(mask, groups[index].blocks[0])
+	}
+
+	// Assign non-decreasing states to the blocks of every group; a run of equal states
+	// divides the arrangements by its length factorial, applied one block at a time
+	var extend func(group, block, first, run int, pattern, size uint64)
+	extend = func(group, block, first, run int, pattern, size uint64) {
+		if group == len(groups) {
+			visit(PatternOrbit{Representative: pattern, Size: size})
+			return
+		}
+		blocks := groups[group].blocks
+		if block == len(blocks) {
+			extend(group+1, 0, 0, 0, pattern, size)
+			return
+		}
+		for index := first; index < len(groups[group].states); index++ {
+			state := groups[group].states[index]
+			length := 1
+			if block > 0 && index == first {
+				length = run + 1
+			}
+			hi, lo := bits.Mul64(size, uint64(block+1))
+			arrangements, _ := bits.Div64(hi, lo, uint64(length))
+			extend(group, block+1, index, length, pattern|placeBlockState(blocks[block], state, N), arrangements*state.size)
+		}
+	}
+	extend(0, 0, 0, 0, 0, 1)
+	return nil
+}
+
+// identicalBlocks splits the mask into its connected components and groups those with the
+// same protection matrix in packet order, in the order of their first packet
+func identicalBlocks(mask Mask) []blockGroup {
+	N := mask.N()
+	rows := patternRows(mask)
+
+	// Union-find over the media packets, joined by every FEC packet
+	parent := make([]int, N)
+	for packetIndex := range parent {
+		parent[packetIndex] = packetIndex
+	}
+	find := func(packetIndex int) int {
+		for parent[packetIndex] != packetIndex {
+			parent[packetIndex] = parent[parent[packetIndex]]
+			packetIndex = parent[packetIndex]
+		}
+		return packetIndex
+	}
+	for _, row := range rows {
+		if row == 0 {
+			continue
+		}
+		first := find(bits.TrailingZeros64(row))
+		for protected := row; protected != 0; protected &= protected - 1 {
+			parent[find(bits.TrailingZeros64(protected))] = first
+		}
+	}
+
+	// Blocks in the order of their first media packet, then FEC packets protecting nothing
+	blockOf := make(map[int]int)
+	var blocks []orbitBlock
+	for packetIndex := 0; packetIndex < N; packetIndex++ {
+		root := find(packetIndex)
+		index, ok := blockOf[root]
+		if !ok {
+			index = len(blocks)
+			blockOf[root] = index
+			blocks = append(blocks, orbitBlock{})
+		}
+		blocks[index].columns = append(blocks[index].columns, packetIndex)
+	}
+	for fecIndex, row := range rows {
+		if row == 0 {
+			blocks = append(blocks, orbitBlock{rows: []int{fecIndex}})
+			continue
+		}
+		index := blockOf[find(bits.TrailingZeros64(row))]
+		blocks[index].rows = append(blocks[index].rows, fecIndex)
+	}
+
+	groupOf := make(map[string]int)
+	var groups []blockGroup
+	for _, block := range blocks {
+		key := fmt.Sprintf("%dx%d:%v", len(block.columns), len(block.rows), blockSignatures(mask, block))
+		index, ok := groupOf[key]
+		if !ok {
+			index = len(groups)
+			groupOf[key] = index
+			groups = append(groups, blockGroup{})
+		}
+		groups[index].blocks = append(groups[index].blocks, block)
+	}
+	return groups
+}
+
+// blockSignatures returns, for each media packet of the block, the bitmask of the block's FEC
+// packets protecting it, numbered by their position in the block
+func blockSignatures(mask Mask, block orbitBlock) []uint64 {
+	signatures := make([]uint64, len(block.columns))
+	for position, packetIndex := range block.columns {
+		for row, fecIndex := range block.rows {
+			if mask.IsProtected(packetIndex, fecIndex) {
+				signatures[position] |= uint64(1) << row
+			}
+		}
+	}
+	return signatures
+}
+
+// blockStateCount returns the number of states of the block: every number of delivered
+// packets among each set of identical columns, with every subset of its FEC packets
+func blockStateCount(mask Mask, block orbitBlock) int64 {
+	count := new(big.Int).Lsh(big.NewInt(1), uint(len(block.rows)))
+	for _, twins := range twinColumns(blockSignatures(mask, block)) {
+		count.Mul(count, big.NewInt(int64(len(twins)+1)))
+	}
+	if !count.IsInt64() {
+		return math.MaxInt64
+	}
+	return count.Int64()
+}
+
+// blockStates enumerates the states of the block, delivering the first columns of every set
+// of identical columns
+func blockStates(mask Mask, block orbitBlock) []blockState {
+	states := []blockState{{size: 1}}
+	for _, twins := range twinColumns(blockSignatures(mask, block)) {
+		next := make([]blockState, 0, len(states)*(len(twins)+1))
+		for _, state := range states {
+			media := state.media
+			for delivered := 0; delivered <= len(twins); delivered++ {
+				if delivered > 0 {
+					media |= uint64(1) << twins[delivered-1]
+				}
+				ways := new(big.Int).Binomial(int64(len(twins)), int64(delivered)).Uint64()
+				next = append(next, blockState{media: media, size: state.size * ways})
+			}
+		}
+		states = next
+	}
+
+	withRows := make([]blockState, 0, len(states)<<len(block.rows))
+	for _, state := range states {
+		for fec := uint64(0); fec < uint64(1)<<len(block.rows); fec++ {
+			withRows = append(withRows, blockState{media: state.media, fec: fec, size: state.size})
+		}
+	}
+	return withRows
+}
+
+// placeBlockState returns the delivery pattern of the block's packets in the state
+func placeBlockState(block orbitBlock, state blockState, N int) uint64 {
+	pattern := uint64(0)
+	for position, packetIndex := range block.columns {
+		pattern |= (state.media >> position & 1) << packetIndex
+	}
+	for position, fecIndex := range block.rows {
+		pattern |= (state.fec >> position & 1) << (N + fecIndex)
+	}
+	return pattern
+}
+
+// columnSignatures returns, for each media packet, the bitmask of FEC packets protecting it
+func columnSignatures(mask Mask) []uint64 {
+	signatures := make([]uint64, mask.N())
+	for fecIndex, row := range patternRows(mask) {
+		for packetIndex := range signatures {
+			signatures[packetIndex] |= (row >> packetIndex & 1) << fecIndex
+		}
+	}
+	return signatures
+}
+
+// twinColumns groups the media packets with identical signatures, in packet order
+func twinColumns(signatures []uint64) [][]int {
+	groups := make(map[uint64]int)
+	var twins [][]int
+	for packetIndex, signature := range signatures {
+		group, ok := groups[signature]
+		if !ok {
+			group = len(twins)
+			groups[signature] = group
+			twins = append(twins, nil)
+		}
+		twins[group] = append(twins[group], packetIndex)
+	}
+	return twins
+}
+
+// identityAutomorphism returns the automorphism fixing every packet
+func identityAutomorphism(N, K int) Automorphism {
+	identity := Automorphism{Columns: make([]int, N), Rows: make([]int, K)}
+	for i := range identity.Columns {
+		identity.Columns[i] = i
+	}
+	for j := range identity.Rows {
+		identity.Rows[j] = j
+	}
+	return identity
+}
+
+// rowPermutationGenerators returns a strong generating set of the permutations of the FEC
+// packets that some column permutation completes to an automorphism. Levels are processed
+// from the deepest stabilizer up: at level i the generators found so far fix rows 0..i, and
+// for every row outside the orbit of row i a permutation fixing rows 0..i-1 and mapping row
+// i onto it is searched, so the generators reach the whole orbit of every level.
+func rowPermutationGenerators(signatures []uint64, K int) [][]int {
+	var generators [][]int
+	for level := K - 1; level >= 0; level-- {
+		for target := level + 1; target < K; target++ {
+			if rowOrbit(generators, level)[target] {
+				continue
+			}
+			images := make([]int, K)
+			for row := 0; row < level; row++ {
+				images[row] = row
+			}
+			images[level] = target
+			if completeRowPermutation(signatures, images, level+1) {
+				generators = append(generators, images)
+			}
+		}
+	}
+	return generators
+}
+
+// rowOrbit returns the orbit of the row under the generators that fix every earlier row
+func rowOrbit(generators [][]int, row int) map[int]bool {
+	var stabilizer [][]int
+	for _, generator := range generators {
+		fixes := true
+		for earlier := 0; earlier < row; earlier++ {
+			fixes = fixes && generator[earlier] == earlier
+		}
+		if fixes {
+			stabilizer = append(stabilizer, generator)
+		}
+	}
+
+	orbit := map[int]bool{row: true}
+	queue := []int{row}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, generator := range stabilizer {
+			if image := generator[current]; !orbit[image] {
+				orbit[image] = true
+				queue = append(queue, image)
+			}
+		}
+	}
+	return orbit
+}
+
+// completeRowPermutation extends the images of rows 0..assigned-1 to a full row permutation
+// realizable by a column permutation, backtracking over the images of the remaining rows
+func completeRowPermutation(signatures []uint64, images []int, assigned int) bool {
+	if !consistentRowImages(signatures, images, assigned) {
+		return false
+	}
+	if assigned == len(images) {
+		return true
+	}
+
+	used := uint64(0)
+	for row := 0; row < assigned; row++ {
+		used |= uint64(1) << images[row]
+	}
+	for image := range images {
+		if used&(uint64(1)<<image) != 0 {
+			continue
+		}
+		images[assigned] = image
+		if completeRowPermutation(signatures, images, assigned+1) {
+			return true
+		}
+	}
+	return false
+}
+
+// consistentRowImages returns true if the columns restricted to rows 0..assigned-1, with
+// their signatures mapped through the images, are a permutation of the columns restricted to
+// the image rows. This is necessary for the partial permutation to extend, and sufficient
+// once every row is assigned.
+func consistentRowImages(signatures []uint64, images []int, assigned int) bool {
+	domain, codomain := uint64(0), uint64(0)
+	for row := 0; row < assigned; row++ {
+		domain |= uint64(1) << row
+		codomain |= uint64(1) << images[row]
+	}
+
+	balance := make(map[uint64]int)
+	for _, signature := range signatures {
+		balance[mapSignature(signature&domain, images)]++
+		balance[signature&codomain]--
+	}
+	for _, count := range balance {
+		if count != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// mapSignature moves every FEC bit of the signature to its image row
+func mapSignature(signature uint64, images []int) uint64 {
+	mapped := uint64(0)
+	for signature != 0 {
+		row := bits.TrailingZeros64(signature)
+		mapped |= uint64(1) << images[row]
+		signature &= signature - 1
+	}
+	return mapped
+}
+
+// liftRowPermutation returns a column permutation completing a realizable row permutation
+// to an automorphism, pairing the columns of every mapped signature in packet order
+func liftRowPermutation(signatures []uint64, images []int) []int {
+	targets := make(map[uint64][]int)
+	for packetIndex, signature := range signatures {
+		targets[signature] = append(targets[signature], packetIndex)
+	}
+
+	columns := make([]int, len(signatures))
+	for packetIndex, signature := range signatures {
+		mapped := mapSignature(signature, images)
+		columns[packetIndex] = targets[mapped][0]
+		targets[mapped] = targets[mapped][1:]
+	}
+	return columns
+}
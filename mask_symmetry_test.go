@@ -0,0 +1,117 @@
+package fecanalysis
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaskAutomorphisms(t *testing.T) {
+	masks := map[string]Mask{}
+	for name, factory := range map[string]MaskFactory{
+		"interleaved":   &InterleavedMaskFactory{},
+		"diagonal":      &DiagonalInterleavedMaskFactory{Offset: 1},
+		"single parity": &SingleParityMaskFactory{},
+		"random":        &GoogleRandomMaskFactory{},
+		"layered":       &LayeredMaskFactory{},
+	} {
+		mask, err := factory.CreateMask(8, 4)
+		require.NoError(t, err, name)
+		masks[name] = mask
+	}
+
+	for name, mask := range masks {
+		t.Run(name, func(t *testing.T) {
+			recoverable := RecoverableSet(mask, MLDecoding)
+			for _, automorphism := range MaskAutomorphisms(mask) {
+				for i := 0; i < mask.N(); i++ {
+					for j := 0; j < mask.K(); j++ {
+						require.Equal(t, mask.IsProtected(i, j), mask.IsProtected(automorphism.Columns[i], automorphism.Rows[j]))
+					}
+				}
+				for pattern := uint64(0); pattern < recoverable.NumVertices(); pattern++ {
+					require.Equal(t, recoverable.Contains(pattern), recoverable.Contains(automorphism.Apply(pattern)))
+				}
+			}
+		})
+	}
+}
+
+func TestAutomorphismGroupOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		factory  MaskFactory
+		N, K     int
+		expected int64
+	}{
+		// 4 pairs of twin columns, each pair guarded by its own FEC packet: 2^4 * 4!
+		{"interleaved", &InterleavedMaskFactory{}, 8, 4, 384},
+		// All columns are twins and the single row is fixed
+		{"single parity", &SingleParityMaskFactory{}, 5, 1, 120},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mask, err := tt.factory.CreateMask(tt.N, tt.K)
+			require.NoError(t, err)
+			assert.Equal(t, big.NewInt(tt.expected), AutomorphismGroupOrder(mask))
+		})
+	}
+}
+
+func TestPatternOrbits(t *testing.T) {
+	mask, err := (&InterleavedMaskFactory{}).CreateMask(8, 4)
+	require.NoError(t, err)
+	orbits, err := PatternOrbits(mask)
+	require.NoError(t, err)
+
+	// Every block of 2 media packets and 1 FEC packet is in one of 6 states, and the orbits
+	// are the multisets of 4 block states: C(9, 4)
+	assert.Len(t, orbits, 126)
+	total := uint64(0)
+	for i, orbit := range orbits {
+		total += orbit.Size
+		if i > 0 {
+			assert.Greater(t, orbit.Representative, orbits[i-1].Representative)
+		}
+	}
+	assert.Equal(t, uint64(1)<<12, total)
+
+	// Orbits are enumerated directly, so blocks far beyond a pattern enumeration work
+	large, err := (&InterleavedMaskFactory{}).CreateMask(32, 8)
+	require.NoError(t, err)
+	orbits, err = PatternOrbits(large)
+	require.NoError(t, err)
+	assert.Len(t, orbits, 24310) // 8 blocks of 4 twins and 1 FEC packet in 10 states: C(17, 8)
+	total = 0
+	for _, orbit := range orbits {
+		total += orbit.Size
+	}
+	assert.Equal(t, uint64(1)<<40, total)
+
+	// A single block without twins has one orbit per pattern
+	unstructured, err := (&RandomMaskFactory{Seed: 1}).CreateMask(24, 8)
+	require.NoError(t, err)
+	_, err = PatternOrbits(unstructured)
+	assert.Error(t, err)
+}
+
+func TestSymmetricRecoveryProbability(t *testing.T) {
+	for _, factory := range []MaskFactory{&InterleavedMaskFactory{}, &GoogleBurstyMaskFactory{}, &LayeredMaskFactory{}, &SingleParityMaskFactory{}} {
+		mask, err := factory.CreateMask(9, 3)
+		require.NoError(t, err)
+
+		probability, err := SymmetricRecoveryProbability(mask, 0.1, PeelingDecoding)
+		require.NoError(t, err)
+		assert.InDelta(t, RecoveryProbability(mask, NewRandomLossModel(0.1)), probability, 1e-12)
+
+		expected := 0.0
+		RecoverableSet(mask, MLDecoding).Iterate(func(pattern uint64) {
+			expected += NewRandomLossModel(0.1).CalculateProbability(int(pattern), 12)
+		})
+		probability, err = SymmetricRecoveryProbability(mask, 0.1, MLDecoding)
+		require.NoError(t, err)
+		assert.InDelta(t, expected, probability, 1e-12)
+	}
+}
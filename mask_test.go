@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMaskWithSpecificPattern(t *testing.T) {
@@ -74,3 +75,70 @@ func TestMaskMultipleFECPackets(t *testing.T) {
 		assert.True(t, mask.IsProtected(i, 1), "Packet %d should be protected by second FEC", i)
 	}
 }
+
+func TestInterleavedMaskMoreFECThanMedia(t *testing.T) {
+	// 200% overhead: every media packet is repeated by two FEC packets
+	mask, err := (&InterleavedMaskFactory{}).CreateMask(3, 6)
+	require.NoError(t, err)
+
+	for fecIndex := 0; fecIndex < 6; fecIndex++ {
+		for packetIndex := 0; packetIndex < 3; packetIndex++ {
+			assert.Equal(t, fecIndex%3 == packetIndex, mask.IsProtected(packetIndex, fecIndex))
+		}
+	}
+
+	// Any two losses are recoverable since every media packet has two copies
+	characteristics := CalculateRecoveryCharacteristics(mask)
+	assert.Equal(t, LossCount(3), characteristics.MinLostPacketsForNonRecovery)
+}
+
+func TestInterleavedMaskDepth(t *testing.T) {
+	// Runs of two consecutive packets share a FEC packet
+	mask, err := (&InterleavedMaskFactory{Depth: 2}).CreateMask(6, 2)
+	require.NoError(t, err)
+	assert.Equal(t, [][]bool{
+		{true, true, false, false, true, true},
+		{false, false, true, true, false, false},
+	}, maskMatrix(mask))
+
+	// Depth one is strict modulo interleaving
+	strict, err := (&InterleavedMaskFactory{Depth: 1}).CreateMask(6, 2)
+	require.NoError(t, err)
+	modulo, err := (&InterleavedMaskFactory{}).CreateMask(6, 2)
+	require.NoError(t, err)
+	assert.Equal(t, maskMatrix(modulo), maskMatrix(strict))
+
+	// Deeper interleaving trades burst tolerance for shorter encoding windows
+	deep, err := (&InterleavedMaskFactory{Depth: 2}).CreateMask(4, 2)
+	require.NoError(t, err)
+	modulo, err = (&InterleavedMaskFactory{}).CreateMask(4, 2)
+	require.NoError(t, err)
+	assert.Equal(t, LossCount(3), CalculateRecoveryCharacteristics(modulo).MinConsecutiveLostForNonRecovery)
+	assert.Equal(t, LossCount(2), CalculateRecoveryCharacteristics(deep).MinConsecutiveLostForNonRecovery)
+	assert.Equal(t, 3, DescribeFECFrame(modulo).MaxEncodingWindow)
+	assert.Equal(t, 2, DescribeFECFrame(deep).MaxEncodingWindow)
+
+	_, err = (&InterleavedMaskFactory{Depth: -1}).CreateMask(6, 2)
+	assert.Error(t, err)
+}
+
+func TestDiagonalInterleavedMask(t *testing.T) {
+	// Rows of 3 media packets, each shifted by one FEC packet
+	mask, err := (&DiagonalInterleavedMaskFactory{Offset: 1}).CreateMask(7, 3)
+	require.NoError(t, err)
+	assert.Equal(t, [][]bool{
+		{true, false, false, false, false, true, false},
+		{false, true, false, true, false, false, false},
+		{false, false, true, false, true, false, true},
+	}, maskMatrix(mask))
+
+	// Offset 0 is plain modulo interleaving
+	diagonal, err := (&DiagonalInterleavedMaskFactory{}).CreateMask(7, 3)
+	require.NoError(t, err)
+	interleaved, err := (&InterleavedMaskFactory{}).CreateMask(7, 3)
+	require.NoError(t, err)
+	assert.Equal(t, maskMatrix(interleaved), maskMatrix(diagonal))
+
+	_, err = (&DiagonalInterleavedMaskFactory{Offset: -1}).CreateMask(7, 3)
+	assert.Error(t, err)
+}
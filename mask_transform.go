@@ -0,0 +1,183 @@
+package fecanalysis
+
+import "fmt"
+
+// Transpose returns the mask with the roles of media and FEC packets swapped: media
+// packet i of the result is protected by FEC packet j if media packet j of the original
+// is protected by FEC packet i
+func Transpose(mask Mask) Mask {
+	return &transposedMask{mask: mask}
+}
+
+// PermuteColumns returns the mask with its media packets reordered: media packet i of the
+// result is media packet order[i] of the original. order must be a permutation of 0..N-1.
+func PermuteColumns(mask Mask, order []int) (Mask, error) {
+	if len(order) != mask.N() {
+		return nil, fmt.Errorf("permutation has %d entries, expected %d", len(order), mask.N())
+	}
+	seen := make([]bool, len(order))
+	for _, packetIndex := range order {
+		if packetIndex < 0 || packetIndex >= len(order) || seen[packetIndex] {
+			return nil, fmt.Errorf("invalid column permutation %v", order)
+		}
+		seen[packetIndex] = true
+	}
+
+	return &permutedMask{mask: mask, order: append([]int(nil), order...)}, nil
+}
+
+// Concat stacks the FEC packets of b below those of a; both masks must protect the same
+// number of media packets
+func Concat(a, b Mask) (Mask, error) {
+	if a.N() != b.N() {
+		return nil, fmt.Errorf("cannot concatenate masks with N=%d and N=%d", a.N(), b.N())
+	}
+	return &concatMask{first: a, second: b}, nil
+}
+
+// Subset returns the mask restricted to the given FEC packets, in the given order;
+// the FEC packets not listed are dropped
+func Subset(mask Mask, rows []int) (Mask, error) {
+	for _, fecIndex := range rows {
+		if fecIndex < 0 || fecIndex >= mask.K() {
+			return nil, fmt.Errorf("FEC index %d out of range for K=%d", fecIndex, mask.K())
+		}
+	}
+	return &subsetMask{mask: mask, rows: append([]int(nil), rows...)}, nil
+}
+
+// Union returns the mask whose FEC packet i protects every media packet protected by FEC
+// packet i of a or of b; both masks must have the same N and K. To keep the FEC packets of
+// both masks separate (base plus enhancement FEC), use Concat instead.
+func Union(a, b Mask) (Mask, error) {
+	if a.N() != b.N() || a.K() != b.K() {
+		return nil, fmt.Errorf("cannot combine masks with N=%d, K=%d and N=%d, K=%d", a.N(), a.K(), b.N(), b.K())
+	}
+	return &combinedMask{first: a, second: b, intersect: false}, nil
+}
+
+// Intersection returns the mask whose FEC packet i protects the media packets protected by
+// FEC packet i of both a and b; both masks must have the same N and K
+func Intersection(a, b Mask) (Mask, error) {
+	if a.N() != b.N() || a.K() != b.K() {
+		return nil, fmt.Errorf("cannot combine masks with N=%d, K=%d and N=%d, K=%d", a.N(), a.K(), b.N(), b.K())
+	}
+	return &combinedMask{first: a, second: b, intersect: true}, nil
+}
+
+// transposedMask swaps media and FEC packets of the wrapped mask
+type transposedMask struct {
+	mask Mask
+}
+
+// IsProtected returns true if the packet at packetIndex is protected by FEC at fecIndex
+func (m *transposedMask) IsProtected(packetIndex, fecIndex int) bool {
+	return m.mask.IsProtected(fecIndex, packetIndex)
+}
+
+// N returns the number of media packets
+func (m *transposedMask) N() int {
+	return m.mask.K()
+}
+
+// K returns the number of FEC packets
+func (m *transposedMask) K() int {
+	return m.mask.N()
+}
+
+// permutedMask reorders the media packets of the wrapped mask
+type permutedMask struct {
+	mask  Mask
+	order []int // media packet i is media packet order[i] of the wrapped mask
+}
+
+// IsProtected returns true if the packet at packetIndex is protected by FEC at fecIndex
+func (m *permutedMask) IsProtected(packetIndex, fecIndex int) bool {
+	if packetIndex < 0 || packetIndex >= len(m.order) {
+		return false
+	}
+	return m.mask.IsProtected(m.order[packetIndex], fecIndex)
+}
+
+// N returns the number of media packets
+func (m *permutedMask) N() int {
+	return m.mask.N()
+}
+
+// K returns the number of FEC packets
+func (m *permutedMask) K() int {
+	return m.mask.K()
+}
+
+// concatMask stacks the FEC packets of two masks over the same media packets
+type concatMask struct {
+	first  Mask
+	second Mask
+}
+
+// IsProtected returns true if the packet at packetIndex is protected by FEC at fecIndex
+func (m *concatMask) IsProtected(packetIndex, fecIndex int) bool {
+	if fecIndex < m.first.K() {
+		return m.first.IsProtected(packetIndex, fecIndex)
+	}
+	return m.second.IsProtected(packetIndex, fecIndex-m.first.K())
+}
+
+// N returns the number of media packets
+func (m *concatMask) N() int {
+	return m.first.N()
+}
+
+// K returns the number of FEC packets
+func (m *concatMask) K() int {
+	return m.first.K() + m.second.K()
+}
+
+// subsetMask keeps a selection of the FEC packets of the wrapped mask
+type subsetMask struct {
+	mask Mask
+	rows []int // FEC packet i is FEC packet rows[i] of the wrapped mask
+}
+
+// IsProtected returns true if the packet at packetIndex is protected by FEC at fecIndex
+func (m *subsetMask) IsProtected(packetIndex, fecIndex int) bool {
+	if fecIndex < 0 || fecIndex >= len(m.rows) {
+		return false
+	}
+	return m.mask.IsProtected(packetIndex, m.rows[fecIndex])
+}
+
+// N returns the number of media packets
+func (m *subsetMask) N() int {
+	return m.mask.N()
+}
+
+// K returns the number of FEC packets
+func (m *subsetMask) K() int {
+	return len(m.rows)
+}
+
+// combinedMask merges the protection sets of matching FEC packets of two masks
+type combinedMask struct {
+	first     Mask
+	second    Mask
+	intersect bool // protected by both masks instead of either
+}
+
+// IsProtected returns true if the packet at packetIndex is protected by FEC at fecIndex
+func (m *combinedMask) IsProtected(packetIndex, fecIndex int) bool {
+	if m.intersect {
+		return m.first.IsProtected(packetIndex, fecIndex) && m.second.IsProtected(packetIndex, fecIndex)
+	}
+	return m.first.IsProtected(packetIndex, fecIndex) || m.second.IsProtected(packetIndex, fecIndex)
+}
+
+// N returns the number of media packets
+func (m *combinedMask) N() int {
+	return m.first.N()
+}
+
+// K returns the number of FEC packets
+func (m *combinedMask) K() int {
+	return m.first.K()
+}
@@ -0,0 +1,100 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaskTransformations(t *testing.T) {
+	mask := NewSimpleMask([][]bool{
+		{true, true, false},
+		{false, true, true},
+	}, 3, 2)
+
+	t.Run("transpose", func(t *testing.T) {
+		transposed := Transpose(mask)
+		assert.Equal(t, 2, transposed.N())
+		assert.Equal(t, 3, transposed.K())
+		assert.Equal(t, [][]bool{
+			{true, false},
+			{true, true},
+			{false, true},
+		}, maskMatrix(transposed))
+		assert.Equal(t, maskMatrix(mask), maskMatrix(Transpose(transposed)))
+	})
+
+	t.Run("permute columns", func(t *testing.T) {
+		permuted, err := PermuteColumns(mask, []int{2, 0, 1})
+		require.NoError(t, err)
+		assert.Equal(t, [][]bool{
+			{false, true, true},
+			{true, false, true},
+		}, maskMatrix(permuted))
+		assert.True(t, EquivalentMasks(mask, permuted, true))
+
+		_, err = PermuteColumns(mask, []int{0, 0, 1})
+		assert.Error(t, err)
+		_, err = PermuteColumns(mask, []int{0, 1})
+		assert.Error(t, err)
+	})
+
+	t.Run("concat", func(t *testing.T) {
+		parity := NewSimpleMask([][]bool{{true, true, true}}, 3, 1)
+		stacked, err := Concat(mask, parity)
+		require.NoError(t, err)
+		assert.Equal(t, 3, stacked.K())
+		assert.Equal(t, append(maskMatrix(mask), []bool{true, true, true}), maskMatrix(stacked))
+
+		_, err = Concat(mask, NewSimpleMask([][]bool{{true}}, 1, 1))
+		assert.Error(t, err)
+	})
+
+	t.Run("subset", func(t *testing.T) {
+		subset, err := Subset(mask, []int{1})
+		require.NoError(t, err)
+		assert.Equal(t, [][]bool{{false, true, true}}, maskMatrix(subset))
+
+		_, err = Subset(mask, []int{2})
+		assert.Error(t, err)
+	})
+
+	t.Run("union and intersection", func(t *testing.T) {
+		other := NewSimpleMask([][]bool{
+			{true, false, true},
+			{false, true, false},
+		}, 3, 2)
+
+		union, err := Union(mask, other)
+		require.NoError(t, err)
+		assert.Equal(t, [][]bool{
+			{true, true, true},
+			{false, true, true},
+		}, maskMatrix(union))
+
+		intersection, err := Intersection(mask, other)
+		require.NoError(t, err)
+		assert.Equal(t, [][]bool{
+			{true, false, false},
+			{false, true, false},
+		}, maskMatrix(intersection))
+
+		_, err = Union(mask, NewSimpleMask([][]bool{{true, true, true}}, 3, 1))
+		assert.Error(t, err)
+		_, err = Intersection(mask, NewSimpleMask([][]bool{{true}, {true}}, 1, 2))
+		assert.Error(t, err)
+	})
+}
+
+func TestSubsetAblation(t *testing.T) {
+	// Dropping FEC packets can only shrink the recoverable set
+	mask, err := (&GoogleRandomMaskFactory{}).CreateMask(6, 3)
+	require.NoError(t, err)
+	subset, err := Subset(mask, []int{0, 2})
+	require.NoError(t, err)
+
+	full := CalculateRecoveryCharacteristics(mask)
+	reduced := CalculateRecoveryCharacteristics(subset)
+	assert.LessOrEqual(t, reduced.MinLostPacketsForNonRecovery, full.MinLostPacketsForNonRecovery)
+}
@@ -0,0 +1,78 @@
+package fecanalysis
+
+import "fmt"
+
+// MatrixMask is a mask defined by an explicit K x N protection matrix, where
+// rows[fecIndex][packetIndex] is true if the media packet is protected by the FEC packet
+type MatrixMask struct {
+	rows [][]bool
+	n    int // number of media packets
+}
+
+// NewMatrixMask creates a mask from a protection matrix with one row per FEC packet.
+// All rows must have the same, non-zero length.
+func NewMatrixMask(rows [][]bool) (*MatrixMask, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("mask matrix has no rows")
+	}
+	n := len(rows[0])
+	if n == 0 {
+		return nil, fmt.Errorf("mask matrix has no columns")
+	}
+
+	copied := make([][]bool, len(rows))
+	for fecIndex, row := range rows {
+		if len(row) != n {
+			return nil, fmt.Errorf("row %d has %d columns, expected %d", fecIndex, len(row), n)
+		}
+		copied[fecIndex] = append([]bool(nil), row...)
+	}
+
+	return &MatrixMask{rows: copied, n: n}, nil
+}
+
+// IsProtected returns true if the packet at packetIndex is protected by FEC at fecIndex
+func (m *MatrixMask) IsProtected(packetIndex, fecIndex int) bool {
+	if packetIndex < 0 || packetIndex >= m.n || fecIndex < 0 || fecIndex >= len(m.rows) {
+		return false
+	}
+	return m.rows[fecIndex][packetIndex]
+}
+
+// N returns the number of media packets
+func (m *MatrixMask) N() int {
+	return m.n
+}
+
+// K returns the number of FEC packets
+func (m *MatrixMask) K() int {
+	return len(m.rows)
+}
+
+// maskMatrix returns the K x N protection matrix of any mask
+func maskMatrix(mask Mask) [][]bool {
+	rows := make([][]bool, mask.K())
+	for fecIndex := range rows {
+		rows[fecIndex] = make([]bool, mask.N())
+		for packetIndex := range rows[fecIndex] {
+			rows[fecIndex][packetIndex] = mask.IsProtected(packetIndex, fecIndex)
+		}
+	}
+	return rows
+}
+
+// matrixKey returns a compact string identifying a protection matrix, for use as a map key
+func matrixKey(rows [][]bool) string {
+	key := make([]byte, 0, len(rows)*(len(rows[0])+1))
+	for _, row := range rows {
+		for _, protected := range row {
+			if protected {
+				key = append(key, '1')
+			} else {
+				key = append(key, '0')
+			}
+		}
+		key = append(key, '|')
+	}
+	return string(key)
+}
@@ -0,0 +1,84 @@
+package fecanalysis
+
+import "math/bits"
+
+// MDSGraph implements the Graph interface for an ideal MDS (Reed-Solomon-like) code,
+// where any N received symbols out of N+K are enough to recover all media packets.
+// It shares the vertex encoding of RecoveryGraph, so BFS from GoodVertices yields the
+// recoverable patterns and XOR masks can be benchmarked against the optimum at the
+// same overhead.
+type MDSGraph struct {
+	numVertices uint64 // 2^(N+K) vertices
+	N           int    // number of media packets
+	K           int    // number of FEC packets
+}
+
+// NewMDSGraph creates a new ideal MDS recovery graph with N media and K FEC packets
+func NewMDSGraph(N, K int) *MDSGraph {
+	return &MDSGraph{
+		numVertices: uint64(1) << (N + K),
+		N:           N,
+		K:           K,
+	}
+}
+
+// NumVertices returns the total number of vertices in the graph (2^(N+K))
+func (g *MDSGraph) NumVertices() uint64 {
+	return g.numVertices
+}
+
+// GetEdges returns a list of edges from the given vertex. As long as more than N symbols
+// are present, any present media packet can be removed and still be recovered.
+func (g *MDSGraph) GetEdges(vertex uint64) []uint64 {
+	var edges []uint64
+	g.VisitEdges(vertex, func(destVertex uint64) bool {
+		edges = append(edges, destVertex)
+		return true
+	})
+	return edges
+}
+
+// VisitEdges calls fn for every edge from the given vertex without allocating; it stops
+// as soon as fn returns false
+func (g *MDSGraph) VisitEdges(vertex uint64, fn func(dst uint64) bool) {
+	if vertex >= g.numVertices || bits.OnesCount64(vertex) <= g.N {
+		return
+	}
+
+	for packetIndex := 0; packetIndex < g.N; packetIndex++ {
+		bit := uint64(1) << packetIndex
+		if vertex&bit != 0 && !fn(vertex&^bit) {
+			return
+		}
+	}
+}
+
+// IsMDSRecoverable returns true if an ideal MDS code with N media packets recovers
+// all of them from the delivery pattern, i.e. at least N symbols were delivered
+func IsMDSRecoverable(vertex uint64, N int) bool {
+	return bits.OnesCount64(vertex) >= N
+}
+
+// MDSRecoveryProbability returns the probability that an ideal MDS code with N media and
+// K FEC packets recovers all media packets, i.e. that at most K of N+K packets are lost
+func MDSRecoveryProbability(model LossModel, N, K int) float64 {
+	return CumulativeLossProbability(model, N+K, K)
+}
+
+// MDSResidualLossRate returns the expected fraction of media packets an ideal MDS code with N
+// media and K FEC packets leaves missing: all are recovered when at most K of the N+K packets
+// are lost, and only the delivered ones otherwise. Codes that partially recover failed blocks
+// can beat it, unlike MDSRecoveryProbability. It visits every delivery pattern.
+func MDSResidualLossRate(model LossModel, N, K int) float64 {
+	if N == 0 {
+		return 0
+	}
+	allMedia := uint64(1)<<N - 1
+	expectedLost := 0.0
+	VisitPatterns(model, N+K, func(pattern uint64, probability float64) {
+		if !IsMDSRecoverable(pattern, N) {
+			expectedLost += probability * float64(bits.OnesCount64(allMedia&^pattern))
+		}
+	})
+	return expectedLost / float64(N)
+}
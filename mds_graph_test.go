@@ -0,0 +1,41 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMDSGraphReachableMatchesPredicate(t *testing.T) {
+	for _, config := range []struct{ N, K int }{{1, 1}, {3, 1}, {3, 2}, {4, 3}} {
+		graph := NewMDSGraph(config.N, config.K)
+		reachable := BFS(graph, GoodVertices(config.N, config.K))
+
+		reachableSet := make(map[uint64]bool)
+		for _, vertex := range reachable {
+			reachableSet[vertex] = true
+		}
+		for vertex := uint64(0); vertex < graph.NumVertices(); vertex++ {
+			assert.Equal(t, IsMDSRecoverable(vertex, config.N), reachableSet[vertex],
+				"N=%d K=%d vertex %b", config.N, config.K, vertex)
+		}
+	}
+}
+
+func TestMDSDominatesXORMask(t *testing.T) {
+	model := NewGilbertElliotLossModel(0.05, 0.7, 0.05, 0.2)
+	N, K := 4, 2
+
+	mask, err := (&GoogleRandomMaskFactory{}).CreateMask(N, K)
+	assert.NoError(t, err)
+
+	xorProbability := 0.0
+	recoverableSet(mask).Iterate(func(vertex uint64) {
+		assert.True(t, IsMDSRecoverable(vertex, N), "XOR-recoverable pattern %b must be MDS-recoverable", vertex)
+		xorProbability += model.CalculateProbability(int(vertex), N+K)
+	})
+
+	mdsProbability := MDSRecoveryProbability(model, N, K)
+	assert.GreaterOrEqual(t, mdsProbability, xorProbability)
+	assert.Less(t, mdsProbability, 1.0)
+}
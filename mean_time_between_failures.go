@@ -0,0 +1,37 @@
+package fecanalysis
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// SecondsBetweenFailures returns the expected number of seconds between unrecoverable groups,
+// which show as visible or audible glitches, for groups sent back to back that each fail with
+// the given probability. The long-run fraction of failing groups of a stationary channel is
+// the failure probability even when losses are correlated across groups, as under a
+// Gilbert-Elliott model, so this is exact for such channels. It is +Inf when groups never fail.
+func SecondsBetweenFailures(failureProbability float64, groupDuration time.Duration) float64 {
+	if failureProbability <= 0 {
+		return math.Inf(1)
+	}
+	return groupDuration.Seconds() / failureProbability
+}
+
+// MeanTimeBetweenFailures returns the expected number of seconds between groups the mask does
+// not fully recover under the loss model, for a stream of packetRate media packets per second
+// protected in groups of the mask's N media packets. The failure probability is the sum over
+// the patterns the mask does not recover rather than one minus the recovery probability,
+// which would lose the digits of failures rarer than about 1e-12.
+func MeanTimeBetweenFailures(mask Mask, model LossModel, mode DecodingMode, packetRate float64) (float64, error) {
+	N, K := mask.N(), mask.K()
+	if err := checkEnumerable(N, K); err != nil {
+		return 0, err
+	}
+	if packetRate <= 0 {
+		return 0, fmt.Errorf("packet rate %v must be positive", packetRate)
+	}
+	groupDuration := time.Duration(float64(N) / packetRate * float64(time.Second))
+	failureProbability := SumProbabilities(RecoverableSet(mask, mode).Complement(), model, N+K, 0)
+	return SecondsBetweenFailures(failureProbability, groupDuration), nil
+}
@@ -0,0 +1,47 @@
+package fecanalysis
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecondsBetweenFailures(t *testing.T) {
+	assert.InDelta(t, 2.0, SecondsBetweenFailures(0.01, 20*time.Millisecond), 1e-12)
+	assert.True(t, math.IsInf(SecondsBetweenFailures(0, 20*time.Millisecond), 1))
+}
+
+func TestMeanTimeBetweenFailures(t *testing.T) {
+	// 10 media packets at 50 packets per second make a group every 200 ms
+	mask, err := (&GoogleBurstyMaskFactory{}).CreateMask(10, 3)
+	require.NoError(t, err)
+	model := NewGilbertElliotLossModel(0.01, 0.5, 0.05, 0.3)
+	seconds, err := MeanTimeBetweenFailures(mask, model, PeelingDecoding, 50)
+	require.NoError(t, err)
+	recovery := RecoveryProbability(mask, model)
+	assert.InDelta(t, 0.2/(1-recovery), seconds, 1e-9)
+
+	// Better decoding only makes glitches rarer
+	mlSeconds, err := MeanTimeBetweenFailures(mask, model, MLDecoding, 50)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, mlSeconds, seconds)
+
+	_, err = MeanTimeBetweenFailures(mask, model, PeelingDecoding, 0)
+	assert.Error(t, err)
+
+	// Rare failures keep their precision: single parity over 4 packets fails on any two
+	// losses, and 4 packets per second make a group every second
+	parity, err := (&SingleParityMaskFactory{}).CreateMask(4, 1)
+	require.NoError(t, err)
+	p := 1e-6
+	failure := 0.0
+	for lost, ways := range []float64{2: 10, 3: 10, 4: 5, 5: 1} {
+		failure += ways * math.Pow(p, float64(lost)) * math.Pow(1-p, float64(5-lost))
+	}
+	seconds, err = MeanTimeBetweenFailures(parity, NewRandomLossModel(p), PeelingDecoding, 4)
+	require.NoError(t, err)
+	assert.InEpsilon(t, 1/failure, seconds, 1e-9)
+}
@@ -0,0 +1,99 @@
+package fecanalysis
+
+import (
+	"fmt"
+	"math"
+)
+
+// EstimateRecoveryProbability estimates the probability that every media packet of the mask
+// is available after decoding by drawing samples delivery patterns from the model (see
+// SamplePattern) and decoding each. It is the fallback for blocks whose 2^(N+K) patterns
+// can't be enumerated; Estimate.ConfidenceInterval gives the error bounds. If rng is nil, a
+// source derived from the package-level seed is used.
+func EstimateRecoveryProbability(mask Mask, model LossModel, mode DecodingMode, samples int, rng RandSource) (Estimate, error) {
+	N, K := mask.N(), mask.K()
+	if err := checkGraphPackets(N, K); err != nil {
+		return Estimate{}, err
+	}
+	if samples <= 0 {
+		return Estimate{}, fmt.Errorf("sample count must be positive, got %d", samples)
+	}
+
+	recovery, _, err := estimateRecovery(N, K, model, nil, samples, rng, recoveryFunc(mask, mode))
+	return recovery, err
+}
+
+// EstimateResidualLossRate estimates WeightedResidualLossRate by decoding samples delivery
+// patterns drawn from the model, for blocks whose patterns can't be enumerated. Nil weights
+// count every media packet once.
+func EstimateResidualLossRate(mask Mask, model LossModel, mode DecodingMode, weights []float64, samples int, rng RandSource) (Estimate, error) {
+	N, K := mask.N(), mask.K()
+	if err := checkGraphPackets(N, K); err != nil {
+		return Estimate{}, err
+	}
+	if samples <= 0 {
+		return Estimate{}, fmt.Errorf("sample count must be positive, got %d", samples)
+	}
+	_, residual, err := estimateRecovery(N, K, model, weights, samples, rng, recoveryFunc(mask, mode))
+	return residual, err
+}
+
+// EstimateMDSResidualLossRate estimates MDSResidualLossRate from samples delivery patterns
+// drawn from the model, for blocks whose patterns can't be enumerated
+func EstimateMDSResidualLossRate(model LossModel, N, K, samples int, rng RandSource) (Estimate, error) {
+	if err := checkGraphPackets(N, K); err != nil {
+		return Estimate{}, err
+	}
+	if samples <= 0 {
+		return Estimate{}, fmt.Errorf("sample count must be positive, got %d", samples)
+	}
+	allMedia := uint64(1)<<N - 1
+	_, residual, err := estimateRecovery(N, K, model, nil, samples, rng, func(pattern uint64) uint64 {
+		if IsMDSRecoverable(pattern, N) {
+			return pattern | allMedia
+		}
+		return pattern
+	})
+	return residual, err
+}
+
+// estimateRecovery decodes samples delivery patterns drawn from the model with recover and
+// returns the estimated recovery probability and (weighted) residual loss rate of a block of
+// N media and K FEC packets
+func estimateRecovery(N, K int, model LossModel, weights []float64, samples int, rng RandSource, recover func(pattern uint64) uint64) (recovery, residual Estimate, err error) {
+	totalWeight, err := totalImportance(weights, N)
+	if err != nil {
+		return Estimate{}, Estimate{}, err
+	}
+
+	rng = randSourceOrDefault(rng)
+	allMedia := uint64(1)<<N - 1
+	recovered := 0
+	lostSum, lostSquares := 0.0, 0.0
+	for i := 0; i < samples; i++ {
+		pattern := SamplePattern(model, N+K, rng)
+		missing := allMedia &^ recover(pattern)
+		if missing == 0 {
+			recovered++
+		} else if totalWeight > 0 {
+			lost := missingWeight(missing, weights) / totalWeight
+			lostSum += lost
+			lostSquares += lost * lost
+		}
+	}
+
+	// Bernoulli samples: the standard error of the mean is sqrt(p(1-p)/n)
+	probability := float64(recovered) / float64(samples)
+	recovery = Estimate{
+		Value:    probability,
+		StdError: math.Sqrt(probability * (1 - probability) / float64(samples)),
+		Samples:  samples,
+	}
+	meanLost := lostSum / float64(samples)
+	residual = Estimate{
+		Value:    meanLost,
+		StdError: math.Sqrt(math.Max(lostSquares/float64(samples)-meanLost*meanLost, 0) / float64(samples)),
+		Samples:  samples,
+	}
+	return recovery, residual, nil
+}
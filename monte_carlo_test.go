@@ -0,0 +1,71 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateRecoveryProbability(t *testing.T) {
+	mask, err := (&GoogleBurstyMaskFactory{}).CreateMask(8, 4)
+	require.NoError(t, err)
+
+	for _, model := range []LossModel{NewRandomLossModel(0.15), NewGilbertElliotLossModel(0.02, 0.6, 0.05, 0.3)} {
+		for _, mode := range []DecodingMode{PeelingDecoding, MLDecoding} {
+			exact := SumProbabilities(RecoverableSet(mask, mode), model, 12, 1)
+			estimate, err := EstimateRecoveryProbability(mask, model, mode, 20000, NewRandSource(7))
+			require.NoError(t, err)
+			assert.Equal(t, 20000, estimate.Samples)
+			assert.InDelta(t, exact, estimate.Value, 4*estimate.StdError, "%T %s", model, mode)
+
+			low, high := estimate.ConfidenceInterval(1.96)
+			assert.Less(t, low, estimate.Value)
+			assert.Greater(t, high, estimate.Value)
+		}
+	}
+}
+
+func TestEstimateRecoveryProbabilityErrors(t *testing.T) {
+	mask, err := (&InterleavedMaskFactory{}).CreateMask(4, 2)
+	require.NoError(t, err)
+	_, err = EstimateRecoveryProbability(mask, NewRandomLossModel(0.1), PeelingDecoding, 0, nil)
+	assert.Error(t, err)
+
+	large, err := (&InterleavedMaskFactory{}).CreateMask(60, 10)
+	require.NoError(t, err)
+	_, err = EstimateRecoveryProbability(large, NewRandomLossModel(0.1), PeelingDecoding, 10, nil)
+	assert.Error(t, err)
+}
+
+func TestEstimateResidualLossRate(t *testing.T) {
+	mask, err := (&GoogleRandomMaskFactory{}).CreateMask(8, 3)
+	require.NoError(t, err)
+	model := NewRandomLossModel(0.15)
+
+	for _, mode := range []DecodingMode{PeelingDecoding, MLDecoding} {
+		exact, err := ResidualLossRateWithMode(mask, model, mode)
+		require.NoError(t, err)
+		estimate, err := EstimateResidualLossRate(mask, model, mode, nil, 20000, NewRandSource(5))
+		require.NoError(t, err)
+		assert.Equal(t, 20000, estimate.Samples)
+		assert.InDelta(t, exact, estimate.Value, 4*estimate.StdError, "%s", mode)
+	}
+
+	_, err = EstimateResidualLossRate(mask, model, PeelingDecoding, []float64{1}, 10, nil)
+	assert.Error(t, err)
+	_, err = EstimateResidualLossRate(mask, model, PeelingDecoding, nil, 0, nil)
+	assert.Error(t, err)
+}
+
+func TestEstimateMDSResidualLossRate(t *testing.T) {
+	model := NewGilbertElliotLossModel(0.05, 0.4, 0.02, 0.5)
+	exact := MDSResidualLossRate(model, 8, 3)
+	estimate, err := EstimateMDSResidualLossRate(model, 8, 3, 20000, NewRandSource(5))
+	require.NoError(t, err)
+	assert.Equal(t, 20000, estimate.Samples)
+	assert.InDelta(t, exact, estimate.Value, 4*estimate.StdError)
+
+	_, err = EstimateMDSResidualLossRate(model, 8, 3, 0, nil)
+	assert.Error(t, err)
+}
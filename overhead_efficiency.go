@@ -0,0 +1,35 @@
+package fecanalysis
+
+import "fmt"
+
+// NoFECRecoveryProbability returns the probability that all N media packets of a block sent
+// without FEC arrive, the baseline every mask improves on
+func NoFECRecoveryProbability(model LossModel, N int) float64 {
+	return model.CalculateProbability(1<<N-1, N)
+}
+
+// OverheadEfficiency returns the recovery probability the mask gains over sending its media
+// packets without FEC, per percent of overhead 100*K/N. Adding FEC packets always costs
+// overhead and rarely loses recovery, so comparing masks of different K on the recovery
+// probability alone favors the larger K; this metric makes the comparison fair.
+func OverheadEfficiency(mask Mask, model LossModel, mode DecodingMode) (float64, error) {
+	N, K := mask.N(), mask.K()
+	if err := checkEnumerable(N, K); err != nil {
+		return 0, err
+	}
+	return OverheadEfficiencyFromSet(N, K, RecoverableSet(mask, mode), model)
+}
+
+// OverheadEfficiencyFromSet computes the overhead efficiency using an existing recoverable set
+func OverheadEfficiencyFromSet(N, K int, reachableSet *ReachableSet, model LossModel) (float64, error) {
+	if N <= 0 || K <= 0 {
+		return 0, fmt.Errorf("overhead efficiency needs media and FEC packets, got N=%d, K=%d", N, K)
+	}
+	return overheadEfficiency(SumProbabilities(reachableSet, model, N+K, 0), model, N, K), nil
+}
+
+// overheadEfficiency divides the recovery probability gained over no FEC by the overhead
+func overheadEfficiency(recoveryProbability float64, model LossModel, N, K int) float64 {
+	gain := recoveryProbability - NoFECRecoveryProbability(model, N)
+	return gain / (float64(K) * 100 / float64(N))
+}
@@ -0,0 +1,46 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoFECRecoveryProbability(t *testing.T) {
+	assert.InDelta(t, 0.9*0.9*0.9, NoFECRecoveryProbability(NewRandomLossModel(0.1), 3), 1e-12)
+	assert.Equal(t, 1.0, NoFECRecoveryProbability(NewRandomLossModel(0), 5))
+}
+
+func TestOverheadEfficiency(t *testing.T) {
+	// Single parity over two packets at 50% overhead recovers every single loss
+	p := 0.1
+	mask := NewSimpleMask([][]bool{{true, true}}, 2, 1)
+	model := NewRandomLossModel(p)
+	efficiency, err := OverheadEfficiency(mask, model, PeelingDecoding)
+	require.NoError(t, err)
+	recovery := (1-p)*(1-p)*(1-p) + 3*p*(1-p)*(1-p)
+	assert.InDelta(t, (recovery-(1-p)*(1-p))/50, efficiency, 1e-12)
+
+	// More FEC packets recover more, but gain less per percent of overhead
+	for _, mode := range []DecodingMode{PeelingDecoding, MLDecoding} {
+		small, err := (&GoogleRandomMaskFactory{}).CreateMask(8, 1)
+		require.NoError(t, err)
+		large, err := (&GoogleRandomMaskFactory{}).CreateMask(8, 6)
+		require.NoError(t, err)
+		smallEfficiency, err := OverheadEfficiency(small, model, mode)
+		require.NoError(t, err)
+		largeEfficiency, err := OverheadEfficiency(large, model, mode)
+		require.NoError(t, err)
+		assert.Greater(t, smallEfficiency, largeEfficiency, "%s", mode)
+		assert.Greater(t, largeEfficiency, 0.0, "%s", mode)
+	}
+
+	// Without loss there is nothing to gain
+	efficiency, err = OverheadEfficiency(mask, NewRandomLossModel(0), PeelingDecoding)
+	require.NoError(t, err)
+	assert.Zero(t, efficiency)
+
+	_, err = OverheadEfficiencyFromSet(2, 0, NewReachableSet(4), model)
+	assert.Error(t, err)
+}
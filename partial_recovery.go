@@ -0,0 +1,239 @@
+package fecanalysis
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// RecoveredMedia returns the bitmask of media packets available after decoding the delivery
+// pattern with the given mode: the delivered media packets plus every lost one the decoder
+// repairs, even if other packets of the group stay lost
+func RecoveredMedia(mask Mask, pattern uint64, mode DecodingMode) uint64 {
+	N := mask.N()
+	rows := patternRows(mask)
+	allMedia := uint64(1)<<N - 1
+	if mode == MLDecoding {
+		return eliminationClosure(rows, N, pattern) & allMedia
+	}
+	return peelingClosure(rows, N, pattern) & allMedia
+}
+
+// eliminationClosure returns the pattern with every media packet set that Gaussian
+// elimination over the delivered FEC packets determines. A missing packet is determined
+// when its unit vector lies in the span of the equations restricted to the missing
+// packets, i.e. when the reduced row echelon form has a row with that single packet.
+func eliminationClosure(rows []uint64, N int, pattern uint64) uint64 {
+	basis := deliveredEquations(rows, N, pattern)
+	return pattern | basis.determined()
+}
+
+// PacketRecoveryProbabilities returns, for every media packet, the probability that it is
+// available after decoding with the given mode, counting packets repaired in groups that are
+// only partially recovered
+func PacketRecoveryProbabilities(mask Mask, model LossModel, mode DecodingMode) ([]float64, error) {
+	N, K := mask.N(), mask.K()
+	if err := checkEnumerable(N, K); err != nil {
+		return nil, err
+	}
+
+	rows := patternRows(mask)
+	allMedia := uint64(1)<<N - 1
+	probabilities := make([]float64, N)
+	for pattern := uint64(0); pattern < uint64(1)<<(N+K); pattern++ {
+		var recovered uint64
+		if mode == MLDecoding {
+			recovered = eliminationClosure(rows, N, pattern)
+		} else {
+			recovered = peelingClosure(rows, N, pattern)
+		}
+		probability := model.CalculateProbability(int(pattern), N+K)
+		for available := recovered & allMedia; available != 0; available &= available - 1 {
+			probabilities[bits.TrailingZeros64(available)] += probability
+		}
+	}
+	return probabilities, nil
+}
+
+// PacketMinLostForNonRecovery returns, for every media packet, the minimum number of lost
+// packets, itself included, that leaves it unrecoverable with the given decoding mode. The
+// minimum over all packets is MinLostPacketsForNonRecovery; packets with a lower value than
+// the others are the ones the mask protects weakly. Losing a packet together with every FEC
+// packet always leaves it lost, so every value is at most K+1.
+func PacketMinLostForNonRecovery(mask Mask, mode DecodingMode) ([]int, error) {
+	N, K := mask.N(), mask.K()
+	if err := checkEnumerable(N, K); err != nil {
+		return nil, err
+	}
+
+	rows := patternRows(mask)
+	closure := peelingClosure
+	if mode == MLDecoding {
+		closure = eliminationClosure
+	}
+
+	// Loss patterns of increasing size; the first one leaving a packet lost gives its minimum
+	minLost := make([]int, N)
+	allDelivered := uint64(1)<<(N+K) - 1
+	unresolved := uint64(1)<<N - 1 // media packets whose minimum is not known yet
+	for numLost := 1; unresolved != 0; numLost++ {
+		generateCombinations(N+K, numLost, func(lossPattern uint64) bool {
+			if lossPattern&unresolved == 0 {
+				return false
+			}
+			lost := lossPattern & unresolved &^ closure(rows, N, allDelivered^lossPattern)
+			for ; lost != 0; lost &= lost - 1 {
+				packetIndex := bits.TrailingZeros64(lost)
+				minLost[packetIndex] = numLost
+				unresolved &^= uint64(1) << packetIndex
+			}
+			return unresolved == 0
+		})
+	}
+	return minLost, nil
+}
+
+// ExpectedRecoveredMediaPackets returns the mean number of media packets available after
+// peeling recovery, counting partially recovered groups; this is the quantity that maps to
+// perceived media quality rather than the all-or-nothing recovery probability
+func ExpectedRecoveredMediaPackets(mask Mask, model LossModel) (float64, error) {
+	probabilities, err := PacketRecoveryProbabilities(mask, model, PeelingDecoding)
+	if err != nil {
+		return 0, err
+	}
+	expected := 0.0
+	for _, probability := range probabilities {
+		expected += probability
+	}
+	return expected, nil
+}
+
+// ResidualLossRate returns the expected fraction of media packets still missing after peeling
+// recovery, counting packets repaired in partially recovered groups. Unlike the recovery
+// probability it does not depend on the block size by construction, so blocks of different
+// N compare directly.
+func ResidualLossRate(mask Mask, model LossModel) (float64, error) {
+	return ResidualLossRateWithMode(mask, model, PeelingDecoding)
+}
+
+// ResidualLossRateWithMode is ResidualLossRate for the given decoding mode
+func ResidualLossRateWithMode(mask Mask, model LossModel, mode DecodingMode) (float64, error) {
+	return WeightedResidualLossRate(mask, model, mode, nil)
+}
+
+// WeightedResidualLossRate is ResidualLossRateWithMode where losing media packet i costs
+// weights[i], normalized by the total weight, so that e.g. losing a packet of a key frame
+// counts more than losing one of a delta frame. This compares unequal protection masks with
+// equal protection ones on what they are designed for. Nil weights count every packet once.
+func WeightedResidualLossRate(mask Mask, model LossModel, mode DecodingMode, weights []float64) (float64, error) {
+	return residualLossRate(mask, model, weights, recoveryFunc(mask, mode))
+}
+
+// ImportanceWeights returns the weights of a block of N media packets whose first
+// numImportant packets are important, the layout of WebRTC unequal protection, weighted
+// importantWeight each while the other packets weigh 1
+func ImportanceWeights(N, numImportant int, importantWeight float64) []float64 {
+	weights := make([]float64, N)
+	for packetIndex := range weights {
+		weights[packetIndex] = 1
+		if packetIndex < numImportant {
+			weights[packetIndex] = importantWeight
+		}
+	}
+	return weights
+}
+
+// ResidualLossRateWithinDepth is ResidualLossRate when the receiver stops peeling after depth
+// rounds of parallel repairs, see RecoverableSetWithinDepth
+func ResidualLossRateWithinDepth(mask Mask, model LossModel, depth int) (float64, error) {
+	return residualLossRate(mask, model, nil, depthLimitedRecoveryFunc(mask, depth))
+}
+
+// recoveryFunc returns the decoder of the mode, mapping a delivery pattern to the pattern
+// with every repaired media packet set
+func recoveryFunc(mask Mask, mode DecodingMode) func(pattern uint64) uint64 {
+	N := mask.N()
+	rows := patternRows(mask)
+	if mode == MLDecoding {
+		return func(pattern uint64) uint64 {
+			return eliminationClosure(rows, N, pattern)
+		}
+	}
+	return func(pattern uint64) uint64 {
+		return peelingClosure(rows, N, pattern)
+	}
+}
+
+// depthLimitedRecoveryFunc is recoveryFunc for a peeling decoder that stops after depth
+// rounds of parallel repairs
+func depthLimitedRecoveryFunc(mask Mask, depth int) func(pattern uint64) uint64 {
+	N := mask.N()
+	rows := patternRows(mask)
+	return func(pattern uint64) uint64 {
+		for round := 0; round < depth; round++ {
+			recovered := peelingRound(rows, N, pattern)
+			if recovered == pattern {
+				break
+			}
+			pattern = recovered
+		}
+		return pattern
+	}
+}
+
+// totalImportance returns the sum of the importance weights of N media packets, N for nil
+// weights, and fails on a length mismatch or a negative weight
+func totalImportance(weights []float64, N int) (float64, error) {
+	if weights == nil {
+		return float64(N), nil
+	}
+	if len(weights) != N {
+		return 0, fmt.Errorf("%d importance weights for %d media packets", len(weights), N)
+	}
+	total := 0.0
+	for packetIndex, weight := range weights {
+		if weight < 0 {
+			return 0, fmt.Errorf("negative weight %g of media packet %d", weight, packetIndex)
+		}
+		total += weight
+	}
+	return total, nil
+}
+
+// residualLossRate averages the weight of the media packets missing from recover(pattern)
+// over all delivery patterns, weighted by the model
+func residualLossRate(mask Mask, model LossModel, weights []float64, recover func(pattern uint64) uint64) (float64, error) {
+	N, K := mask.N(), mask.K()
+	if err := checkEnumerable(N, K); err != nil {
+		return 0, err
+	}
+	totalWeight, err := totalImportance(weights, N)
+	if err != nil {
+		return 0, err
+	}
+	if totalWeight == 0 {
+		return 0, nil
+	}
+
+	allMedia := uint64(1)<<N - 1
+	expectedLost := 0.0
+	VisitPatterns(model, N+K, func(pattern uint64, probability float64) {
+		if pattern&allMedia == allMedia {
+			return
+		}
+		expectedLost += probability * missingWeight(allMedia&^recover(pattern), weights)
+	})
+	return expectedLost / totalWeight, nil
+}
+
+// missingWeight returns the total weight of the missing media packets, their count for nil
+// weights
+func missingWeight(missing uint64, weights []float64) float64 {
+	if weights == nil {
+		return float64(bits.OnesCount64(missing))
+	}
+	weight := 0.0
+	for ; missing != 0; missing &= missing - 1 {
+		weight += weights[bits.TrailingZeros64(missing)]
+	}
+	return weight
+}
@@ -0,0 +1,214 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoveredMedia(t *testing.T) {
+	// Two groups: losing three packets leaves the second group repairable
+	groups := NewSimpleMask([][]bool{
+		{true, true, false, false},
+		{false, false, true, true},
+	}, 4, 2)
+	for _, mode := range []DecodingMode{PeelingDecoding, MLDecoding} {
+		assert.Equal(t, uint64(0b1100), RecoveredMedia(groups, 0b11_1000, mode), "%s", mode)
+		assert.Equal(t, uint64(0b1111), RecoveredMedia(groups, 0b11_1010, mode), "%s", mode)
+	}
+
+	// With all three packets lost, only the sum of the two equations isolates packet 2
+	nested := NewSimpleMask([][]bool{
+		{true, true, true},
+		{true, true, false},
+	}, 3, 2)
+	assert.Equal(t, uint64(0), RecoveredMedia(nested, 0b11_000, PeelingDecoding))
+	assert.Equal(t, uint64(0b100), RecoveredMedia(nested, 0b11_000, MLDecoding))
+}
+
+func TestRecoveredMediaMatchesRecoverableSet(t *testing.T) {
+	mask, err := (&GoogleBurstyMaskFactory{}).CreateMask(5, 3)
+	require.NoError(t, err)
+
+	for _, mode := range []DecodingMode{PeelingDecoding, MLDecoding} {
+		recoverable := RecoverableSet(mask, mode)
+		for pattern := uint64(0); pattern < recoverable.NumVertices(); pattern++ {
+			recovered := RecoveredMedia(mask, pattern, mode)
+			assert.Equal(t, pattern&0b11111, recovered&pattern&0b11111, "delivered packets stay available")
+			assert.Equal(t, recoverable.Contains(pattern), recovered == 0b11111, "%s pattern %08b", mode, pattern)
+		}
+	}
+}
+
+func TestPacketRecoveryProbabilities(t *testing.T) {
+	mask, err := (&GoogleRandomMaskFactory{}).CreateMask(6, 3)
+	require.NoError(t, err)
+	model := NewRandomLossModel(0.1)
+
+	peeling, err := PacketRecoveryProbabilities(mask, model, PeelingDecoding)
+	require.NoError(t, err)
+	require.Len(t, peeling, 6)
+	mean := 0.0
+	for _, probability := range peeling {
+		assert.Greater(t, probability, 0.9, "FEC improves on the raw delivery probability")
+		mean += probability / 6
+	}
+	residual, err := ResidualLossRate(mask, model)
+	require.NoError(t, err)
+	assert.InDelta(t, 1-residual, mean, 1e-12)
+
+	// Every packet is available at least as often as the whole group
+	for _, probability := range peeling {
+		assert.GreaterOrEqual(t, probability, RecoveryProbability(mask, model))
+	}
+
+	ml, err := PacketRecoveryProbabilities(mask, model, MLDecoding)
+	require.NoError(t, err)
+	for packetIndex := range ml {
+		assert.GreaterOrEqual(t, ml[packetIndex], peeling[packetIndex]-1e-12)
+	}
+
+	large, err := (&SingleParityMaskFactory{}).CreateMask(20, 10)
+	require.NoError(t, err)
+	_, err = PacketRecoveryProbabilities(large, model, PeelingDecoding)
+	assert.Error(t, err)
+}
+
+func TestExpectedRecoveredMediaPackets(t *testing.T) {
+	// Two independent groups of two packets, each protected by one FEC packet
+	mask := NewSimpleMask([][]bool{
+		{true, true, false, false},
+		{false, false, true, true},
+	}, 4, 2)
+	model := NewRandomLossModel(0.1)
+
+	// A packet is available if delivered, or lost while its partner and FEC are delivered
+	expected, err := ExpectedRecoveredMediaPackets(mask, model)
+	require.NoError(t, err)
+	assert.InDelta(t, 4*(0.9+0.1*0.9*0.9), expected, 1e-12)
+	residual, err := ResidualLossRate(mask, model)
+	require.NoError(t, err)
+	assert.InDelta(t, 4*(1-residual), expected, 1e-12)
+
+	// Without FEC the expectation is the delivered packet count
+	unprotected := NewSimpleMask([][]bool{{false, false, false, false}}, 4, 1)
+	expected, err = ExpectedRecoveredMediaPackets(unprotected, model)
+	require.NoError(t, err)
+	assert.InDelta(t, 3.6, expected, 1e-12)
+}
+
+func TestResidualLossRate(t *testing.T) {
+	model := NewGilbertElliotLossModel(0.02, 0.6, 0.05, 0.3)
+	mask, err := (&GoogleRandomMaskFactory{}).CreateMask(8, 4)
+	require.NoError(t, err)
+
+	residual, err := ResidualLossRate(mask, model)
+	require.NoError(t, err)
+	expected, err := ExpectedRecoveredMediaPackets(mask, model)
+	require.NoError(t, err)
+	assert.InDelta(t, 1-expected/8, residual, 1e-12)
+	assert.Less(t, residual, model.GetAverageLossProbability())
+
+	// Elimination repairs at least what peeling does
+	ml, err := ResidualLossRateWithMode(mask, model, MLDecoding)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, ml, residual)
+
+	// Without repairs the residual loss is the channel loss; enough rounds match full peeling
+	unrepaired, err := ResidualLossRateWithinDepth(mask, model, 0)
+	require.NoError(t, err)
+	assert.InDelta(t, model.GetAverageLossProbability(), unrepaired, 1e-12)
+	full, err := ResidualLossRateWithinDepth(mask, model, 8)
+	require.NoError(t, err)
+	assert.InDelta(t, residual, full, 1e-12)
+	shallow, err := ResidualLossRateWithinDepth(mask, model, 1)
+	require.NoError(t, err)
+	assert.Less(t, residual, shallow)
+	assert.Less(t, shallow, unrepaired)
+
+	// A single parity packet is an MDS code
+	parity, err := (&SingleParityMaskFactory{}).CreateMask(6, 1)
+	require.NoError(t, err)
+	parityResidual, err := ResidualLossRate(parity, model)
+	require.NoError(t, err)
+	assert.InDelta(t, MDSResidualLossRate(model, 6, 1), parityResidual, 1e-12)
+
+	large, err := (&InterleavedMaskFactory{}).CreateMask(20, 5)
+	require.NoError(t, err)
+	_, err = ResidualLossRate(large, model)
+	assert.Error(t, err)
+}
+
+func TestWeightedResidualLossRate(t *testing.T) {
+	model := NewGilbertElliotLossModel(0.02, 0.6, 0.05, 0.3)
+	factory := &GoogleRandomMaskFactory{}
+	mask, err := factory.CreateMask(8, 3)
+	require.NoError(t, err)
+
+	for _, mode := range []DecodingMode{PeelingDecoding, MLDecoding} {
+		residual, err := ResidualLossRateWithMode(mask, model, mode)
+		require.NoError(t, err)
+		uniform, err := WeightedResidualLossRate(mask, model, mode, ImportanceWeights(8, 0, 5))
+		require.NoError(t, err)
+		assert.InDelta(t, residual, uniform, 1e-12, "%s", mode)
+
+		// Weighting a single packet gives its own loss probability after recovery
+		probabilities, err := PacketRecoveryProbabilities(mask, model, mode)
+		require.NoError(t, err)
+		single := make([]float64, 8)
+		single[3] = 2
+		weighted, err := WeightedResidualLossRate(mask, model, mode, single)
+		require.NoError(t, err)
+		assert.InDelta(t, 1-probabilities[3], weighted, 1e-12, "%s", mode)
+	}
+
+	// With four FEC packets the unequal protection mask protects the important packets better
+	regular, err := factory.CreateMask(8, 4)
+	require.NoError(t, err)
+	uep, err := factory.CreateMaskUEP(8, 4, 2)
+	require.NoError(t, err)
+	weights := ImportanceWeights(8, 2, 10)
+	assert.Equal(t, []float64{10, 10, 1, 1, 1, 1, 1, 1}, weights)
+	equal, err := WeightedResidualLossRate(regular, model, PeelingDecoding, weights)
+	require.NoError(t, err)
+	unequal, err := WeightedResidualLossRate(uep, model, PeelingDecoding, weights)
+	require.NoError(t, err)
+	assert.Less(t, unequal, equal)
+
+	_, err = WeightedResidualLossRate(mask, model, PeelingDecoding, []float64{1, 1})
+	assert.Error(t, err)
+	_, err = WeightedResidualLossRate(mask, model, PeelingDecoding, ImportanceWeights(8, 1, -1))
+	assert.Error(t, err)
+}
+
+func TestPacketMinLostForNonRecovery(t *testing.T) {
+	// Packet 0 is protected by both FEC packets, packet 2 by none
+	mask := NewSimpleMask([][]bool{
+		{true, true, false},
+		{true, false, false},
+	}, 3, 2)
+	for _, mode := range []DecodingMode{PeelingDecoding, MLDecoding} {
+		minLost, err := PacketMinLostForNonRecovery(mask, mode)
+		require.NoError(t, err)
+		assert.Equal(t, []int{3, 2, 1}, minLost, "%s", mode)
+	}
+
+	// The weakest packet gives the minimum of the mask
+	bursty, err := (&GoogleBurstyMaskFactory{}).CreateMask(8, 4)
+	require.NoError(t, err)
+	minLost, err := PacketMinLostForNonRecovery(bursty, PeelingDecoding)
+	require.NoError(t, err)
+	require.Len(t, minLost, 8)
+	weakest := minLost[0]
+	for _, packetMinLost := range minLost {
+		assert.LessOrEqual(t, packetMinLost, 5)
+		weakest = min(weakest, packetMinLost)
+	}
+	assert.Equal(t, CalculateRecoveryCharacteristics(bursty).MinLostPacketsForNonRecovery, LossCount(weakest))
+
+	large, err := (&InterleavedMaskFactory{}).CreateMask(16, 9)
+	require.NoError(t, err)
+	_, err = PacketMinLostForNonRecovery(large, PeelingDecoding)
+	assert.Error(t, err)
+}
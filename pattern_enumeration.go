@@ -0,0 +1,91 @@
+package fecanalysis
+
+import (
+	"container/heap"
+	"iter"
+)
+
+// patternCandidate is a delivery pattern waiting in the enumeration frontier
+type patternCandidate struct {
+	pattern     int     // delivery pattern (bit i set if packet i delivered)
+	probability float64 // probability of the pattern under the loss model
+	firstFree   int     // lowest packet index that children may additionally lose
+}
+
+// patternHeap is a max-heap of candidates ordered by probability
+type patternHeap []patternCandidate
+
+func (h patternHeap) Len() int           { return len(h) }
+func (h patternHeap) Less(i, j int) bool { return h[i].probability > h[j].probability }
+func (h patternHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *patternHeap) Push(x any)        { *h = append(*h, x.(patternCandidate)) }
+func (h *patternHeap) Pop() any {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
+
+// ProbablePatterns returns an iterator over delivery patterns of N packets together with
+// their probabilities, in approximately descending probability order. Iteration stops
+// once the cumulative probability of the yielded patterns reaches coverage (use 1.0 to
+// enumerate everything).
+//
+// Patterns are organized in a tree rooted at the all-delivered pattern, where children
+// lose one more packet after the last lost one, and explored best-first. The order is
+// exact for models where losing an extra packet never makes a pattern more likely
+// (e.g. random loss below 50%), and approximate for bursty models.
+func ProbablePatterns(model LossModel, N int, coverage float64) iter.Seq2[int, float64] {
+	return func(yield func(int, float64) bool) {
+		if N <= 0 || N > 62 {
+			return
+		}
+
+		allDelivered := (1 << N) - 1
+		frontier := &patternHeap{{
+			pattern:     allDelivered,
+			probability: model.CalculateProbability(allDelivered, N),
+			firstFree:   0,
+		}}
+
+		cumulative := 0.0
+		for frontier.Len() > 0 && cumulative < coverage {
+			candidate := heap.Pop(frontier).(patternCandidate)
+			cumulative += candidate.probability
+			if !yield(candidate.pattern, candidate.probability) {
+				return
+			}
+
+			// Children additionally lose one packet after the last lost one
+			for i := candidate.firstFree; i < N; i++ {
+				child := candidate.pattern &^ (1 << i)
+				heap.Push(frontier, patternCandidate{
+					pattern:     child,
+					probability: model.CalculateProbability(child, N),
+					firstFree:   i + 1,
+				})
+			}
+		}
+	}
+}
+
+// TruncatedProbability bounds the probability of an event by enumerating the most likely
+// delivery patterns of N packets until coverage is reached. The returned lower bound is the
+// probability of enumerated patterns satisfying event, the upper bound additionally assumes
+// all non-enumerated probability mass satisfies it.
+func TruncatedProbability(model LossModel, N int, coverage float64, event func(vertex int) bool) (float64, float64) {
+	lower := 0.0
+	covered := 0.0
+	for pattern, probability := range ProbablePatterns(model, N, coverage) {
+		covered += probability
+		if event(pattern) {
+			lower += probability
+		}
+	}
+
+	upper := lower + (1.0 - covered)
+	if upper < lower {
+		upper = lower // rounding noise when everything was enumerated
+	}
+	return lower, upper
+}
@@ -0,0 +1,48 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProbablePatternsEnumeratesEverythingOnce(t *testing.T) {
+	model := NewGilbertElliotLossModel(0.05, 0.7, 0.05, 0.2)
+	N := 6
+
+	seen := make(map[int]bool)
+	total := 0.0
+	for pattern, probability := range ProbablePatterns(model, N, 1.0+1e-9) {
+		assert.False(t, seen[pattern], "pattern %b yielded twice", pattern)
+		seen[pattern] = true
+		assert.Equal(t, model.CalculateProbability(pattern, N), probability)
+		total += probability
+	}
+	assert.Len(t, seen, 1<<N)
+	assert.InDelta(t, 1.0, total, 1e-9)
+}
+
+func TestProbablePatternsExactOrderForRandomLoss(t *testing.T) {
+	model := NewRandomLossModel(0.1)
+
+	previous := 2.0
+	count := 0
+	for _, probability := range ProbablePatterns(model, 8, 0.99) {
+		assert.LessOrEqual(t, probability, previous+1e-15)
+		previous = probability
+		count++
+	}
+	assert.Less(t, count, 1<<8, "coverage threshold should stop enumeration early")
+}
+
+func TestTruncatedProbabilityBoundsExactValue(t *testing.T) {
+	model := NewGilbertElliotLossModel(0.01, 0.5, 0.05, 0.3)
+	N := 10
+	event := func(vertex int) bool { return N-popcount(vertex) >= 2 }
+
+	exact := TailLossProbability(model, N, 2)
+	lower, upper := TruncatedProbability(model, N, 0.999, event)
+	assert.LessOrEqual(t, lower, exact+1e-12)
+	assert.GreaterOrEqual(t, upper, exact-1e-12)
+	assert.Less(t, upper-lower, 0.0011)
+}
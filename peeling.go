@@ -0,0 +1,36 @@
+package fecanalysis
+
+// patternRows returns, for each FEC packet, the bitmask of media packets it protects; the
+// bitmasks are uint64 like delivery patterns, so up to MaxGraphPackets packets are addressable
+func patternRows(mask Mask) []uint64 {
+	rows := make([]uint64, mask.K())
+	for fecIndex := range rows {
+		for packetIndex := 0; packetIndex < mask.N(); packetIndex++ {
+			if mask.IsProtected(packetIndex, fecIndex) {
+				rows[fecIndex] |= uint64(1) << packetIndex
+			}
+		}
+	}
+	return rows
+}
+
+// peelingClosure runs iterative (peeling) XOR recovery on a delivery pattern and returns
+// the pattern with every recoverable media packet set. A delivered FEC packet recovers a
+// media packet when it is the only protected packet still missing. rows holds the media
+// bitmask of every FEC packet and N is the number of media packets.
+func peelingClosure(rows []uint64, N int, pattern uint64) uint64 {
+	for changed := true; changed; {
+		changed = false
+		for fecIndex, row := range rows {
+			if pattern&(uint64(1)<<(N+fecIndex)) == 0 {
+				continue // FEC packet not delivered
+			}
+			missing := row &^ pattern
+			if missing != 0 && missing&(missing-1) == 0 {
+				pattern |= missing // exactly one protected packet missing: recover it
+				changed = true
+			}
+		}
+	}
+	return pattern
+}
@@ -0,0 +1,132 @@
+package fecanalysis
+
+import (
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// probabilityChunkWords is the number of set words (64 patterns each) summed per chunk by
+// SumProbabilities. Chunks don't depend on the worker count, which keeps the result
+// identical for every count.
+const probabilityChunkWords = 1 << 10
+
+// SumProbabilities returns the total probability of the patterns in the set under the model,
+// summed by a pool of workers goroutines, or GOMAXPROCS goroutines if workers <= 0. The set
+// is split into fixed chunks of consecutive patterns, each summed in ascending order, and the
+// chunk sums are combined in chunk order, so the result is deterministic. It may differ from
+// a single ascending sum in the last bits. Gilbert-Elliott models are summed over whole ranges
+// of patterns at once instead, see sumGilbertElliotProbabilities, and random loss models by
+// number of lost packets.
+func SumProbabilities(set *ReachableSet, model LossModel, totalPackets, workers int) float64 {
+	if set == nil {
+		return 0
+	}
+	if randomModel, ok := model.(*RandomLossModel); ok && totalPackets > 0 && set.NumVertices() == uint64(1)<<totalPackets {
+		total := 0.0
+		for lost, count := range lossCounts(set, totalPackets) {
+			total += float64(count) * math.Pow(randomModel.P, float64(lost)) * math.Pow(1-randomModel.P, float64(totalPackets-lost))
+		}
+		return total
+	}
+	if geModel, ok := model.(*GilbertElliotLossModel); ok && totalPackets > 0 && set.NumVertices() == uint64(1)<<totalPackets {
+		return sumGilbertElliotProbabilities(set, geModel, totalPackets, workers)
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	chunks := (len(set.words) + probabilityChunkWords - 1) / probabilityChunkWords
+	workers = min(workers, chunks)
+
+	sums := make([]float64, chunks)
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for chunk := int(next.Add(1) - 1); chunk < chunks; chunk = int(next.Add(1) - 1) {
+				first := chunk * probabilityChunkWords
+				end := min(first+probabilityChunkWords, len(set.words))
+				set.iterateWords(first, end, func(pattern uint64) {
+					sums[chunk] += model.CalculateProbability(int(pattern), totalPackets)
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	total := 0.0
+	for _, sum := range sums {
+		total += sum
+	}
+	return total
+}
+
+// gilbertElliottSplitPackets is the block size from which sumGilbertElliotProbabilities
+// splits its walk into gilbertElliottSplitLevels levels of subtrees for the workers; smaller
+// blocks are summed in one walk, as starting goroutines would cost more than the walk
+const (
+	gilbertElliottSplitPackets = 16
+	gilbertElliottSplitLevels  = 6
+)
+
+// sumGilbertElliotProbabilities walks the delivery patterns from the last packet to the first
+// like VisitPatterns, carrying the backward probabilities of the packets fixed so far.
+// The patterns below a node of the walk are a range of consecutive patterns whose total
+// probability is the marginal probability of the fixed packets, so ranges the set holds
+// entirely cost one step and ranges it holds none of are skipped; recoverable sets consist
+// mostly of such ranges. Large blocks are split into a fixed number of subtrees summed by the
+// workers and combined in order, so the result does not depend on the worker count.
+func sumGilbertElliotProbabilities(set *ReachableSet, model *GilbertElliotLossModel, totalPackets, workers int) float64 {
+	// visit sums the patterns below the node
+	var visit func(node gilbertElliottNode) float64
+	visit = func(node gilbertElliottNode) float64 {
+		none, all := set.rangeState(node.pattern, node.pattern+uint64(1)<<(node.index+1))
+		if none {
+			return 0
+		}
+		if all {
+			return node.probability(model)
+		}
+		delivered, lost := node.children(model)
+		return visit(delivered) + visit(lost)
+	}
+
+	roots := []gilbertElliottNode{newGilbertElliottNode(totalPackets)}
+	if totalPackets < gilbertElliottSplitPackets {
+		return visit(roots[0])
+	}
+	for range gilbertElliottSplitLevels {
+		next := make([]gilbertElliottNode, 0, 2*len(roots))
+		for _, root := range roots {
+			delivered, lost := root.children(model)
+			next = append(next, delivered, lost)
+		}
+		roots = next
+	}
+
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	sums := make([]float64, len(roots))
+	var nextRoot atomic.Int64
+	var wg sync.WaitGroup
+	for range min(workers, len(roots)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for root := int(nextRoot.Add(1) - 1); root < len(roots); root = int(nextRoot.Add(1) - 1) {
+				sums[root] = visit(roots[root])
+			}
+		}()
+	}
+	wg.Wait()
+
+	total := 0.0
+	for _, sum := range sums {
+		total += sum
+	}
+	return total
+}
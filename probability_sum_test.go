@@ -0,0 +1,74 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSumProbabilities(t *testing.T) {
+	mask, err := (&GoogleBurstyMaskFactory{}).CreateMask(12, 6)
+	require.NoError(t, err)
+	set := RecoverableSet(mask, PeelingDecoding)
+	model := NewGilbertLossModel(0.5, 0.05, 0.3)
+
+	// Deterministic for every worker count, and equal to the sequential sum up to rounding
+	generic := probabilityOnlyModel{model}
+	expected := SumProbabilities(set, generic, 18, 1)
+	for _, workers := range []int{0, 2, 3, 16, 1000} {
+		assert.Equal(t, expected, SumProbabilities(set, generic, 18, workers), "workers=%d", workers)
+	}
+	assert.InDelta(t, RecoveryProbability(mask, model), expected, 1e-12)
+
+	// Gilbert-Elliott models are summed by ranges, split among the workers the same way for
+	// every worker count
+	ranged := SumProbabilities(set, model, 18, 1)
+	assert.InDelta(t, expected, ranged, 1e-12)
+	for _, workers := range []int{0, 2, 3, 1000} {
+		assert.Equal(t, ranged, SumProbabilities(set, model, 18, workers), "workers=%d", workers)
+	}
+	full := NewReachableSet(1 << 6)
+	for pattern := uint64(0); pattern < full.NumVertices(); pattern++ {
+		full.Add(pattern)
+	}
+	assert.InDelta(t, 1.0, SumProbabilities(full, model, 6, 0), 1e-12)
+
+	assert.Zero(t, SumProbabilities(nil, model, 18, 4))
+	assert.Zero(t, SumProbabilities(NewReachableSet(0), model, 0, 4))
+}
+
+func TestSumProbabilitiesRandomLoss(t *testing.T) {
+	// Summing by number of lost packets equals the sum over the patterns
+	mask, err := (&GoogleRandomMaskFactory{}).CreateMask(10, 5)
+	require.NoError(t, err)
+	full := NewReachableSet(1 << 15)
+	for pattern := uint64(0); pattern < full.NumVertices(); pattern++ {
+		full.Add(pattern)
+	}
+	sets := []*ReachableSet{RecoverableSet(mask, PeelingDecoding), RecoverableSet(mask, MLDecoding), full, NewReachableSet(1 << 15)}
+	for _, p := range []float64{0, 0.01, 0.1, 0.5, 1} {
+		model := NewRandomLossModel(p)
+		for i, set := range sets {
+			assert.InDelta(t, SumProbabilities(set, probabilityOnlyModel{model}, 15, 0), SumProbabilities(set, model, 15, 0), 1e-12, "p=%g, set %d", p, i)
+		}
+		assert.InDelta(t, 1.0, SumProbabilities(full, model, 15, 0), 1e-12, "p=%g", p)
+	}
+}
+
+func BenchmarkSumProbabilities(b *testing.B) {
+	mask, err := (&GoogleRandomMaskFactory{}).CreateMask(12, 8)
+	require.NoError(b, err)
+	set := RecoverableSet(mask, PeelingDecoding)
+
+	b.Run("sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			SumProbabilities(set, NewRandomLossModel(0.1), 20, 1)
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			SumProbabilities(set, NewRandomLossModel(0.1), 20, 0)
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package fecanalysis
+
+import "context"
+
+// ProgressFunc receives the progress of a long computation as done units of work out of
+// total; total is an upper bound when the computation may finish early
+type ProgressFunc func(done, total uint64)
+
+// progressInterval is the number of work units between cancellation checks and progress reports
+const progressInterval = 1 << 16
+
+// progressTracker checks a context for cancellation and reports progress every
+// progressInterval units of work. A nil tracker does neither.
+type progressTracker struct {
+	ctx      context.Context
+	progress ProgressFunc
+	done     uint64
+	reported uint64
+	total    uint64
+}
+
+// newProgressTracker creates a tracker for total units of work; progress may be nil
+func newProgressTracker(ctx context.Context, progress ProgressFunc, total uint64) *progressTracker {
+	return &progressTracker{ctx: ctx, progress: progress, total: total}
+}
+
+// step records one unit of work and returns the context error once it is canceled
+func (t *progressTracker) step() error {
+	if t == nil {
+		return nil
+	}
+	t.done++
+	if t.done%progressInterval != 0 {
+		if t.done == 1 {
+			return t.ctx.Err() // Don't start on an already canceled context
+		}
+		return nil
+	}
+	if t.progress != nil {
+		t.progress(t.done, t.total)
+		t.reported = t.done
+	}
+	return t.ctx.Err()
+}
+
+// finish reports the computation as complete unless the last step already did
+func (t *progressTracker) finish() {
+	if t != nil && t.progress != nil && t.reported != t.total {
+		t.progress(t.total, t.total)
+	}
+}
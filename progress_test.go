@@ -0,0 +1,81 @@
+package fecanalysis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextVariantsMatch(t *testing.T) {
+	mask, err := (&InterleavedMaskFactory{}).CreateMask(6, 3)
+	require.NoError(t, err)
+	graph := NewRecoveryGraph(mask)
+	sources := GoodVertices(6, 3)
+
+	reachable, err := ReachableBFSContext(context.Background(), graph, sources, nil)
+	require.NoError(t, err)
+	assert.Equal(t, ReachableBFS(graph, sources).Vertices(), reachable.Vertices())
+
+	set, err := RecoverableSetContext(context.Background(), mask, PeelingDecoding, nil)
+	require.NoError(t, err)
+	assert.Equal(t, reachable.Vertices(), set.Vertices())
+
+	characteristics, err := CalculateRecoveryCharacteristicsFromSetContext(context.Background(), 6, 3, set, nil)
+	require.NoError(t, err)
+	assert.Equal(t, CalculateRecoveryCharacteristicsFromSet(6, 3, set), characteristics)
+
+	withinDepth, err := RecoverableSetWithinDepthContext(context.Background(), mask, 1, nil)
+	require.NoError(t, err)
+	assert.Equal(t, RecoverableSetWithinDepth(mask, 1).Vertices(), withinDepth.Vertices())
+}
+
+func TestContextCancellation(t *testing.T) {
+	// 2^20 patterns span several cancellation checks
+	mask, err := (&InterleavedMaskFactory{}).CreateMask(14, 6)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = ReachableBFSContext(ctx, NewRecoveryGraph(mask), GoodVertices(14, 6), nil)
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = RecoverableSetContext(ctx, mask, MLDecoding, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = RecoverableSetWithinDepthContext(ctx, mask, 2, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// A mask without FEC fails at the first loss, so only a full sweep would be long enough
+	full := NewReachableSet(1 << 20)
+	for vertex := uint64(0); vertex < full.NumVertices(); vertex++ {
+		full.Add(vertex)
+	}
+	_, err = CalculateRecoveryCharacteristicsFromSetContext(ctx, 14, 6, full, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestProgressReachesTotal(t *testing.T) {
+	mask, err := (&InterleavedMaskFactory{}).CreateMask(12, 6)
+	require.NoError(t, err)
+
+	var reports [][2]uint64
+	_, err = RecoverableSetContext(context.Background(), mask, PeelingDecoding, func(done, total uint64) {
+		reports = append(reports, [2]uint64{done, total})
+	})
+	require.NoError(t, err)
+
+	require.NotEmpty(t, reports)
+	for i := 1; i < len(reports); i++ {
+		assert.Greater(t, reports[i][0], reports[i-1][0])
+	}
+	assert.Equal(t, [2]uint64{1 << 18, 1 << 18}, reports[len(reports)-1])
+
+	// The depth-limited set, which EvaluateMask uses with a max depth, reports its progress too
+	reports = nil
+	_, err = RecoverableSetWithinDepthContext(context.Background(), mask, 2, func(done, total uint64) {
+		reports = append(reports, [2]uint64{done, total})
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, reports)
+	assert.Equal(t, [2]uint64{1 << 18, 1 << 18}, reports[len(reports)-1])
+}
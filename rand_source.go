@@ -0,0 +1,57 @@
+package fecanalysis
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// RandSource is the source of randomness used by all stochastic components
+// (samplers, random mask generators, optimizers). *rand.Rand satisfies it.
+type RandSource interface {
+	// Float64 returns a pseudo-random number in [0.0, 1.0)
+	Float64() float64
+	// Intn returns a pseudo-random number in [0, n)
+	Intn(n int) int
+	// Int63 returns a non-negative pseudo-random 63-bit integer
+	Int63() int64
+	// Perm returns a pseudo-random permutation of the integers [0, n)
+	Perm(n int) []int
+}
+
+// DefaultSeed is the seed used by the package-level random source until SetSeed is called
+const DefaultSeed int64 = 1
+
+var (
+	seedMutex  sync.Mutex
+	seedSource RandSource = NewRandSource(DefaultSeed)
+)
+
+// NewRandSource creates a deterministic random source from the given seed
+func NewRandSource(seed int64) RandSource {
+	return rand.New(rand.NewSource(seed))
+}
+
+// SetSeed reseeds the package-level random source. Every RandSource handed out by
+// NextRandSource afterwards is derived from this seed, so a whole analysis run is
+// reproducible from a single value.
+func SetSeed(seed int64) {
+	seedMutex.Lock()
+	defer seedMutex.Unlock()
+	seedSource = NewRandSource(seed)
+}
+
+// NextRandSource returns a new independent random source derived from the package-level
+// seed. Components that are not given an explicit RandSource call this once on creation.
+func NextRandSource() RandSource {
+	seedMutex.Lock()
+	defer seedMutex.Unlock()
+	return NewRandSource(seedSource.Int63())
+}
+
+// randSourceOrDefault returns rng, or a fresh source derived from the package seed if rng is nil
+func randSourceOrDefault(rng RandSource) RandSource {
+	if rng != nil {
+		return rng
+	}
+	return NextRandSource()
+}
@@ -39,3 +39,29 @@ func (m *RandomLossModel) CalculateProbability(vertex int, N int) float64 {
 func (m *RandomLossModel) GetAverageLossProbability() float64 {
 	return m.P
 }
+
+// LossCountDistribution returns the binomial distribution of the number of lost packets
+func (m *RandomLossModel) LossCountDistribution(N int) []float64 {
+	if N < 0 {
+		return nil
+	}
+
+	distribution := make([]float64, N+1)
+	binomial := 1.0 // C(N, k), updated incrementally
+	for k := 0; k <= N; k++ {
+		distribution[k] = binomial * math.Pow(m.P, float64(k)) * math.Pow(1.0-m.P, float64(N-k))
+		binomial = binomial * float64(N-k) / float64(k+1)
+	}
+	return distribution
+}
+
+// SamplePattern draws a delivery pattern of N packets, each lost independently with probability P
+func (m *RandomLossModel) SamplePattern(N int, rng RandSource) uint64 {
+	pattern := uint64(0)
+	for i := 0; i < N; i++ {
+		if rng.Float64() >= m.P {
+			pattern |= uint64(1) << i
+		}
+	}
+	return pattern
+}
@@ -0,0 +1,68 @@
+package fecanalysis
+
+import "fmt"
+
+// RandomMaskFactory generates random masks for arbitrary N and K, independent of the
+// WebRTC tables, as baselines and starting points for mask optimizers. Each FEC packet
+// protects RowWeight media packets chosen uniformly at random. Masks are reproducible:
+// the same Seed, N and K always produce the same mask.
+type RandomMaskFactory struct {
+	RowWeight int   // media packets protected by each FEC packet; 0 means half of N, rounded up
+	Seed      int64 // seed of the generator
+}
+
+// CreateMask creates a random mask with N media packets and K FEC packets
+func (f *RandomMaskFactory) CreateMask(N, K int) (Mask, error) {
+	if N <= 0 || K <= 0 {
+		return nil, fmt.Errorf("invalid parameters for random mask: N=%d, K=%d", N, K)
+	}
+
+	rowWeight := f.RowWeight
+	if rowWeight == 0 {
+		rowWeight = (N + 1) / 2
+	}
+
+	// Derive the generator from (Seed, N, K) so masks don't depend on creation order
+	rng := NewRandSource(f.Seed*1_000_003 + int64(N)*1_009 + int64(K))
+	return RandomMask(N, K, rowWeight, rng)
+}
+
+// RandomMask draws a mask where each FEC packet protects rowWeight distinct media packets
+// chosen at random. Packets not yet protected by earlier rows are picked first, so every
+// media packet is protected whenever K*rowWeight >= N.
+func RandomMask(N, K, rowWeight int, rng RandSource) (*MatrixMask, error) {
+	if N <= 0 || K <= 0 {
+		return nil, fmt.Errorf("invalid parameters for random mask: N=%d, K=%d", N, K)
+	}
+	if rowWeight <= 0 || rowWeight > N {
+		return nil, fmt.Errorf("row weight must be in 1..%d, got %d", N, rowWeight)
+	}
+	rng = randSourceOrDefault(rng)
+
+	rows := make([][]bool, K)
+	covered := make([]bool, N)
+	for fecIndex := range rows {
+		rows[fecIndex] = make([]bool, N)
+
+		// Random order with uncovered packets first
+		order := rng.Perm(N)
+		candidates := make([]int, 0, N)
+		for _, packetIndex := range order {
+			if !covered[packetIndex] {
+				candidates = append(candidates, packetIndex)
+			}
+		}
+		for _, packetIndex := range order {
+			if covered[packetIndex] {
+				candidates = append(candidates, packetIndex)
+			}
+		}
+
+		for _, packetIndex := range candidates[:rowWeight] {
+			rows[fecIndex][packetIndex] = true
+			covered[packetIndex] = true
+		}
+	}
+
+	return NewMatrixMask(rows)
+}
@@ -0,0 +1,57 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRandomMaskFactoryRowWeight(t *testing.T) {
+	factory := &RandomMaskFactory{RowWeight: 3, Seed: 42}
+
+	for N := 3; N <= 10; N++ {
+		for K := 1; K <= N; K++ {
+			mask, err := factory.CreateMask(N, K)
+			require.NoError(t, err)
+			assert.Equal(t, N, mask.N())
+			assert.Equal(t, K, mask.K())
+
+			covered := 0
+			for fecIndex := 0; fecIndex < K; fecIndex++ {
+				weight := 0
+				for packetIndex := 0; packetIndex < N; packetIndex++ {
+					if mask.IsProtected(packetIndex, fecIndex) {
+						weight++
+						covered |= 1 << packetIndex
+					}
+				}
+				assert.Equal(t, 3, weight, "N=%d K=%d row %d", N, K, fecIndex)
+			}
+			if K*3 >= N {
+				assert.Equal(t, (1<<N)-1, covered, "N=%d K=%d should protect every packet", N, K)
+			}
+		}
+	}
+}
+
+func TestRandomMaskFactoryReproducible(t *testing.T) {
+	a, err := (&RandomMaskFactory{Seed: 7}).CreateMask(10, 4)
+	require.NoError(t, err)
+	b, err := (&RandomMaskFactory{Seed: 7}).CreateMask(10, 4)
+	require.NoError(t, err)
+	c, err := (&RandomMaskFactory{Seed: 8}).CreateMask(10, 4)
+	require.NoError(t, err)
+
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, c)
+}
+
+func TestRandomMaskValidation(t *testing.T) {
+	_, err := RandomMask(4, 2, 5, NewRandSource(1))
+	assert.Error(t, err)
+	_, err = RandomMask(4, 2, 0, NewRandSource(1))
+	assert.Error(t, err)
+	_, err = (&RandomMaskFactory{}).CreateMask(0, 1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,164 @@
+package fecanalysis
+
+import "math/bits"
+
+// ReachableSet is a set of graph vertices stored as a bitset, one bit per vertex. For N+K=24
+// it takes 2 MiB where a slice of vertex indices takes up to 128 MiB.
+type ReachableSet struct {
+	words       []uint64
+	numVertices uint64
+	count       uint64
+}
+
+// NewReachableSet creates an empty set for the vertices 0..numVertices-1
+func NewReachableSet(numVertices uint64) *ReachableSet {
+	return &ReachableSet{
+		words:       make([]uint64, (numVertices+63)/64),
+		numVertices: numVertices,
+	}
+}
+
+// NumVertices returns the number of vertices the set ranges over
+func (s *ReachableSet) NumVertices() uint64 {
+	if s == nil {
+		return 0
+	}
+	return s.numVertices
+}
+
+// Add inserts the vertex and returns true if it was not in the set yet
+func (s *ReachableSet) Add(vertex uint64) bool {
+	word, bit := vertex/64, uint64(1)<<(vertex%64)
+	if s.words[word]&bit != 0 {
+		return false
+	}
+	s.words[word] |= bit
+	s.count++
+	return true
+}
+
+// Contains returns true if the vertex is in the set; vertices out of range and a nil set
+// contain nothing
+func (s *ReachableSet) Contains(vertex uint64) bool {
+	if s == nil || vertex >= s.numVertices {
+		return false
+	}
+	return s.words[vertex/64]&(1<<(vertex%64)) != 0
+}
+
+// MarkReachable adds the vertex, so that a set is a VisitedMarker
+func (s *ReachableSet) MarkReachable(vertex uint64) {
+	s.Add(vertex)
+}
+
+// IsReachable is Contains, so that a set is a VisitedMarker
+func (s *ReachableSet) IsReachable(vertex uint64) bool {
+	return s.Contains(vertex)
+}
+
+// Count returns the number of vertices in the set
+func (s *ReachableSet) Count() uint64 {
+	if s == nil {
+		return 0
+	}
+	return s.count
+}
+
+// Iterate calls fn for every vertex in the set in ascending order
+func (s *ReachableSet) Iterate(fn func(vertex uint64)) {
+	if s == nil {
+		return
+	}
+	s.iterateWords(0, len(s.words), fn)
+}
+
+// iterateWords calls fn for every vertex stored in the words [first, end) in ascending order
+func (s *ReachableSet) iterateWords(first, end int, fn func(vertex uint64)) {
+	for w := first; w < end; w++ {
+		for word := s.words[w]; word != 0; word &= word - 1 {
+			fn(uint64(w)*64 + uint64(bits.TrailingZeros64(word)))
+		}
+	}
+}
+
+// rangeState reports whether the set holds none or all of the vertices first..end-1
+func (s *ReachableSet) rangeState(first, end uint64) (none, all bool) {
+	none, all = true, true
+	for first < end {
+		offset := first % 64
+		length := min(64-offset, end-first)
+		bitMask := ^uint64(0) >> (64 - length) << offset
+		held := s.words[first/64] & bitMask
+		none = none && held == 0
+		all = all && held == bitMask
+		if !none && !all {
+			return false, false
+		}
+		first += length
+	}
+	return none, all
+}
+
+// Vertices returns the vertices of the set in ascending order
+func (s *ReachableSet) Vertices() []uint64 {
+	vertices := make([]uint64, 0, s.Count())
+	s.Iterate(func(vertex uint64) {
+		vertices = append(vertices, vertex)
+	})
+	return vertices
+}
+
+// Intersect removes every vertex that is not in other
+func (s *ReachableSet) Intersect(other *ReachableSet) {
+	s.count = 0
+	for w := range s.words {
+		if other != nil && w < len(other.words) {
+			s.words[w] &= other.words[w]
+		} else {
+			s.words[w] = 0
+		}
+		s.count += uint64(bits.OnesCount64(s.words[w]))
+	}
+}
+
+// Clear removes every vertex from the set; an empty set is left untouched, so clearing it does
+// not dirty the pages of a memory-mapped bitset
+func (s *ReachableSet) Clear() {
+	if s.count == 0 {
+		return
+	}
+	clear(s.words)
+	s.count = 0
+}
+
+// Complement returns a new set of the vertices that are not in the set
+func (s *ReachableSet) Complement() *ReachableSet {
+	complement := NewReachableSet(s.numVertices)
+	for w, word := range s.words {
+		complement.words[w] = ^word
+	}
+	if tail := s.numVertices % 64; tail != 0 {
+		complement.words[len(complement.words)-1] &= uint64(1)<<tail - 1
+	}
+	complement.count = s.numVertices - s.count
+	return complement
+}
+
+// Clone returns a copy of the set
+func (s *ReachableSet) Clone() *ReachableSet {
+	return &ReachableSet{words: append([]uint64(nil), s.words...), numVertices: s.numVertices, count: s.count}
+}
+
+// Subtract removes every vertex that is in other
+func (s *ReachableSet) Subtract(other *ReachableSet) {
+	if other == nil {
+		return
+	}
+	s.count = 0
+	for w := range s.words {
+		if w < len(other.words) {
+			s.words[w] &^= other.words[w]
+		}
+		s.count += uint64(bits.OnesCount64(s.words[w]))
+	}
+}
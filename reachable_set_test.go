@@ -0,0 +1,71 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reachableSetFromMap converts a vertex -> reachable map over totalPackets packets to a ReachableSet
+func reachableSetFromMap(totalPackets int, vertices map[int]bool) *ReachableSet {
+	set := NewReachableSet(1 << totalPackets)
+	for vertex, reachable := range vertices {
+		if reachable {
+			set.Add(uint64(vertex))
+		}
+	}
+	return set
+}
+
+func TestReachableSet(t *testing.T) {
+	set := NewReachableSet(130)
+	assert.True(t, set.Add(129))
+	assert.True(t, set.Add(3))
+	assert.True(t, set.Add(64))
+	assert.False(t, set.Add(3), "duplicates are not added twice")
+
+	assert.Equal(t, uint64(3), set.Count())
+	assert.True(t, set.Contains(64))
+	assert.False(t, set.Contains(65))
+	assert.False(t, set.Contains(130))
+	assert.Equal(t, []uint64{3, 64, 129}, set.Vertices())
+
+	other := NewReachableSet(130)
+	other.Add(64)
+	other.Add(5)
+	clone := set.Clone()
+	set.Intersect(other)
+	assert.Equal(t, []uint64{64}, set.Vertices())
+	assert.Equal(t, uint64(1), set.Count())
+	assert.Equal(t, []uint64{3, 64, 129}, clone.Vertices(), "clones do not share words")
+	clone.Subtract(other)
+	assert.Equal(t, []uint64{3, 129}, clone.Vertices())
+	assert.Equal(t, uint64(2), clone.Count())
+	complement := clone.Complement()
+	assert.Equal(t, uint64(128), complement.Count())
+	assert.False(t, complement.Contains(3))
+	assert.True(t, complement.Contains(4))
+	assert.Len(t, complement.Vertices(), 128)
+	clone.Clear()
+	assert.Empty(t, clone.Vertices())
+	assert.Zero(t, clone.Count())
+
+	var empty *ReachableSet
+	assert.False(t, empty.Contains(0))
+	assert.Zero(t, empty.Count())
+	assert.Empty(t, empty.Vertices())
+}
+
+func TestReachableBFSMatchesBFS(t *testing.T) {
+	mask, err := (&GoogleBurstyMaskFactory{}).CreateMask(6, 3)
+	require.NoError(t, err)
+	graph := NewRecoveryGraph(mask)
+	reachable := ReachableBFS(graph, GoodVertices(6, 3))
+	vertices := BFS(graph, GoodVertices(6, 3))
+
+	assert.Equal(t, uint64(len(vertices)), reachable.Count())
+	assert.Equal(t, vertices, reachable.Vertices())
+	assert.Equal(t, CalculateRecoveryCharacteristicsFromReachable(6, 3, vertices),
+		CalculateRecoveryCharacteristicsFromSet(6, 3, reachable))
+}
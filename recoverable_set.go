@@ -0,0 +1,190 @@
+package fecanalysis
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// RecoverableSet returns the delivery patterns from which a receiver using the given decoding
+// mode recovers every media packet, the same set as ReachableBFS from GoodVertices on the
+// recovery graph but without building or walking the graph.
+//
+// Recoverability is monotone in the delivered packets, so the set is computed as a downward
+// closure: patterns are visited in decreasing numeric order, which visits every pattern after
+// all of its supersets, and a pattern with a missing media packet is recoverable only if
+// delivering that packet keeps it recoverable. With peeling decoding that check is complete:
+// a pattern is recoverable exactly when some delivered FEC packet misses a single media
+// packet whose delivery leads to a recoverable pattern. With ML decoding it prunes the
+// Gaussian elimination to the candidates that can still be decodable.
+func RecoverableSet(mask Mask, mode DecodingMode) *ReachableSet {
+	set, _ := recoverableSetTracked(mask, mode, nil)
+	return set
+}
+
+// RecoverableSetContext is RecoverableSet that stops with the context error once ctx is
+// canceled and reports the visited patterns out of 2^(N+K) to progress, which may be nil
+func RecoverableSetContext(ctx context.Context, mask Mask, mode DecodingMode, progress ProgressFunc) (*ReachableSet, error) {
+	tracker := newProgressTracker(ctx, progress, uint64(1)<<(mask.N()+mask.K()))
+	set, err := recoverableSetTracked(mask, mode, tracker)
+	if err != nil {
+		return nil, err
+	}
+	tracker.finish()
+	return set, nil
+}
+
+// RecoverableSetInto is RecoverableSetContext that adds the recoverable patterns to an empty set
+// over 2^(N+K) vertices, such as the Set of a DiskVisitedMarker. Unlike ReachableBFS it needs no
+// memory beyond the set, so it reaches N+K where even the bitset does not fit in RAM.
+func RecoverableSetInto(ctx context.Context, mask Mask, mode DecodingMode, set *ReachableSet, progress ProgressFunc) error {
+	numVertices := uint64(1) << (mask.N() + mask.K())
+	if set.NumVertices() != numVertices || set.Count() != 0 {
+		return fmt.Errorf("recoverable set needs an empty set of %d vertices, got %d vertices with %d set", numVertices, set.NumVertices(), set.Count())
+	}
+	tracker := newProgressTracker(ctx, progress, numVertices)
+	if err := fillRecoverableSet(mask, mode, set, tracker); err != nil {
+		return err
+	}
+	tracker.finish()
+	return nil
+}
+
+// recoverableSetTracked computes RecoverableSet, counting every pattern as a unit of work
+func recoverableSetTracked(mask Mask, mode DecodingMode, tracker *progressTracker) (*ReachableSet, error) {
+	set := NewReachableSet(uint64(1) << (mask.N() + mask.K()))
+	if err := fillRecoverableSet(mask, mode, set, tracker); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// fillRecoverableSet adds the recoverable patterns to the empty set
+func fillRecoverableSet(mask Mask, mode DecodingMode, set *ReachableSet, tracker *progressTracker) error {
+	N := mask.N()
+	rows := patternRows(mask)
+
+	for next := set.NumVertices(); next > 0; next-- {
+		if err := tracker.step(); err != nil {
+			return err
+		}
+		pattern := next - 1
+		if recoverableGivenSupersets(rows, N, pattern, set, mode) {
+			set.Add(pattern)
+		}
+	}
+	return nil
+}
+
+// ExtendRecoverableSet computes the recoverable set of a mask from the recoverable set of the
+// same mask without its last FEC row, for optimizers that grow masks row by row. Patterns
+// that lose the new FEC packet keep their previous recoverability, as do recoverable
+// patterns that deliver it, so only the previously unrecoverable patterns delivering it are
+// decoded. previous must be the RecoverableSet of the first K-1 rows in the same mode.
+func ExtendRecoverableSet(mask Mask, previous *ReachableSet, mode DecodingMode) (*ReachableSet, error) {
+	N, K := mask.N(), mask.K()
+	if K == 0 {
+		return nil, fmt.Errorf("mask has no FEC row to add")
+	}
+	newFEC := uint64(1) << (N + K - 1)
+	if previous.NumVertices() != newFEC {
+		return nil, fmt.Errorf("previous set ranges over %d patterns, expected %d for N=%d, K=%d", previous.NumVertices(), newFEC, N, K-1)
+	}
+
+	set := NewReachableSet(newFEC << 1)
+	previous.Iterate(func(pattern uint64) {
+		set.Add(pattern)
+		set.Add(pattern | newFEC)
+	})
+
+	rows := patternRows(mask)
+	for next := newFEC; next > 0; next-- {
+		pattern := (next - 1) | newFEC
+		if !set.Contains(pattern) && recoverableGivenSupersets(rows, N, pattern, set, mode) {
+			set.Add(pattern)
+		}
+	}
+	return set, nil
+}
+
+// recoverableGivenSupersets decides whether the pattern is recoverable given the
+// recoverability of all of its supersets, which must already be in the set
+func recoverableGivenSupersets(rows []uint64, N int, pattern uint64, set *ReachableSet, mode DecodingMode) bool {
+	missing := (uint64(1)<<N - 1) &^ pattern
+	if missing == 0 {
+		return true
+	}
+
+	// Delivering a missing packet never hurts, so a pattern is unrecoverable as soon as
+	// one of its supersets is
+	lowest := missing & -missing
+	if !set.Contains(pattern | lowest) {
+		return false
+	}
+	if mode == MLDecoding {
+		return solvableByElimination(rows, N, pattern)
+	}
+
+	for fecIndex, row := range rows {
+		if pattern&(uint64(1)<<(N+fecIndex)) == 0 {
+			continue // FEC packet not delivered
+		}
+		recovered := row & missing
+		if recovered != 0 && recovered&(recovered-1) == 0 && set.Contains(pattern|recovered) {
+			return true
+		}
+	}
+	return false
+}
+
+// RecoverableSetWithinDepth returns the delivery patterns that peeling recovers within depth
+// rounds of parallel repairs (see RecoveryDepth), a proxy for the playout deadline of a
+// real-time receiver that can't run unbounded cascaded recovery. A round only adds packets,
+// so patterns are visited in decreasing numeric order and the depth of a pattern is one more
+// than the depth of the pattern after its first round.
+func RecoverableSetWithinDepth(mask Mask, depth int) *ReachableSet {
+	set, _ := recoverableSetWithinDepthTracked(mask, depth, nil)
+	return set
+}
+
+// RecoverableSetWithinDepthContext is RecoverableSetWithinDepth that stops with the context
+// error once ctx is canceled and reports the visited patterns out of 2^(N+K) to progress,
+// which may be nil
+func RecoverableSetWithinDepthContext(ctx context.Context, mask Mask, depth int, progress ProgressFunc) (*ReachableSet, error) {
+	tracker := newProgressTracker(ctx, progress, uint64(1)<<(mask.N()+mask.K()))
+	set, err := recoverableSetWithinDepthTracked(mask, depth, tracker)
+	if err != nil {
+		return nil, err
+	}
+	tracker.finish()
+	return set, nil
+}
+
+// recoverableSetWithinDepthTracked computes RecoverableSetWithinDepth, counting every pattern
+// as a unit of work
+func recoverableSetWithinDepthTracked(mask Mask, depth int, tracker *progressTracker) (*ReachableSet, error) {
+	N, K := mask.N(), mask.K()
+	rows := patternRows(mask)
+	allMedia := uint64(1)<<N - 1
+	set := NewReachableSet(uint64(1) << (N + K))
+
+	// Depth of every pattern so far, unreachableDepth if beyond the limit or unrecoverable
+	const unreachableDepth = math.MaxUint8
+	depths := make([]uint8, set.NumVertices())
+	for next := set.NumVertices(); next > 0; next-- {
+		if err := tracker.step(); err != nil {
+			return nil, err
+		}
+		pattern := next - 1
+		depths[pattern] = unreachableDepth
+		if pattern&allMedia == allMedia {
+			depths[pattern] = 0
+		} else if recovered := peelingRound(rows, N, pattern); recovered != pattern && depths[recovered] != unreachableDepth && int(depths[recovered]) < depth {
+			depths[pattern] = depths[recovered] + 1
+		}
+		if depths[pattern] != unreachableDepth {
+			set.Add(pattern)
+		}
+	}
+	return set, nil
+}
@@ -0,0 +1,175 @@
+package fecanalysis
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/bits"
+	"os"
+	"path/filepath"
+)
+
+// recoverableSetCacheVersion identifies the engine that computed cached sets; bump it whenever
+// a change to the decoders can change a recoverable set so that stale entries are ignored
+const recoverableSetCacheVersion = 1
+
+// RecoverableSetCache is an on-disk store of recoverable sets, one gzip-compressed bitset file
+// per mask and decoding mode in a directory, so repeated runs over the same masks skip the
+// closure. Entries are keyed by the exact rows of the mask rather than its Fingerprint, since
+// permuting the FEC packets permutes the bits of the delivery patterns.
+type RecoverableSetCache struct {
+	dir string
+
+	// Warn receives the errors CachedRecoverableSet recovers from, damaged entries and failed
+	// stores, which only cost a recomputation next time; nil ignores them
+	Warn func(err error)
+}
+
+// NewRecoverableSetCache opens the cache in dir, creating the directory if needed
+func NewRecoverableSetCache(dir string) (*RecoverableSetCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %v", err)
+	}
+	return &RecoverableSetCache{dir: dir}, nil
+}
+
+// Load returns the cached recoverable set of the mask, or false if there is no entry. A
+// damaged entry is reported as an error.
+func (c *RecoverableSetCache) Load(mask Mask, mode DecodingMode) (*ReachableSet, bool, error) {
+	file, err := os.Open(c.path(mask, mode))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	defer file.Close()
+
+	set, err := readReachableSet(file, uint64(1)<<(mask.N()+mask.K()))
+	if err != nil {
+		return nil, false, fmt.Errorf("damaged cache entry %s: %v", file.Name(), err)
+	}
+	return set, true, nil
+}
+
+// Store writes the recoverable set of the mask to the cache, replacing any existing entry.
+// The entry is written to a temporary file and renamed, so concurrent runs never see a
+// partial file.
+func (c *RecoverableSetCache) Store(mask Mask, mode DecodingMode, set *ReachableSet) error {
+	if set.NumVertices() != uint64(1)<<(mask.N()+mask.K()) {
+		return fmt.Errorf("set of %d vertices does not match a %dx%d mask", set.NumVertices(), mask.N(), mask.K())
+	}
+
+	file, err := os.CreateTemp(c.dir, "recoverable-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if err := writeReachableSet(file, set); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), c.path(mask, mode))
+}
+
+// path returns the entry file of the mask: the hash of the cache version, the decoding mode
+// and the mask rows
+func (c *RecoverableSetCache) path(mask Mask, mode DecodingMode) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "v%d %s %dx%d", recoverableSetCacheVersion, mode, mask.N(), mask.K())
+	for _, row := range patternRows(mask) {
+		binary.Write(hash, binary.LittleEndian, row)
+	}
+	return filepath.Join(c.dir, fmt.Sprintf("%dx%d-%x.bin.gz", mask.N(), mask.K(), hash.Sum(nil)[:16]))
+}
+
+// CachedRecoverableSet returns the recoverable set of the mask from the cache, computing it
+// with RecoverableSetContext and storing it on a miss. The cache is optional: a damaged entry
+// counts as a miss and is overwritten, and a failed store still returns the computed set; both
+// are reported to cache.Warn. A nil cache always computes the set.
+func CachedRecoverableSet(ctx context.Context, cache *RecoverableSetCache, mask Mask, mode DecodingMode, progress ProgressFunc) (*ReachableSet, error) {
+	if cache != nil {
+		set, ok, err := cache.Load(mask, mode)
+		if err != nil {
+			cache.warn(err)
+		} else if ok {
+			return set, nil
+		}
+	}
+
+	set, err := RecoverableSetContext(ctx, mask, mode, progress)
+	if err != nil {
+		return nil, err
+	}
+	if cache != nil {
+		if err := cache.Store(mask, mode, set); err != nil {
+			cache.warn(fmt.Errorf("failed to cache recoverable set: %v", err))
+		}
+	}
+	return set, nil
+}
+
+// warn passes the error to Warn if it is set
+func (c *RecoverableSetCache) warn(err error) {
+	if c.Warn != nil {
+		c.Warn(err)
+	}
+}
+
+// writeReachableSet writes the number of vertices, the count and the bitset words, all little
+// endian, gzip-compressed
+func writeReachableSet(w io.Writer, set *ReachableSet) error {
+	compressed := gzip.NewWriter(w)
+	buffered := bufio.NewWriter(compressed)
+	if err := binary.Write(buffered, binary.LittleEndian, [2]uint64{set.numVertices, set.count}); err != nil {
+		return err
+	}
+	if err := binary.Write(buffered, binary.LittleEndian, set.words); err != nil {
+		return err
+	}
+	if err := buffered.Flush(); err != nil {
+		return err
+	}
+	return compressed.Close()
+}
+
+// readReachableSet reads a set written by writeReachableSet, checking that it ranges over
+// numVertices vertices and that its count matches its bits
+func readReachableSet(r io.Reader, numVertices uint64) (*ReachableSet, error) {
+	compressed, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer compressed.Close()
+	buffered := bufio.NewReader(compressed)
+
+	var header [2]uint64
+	if err := binary.Read(buffered, binary.LittleEndian, &header); err != nil {
+		return nil, err
+	}
+	if header[0] != numVertices {
+		return nil, fmt.Errorf("set of %d vertices, expected %d", header[0], numVertices)
+	}
+
+	set := NewReachableSet(numVertices)
+	if err := binary.Read(buffered, binary.LittleEndian, set.words); err != nil {
+		return nil, err
+	}
+	for _, word := range set.words {
+		set.count += uint64(bits.OnesCount64(word))
+	}
+	if set.count != header[1] {
+		return nil, fmt.Errorf("set has %d vertices, header says %d", set.count, header[1])
+	}
+	return set, nil
+}
@@ -0,0 +1,93 @@
+package fecanalysis
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoverableSetCache(t *testing.T) {
+	cache, err := NewRecoverableSetCache(filepath.Join(t.TempDir(), "cache"))
+	require.NoError(t, err)
+	mask, err := (&GoogleBurstyMaskFactory{}).CreateMask(8, 4)
+	require.NoError(t, err)
+
+	_, ok, err := cache.Load(mask, PeelingDecoding)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	expected := RecoverableSet(mask, PeelingDecoding)
+	require.NoError(t, cache.Store(mask, PeelingDecoding, expected))
+	loaded, ok, err := cache.Load(mask, PeelingDecoding)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, expected.Count(), loaded.Count())
+	assert.Equal(t, expected.Vertices(), loaded.Vertices())
+
+	// Other decoding modes and row orders are different entries
+	_, ok, err = cache.Load(mask, MLDecoding)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	rows := maskMatrix(mask)
+	rows[0], rows[1] = rows[1], rows[0]
+	swapped, err := NewMatrixMask(rows)
+	require.NoError(t, err)
+	_, ok, err = cache.Load(swapped, PeelingDecoding)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	other, err := (&GoogleBurstyMaskFactory{}).CreateMask(6, 3)
+	require.NoError(t, err)
+	assert.Error(t, cache.Store(other, PeelingDecoding, expected))
+}
+
+func TestCachedRecoverableSet(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewRecoverableSetCache(dir)
+	require.NoError(t, err)
+	mask, err := (&GoogleRandomMaskFactory{}).CreateMask(7, 3)
+	require.NoError(t, err)
+	expected := RecoverableSet(mask, MLDecoding).Vertices()
+	var warnings []error
+	cache.Warn = func(err error) { warnings = append(warnings, err) }
+
+	set, err := CachedRecoverableSet(context.Background(), cache, mask, MLDecoding, nil)
+	require.NoError(t, err)
+	assert.Equal(t, expected, set.Vertices())
+	_, ok, err := cache.Load(mask, MLDecoding)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	assert.Empty(t, warnings)
+
+	// A damaged entry is an error for Load and a warning for CachedRecoverableSet, which
+	// recomputes it
+	entries, err := filepath.Glob(filepath.Join(dir, "*.bin.gz"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.NoError(t, os.WriteFile(entries[0], []byte("damaged"), 0o644))
+	_, _, err = cache.Load(mask, MLDecoding)
+	assert.Error(t, err)
+	set, err = CachedRecoverableSet(context.Background(), cache, mask, MLDecoding, nil)
+	require.NoError(t, err)
+	assert.Equal(t, expected, set.Vertices())
+	_, ok, err = cache.Load(mask, MLDecoding)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Len(t, warnings, 1)
+
+	// A failed store still returns the computed set
+	require.NoError(t, os.RemoveAll(dir))
+	set, err = CachedRecoverableSet(context.Background(), cache, mask, MLDecoding, nil)
+	require.NoError(t, err)
+	assert.Equal(t, expected, set.Vertices())
+	assert.Len(t, warnings, 2)
+
+	set, err = CachedRecoverableSet(context.Background(), nil, mask, MLDecoding, nil)
+	require.NoError(t, err)
+	assert.Equal(t, expected, set.Vertices())
+}
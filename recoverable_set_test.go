@@ -0,0 +1,88 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoverableSetMatchesGraphBFS(t *testing.T) {
+	factories := map[string]MaskFactory{
+		"bursty":      &GoogleBurstyMaskFactory{},
+		"random":      &GoogleRandomMaskFactory{},
+		"interleaved": &InterleavedMaskFactory{},
+		"ldpc":        &LDPCMaskFactory{Seed: 1},
+	}
+
+	for name, factory := range factories {
+		for _, config := range []struct{ N, K int }{{4, 3}, {6, 3}, {7, 3}, {8, 4}} {
+			mask, err := factory.CreateMask(config.N, config.K)
+			require.NoError(t, err)
+			for _, mode := range []DecodingMode{PeelingDecoding, MLDecoding} {
+				graph := NewRecoveryGraphWithMode(mask, mode)
+				expected := ReachableBFS(graph, GoodVertices(config.N, config.K))
+				actual := RecoverableSet(mask, mode)
+				assert.Equal(t, expected.Vertices(), actual.Vertices(), "%s N=%d K=%d %s", name, config.N, config.K, mode)
+			}
+		}
+	}
+}
+
+func TestExtendRecoverableSet(t *testing.T) {
+	for _, factory := range []MaskFactory{&GoogleRandomMaskFactory{}, &LDPCMaskFactory{Seed: 2}} {
+		mask, err := factory.CreateMask(7, 4)
+		require.NoError(t, err)
+		for _, mode := range []DecodingMode{PeelingDecoding, MLDecoding} {
+			first, err := Subset(mask, []int{0})
+			require.NoError(t, err)
+			set := RecoverableSet(first, mode)
+
+			// Grow the mask one row at a time and compare with recomputing from scratch
+			for K := 2; K <= mask.K(); K++ {
+				grown, err := Subset(mask, []int{0, 1, 2, 3}[:K])
+				require.NoError(t, err)
+				set, err = ExtendRecoverableSet(grown, set, mode)
+				require.NoError(t, err)
+				assert.Equal(t, RecoverableSet(grown, mode).Vertices(), set.Vertices(), "K=%d %s", K, mode)
+			}
+		}
+	}
+
+	mask, err := (&InterleavedMaskFactory{}).CreateMask(4, 2)
+	require.NoError(t, err)
+	_, err = ExtendRecoverableSet(mask, RecoverableSet(mask, PeelingDecoding), PeelingDecoding)
+	assert.Error(t, err)
+}
+
+func TestRecoverableSetWithinDepth(t *testing.T) {
+	mask, err := (&GoogleBurstyMaskFactory{}).CreateMask(8, 4)
+	require.NoError(t, err)
+
+	for depth := 0; depth <= 3; depth++ {
+		set := RecoverableSetWithinDepth(mask, depth)
+		for pattern := uint64(0); pattern < set.NumVertices(); pattern++ {
+			patternDepth := RecoveryDepth(mask, pattern)
+			require.Equal(t, patternDepth >= 0 && patternDepth <= depth, set.Contains(pattern), "depth %d pattern %012b", depth, pattern)
+		}
+	}
+
+	// Every recoverable pattern of 8 media packets is recovered within 8 rounds
+	assert.Equal(t, RecoverableSet(mask, PeelingDecoding).Vertices(), RecoverableSetWithinDepth(mask, 8).Vertices())
+}
+
+func BenchmarkRecoverableSet(b *testing.B) {
+	mask, err := (&GoogleRandomMaskFactory{}).CreateMask(12, 6)
+	require.NoError(b, err)
+
+	b.Run("closure", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			RecoverableSet(mask, PeelingDecoding)
+		}
+	})
+	b.Run("graph", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ReachableBFS(NewRecoveryGraph(mask), GoodVertices(12, 6))
+		}
+	})
+}
@@ -1,81 +1,301 @@
 package fecanalysis
 
-// RecoveryCharacteristics holds the key recovery metrics for a FEC mask
+import (
+	"context"
+	"fmt"
+	"math/bits"
+)
+
+// PerfectRecovery is the value of the minimum loss counts of RecoveryCharacteristics when no
+// loss pattern of that kind results in non-recovery; it is encoded as "infinite" in JSON
+const PerfectRecovery = -1
+
+// RecoveryCharacteristics holds the key recovery metrics for a FEC mask. The minimum loss
+// counts are PerfectRecovery when no pattern of their kind fails. They are minima over
+// every delivery pattern, which sampling can't estimate, so they carry no standard errors
+// and sampled evaluations (EvaluationOptions.Samples) have none.
 type RecoveryCharacteristics struct {
-	MinLostPacketsForNonRecovery     int // Minimum number of lost packets that results in non-recovery
-	MinConsecutiveLostForNonRecovery int // Minimum number of consecutive lost packets that results in non-recovery
+	MinLostPacketsForNonRecovery     LossCount `json:"min_lost_packets_for_non_recovery"`     // Minimum number of lost packets that results in non-recovery
+	MinConsecutiveLostForNonRecovery LossCount `json:"min_consecutive_lost_for_non_recovery"` // Minimum number of consecutive lost packets in wire order (media packets, then FEC packets) that results in non-recovery
+	MinMediaBurstForNonRecovery      LossCount `json:"min_media_burst_for_non_recovery"`      // Minimum number of consecutive lost media packets that results in non-recovery with every FEC packet delivered
+	MinFECBurstForNonRecovery        LossCount `json:"min_fec_burst_for_non_recovery"`        // Minimum number of consecutive lost FEC packets that results in non-recovery together with a single lost media packet
+	MaxGuaranteedLosses              int       `json:"max_guaranteed_losses"`                 // Largest t such that every pattern with at most t lost packets is recovered
 }
 
-// CalculateRecoveryCharacteristicsFromReachable computes the recovery characteristics using existing BFS results
-func CalculateRecoveryCharacteristicsFromReachable(N, K int, reachable []int) RecoveryCharacteristics {
-	totalPackets := N + K
+// CalculateRecoveryCharacteristics computes the recoverable set of the mask and its recovery characteristics
+func CalculateRecoveryCharacteristics(mask Mask) RecoveryCharacteristics {
+	return CalculateRecoveryCharacteristicsFromSet(mask.N(), mask.K(), recoverableSet(mask))
+}
 
+// CalculateRecoveryCharacteristicsFromReachable computes the recovery characteristics using existing BFS results
+func CalculateRecoveryCharacteristicsFromReachable(N, K int, reachable []uint64) RecoveryCharacteristics {
 	// Convert reachable slice to set for faster lookup
-	reachableSet := make(map[int]bool)
+	reachableSet := NewReachableSet(uint64(1) << (N + K))
 	for _, v := range reachable {
-		reachableSet[v] = true
+		if v < reachableSet.NumVertices() {
+			reachableSet.Add(v)
+		}
+	}
+	return CalculateRecoveryCharacteristicsFromSet(N, K, reachableSet)
+}
+
+// CalculateRecoveryCharacteristicsFromSet computes the recovery characteristics using an existing ReachableBFS result
+func CalculateRecoveryCharacteristicsFromSet(N, K int, reachableSet *ReachableSet) RecoveryCharacteristics {
+	characteristics, _, _ := calculateRecoveryCharacteristics(N, K, reachableSet, nil)
+	return characteristics
+}
+
+// CalculateRecoveryCharacteristicsFromSetContext is CalculateRecoveryCharacteristicsFromSet that
+// stops with the context error once ctx is canceled and reports the checked loss patterns to
+// progress, which may be nil
+func CalculateRecoveryCharacteristicsFromSetContext(ctx context.Context, N, K int, reachableSet *ReachableSet, progress ProgressFunc) (RecoveryCharacteristics, error) {
+	runs := func(length int) uint64 { return uint64(length * (length + 1) / 2) }
+	// Every loss pattern once, plus every run of consecutive losses in wire order, among the
+	// media packets and among the FEC packets
+	tracker := newProgressTracker(ctx, progress, uint64(1)<<(N+K)+runs(N+K)+runs(N)+runs(K))
+	characteristics, _, err := calculateRecoveryCharacteristics(N, K, reachableSet, tracker)
+	if err != nil {
+		return RecoveryCharacteristics{}, err
+	}
+	tracker.finish()
+	return characteristics, nil
+}
+
+// RecoveryProfile returns, for every k in 0..N+K, the fraction of the patterns with k lost
+// packets that peeling recovers. MinLostPacketsForNonRecovery is the first k below 1 and
+// MaxGuaranteedLosses the last k before it; the profile shows how fast recovery degrades after.
+func RecoveryProfile(mask Mask) []float64 {
+	return RecoveryProfileFromSet(mask.N(), mask.K(), recoverableSet(mask))
+}
+
+// RecoveryProfileFromSet computes the recovery profile using an existing ReachableBFS result
+func RecoveryProfileFromSet(N, K int, reachableSet *ReachableSet) []float64 {
+	totalPackets := N + K
+	recovered := lossCounts(reachableSet, totalPackets)
+
+	profile := make([]float64, totalPackets+1)
+	patterns := 1.0 // C(N+K, k), updated incrementally
+	for lost := range profile {
+		profile[lost] = float64(recovered[lost]) / patterns
+		patterns = patterns * float64(totalPackets-lost) / float64(lost+1)
+	}
+	return profile
+}
+
+// lossCounts returns the number of patterns of the set with k lost packets for every k in
+// 0..totalPackets
+func lossCounts(set *ReachableSet, totalPackets int) []uint64 {
+	counts := make([]uint64, totalPackets+1)
+	set.Iterate(func(pattern uint64) {
+		counts[totalPackets-bits.OnesCount64(pattern)]++
+	})
+	return counts
+}
+
+// calculateRecoveryCharacteristics computes the recovery characteristics together with the
+// failing pattern found for every minimum, counting every checked loss pattern as a unit of work
+func calculateRecoveryCharacteristics(N, K int, reachableSet *ReachableSet, tracker *progressTracker) (RecoveryCharacteristics, RecoveryWitnesses, error) {
+	totalPackets := N + K
+	allDelivered := uint64(1)<<totalPackets - 1
+	witness := func(length int, lossPattern uint64) *Pattern {
+		if length == PerfectRecovery {
+			return nil
+		}
+		return &Pattern{Delivered: allDelivered ^ lossPattern, N: N, K: K}
 	}
 
 	// Find characteristics
-	minLostPackets := findMinLostPacketsForNonRecovery(N, K, totalPackets, reachableSet)
-	minConsecutiveLost := findMinConsecutiveLostForNonRecovery(N, K, totalPackets, reachableSet)
+	minLostPackets, minLostPattern, err := findMinLostPacketsForNonRecovery(N, K, totalPackets, reachableSet, tracker)
+	if err != nil {
+		return RecoveryCharacteristics{}, RecoveryWitnesses{}, err
+	}
+	minConsecutiveLost, minConsecutivePattern, err := findMinConsecutiveLostForNonRecovery(N, K, totalPackets, reachableSet, tracker)
+	if err != nil {
+		return RecoveryCharacteristics{}, RecoveryWitnesses{}, err
+	}
+	minMediaBurst, minMediaPattern, err := findMinMediaBurstForNonRecovery(N, K, reachableSet, tracker)
+	if err != nil {
+		return RecoveryCharacteristics{}, RecoveryWitnesses{}, err
+	}
+	minFECBurst, minFECPattern, err := findMinFECBurstForNonRecovery(N, K, reachableSet, tracker)
+	if err != nil {
+		return RecoveryCharacteristics{}, RecoveryWitnesses{}, err
+	}
 
-	return RecoveryCharacteristics{
-		MinLostPacketsForNonRecovery:     minLostPackets,
-		MinConsecutiveLostForNonRecovery: minConsecutiveLost,
+	// Every pattern with fewer losses than the smallest failing one is recovered
+	maxGuaranteedLosses := minLostPackets - 1
+	if minLostPackets == PerfectRecovery {
+		maxGuaranteedLosses = totalPackets
 	}
+
+	characteristics := RecoveryCharacteristics{
+		MinLostPacketsForNonRecovery:     LossCount(minLostPackets),
+		MinConsecutiveLostForNonRecovery: LossCount(minConsecutiveLost),
+		MinMediaBurstForNonRecovery:      LossCount(minMediaBurst),
+		MinFECBurstForNonRecovery:        LossCount(minFECBurst),
+		MaxGuaranteedLosses:              maxGuaranteedLosses,
+	}
+	witnesses := RecoveryWitnesses{
+		MinLostPacketsForNonRecovery:     witness(minLostPackets, minLostPattern),
+		MinConsecutiveLostForNonRecovery: witness(minConsecutiveLost, minConsecutivePattern),
+		MinMediaBurstForNonRecovery:      witness(minMediaBurst, minMediaPattern),
+		MinFECBurstForNonRecovery:        witness(minFECBurst, minFECPattern),
+	}
+	return characteristics, witnesses, nil
 }
 
-// findMinLostPacketsForNonRecovery finds the minimum number of lost packets that results in non-recovery
-func findMinLostPacketsForNonRecovery(N, K, totalPackets int, reachableSet map[int]bool) int {
+// findMinLostPacketsForNonRecovery finds the minimum number of lost packets that results in
+// non-recovery and the first failing loss pattern of that size
+func findMinLostPacketsForNonRecovery(N, K, totalPackets int, reachableSet *ReachableSet, tracker *progressTracker) (int, uint64, error) {
 	// Check all possible loss patterns, starting from 1 lost packet
 	for numLost := 1; numLost <= totalPackets; numLost++ {
 		// Generate all combinations of numLost lost packets
-		if hasNonRecoverablePattern(N, K, totalPackets, numLost, reachableSet) {
-			return numLost
+		found, lossPattern, err := hasNonRecoverablePattern(N, K, totalPackets, numLost, reachableSet, tracker)
+		if err != nil || found {
+			return numLost, lossPattern, err
 		}
 	}
-	return -1 // No non-recoverable pattern exists (perfect recovery)
+	return PerfectRecovery, 0, nil // No non-recoverable pattern exists
 }
 
 // findMinConsecutiveLostForNonRecovery finds the minimum number of consecutive lost packets that results in non-recovery
-func findMinConsecutiveLostForNonRecovery(N, K, totalPackets int, reachableSet map[int]bool) int {
-	// Check consecutive loss patterns of increasing length
-	for consecutiveLen := 1; consecutiveLen <= totalPackets; consecutiveLen++ {
-		// Try all possible starting positions for consecutive losses
-		for startPos := 0; startPos <= totalPackets-consecutiveLen; startPos++ {
-			// Create loss pattern: consecutive losses from startPos to startPos+consecutiveLen-1
-			lossPattern := 0
-			for i := startPos; i < startPos+consecutiveLen; i++ {
-				lossPattern |= 1 << i
+func findMinConsecutiveLostForNonRecovery(N, K, totalPackets int, reachableSet *ReachableSet, tracker *progressTracker) (int, uint64, error) {
+	allDelivered := uint64(1)<<totalPackets - 1
+	return findMinFailingBurst(packetRange(0, totalPackets), func(lossPattern uint64) bool {
+		return !reachableSet.Contains(allDelivered ^ lossPattern)
+	}, tracker)
+}
+
+// findMinMediaBurstForNonRecovery finds the minimum number of consecutive lost media packets
+// that results in non-recovery when every FEC packet is delivered
+func findMinMediaBurstForNonRecovery(N, K int, reachableSet *ReachableSet, tracker *progressTracker) (int, uint64, error) {
+	allDelivered := uint64(1)<<(N+K) - 1
+	return findMinFailingBurst(packetRange(0, N), func(lossPattern uint64) bool {
+		return !reachableSet.Contains(allDelivered ^ lossPattern)
+	}, tracker)
+}
+
+// findMinFECBurstForNonRecovery finds the minimum number of consecutive lost FEC packets that
+// results in non-recovery together with a single lost media packet. Losing FEC packets alone
+// never loses media, so this measures how many consecutive FEC losses leave some media packet
+// without protection. The returned loss pattern includes that media packet.
+func findMinFECBurstForNonRecovery(N, K int, reachableSet *ReachableSet, tracker *progressTracker) (int, uint64, error) {
+	allDelivered := uint64(1)<<(N+K) - 1
+	var failing uint64
+	length, _, err := findMinFailingBurst(packetRange(N, N+K), func(lossPattern uint64) bool {
+		for packet := 0; packet < N; packet++ {
+			failing = lossPattern | uint64(1)<<packet
+			if !reachableSet.Contains(allDelivered ^ failing) {
+				return true
 			}
+		}
+		return false
+	}, tracker)
+	return length, failing, err
+}
+
+// MinBurstForNonRecoveryInOrder returns the minimum number of consecutive lost packets that
+// results in non-recovery when the packets of an N+K block are sent in the given order, a
+// permutation of 0..N+K-1, or PerfectRecovery if no burst fails. MinConsecutiveLostForNonRecovery is the
+// burst for media packets followed by FEC packets; see EarliestTransmissionOrder for senders
+// that emit every FEC packet as soon as its media packets are out.
+func MinBurstForNonRecoveryInOrder(N, K int, reachableSet *ReachableSet, order []int) (int, error) {
+	totalPackets := N + K
+	if len(order) != totalPackets {
+		return 0, fmt.Errorf("order has %d packets, expected %d", len(order), totalPackets)
+	}
+	seen := uint64(0)
+	for _, packet := range order {
+		if packet < 0 || packet >= totalPackets || seen&(uint64(1)<<packet) != 0 {
+			return 0, fmt.Errorf("order %v is not a permutation of the %d packets", order, totalPackets)
+		}
+		seen |= uint64(1) << packet
+	}
 
-			// Convert loss pattern to delivery pattern (invert bits)
-			deliveryPattern := ((1 << totalPackets) - 1) ^ lossPattern
+	allDelivered := uint64(1)<<totalPackets - 1
+	length, _, err := findMinFailingBurst(order, func(lossPattern uint64) bool {
+		return !reachableSet.Contains(allDelivered ^ lossPattern)
+	}, nil)
+	return length, err
+}
 
-			// Check if this pattern is non-recoverable
-			if !reachableSet[deliveryPattern] {
-				return consecutiveLen
+// EarliestTransmissionOrder returns the wire order in which every FEC packet of the mask is
+// sent right after the last media packet it protects, FEC packets in index order, for use
+// with MinBurstForNonRecoveryInOrder. FEC packets protecting nothing are sent first.
+func EarliestTransmissionOrder(mask Mask) []int {
+	N := mask.N()
+	// FEC packets grouped by the media packet they follow, -1 for none
+	after := make(map[int][]int)
+	for fecIndex, row := range patternRows(mask) {
+		last := bits.Len64(row) - 1
+		after[last] = append(after[last], N+fecIndex)
+	}
+
+	order := append([]int(nil), after[-1]...)
+	for packet := 0; packet < N; packet++ {
+		order = append(order, packet)
+		order = append(order, after[packet]...)
+	}
+	return order
+}
+
+// findMinFailingBurst returns the length of the shortest run of consecutive packets of order
+// whose loss fails, trying runs of increasing length, and the first failing run as a loss
+// pattern, or PerfectRecovery if no run fails
+func findMinFailingBurst(order []int, fails func(lossPattern uint64) bool, tracker *progressTracker) (int, uint64, error) {
+	for length := 1; length <= len(order); length++ {
+		for start := 0; start+length <= len(order); start++ {
+			if err := tracker.step(); err != nil {
+				return 0, 0, err
+			}
+
+			lossPattern := uint64(0)
+			for _, packet := range order[start : start+length] {
+				lossPattern |= uint64(1) << packet
+			}
+			if fails(lossPattern) {
+				return length, lossPattern, nil
 			}
 		}
 	}
-	return -1 // No non-recoverable consecutive pattern exists (perfect recovery)
+	return PerfectRecovery, 0, nil
 }
 
-// hasNonRecoverablePattern checks if there exists any loss pattern with numLost packets that is non-recoverable
-func hasNonRecoverablePattern(N, K, totalPackets, numLost int, reachableSet map[int]bool) bool {
-	return generateCombinations(totalPackets, numLost, func(lossPattern int) bool {
+// packetRange returns the packet indices first..end-1
+func packetRange(first, end int) []int {
+	packets := make([]int, 0, end-first)
+	for packet := first; packet < end; packet++ {
+		packets = append(packets, packet)
+	}
+	return packets
+}
+
+// hasNonRecoverablePattern checks if there exists any loss pattern with numLost packets that
+// is non-recoverable and returns the first one found
+func hasNonRecoverablePattern(N, K, totalPackets, numLost int, reachableSet *ReachableSet, tracker *progressTracker) (bool, uint64, error) {
+	var err error
+	var failing uint64
+	found := generateCombinations(totalPackets, numLost, func(lossPattern uint64) bool {
+		if err = tracker.step(); err != nil {
+			return true
+		}
+
 		// Convert loss pattern to delivery pattern (invert bits)
-		deliveryPattern := ((1 << totalPackets) - 1) ^ lossPattern
+		deliveryPattern := (uint64(1)<<totalPackets - 1) ^ lossPattern
 
 		// If this delivery pattern is not reachable, we found a non-recoverable pattern
-		return !reachableSet[deliveryPattern]
+		failing = lossPattern
+		return !reachableSet.Contains(deliveryPattern)
 	})
+	if !found || err != nil {
+		return false, 0, err
+	}
+	return true, failing, nil
 }
 
 // generateCombinations generates all combinations of k bits set in n positions
 // and calls the callback for each combination. Returns true if callback ever returns true.
-func generateCombinations(n, k int, callback func(int) bool) bool {
+func generateCombinations(n, k int, callback func(uint64) bool) bool {
 	if k == 0 {
 		return callback(0)
 	}
@@ -85,7 +305,7 @@ func generateCombinations(n, k int, callback func(int) bool) bool {
 
 	// Use iterative approach to generate combinations
 	// Start with the first combination: k lowest bits set
-	combination := (1 << k) - 1
+	combination := uint64(1)<<k - 1
 	maxCombination := combination << (n - k)
 
 	for combination <= maxCombination {
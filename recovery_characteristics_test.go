@@ -9,33 +9,33 @@ func TestRecoveryCharacteristics(t *testing.T) {
 		name                       string
 		N                          int
 		K                          int
-		reachable                  []int
-		expectedMinLost            int
-		expectedMinConsecutiveLost int
+		reachable                  []uint64
+		expectedMinLost            LossCount
+		expectedMinConsecutiveLost LossCount
 	}{
 		{
 			name:                       "Simple case N=2, K=1 with perfect recovery",
 			N:                          2,
 			K:                          1,
-			reachable:                  []int{0, 1, 2, 3, 4, 5, 6, 7}, // All patterns recoverable
-			expectedMinLost:            -1,                            // Perfect recovery
-			expectedMinConsecutiveLost: -1,                            // Perfect recovery
+			reachable:                  []uint64{0, 1, 2, 3, 4, 5, 6, 7}, // All patterns recoverable
+			expectedMinLost:            -1,                               // Perfect recovery
+			expectedMinConsecutiveLost: -1,                               // Perfect recovery
 		},
 		{
 			name:                       "N=2, K=1 with some non-recoverable patterns",
 			N:                          2,
 			K:                          1,
-			reachable:                  []int{3, 5, 6, 7}, // Missing patterns 0,1,2,4 (binary: 000,001,010,100)
-			expectedMinLost:            2,                 // Need 2 losses to get non-recoverable pattern
-			expectedMinConsecutiveLost: 2,                 // Need 2 consecutive losses
+			reachable:                  []uint64{3, 5, 6, 7}, // Missing patterns 0,1,2,4 (binary: 000,001,010,100)
+			expectedMinLost:            2,                    // Need 2 losses to get non-recoverable pattern
+			expectedMinConsecutiveLost: 2,                    // Need 2 consecutive losses
 		},
 		{
 			name:                       "N=3, K=2 partial recovery",
 			N:                          3,
 			K:                          2,
-			reachable:                  []int{7, 15, 23, 31}, // Only patterns with all media packets (bits 0,1,2)
-			expectedMinLost:            1,                    // Any single loss of media packet
-			expectedMinConsecutiveLost: 1,                    // Any consecutive loss including media packet
+			reachable:                  []uint64{7, 15, 23, 31}, // Only patterns with all media packets (bits 0,1,2)
+			expectedMinLost:            1,                       // Any single loss of media packet
+			expectedMinConsecutiveLost: 1,                       // Any consecutive loss including media packet
 		},
 	}
 
@@ -93,7 +93,7 @@ func TestFindMinLostPacketsForNonRecovery(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := findMinLostPacketsForNonRecovery(tt.N, tt.K, tt.totalPackets, tt.reachableSet)
+			result, _, _ := findMinLostPacketsForNonRecovery(tt.N, tt.K, tt.totalPackets, reachableSetFromMap(tt.totalPackets, tt.reachableSet), nil)
 			if result != tt.expected {
 				t.Errorf("findMinLostPacketsForNonRecovery() = %d, expected %d", result, tt.expected)
 			}
@@ -141,7 +141,7 @@ func TestFindMinConsecutiveLostForNonRecovery(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := findMinConsecutiveLostForNonRecovery(tt.N, tt.K, tt.totalPackets, tt.reachableSet)
+			result, _, _ := findMinConsecutiveLostForNonRecovery(tt.N, tt.K, tt.totalPackets, reachableSetFromMap(tt.totalPackets, tt.reachableSet), nil)
 			if result != tt.expected {
 				t.Errorf("findMinConsecutiveLostForNonRecovery() = %d, expected %d", result, tt.expected)
 			}
@@ -190,7 +190,7 @@ func TestHasNonRecoverablePattern(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := hasNonRecoverablePattern(tt.N, tt.K, tt.totalPackets, tt.numLost, tt.reachableSet)
+			result, _, _ := hasNonRecoverablePattern(tt.N, tt.K, tt.totalPackets, tt.numLost, reachableSetFromMap(tt.totalPackets, tt.reachableSet), nil)
 			if result != tt.expected {
 				t.Errorf("hasNonRecoverablePattern() = %v, expected %v", result, tt.expected)
 			}
@@ -203,7 +203,7 @@ func TestGenerateCombinations(t *testing.T) {
 		name                 string
 		n                    int
 		k                    int
-		expectedCombinations []int
+		expectedCombinations []uint64
 		callbackReturnTrue   bool
 		expectedResult       bool
 	}{
@@ -211,7 +211,7 @@ func TestGenerateCombinations(t *testing.T) {
 			name:                 "Generate combinations C(3,2)",
 			n:                    3,
 			k:                    2,
-			expectedCombinations: []int{3, 5, 6}, // 011, 101, 110 in binary
+			expectedCombinations: []uint64{3, 5, 6}, // 011, 101, 110 in binary
 			callbackReturnTrue:   false,
 			expectedResult:       false,
 		},
@@ -219,7 +219,7 @@ func TestGenerateCombinations(t *testing.T) {
 			name:                 "Generate combinations C(4,2)",
 			n:                    4,
 			k:                    2,
-			expectedCombinations: []int{3, 5, 6, 9, 10, 12}, // All combinations of 2 bits in 4 positions
+			expectedCombinations: []uint64{3, 5, 6, 9, 10, 12}, // All combinations of 2 bits in 4 positions
 			callbackReturnTrue:   false,
 			expectedResult:       false,
 		},
@@ -227,7 +227,7 @@ func TestGenerateCombinations(t *testing.T) {
 			name:                 "Callback returns true early",
 			n:                    3,
 			k:                    2,
-			expectedCombinations: []int{3}, // Should stop after first combination
+			expectedCombinations: []uint64{3}, // Should stop after first combination
 			callbackReturnTrue:   true,
 			expectedResult:       true,
 		},
@@ -235,7 +235,7 @@ func TestGenerateCombinations(t *testing.T) {
 			name:                 "Edge case: k=0",
 			n:                    3,
 			k:                    0,
-			expectedCombinations: []int{0},
+			expectedCombinations: []uint64{0},
 			callbackReturnTrue:   false,
 			expectedResult:       false,
 		},
@@ -243,7 +243,7 @@ func TestGenerateCombinations(t *testing.T) {
 			name:                 "Edge case: k > n",
 			n:                    2,
 			k:                    3,
-			expectedCombinations: []int{},
+			expectedCombinations: []uint64{},
 			callbackReturnTrue:   false,
 			expectedResult:       false,
 		},
@@ -251,10 +251,10 @@ func TestGenerateCombinations(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			var combinations []int
+			var combinations []uint64
 			callCount := 0
 
-			result := generateCombinations(tt.n, tt.k, func(combination int) bool {
+			result := generateCombinations(tt.n, tt.k, func(combination uint64) bool {
 				combinations = append(combinations, combination)
 				callCount++
 				return tt.callbackReturnTrue
@@ -287,8 +287,8 @@ func TestGenerateCombinations(t *testing.T) {
 
 func TestGenerateCombinationsEdgeCases(t *testing.T) {
 	// Test k=n (all bits set)
-	var combinations []int
-	result := generateCombinations(3, 3, func(combination int) bool {
+	var combinations []uint64
+	result := generateCombinations(3, 3, func(combination uint64) bool {
 		combinations = append(combinations, combination)
 		return false
 	})
@@ -297,7 +297,7 @@ func TestGenerateCombinationsEdgeCases(t *testing.T) {
 		t.Errorf("generateCombinations(3,3) returned %v, expected false", result)
 	}
 
-	expected := []int{7} // 111 in binary
+	expected := []uint64{7} // 111 in binary
 	if len(combinations) != 1 || combinations[0] != expected[0] {
 		t.Errorf("generateCombinations(3,3) = %v, expected %v", combinations, expected)
 	}
@@ -305,9 +305,9 @@ func TestGenerateCombinationsEdgeCases(t *testing.T) {
 
 func TestGenerateCombinationsCallbackLogic(t *testing.T) {
 	// Test that callback receives correct values for C(4,2)
-	var combinations []int
+	var combinations []uint64
 
-	generateCombinations(4, 2, func(combination int) bool {
+	generateCombinations(4, 2, func(combination uint64) bool {
 		combinations = append(combinations, combination)
 
 		// Count bits in combination
@@ -335,27 +335,92 @@ func TestRecoveryCharacteristicsPerfectRecovery(t *testing.T) {
 	// N=1, K=1 with all patterns reachable should return -1 for both metrics
 	N, K := 1, 1
 	totalPackets := N + K
-	reachable := make([]int, 1<<totalPackets)
+	reachable := make([]uint64, 1<<totalPackets)
 	for i := 0; i < (1 << totalPackets); i++ {
-		reachable[i] = i
+		reachable[i] = uint64(i)
 	}
 
 	result := CalculateRecoveryCharacteristicsFromReachable(N, K, reachable)
-	
+
 	if result.MinLostPacketsForNonRecovery != -1 {
-		t.Errorf("Expected MinLostPacketsForNonRecovery = -1 for perfect recovery, got %d", 
+		t.Errorf("Expected MinLostPacketsForNonRecovery = -1 for perfect recovery, got %d",
 			result.MinLostPacketsForNonRecovery)
 	}
-	
+
 	if result.MinConsecutiveLostForNonRecovery != -1 {
-		t.Errorf("Expected MinConsecutiveLostForNonRecovery = -1 for perfect recovery, got %d", 
+		t.Errorf("Expected MinConsecutiveLostForNonRecovery = -1 for perfect recovery, got %d",
 			result.MinConsecutiveLostForNonRecovery)
 	}
 }
 
+func TestMaxGuaranteedLosses(t *testing.T) {
+	tests := []struct {
+		name      string
+		N, K      int
+		reachable []uint64
+		expected  int
+	}{
+		{"perfect recovery", 2, 1, []uint64{0, 1, 2, 3, 4, 5, 6, 7}, 3},
+		{"single losses recovered", 2, 1, []uint64{3, 5, 6, 7}, 1},
+		{"no loss tolerated", 3, 2, []uint64{7, 15, 23, 31}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := CalculateRecoveryCharacteristicsFromReachable(tt.N, tt.K, tt.reachable)
+			if result.MaxGuaranteedLosses != tt.expected {
+				t.Errorf("MaxGuaranteedLosses = %d, expected %d", result.MaxGuaranteedLosses, tt.expected)
+			}
+		})
+	}
+
+	// A Hamming(7,4) code corrects every single and double erasure
+	mask, err := (&HammingMaskFactory{}).CreateMask(4, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := CalculateRecoveryCharacteristics(mask); result.MaxGuaranteedLosses != 2 {
+		t.Errorf("Hamming MaxGuaranteedLosses = %d, expected 2", result.MaxGuaranteedLosses)
+	}
+}
+
+func TestRecoveryProfile(t *testing.T) {
+	// N=2, K=1 single parity: every pattern with at most one loss is recovered
+	profile := RecoveryProfileFromSet(2, 1, reachableSetFromMap(3, map[int]bool{3: true, 5: true, 6: true, 7: true}))
+	expected := []float64{1, 1, 0, 0}
+	if len(profile) != len(expected) {
+		t.Fatalf("profile has %d entries, expected %d", len(profile), len(expected))
+	}
+	for lost := range expected {
+		if profile[lost] != expected[lost] {
+			t.Errorf("profile[%d] = %g, expected %g", lost, profile[lost], expected[lost])
+		}
+	}
+
+	// The profile agrees with the characteristics and the recoverable set size
+	mask, err := (&GoogleBurstyMaskFactory{}).CreateMask(8, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	profile = RecoveryProfile(mask)
+	characteristics := CalculateRecoveryCharacteristics(mask)
+	for lost, fraction := range profile {
+		if lost <= characteristics.MaxGuaranteedLosses && fraction != 1 {
+			t.Errorf("profile[%d] = %g, expected 1 up to MaxGuaranteedLosses", lost, fraction)
+		}
+	}
+	if profile[characteristics.MinLostPacketsForNonRecovery] >= 1 {
+		t.Errorf("profile[%d] = %g, expected below 1 at MinLostPacketsForNonRecovery",
+			characteristics.MinLostPacketsForNonRecovery, profile[characteristics.MinLostPacketsForNonRecovery])
+	}
+	if profile[12] != 0 {
+		t.Errorf("profile[12] = %g, expected 0 when every packet is lost", profile[12])
+	}
+}
+
 func BenchmarkCalculateRecoveryCharacteristics(b *testing.B) {
 	// Create a realistic reachable set for N=8, K=4
-	reachable := make([]int, 0, 2048)
+	reachable := make([]uint64, 0, 2048)
 	for i := 0; i < (1 << 12); i++ {
 		// Include patterns where at least 6 out of first 8 bits are set (media packets)
 		mediaCount := 0
@@ -365,7 +430,7 @@ func BenchmarkCalculateRecoveryCharacteristics(b *testing.B) {
 			}
 		}
 		if mediaCount >= 6 {
-			reachable = append(reachable, i)
+			reachable = append(reachable, uint64(i))
 		}
 	}
 
@@ -374,3 +439,73 @@ func BenchmarkCalculateRecoveryCharacteristics(b *testing.B) {
 		CalculateRecoveryCharacteristicsFromReachable(8, 4, reachable)
 	}
 }
+
+func TestBurstCharacteristics(t *testing.T) {
+	// N=2, K=1 single parity: losing both media packets or the FEC packet and a media packet fails
+	result := CalculateRecoveryCharacteristicsFromReachable(2, 1, []uint64{3, 5, 6, 7})
+	if result.MinMediaBurstForNonRecovery != 2 {
+		t.Errorf("MinMediaBurstForNonRecovery = %d, expected 2", result.MinMediaBurstForNonRecovery)
+	}
+	if result.MinFECBurstForNonRecovery != 1 {
+		t.Errorf("MinFECBurstForNonRecovery = %d, expected 1", result.MinFECBurstForNonRecovery)
+	}
+
+	// Interleaved 8x4: media bursts up to K are recovered, and every FEC packet covers media
+	// no other FEC packet does
+	mask, err := (&InterleavedMaskFactory{}).CreateMask(8, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result = CalculateRecoveryCharacteristics(mask)
+	if result.MinMediaBurstForNonRecovery != 5 {
+		t.Errorf("interleaved MinMediaBurstForNonRecovery = %d, expected 5", result.MinMediaBurstForNonRecovery)
+	}
+	if result.MinFECBurstForNonRecovery != 1 {
+		t.Errorf("interleaved MinFECBurstForNonRecovery = %d, expected 1", result.MinFECBurstForNonRecovery)
+	}
+}
+
+func TestMinBurstForNonRecoveryInOrder(t *testing.T) {
+	mask, err := (&InterleavedMaskFactory{}).CreateMask(8, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := recoverableSet(mask)
+	characteristics := CalculateRecoveryCharacteristicsFromSet(8, 4, set)
+
+	// Media packets followed by FEC packets is the wire order of the characteristics
+	burst, err := MinBurstForNonRecoveryInOrder(8, 4, set, packetRange(0, 12))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if LossCount(burst) != characteristics.MinConsecutiveLostForNonRecovery {
+		t.Errorf("burst in media-then-FEC order = %d, expected %d", burst, characteristics.MinConsecutiveLostForNonRecovery)
+	}
+
+	// Sending every FEC packet right after its last media packet puts each of them next to
+	// the media packets it protects
+	order := EarliestTransmissionOrder(mask)
+	expected := []int{0, 1, 2, 3, 4, 8, 5, 9, 6, 10, 7, 11}
+	if len(order) != len(expected) {
+		t.Fatalf("order = %v, expected %v", order, expected)
+	}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("order = %v, expected %v", order, expected)
+		}
+	}
+	burst, err = MinBurstForNonRecoveryInOrder(8, 4, set, order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if burst != 2 {
+		t.Errorf("burst in earliest order = %d, expected 2", burst)
+	}
+
+	if _, err := MinBurstForNonRecoveryInOrder(8, 4, set, []int{0, 1, 2}); err == nil {
+		t.Error("expected an error for a short order")
+	}
+	if _, err := MinBurstForNonRecoveryInOrder(8, 4, set, []int{0, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10}); err == nil {
+		t.Error("expected an error for a repeated packet")
+	}
+}
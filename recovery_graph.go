@@ -1,88 +1,307 @@
 package fecanalysis
 
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// DecodingMode selects how a receiver recovers lost media packets from delivered FEC packets
+type DecodingMode int
+
+const (
+	// PeelingDecoding recovers one packet at a time from a FEC packet that misses only that
+	// packet, the way XOR-based FEC receivers such as WebRTC's work
+	PeelingDecoding DecodingMode = iota
+	// MLDecoding solves the delivered FEC packets jointly by Gaussian elimination over
+	// GF(2) (maximum-likelihood erasure decoding), which also recovers patterns that need
+	// two or more equations combined
+	MLDecoding
+)
+
+// String returns the name of the decoding mode
+func (m DecodingMode) String() string {
+	switch m {
+	case PeelingDecoding:
+		return "peeling"
+	case MLDecoding:
+		return "ml"
+	default:
+		return fmt.Sprintf("DecodingMode(%d)", int(m))
+	}
+}
+
+// ParseDecodingMode parses "peeling" or "ml"
+func ParseDecodingMode(name string) (DecodingMode, error) {
+	switch strings.ToLower(name) {
+	case "peeling":
+		return PeelingDecoding, nil
+	case "ml":
+		return MLDecoding, nil
+	default:
+		return 0, fmt.Errorf("unknown decoding mode %q (expected peeling or ml)", name)
+	}
+}
+
+// MaxGraphPackets is the largest N+K whose delivery patterns fit the uint64 vertices of
+// the recovery graph layer
+const MaxGraphPackets = 63
+
+// MaxEnumerablePackets is the largest N+K accepted by the analyses that visit all 2^(N+K)
+// delivery patterns of a block or hold a recoverable set of them. At 24 packets that is 16M
+// patterns and a 2 MiB set; every further packet doubles both, so blocks around 40 packets
+// would run out of memory rather than fail. Larger blocks are estimated by sampling, see
+// EvaluationOptions.Samples, or traversed with BFSWithFrontier.
+const MaxEnumerablePackets = 24
+
+// checkEnumerable returns an error if the delivery patterns of a block of N media and K FEC
+// packets are too many to enumerate, see MaxEnumerablePackets
+func checkEnumerable(N, K int) error {
+	if N+K > MaxEnumerablePackets {
+		return fmt.Errorf("block of %d packets exceeds the limit of %d", N+K, MaxEnumerablePackets)
+	}
+	return nil
+}
+
+// checkGraphPackets returns an error if the delivery patterns of a block of N media and K FEC
+// packets do not fit the uint64 vertices, see MaxGraphPackets; analyses that only sample or
+// scan a given set of patterns need no more
+func checkGraphPackets(N, K int) error {
+	if N+K > MaxGraphPackets {
+		return fmt.Errorf("block of %d packets exceeds the limit of %d", N+K, MaxGraphPackets)
+	}
+	return nil
+}
+
 // RecoveryGraph implements the Graph interface for FEC recovery analysis
 // Each vertex represents a bitset of delivered/recovered packets
 // Edges represent possible recovery operations using FEC packets
 type RecoveryGraph struct {
-	numVertices int  // 2^(N+K) vertices
-	N           int  // number of media packets
-	K           int  // number of FEC packets (derived from mask)
-	mask        Mask // FEC protection mask
+	numVertices uint64       // 2^(N+K) vertices
+	N           int          // number of media packets
+	K           int          // number of FEC packets (derived from mask)
+	mask        Mask         // FEC protection mask
+	mode        DecodingMode // how lost media packets are recovered
+	rows        []uint64     // media bitmask of every FEC packet
+	fecRecovery bool         // whether known FEC packets that the other known packets determine can be removed
+
+	// Adjacency cache filled by Materialize: the edges of vertex v are
+	// targets[offsets[v]:offsets[v+1]]
+	offsets []int
+	targets []uint64
 }
 
-// NewRecoveryGraph creates a new recovery graph with the given mask
+// NewRecoveryGraph creates a new recovery graph with the given mask for peeling decoding.
+// Masks with N+K up to MaxGraphPackets are addressable, although only small graphs can be
+// traversed in practice.
 func NewRecoveryGraph(mask Mask) *RecoveryGraph {
 	N := mask.N()
 	K := mask.K()
-	numVertices := 1 << (N + K) // 2^(N+K) vertices
+	numVertices := uint64(1) << (N + K) // 2^(N+K) vertices
 
 	return &RecoveryGraph{
 		numVertices: numVertices,
 		N:           N,
 		K:           K,
 		mask:        mask,
+		rows:        patternRows(mask),
 	}
 }
 
+// NewRecoveryGraphWithMode creates a new recovery graph with the given mask and decoding
+// mode. With MLDecoding there is an edge from a vertex to every vertex with one media
+// packet less that Gaussian elimination still decodes; since decodability is monotone,
+// BFS from GoodVertices reaches exactly the decodable patterns.
+func NewRecoveryGraphWithMode(mask Mask, mode DecodingMode) *RecoveryGraph {
+	graph := NewRecoveryGraph(mask)
+	graph.mode = mode
+	return graph
+}
+
+// EnableFECRecovery adds edges that remove a FEC packet from a vertex when the other packets
+// of the vertex determine it, so that missing FEC packets are recovered too. With peeling
+// decoding a FEC packet is re-encoded from its protected media packets once they are all
+// known; with MLDecoding it is any GF(2) combination of the known media and FEC packets,
+// which makes the graph model full maximum-likelihood XOR decoding. Such a FEC packet adds no
+// information, so the set reachable from GoodVertices is unchanged. A materialized
+// adjacency cache is dropped.
+func (g *RecoveryGraph) EnableFECRecovery() {
+	g.fecRecovery = true
+	g.offsets, g.targets = nil, nil
+}
+
 // NumVertices returns the total number of vertices in the graph (2^(N+K))
-func (g *RecoveryGraph) NumVertices() int {
+func (g *RecoveryGraph) NumVertices() uint64 {
 	return g.numVertices
 }
 
+// Materialize precomputes and stores the edges of every vertex, so that graphs traversed
+// repeatedly (e.g. once per loss model) compute each edge once, at the cost of memory
+// proportional to the number of edges. Calling it again is a no-op.
+func (g *RecoveryGraph) Materialize() {
+	if g.IsMaterialized() {
+		return
+	}
+
+	offsets := make([]int, g.numVertices+1)
+	var targets []uint64
+	for vertex := uint64(0); vertex < g.numVertices; vertex++ {
+		g.VisitEdges(vertex, func(destVertex uint64) bool {
+			targets = append(targets, destVertex)
+			return true
+		})
+		offsets[vertex+1] = len(targets)
+	}
+	g.offsets, g.targets = offsets, targets
+}
+
+// IsMaterialized returns true if the edges were precomputed by Materialize
+func (g *RecoveryGraph) IsMaterialized() bool {
+	return g.offsets != nil
+}
+
 // GetEdges returns a list of edges from the given vertex, calculated on demand
-func (g *RecoveryGraph) GetEdges(vertex int) []int {
-	if vertex < 0 || vertex >= g.numVertices {
-		return nil
+func (g *RecoveryGraph) GetEdges(vertex uint64) []uint64 {
+	var edges []uint64
+	g.VisitEdges(vertex, func(destVertex uint64) bool {
+		edges = append(edges, destVertex)
+		return true
+	})
+	return edges
+}
+
+// VisitEdges calls fn for every edge from the given vertex, in GetEdges order, without
+// allocating; it stops as soon as fn returns false
+func (g *RecoveryGraph) VisitEdges(vertex uint64, fn func(dst uint64) bool) {
+	if vertex >= g.numVertices {
+		return
 	}
 
-	var edges []int
+	if g.IsMaterialized() {
+		for _, destVertex := range g.targets[g.offsets[vertex]:g.offsets[vertex+1]] {
+			if !fn(destVertex) {
+				return
+			}
+		}
+		return
+	}
 
-	// For each FEC packet
-	for fecIndex := 0; fecIndex < g.K; fecIndex++ {
-		// Check if all packets protected by this FEC packet are present in current vertex
-		if g.canUseFECPacket(vertex, fecIndex) {
-			// Add edges to vertices where we can recover missing packets
-			edges = g.addRecoveryEdges(edges, vertex, fecIndex)
+	if g.mode == MLDecoding {
+		for packetIndex := 0; packetIndex < g.N; packetIndex++ {
+			destVertex := vertex &^ (uint64(1) << packetIndex)
+			if destVertex != vertex && solvableByElimination(g.rows, g.N, destVertex) && !fn(destVertex) {
+				return
+			}
 		}
+		g.visitFECRecoveryEdges(vertex, fn)
+		return
 	}
 
-	return edges
+	// For each FEC packet whose protected packets are all present, every protected packet
+	// can be removed and recovered from the others
+	for fecIndex, row := range g.rows {
+		if !g.canUseFECPacket(vertex, fecIndex) {
+			continue
+		}
+		for protected := row; protected != 0; protected &= protected - 1 {
+			if !fn(vertex &^ (protected & -protected)) {
+				return
+			}
+		}
+	}
+	g.visitFECRecoveryEdges(vertex, fn)
 }
 
-// canUseFECPacket checks if the FEC packet is delivered and all packets protected by it are present in the vertex
-func (g *RecoveryGraph) canUseFECPacket(vertex int, fecIndex int) bool {
-	// Check if the FEC packet itself is delivered (bit N+fecIndex)
-	fecBitPosition := g.N + fecIndex
-	if (vertex & (1 << fecBitPosition)) == 0 {
-		return false // FEC packet is not delivered
-	}
-
-	// Check if all protected packets are present
-	for packetIndex := 0; packetIndex < g.N; packetIndex++ {
-		if g.mask.IsProtected(packetIndex, fecIndex) {
-			// Check if this packet is present in the vertex (bit is set)
-			if (vertex & (1 << packetIndex)) == 0 {
-				return false // This protected packet is missing
-			}
+// visitFECRecoveryEdges calls fn for the edges removing a FEC packet that the rest of the
+// vertex determines, if EnableFECRecovery was called
+func (g *RecoveryGraph) visitFECRecoveryEdges(vertex uint64, fn func(dst uint64) bool) {
+	if !g.fecRecovery {
+		return
+	}
+	missing := (uint64(1)<<g.N - 1) &^ vertex
+	for fecIndex, row := range g.rows {
+		fecBit := uint64(1) << (g.N + fecIndex)
+		if vertex&fecBit == 0 {
+			continue
+		}
+		// Re-encoding needs every protected media packet; a combination with other
+		// FEC packets needs the row restricted to the missing packets in their span
+		determined := row&missing == 0
+		if !determined && g.mode == MLDecoding {
+			determined = inFECSpan(g.rows, g.N, vertex&^fecBit, row&missing)
+		}
+		if determined && !fn(vertex&^fecBit) {
+			return
 		}
 	}
-	return true
 }
 
-// addRecoveryEdges adds edges from the current vertex to vertices with recovered packets
-func (g *RecoveryGraph) addRecoveryEdges(edges []int, vertex int, fecIndex int) []int {
-	// For each protected packet, create an edge to a vertex where that packet is removed
-	for packetIndex := 0; packetIndex < g.N; packetIndex++ {
-		if g.mask.IsProtected(packetIndex, fecIndex) {
-			// Create destination vertex by removing this packet (clearing the bit)
-			destVertex := vertex &^ (1 << packetIndex)
+// inFECSpan returns true if the target, a set of missing media packets, is the XOR of the
+// rows of some delivered FEC packets of the pattern restricted to the missing media packets
+func inFECSpan(rows []uint64, N int, pattern, target uint64) bool {
+	basis := deliveredEquations(rows, N, pattern)
+	return basis.spans(target)
+}
 
-			// Only add edge if destination vertex is different (packet was actually present)
-			if destVertex != vertex {
-				edges = append(edges, destVertex)
-			}
+// canUseFECPacket checks if the FEC packet is delivered and all packets protected by it are present in the vertex
+func (g *RecoveryGraph) canUseFECPacket(vertex uint64, fecIndex int) bool {
+	return vertex&(uint64(1)<<(g.N+fecIndex)) != 0 && g.rows[fecIndex]&^vertex == 0
+}
+
+// GoodVertices returns all vertices where every media packet is present, i.e. the first
+// N bits are set and the K FEC bits take every possible value. These are the BFS sources
+// of the recovery analysis.
+func GoodVertices(N, K int) []uint64 {
+	allMediaPackets := uint64(1)<<N - 1 // First N bits set to 1
+
+	goodVertices := make([]uint64, 0, 1<<K)
+	for fecState := uint64(0); fecState < uint64(1)<<K; fecState++ {
+		goodVertices = append(goodVertices, allMediaPackets|(fecState<<N))
+	}
+	return goodVertices
+}
+
+// recoverableSet returns the set of delivery patterns from which all media packets can be recovered
+func recoverableSet(mask Mask) *ReachableSet {
+	return RecoverableSet(mask, PeelingDecoding)
+}
+
+// solvableByElimination returns true if Gaussian elimination over GF(2) recovers every
+// missing media packet of the pattern: the delivered FEC packets, restricted to the missing
+// media packets, must have full rank. rows holds the media bitmask of every FEC packet.
+func solvableByElimination(rows []uint64, N int, pattern uint64) bool {
+	missing := (uint64(1)<<N - 1) &^ pattern
+	unknowns := bits.OnesCount64(missing)
+	if unknowns == 0 {
+		return true
+	}
+
+	var basis gf2Basis
+	for fecIndex, row := range rows {
+		if pattern&(uint64(1)<<(N+fecIndex)) == 0 {
+			continue
+		}
+		if basis.insert(row&missing) && basis.rank == unknowns {
+			return true
 		}
 	}
+	return false
+}
 
-	return edges
+// IsRecoverable returns true if peeling recovers every media packet of the mask from the
+// delivery pattern. It decodes the single pattern, so tools that query a handful of
+// patterns (e.g. trace replay) don't pay for the exhaustive RecoverableSet.
+func IsRecoverable(mask Mask, pattern uint64) bool {
+	return IsDecodable(mask, pattern, PeelingDecoding)
+}
+
+// IsDecodable returns true if a receiver using the given decoding mode recovers every
+// media packet of the mask from the delivery pattern
+func IsDecodable(mask Mask, pattern uint64, mode DecodingMode) bool {
+	rows := patternRows(mask)
+	allMedia := uint64(1)<<mask.N() - 1
+	if mode == MLDecoding {
+		return solvableByElimination(rows, mask.N(), pattern)
+	}
+	return peelingClosure(rows, mask.N(), pattern)&allMedia == allMedia
 }
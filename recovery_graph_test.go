@@ -1,6 +1,7 @@
 package fecanalysis
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -55,7 +56,7 @@ func TestRecoveryGraphBasic(t *testing.T) {
 	graph := NewRecoveryGraph(mask)
 
 	// Test basic properties
-	assert.Equal(t, 16, graph.NumVertices()) // 2^(3+1) = 16 vertices
+	assert.Equal(t, uint64(16), graph.NumVertices()) // 2^(3+1) = 16 vertices
 	assert.Equal(t, 3, graph.N)
 	assert.Equal(t, 1, graph.K)
 
@@ -76,14 +77,14 @@ func TestRecoveryGraphEdges(t *testing.T) {
 	// Vertex 11 (binary 1011) has packets 0,1 and FEC 0, so FEC 0 can be used
 	edges := graph.GetEdges(11)
 	assert.Len(t, edges, 2)
-	assert.Contains(t, edges, 9)  // Remove packet 1: 1011 -> 1001
-	assert.Contains(t, edges, 10) // Remove packet 0: 1011 -> 1010
+	assert.Contains(t, edges, uint64(9))  // Remove packet 1: 1011 -> 1001
+	assert.Contains(t, edges, uint64(10)) // Remove packet 0: 1011 -> 1010
 
 	// Vertex 15 (binary 1111) has all packets and FEC 0, so FEC 0 can be used
 	edges = graph.GetEdges(15)
 	assert.Len(t, edges, 2)
-	assert.Contains(t, edges, 13) // Remove packet 1: 1111 -> 1101
-	assert.Contains(t, edges, 14) // Remove packet 0: 1111 -> 1110
+	assert.Contains(t, edges, uint64(13)) // Remove packet 1: 1111 -> 1101
+	assert.Contains(t, edges, uint64(14)) // Remove packet 0: 1111 -> 1110
 
 	// Vertex 3 (binary 0011) has packets 0,1 but no FEC 0, so FEC 0 cannot be used
 	edges = graph.GetEdges(3)
@@ -103,21 +104,21 @@ func TestRecoveryGraphMultipleFEC(t *testing.T) {
 	mask := NewSimpleMask(protectionMatrix, 3, 2)
 	graph := NewRecoveryGraph(mask)
 
-	assert.Equal(t, 32, graph.NumVertices()) // 2^(3+2) = 32 vertices
+	assert.Equal(t, uint64(32), graph.NumVertices()) // 2^(3+2) = 32 vertices
 	assert.Equal(t, 3, graph.N)
 	assert.Equal(t, 2, graph.K)
 
 	// Now with N=3, K=2, vertices are 5 bits: [media2][media1][media0][fec1][fec0]
 	// Vertex 31 (binary 11111) has all media packets and both FEC packets
 	edges := graph.GetEdges(31)
-	assert.Len(t, edges, 4)       // 2 edges from FEC 0 + 2 edges from FEC 1
-	assert.Contains(t, edges, 29) // Remove packet 1 (FEC 0): 11111 -> 11101
-	assert.Contains(t, edges, 30) // Remove packet 0 (FEC 0): 11111 -> 11110
-	assert.Contains(t, edges, 27) // Remove packet 2 (FEC 1): 11111 -> 11011
-	assert.Contains(t, edges, 29) // Remove packet 1 (FEC 1): 11111 -> 11101 (duplicate)
+	assert.Len(t, edges, 4)               // 2 edges from FEC 0 + 2 edges from FEC 1
+	assert.Contains(t, edges, uint64(29)) // Remove packet 1 (FEC 0): 11111 -> 11101
+	assert.Contains(t, edges, uint64(30)) // Remove packet 0 (FEC 0): 11111 -> 11110
+	assert.Contains(t, edges, uint64(27)) // Remove packet 2 (FEC 1): 11111 -> 11011
+	assert.Contains(t, edges, uint64(29)) // Remove packet 1 (FEC 1): 11111 -> 11101 (duplicate)
 
 	// Remove duplicates and check again
-	uniqueEdges := make(map[int]bool)
+	uniqueEdges := make(map[uint64]bool)
 	for _, edge := range edges {
 		uniqueEdges[edge] = true
 	}
@@ -135,7 +136,7 @@ func TestRecoveryGraphBoundaryConditions(t *testing.T) {
 	graph := NewRecoveryGraph(mask)
 
 	// Test invalid vertex indices
-	assert.Nil(t, graph.GetEdges(-1))
+	assert.Nil(t, graph.GetEdges(math.MaxUint64))
 	assert.Nil(t, graph.GetEdges(8)) // 2^(2+1) = 8 vertices (0-7)
 }
 
@@ -146,12 +147,12 @@ func TestRecoveryGraphWithBurstyMask(t *testing.T) {
 	require.NoError(t, err)
 
 	graph := NewRecoveryGraph(mask)
-	assert.Equal(t, 32, graph.NumVertices()) // 2^(3+2) = 32 vertices
+	assert.Equal(t, uint64(32), graph.NumVertices()) // 2^(3+2) = 32 vertices
 	assert.Equal(t, 3, graph.N)
 
 	// Test that the graph has some edges (exact edges depend on the mask pattern)
 	hasEdges := false
-	for vertex := 0; vertex < graph.NumVertices(); vertex++ {
+	for vertex := uint64(0); vertex < graph.NumVertices(); vertex++ {
 		if len(graph.GetEdges(vertex)) > 0 {
 			hasEdges = true
 			break
@@ -169,17 +170,17 @@ func TestRecoveryGraphBFS(t *testing.T) {
 	graph := NewRecoveryGraph(mask)
 
 	// Test BFS from vertex 15 (binary 1111) - all media packets and FEC 0 present
-	reachable := BFS(graph, []int{15})
+	reachable := BFS(graph, []uint64{15})
 
 	// From vertex 15, we should be able to reach vertices with fewer packets
-	assert.Contains(t, reachable, 15) // Source vertex
-	assert.Contains(t, reachable, 13) // Remove packet 1: 1111 -> 1101
-	assert.Contains(t, reachable, 14) // Remove packet 0: 1111 -> 1110
+	assert.Contains(t, reachable, uint64(15)) // Source vertex
+	assert.Contains(t, reachable, uint64(13)) // Remove packet 1: 1111 -> 1101
+	assert.Contains(t, reachable, uint64(14)) // Remove packet 0: 1111 -> 1110
 
 	// Test BFS from vertex with no outgoing edges
-	reachable = BFS(graph, []int{0})
+	reachable = BFS(graph, []uint64{0})
 	assert.Len(t, reachable, 1)
-	assert.Contains(t, reachable, 0)
+	assert.Contains(t, reachable, uint64(0))
 }
 
 func TestRecoveryGraphInterfaceCompliance(t *testing.T) {
@@ -191,9 +192,225 @@ func TestRecoveryGraphInterfaceCompliance(t *testing.T) {
 	// Test that RecoveryGraph implements Graph interface
 	var graph Graph = NewRecoveryGraph(mask)
 	require.NotNil(t, graph)
-	assert.Equal(t, 8, graph.NumVertices()) // 2^(2+1) = 8 vertices
+	assert.Equal(t, uint64(8), graph.NumVertices()) // 2^(2+1) = 8 vertices
 
 	// Test that BFS works with RecoveryGraph
-	reachable := BFS(graph, []int{7}) // Vertex with both media packets and FEC 0
-	assert.Contains(t, reachable, 7)
+	reachable := BFS(graph, []uint64{7}) // Vertex with both media packets and FEC 0
+	assert.Contains(t, reachable, uint64(7))
+}
+
+func TestMLDecoding(t *testing.T) {
+	// Every FEC packet misses two media packets when all three are lost, so peeling is
+	// stuck, but the three equations are independent
+	mask := NewSimpleMask([][]bool{
+		{true, true, false},
+		{false, true, true},
+		{true, true, true},
+	}, 3, 3)
+	allFEC := uint64(0b111000)
+	assert.False(t, IsDecodable(mask, allFEC, PeelingDecoding))
+	assert.True(t, IsDecodable(mask, allFEC, MLDecoding))
+
+	peeling := BFS(NewRecoveryGraph(mask), GoodVertices(3, 3))
+	ml := BFS(NewRecoveryGraphWithMode(mask, MLDecoding), GoodVertices(3, 3))
+	assert.NotContains(t, peeling, allFEC)
+	assert.Contains(t, ml, allFEC)
+
+	// ML decoding recovers everything peeling does, and exactly the full-rank patterns
+	mlSet := make(map[uint64]bool, len(ml))
+	for _, v := range ml {
+		mlSet[v] = true
+	}
+	for _, v := range peeling {
+		assert.True(t, mlSet[v], "vertex %b", v)
+	}
+	for v := uint64(0); v < 1<<6; v++ {
+		missing := 0b111 &^ v
+		var columns [][]bool
+		for fecIndex := 0; fecIndex < 3; fecIndex++ {
+			if v&(1<<(3+fecIndex)) == 0 {
+				continue
+			}
+			var row []bool
+			for packetIndex := 0; packetIndex < 3; packetIndex++ {
+				row = append(row, missing&(1<<packetIndex) != 0 && mask.IsProtected(packetIndex, fecIndex))
+			}
+			columns = append(columns, row)
+		}
+		rank := 0
+		if len(columns) > 0 {
+			rank = ProtectionMatrix(NewSimpleMask(columns, 3, len(columns))).Rank()
+		}
+		assert.Equal(t, rank == popcount(int(missing)), mlSet[v], "vertex %b", v)
+		assert.Equal(t, mlSet[v], IsDecodable(mask, v, MLDecoding), "vertex %b", v)
+	}
+}
+
+func TestParseDecodingMode(t *testing.T) {
+	for _, mode := range []DecodingMode{PeelingDecoding, MLDecoding} {
+		parsed, err := ParseDecodingMode(mode.String())
+		require.NoError(t, err)
+		assert.Equal(t, mode, parsed)
+	}
+	_, err := ParseDecodingMode("belief")
+	assert.Error(t, err)
+}
+
+func TestRecoveryGraphVisitEdges(t *testing.T) {
+	mask, err := (&GoogleRandomMaskFactory{}).CreateMask(6, 3)
+	require.NoError(t, err)
+
+	for _, graph := range []Graph{NewRecoveryGraph(mask), NewRecoveryGraphWithMode(mask, MLDecoding), NewMDSGraph(6, 3)} {
+		for vertex := uint64(0); vertex < graph.NumVertices(); vertex++ {
+			var visited []uint64
+			graph.VisitEdges(vertex, func(dst uint64) bool {
+				visited = append(visited, dst)
+				return true
+			})
+			assert.Equal(t, graph.GetEdges(vertex), visited, "vertex %b", vertex)
+		}
+	}
+
+	// Returning false stops the visit
+	graph := NewRecoveryGraph(mask)
+	visits := 0
+	graph.VisitEdges(graph.NumVertices()-1, func(uint64) bool {
+		visits++
+		return false
+	})
+	assert.Equal(t, 1, visits)
+
+	// Visiting does not allocate
+	count := 0
+	visit := func(uint64) bool {
+		count++
+		return true
+	}
+	allocs := testing.AllocsPerRun(10, func() {
+		graph.VisitEdges(graph.NumVertices()-1, visit)
+	})
+	assert.Zero(t, allocs)
+	assert.Positive(t, count)
+}
+
+func TestRecoveryGraphMaterialize(t *testing.T) {
+	mask, err := (&GoogleBurstyMaskFactory{}).CreateMask(5, 3)
+	require.NoError(t, err)
+
+	for _, mode := range []DecodingMode{PeelingDecoding, MLDecoding} {
+		onDemand := NewRecoveryGraphWithMode(mask, mode)
+		materialized := NewRecoveryGraphWithMode(mask, mode)
+		assert.False(t, materialized.IsMaterialized())
+		materialized.Materialize()
+		materialized.Materialize()
+		assert.True(t, materialized.IsMaterialized())
+
+		for vertex := uint64(0); vertex <= onDemand.NumVertices(); vertex++ {
+			assert.Equal(t, onDemand.GetEdges(vertex), materialized.GetEdges(vertex), "%s vertex %b", mode, vertex)
+		}
+		assert.Equal(t, BFS(onDemand, GoodVertices(5, 3)), BFS(materialized, GoodVertices(5, 3)))
+	}
+}
+
+func TestRecoveryGraphBeyond32Packets(t *testing.T) {
+	// N=32 media packets protected by 8 interleaved FEC packets: 40-bit patterns
+	N, K := 32, 8
+	rows := make([][]bool, K)
+	for fecIndex := range rows {
+		rows[fecIndex] = make([]bool, N)
+		for packetIndex := fecIndex; packetIndex < N; packetIndex += K {
+			rows[fecIndex][packetIndex] = true
+		}
+	}
+	mask := NewSimpleMask(rows, N, K)
+	graph := NewRecoveryGraph(mask)
+	assert.Equal(t, uint64(1)<<40, graph.NumVertices())
+
+	allDelivered := graph.NumVertices() - 1
+	assert.Len(t, graph.GetEdges(allDelivered), N)
+
+	// Losing one packet per FEC group is recoverable, two packets of a group are not
+	lostPerGroup := allDelivered &^ (uint64(1)<<31 | uint64(1)<<0)
+	assert.True(t, IsDecodable(mask, lostPerGroup, PeelingDecoding))
+	sameGroup := allDelivered &^ (uint64(1)<<31 | uint64(1)<<7)
+	assert.False(t, IsDecodable(mask, sameGroup, PeelingDecoding))
+	assert.False(t, IsDecodable(mask, sameGroup, MLDecoding))
+	assert.True(t, IsMDSRecoverable(sameGroup, N))
+}
+
+func TestIsRecoverableMatchesRecoverableSet(t *testing.T) {
+	for _, factory := range []MaskFactory{&GoogleBurstyMaskFactory{}, &LayeredMaskFactory{}, &SingleParityMaskFactory{}} {
+		mask, err := factory.CreateMask(5, 3)
+		require.NoError(t, err)
+		recoverable := recoverableSet(mask)
+		for pattern := uint64(0); pattern < recoverable.NumVertices(); pattern++ {
+			assert.Equal(t, recoverable.Contains(pattern), IsRecoverable(mask, pattern), "pattern %08b", pattern)
+		}
+	}
+}
+
+func TestRecoveryGraphFECRecovery(t *testing.T) {
+	// F0 = M0^M1, F1 = M1, F2 = M0
+	mask := NewSimpleMask([][]bool{{true, true}, {false, true}, {true, false}}, 2, 3)
+
+	peeling := NewRecoveryGraph(mask)
+	peeling.EnableFECRecovery()
+	// With every packet known, each FEC packet can be re-encoded
+	assert.Contains(t, peeling.GetEdges(0b11111), uint64(0b11011))
+	// Without M0, F2 can't be re-encoded, but F1 can
+	edges := peeling.GetEdges(0b11110)
+	assert.Contains(t, edges, uint64(0b10110))
+	assert.NotContains(t, edges, uint64(0b01110))
+
+	// ML decoding recovers F2 as F0^F1 without M0
+	ml := NewRecoveryGraphWithMode(mask, MLDecoding)
+	ml.EnableFECRecovery()
+	assert.Contains(t, ml.GetEdges(0b11110), uint64(0b01110))
+	// Without M0 and F0, F2 = M0 is not determined
+	assert.NotContains(t, ml.GetEdges(0b11010), uint64(0b01010))
+
+	// The reachable set is unchanged
+	for _, factory := range []MaskFactory{&GoogleRandomMaskFactory{}, &LDPCMaskFactory{Seed: 5}} {
+		mask, err := factory.CreateMask(6, 4)
+		require.NoError(t, err)
+		for _, mode := range []DecodingMode{PeelingDecoding, MLDecoding} {
+			graph := NewRecoveryGraphWithMode(mask, mode)
+			graph.EnableFECRecovery()
+			assert.Equal(t, RecoverableSet(mask, mode).Vertices(), ReachableBFS(graph, GoodVertices(6, 4)).Vertices())
+		}
+	}
+}
+
+func TestEnumerationLimit(t *testing.T) {
+	assert.NoError(t, checkEnumerable(20, MaxEnumerablePackets-20))
+	assert.Error(t, checkEnumerable(20, MaxEnumerablePackets-19))
+
+	// Analyses of every pattern refuse blocks that would exhaust memory instead of trying
+	mask, err := (&SingleParityMaskFactory{}).CreateMask(30, 10)
+	require.NoError(t, err)
+	model := NewRandomLossModel(0.1)
+	_, err = FailureThreshold(mask, model, PeelingDecoding, 1e-3)
+	assert.Error(t, err)
+	_, err = OverheadEfficiency(mask, model, PeelingDecoding)
+	assert.Error(t, err)
+	_, err = MeanTimeBetweenFailures(mask, model, PeelingDecoding, 100)
+	assert.Error(t, err)
+	_, err = RecoverySensitivity(mask, 0.1, 2, PeelingDecoding)
+	assert.Error(t, err)
+	_, err = BreakEvenLossProbability(mask, RandomLossFamily, 0.9, PeelingDecoding)
+	assert.Error(t, err)
+	_, err = EvaluateMask(mask, model, EvaluationOptions{})
+	assert.Error(t, err)
+
+	// Sampling is not limited by enumeration
+	_, err = EvaluateMask(mask, model, EvaluationOptions{Samples: 100})
+	assert.NoError(t, err)
+
+	// FEC subsets are limited by the patterns they decode rather than N+K
+	wide, err := (&SingleParityMaskFactory{}).CreateMask(40, 2)
+	require.NoError(t, err)
+	_, err = EvaluateByFECSubset(wide, PeelingDecoding)
+	assert.NoError(t, err)
+	_, err = EvaluateByFECSubset(mask, PeelingDecoding)
+	assert.Error(t, err)
 }
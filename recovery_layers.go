@@ -0,0 +1,45 @@
+package fecanalysis
+
+import "math/bits"
+
+// RecoveryLayer summarizes the delivery patterns of a recovery graph with the same number of
+// delivered packets
+type RecoveryLayer struct {
+	Delivered   int      // number of delivered packets, media and FEC
+	Patterns    uint64   // number of patterns in the layer, C(N+K, Delivered)
+	Recoverable uint64   // patterns from which every media packet is recovered
+	StepCounts  []uint64 // StepCounts[s] is the number of recoverable patterns peeling repairs in s rounds
+}
+
+// RecoveryGraphLayers groups the vertices of the recovery graph by the number of delivered
+// packets and reports per layer how many are recoverable and how many rounds of parallel
+// peeling repairs (RecoveryDepth) they need, i.e. the recovery frontier: the layers where
+// recoverability drops from all to none. The BFS distance from GoodVertices is not used as
+// the step count because it always equals the number of lost media packets. Patterns only
+// maximum-likelihood decoding recovers have no peeling depth and are left out of
+// StepCounts. Layers are indexed by Delivered.
+func RecoveryGraphLayers(graph *RecoveryGraph) []RecoveryLayer {
+	totalPackets := graph.N + graph.K
+	layers := make([]RecoveryLayer, totalPackets+1)
+	for delivered := range layers {
+		layers[delivered].Delivered = delivered
+	}
+
+	for vertex, distance := range BFSDistances(graph, GoodVertices(graph.N, graph.K)) {
+		layer := &layers[bits.OnesCount64(uint64(vertex))]
+		layer.Patterns++
+		if distance < 0 {
+			continue
+		}
+		layer.Recoverable++
+		depth := RecoveryDepth(graph.mask, uint64(vertex))
+		if depth < 0 {
+			continue
+		}
+		for len(layer.StepCounts) <= depth {
+			layer.StepCounts = append(layer.StepCounts, 0)
+		}
+		layer.StepCounts[depth]++
+	}
+	return layers
+}
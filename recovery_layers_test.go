@@ -0,0 +1,58 @@
+package fecanalysis
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoveryGraphLayers(t *testing.T) {
+	// Single parity over 3 media packets recovers exactly the patterns losing at most one
+	// packet, media or FEC
+	mask, err := (&SingleParityMaskFactory{}).CreateMask(3, 1)
+	require.NoError(t, err)
+	layers := RecoveryGraphLayers(NewRecoveryGraph(mask))
+	require.Len(t, layers, 5)
+
+	assert.Equal(t, RecoveryLayer{Delivered: 4, Patterns: 1, Recoverable: 1, StepCounts: []uint64{1}}, layers[4])
+	// Losing the FEC packet needs no recovery, losing one of the media packets needs one step
+	assert.Equal(t, RecoveryLayer{Delivered: 3, Patterns: 4, Recoverable: 4, StepCounts: []uint64{1, 3}}, layers[3])
+	for delivered := 0; delivered <= 2; delivered++ {
+		assert.Zero(t, layers[delivered].Recoverable)
+		assert.Empty(t, layers[delivered].StepCounts)
+	}
+}
+
+func TestRecoveryGraphLayersTotals(t *testing.T) {
+	mask, err := (&GoogleBurstyMaskFactory{}).CreateMask(6, 3)
+	require.NoError(t, err)
+
+	recoverable := uint64(0)
+	for _, layer := range RecoveryGraphLayers(NewRecoveryGraph(mask)) {
+		assert.Equal(t, new(big.Int).Binomial(9, int64(layer.Delivered)).Uint64(), layer.Patterns)
+		steps := uint64(0)
+		for _, count := range layer.StepCounts {
+			steps += count
+		}
+		assert.Equal(t, layer.Recoverable, steps)
+		recoverable += layer.Recoverable
+	}
+	assert.Equal(t, RecoverableSet(mask, PeelingDecoding).Count(), recoverable)
+}
+
+func TestRecoveryGraphLayersCountsParallelRounds(t *testing.T) {
+	// Interleaving over 4 media packets repairs two lost media packets protected by
+	// different FEC packets in a single round
+	mask, err := (&InterleavedMaskFactory{}).CreateMask(4, 2)
+	require.NoError(t, err)
+	layers := RecoveryGraphLayers(NewRecoveryGraph(mask))
+
+	// Every recoverable pattern needs at most one round, although losing media packets 0
+	// and 1 takes two sequential repairs
+	assert.Equal(t, []uint64{1, 8}, layers[4].StepCounts)
+	for _, layer := range layers {
+		assert.LessOrEqual(t, len(layer.StepCounts), 2, "delivered=%d", layer.Delivered)
+	}
+}
@@ -0,0 +1,153 @@
+package fecanalysis
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+)
+
+// RecoveryStep is one peeling operation: a delivered FEC packet repairs the only media
+// packet it protects that is still missing
+type RecoveryStep struct {
+	FECIndex    int // FEC packet (mask row) used for the repair
+	PacketIndex int // recovered media packet
+}
+
+// String formats the step as "FEC f recovers packet p"
+func (s RecoveryStep) String() string {
+	return fmt.Sprintf("FEC %d recovers packet %d", s.FECIndex, s.PacketIndex)
+}
+
+// ExplainRecovery returns the ordered peeling operations that repair every lost media packet
+// of the delivery pattern, or nil if peeling gets stuck before all media packets are back.
+// A pattern without lost media packets needs no operations and yields an empty, non-nil
+// slice. Every step uses the lowest-indexed FEC packet that can repair a packet.
+func ExplainRecovery(mask Mask, pattern uint64) []RecoveryStep {
+	N := mask.N()
+	rows := patternRows(mask)
+	allMedia := uint64(1)<<N - 1
+
+	steps := []RecoveryStep{}
+	for pattern&allMedia != allMedia {
+		step, ok := nextRecoveryStep(rows, N, pattern)
+		if !ok {
+			return nil
+		}
+		steps = append(steps, step)
+		pattern |= uint64(1) << step.PacketIndex
+	}
+	return steps
+}
+
+// nextRecoveryStep finds the lowest-indexed delivered FEC packet missing exactly one of its
+// protected media packets
+func nextRecoveryStep(rows []uint64, N int, pattern uint64) (RecoveryStep, bool) {
+	for fecIndex, row := range rows {
+		if pattern&(uint64(1)<<(N+fecIndex)) == 0 {
+			continue // FEC packet not delivered
+		}
+		missing := row &^ pattern
+		if missing != 0 && missing&(missing-1) == 0 {
+			return RecoveryStep{FECIndex: fecIndex, PacketIndex: bits.TrailingZeros64(missing)}, true
+		}
+	}
+	return RecoveryStep{}, false
+}
+
+// CountDecodingOrders returns the number of distinct peeling sequences, i.e. ordered lists of
+// recovery steps as returned by ExplainRecovery, that repair every lost media packet of the
+// delivery pattern; this is the number of paths from the pattern to an all-media vertex in
+// the recovery graph, counting one path per FEC packet that can repair a packet. It is zero
+// for unrecoverable patterns and one when no media packet is lost. Patterns with few orders
+// depend on few FEC packets and are fragile under partial corruption.
+func CountDecodingOrders(mask Mask, pattern uint64) *big.Int {
+	rows := patternRows(mask)
+	memo := make(map[uint64]*big.Int)
+	return new(big.Int).Set(countDecodingOrders(rows, mask.N(), pattern, memo))
+}
+
+// countDecodingOrders counts the peeling sequences from the pattern, memoized by pattern
+func countDecodingOrders(rows []uint64, N int, pattern uint64, memo map[uint64]*big.Int) *big.Int {
+	allMedia := uint64(1)<<N - 1
+	if pattern&allMedia == allMedia {
+		return big.NewInt(1)
+	}
+	if count, ok := memo[pattern]; ok {
+		return count
+	}
+
+	count := new(big.Int)
+	for fecIndex, row := range rows {
+		if pattern&(uint64(1)<<(N+fecIndex)) == 0 {
+			continue // FEC packet not delivered
+		}
+		missing := row &^ pattern
+		if missing != 0 && missing&(missing-1) == 0 {
+			count.Add(count, countDecodingOrders(rows, N, pattern|missing, memo))
+		}
+	}
+	memo[pattern] = count
+	return count
+}
+
+// RecoveryDepth returns the number of rounds of peeling needed to repair every lost media
+// packet of the delivery pattern when every round runs all currently possible repairs in
+// parallel, i.e. the length of the shortest chain of dependent XOR operations; -1 if the
+// pattern is unrecoverable and 0 if no media packet is lost. The BFS distance in the
+// recovery graph instead counts one sequential operation per lost packet.
+func RecoveryDepth(mask Mask, pattern uint64) int {
+	N := mask.N()
+	rows := patternRows(mask)
+	allMedia := uint64(1)<<N - 1
+
+	depth := 0
+	for pattern&allMedia != allMedia {
+		recovered := peelingRound(rows, N, pattern)
+		if recovered == pattern {
+			return -1
+		}
+		pattern = recovered
+		depth++
+	}
+	return depth
+}
+
+// ExpectedRecoveryOperations returns the mean number of peeling operations a receiver runs
+// per group under the loss model, a proxy for the decoding CPU cost. Every operation XORs a
+// delivered FEC packet with the other packets it protects to repair one media packet, so
+// this is the expected number of repaired media packets, counting repairs in groups that
+// stay partially lost; it is 0 when nothing is lost.
+func ExpectedRecoveryOperations(mask Mask, model LossModel) (float64, error) {
+	N, K := mask.N(), mask.K()
+	if err := checkEnumerable(N, K); err != nil {
+		return 0, err
+	}
+
+	rows := patternRows(mask)
+	allMedia := uint64(1)<<N - 1
+	expected := 0.0
+	VisitPatterns(model, N+K, func(pattern uint64, probability float64) {
+		if pattern&allMedia == allMedia {
+			return
+		}
+		repaired := peelingClosure(rows, N, pattern) &^ pattern & allMedia
+		expected += probability * float64(bits.OnesCount64(repaired))
+	})
+	return expected, nil
+}
+
+// peelingRound returns the pattern with every media packet that a delivered FEC packet can
+// repair from the pattern as is, i.e. one round of parallel peeling
+func peelingRound(rows []uint64, N int, pattern uint64) uint64 {
+	recovered := pattern
+	for fecIndex, row := range rows {
+		if pattern&(uint64(1)<<(N+fecIndex)) == 0 {
+			continue // FEC packet not delivered
+		}
+		missing := row &^ pattern
+		if missing != 0 && missing&(missing-1) == 0 {
+			recovered |= missing
+		}
+	}
+	return recovered
+}
@@ -0,0 +1,143 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplainRecovery(t *testing.T) {
+	// FEC 0 protects packets 0 and 1, FEC 1 protects packets 1 and 2
+	mask := NewSimpleMask([][]bool{
+		{true, true, false},
+		{false, true, true},
+	}, 3, 2)
+
+	// Losing packets 1 and 2: FEC 0 repairs packet 1, then FEC 1 repairs packet 2
+	steps := ExplainRecovery(mask, 0b11001)
+	assert.Equal(t, []RecoveryStep{{FECIndex: 0, PacketIndex: 1}, {FECIndex: 1, PacketIndex: 2}}, steps)
+	assert.Equal(t, "FEC 0 recovers packet 1", steps[0].String())
+
+	// Nothing to repair
+	steps = ExplainRecovery(mask, 0b00111)
+	assert.NotNil(t, steps)
+	assert.Empty(t, steps)
+
+	// FEC 1 is lost, so packet 2 cannot be repaired
+	assert.Nil(t, ExplainRecovery(mask, 0b01001))
+}
+
+func TestExplainRecoveryMatchesRecoverableSet(t *testing.T) {
+	mask, err := (&GoogleRandomMaskFactory{}).CreateMask(5, 3)
+	require.NoError(t, err)
+
+	recoverable := recoverableSet(mask)
+	for pattern := uint64(0); pattern < recoverable.NumVertices(); pattern++ {
+		steps := ExplainRecovery(mask, pattern)
+		if !recoverable.Contains(pattern) {
+			assert.Nil(t, steps, "pattern %08b", pattern)
+			continue
+		}
+
+		// Replaying the steps repairs exactly the lost media packets
+		require.NotNil(t, steps, "pattern %08b", pattern)
+		repaired := pattern
+		for _, step := range steps {
+			assert.True(t, mask.IsProtected(step.PacketIndex, step.FECIndex))
+			assert.Zero(t, repaired&(1<<step.PacketIndex), "packet %d is repaired twice", step.PacketIndex)
+			repaired |= 1 << step.PacketIndex
+		}
+		assert.Equal(t, popcount(int(0b11111&^pattern)), len(steps))
+	}
+}
+
+func TestCountDecodingOrders(t *testing.T) {
+	// FEC 0 protects packets 0 and 1, FEC 1 protects packets 1 and 2, FEC 2 protects packet 0
+	mask := NewSimpleMask([][]bool{
+		{true, true, false},
+		{false, true, true},
+		{true, false, false},
+	}, 3, 3)
+
+	tests := []struct {
+		name     string
+		pattern  uint64
+		expected int64
+	}{
+		{"nothing lost", 0b000111, 1},
+		{"single repair path", 0b011011, 1},              // lost 2: only FEC 1
+		{"two FEC packets repair packet 0", 0b111110, 2}, // FEC 0 or FEC 2
+		// Lost 0 and 1: FEC 2 repairs 0 then FEC 0 or FEC 1 repairs 1, or FEC 1 repairs 1
+		// then FEC 0 or FEC 2 repairs 0
+		{"several orders", 0b111100, 4},
+		{"unrecoverable", 0b000100, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, CountDecodingOrders(mask, tt.pattern).Int64())
+		})
+	}
+}
+
+func TestCountDecodingOrdersMatchesRecoverableSet(t *testing.T) {
+	mask, err := (&GoogleBurstyMaskFactory{}).CreateMask(5, 3)
+	require.NoError(t, err)
+
+	recoverable := recoverableSet(mask)
+	for pattern := uint64(0); pattern < recoverable.NumVertices(); pattern++ {
+		assert.Equal(t, recoverable.Contains(pattern), CountDecodingOrders(mask, pattern).Sign() > 0, "pattern %08b", pattern)
+	}
+}
+
+func TestRecoveryDepth(t *testing.T) {
+	// FEC 0 protects packets 0 and 1, FEC 1 protects packets 1 and 2, FEC 2 protects packet 0
+	mask := NewSimpleMask([][]bool{
+		{true, true, false},
+		{false, true, true},
+		{true, false, false},
+	}, 3, 3)
+
+	assert.Equal(t, 0, RecoveryDepth(mask, 0b000111))
+	assert.Equal(t, 1, RecoveryDepth(mask, 0b111100), "FEC 2 and FEC 1 repair packets 0 and 1 in parallel")
+	assert.Equal(t, 2, RecoveryDepth(mask, 0b101100), "packet 1 needs packet 0 repaired first")
+	assert.Equal(t, 3, RecoveryDepth(mask, 0b111000), "a chain: FEC 2, then FEC 0, then FEC 1")
+	assert.Equal(t, -1, RecoveryDepth(mask, 0b000100))
+
+	// The BFS distance in the recovery graph counts one operation per lost packet
+	distances := BFSDistances(NewRecoveryGraph(mask), GoodVertices(3, 3))
+	assert.Equal(t, int32(2), distances[0b111100])
+	assert.Equal(t, int32(3), distances[0b111000])
+	assert.Equal(t, int32(-1), distances[0b000100])
+}
+
+func TestExpectedRecoveryOperations(t *testing.T) {
+	// Single parity over two packets: one repair when exactly one media packet is lost and
+	// the FEC packet arrives
+	mask := NewSimpleMask([][]bool{{true, true}}, 2, 1)
+	p := 0.1
+	operations, err := ExpectedRecoveryOperations(mask, NewRandomLossModel(p))
+	require.NoError(t, err)
+	assert.InDelta(t, 2*p*(1-p)*(1-p), operations, 1e-12)
+
+	operations, err = ExpectedRecoveryOperations(mask, NewRandomLossModel(0))
+	require.NoError(t, err)
+	assert.Zero(t, operations)
+
+	// Every repair adds one media packet, so the operations are the available media packets
+	// beyond the delivered ones
+	bursty, err := (&GoogleBurstyMaskFactory{}).CreateMask(6, 3)
+	require.NoError(t, err)
+	model := NewRandomLossModel(0.2)
+	operations, err = ExpectedRecoveryOperations(bursty, model)
+	require.NoError(t, err)
+	recovered, err := ExpectedRecoveredMediaPackets(bursty, model)
+	require.NoError(t, err)
+	assert.InDelta(t, recovered-6*(1-0.2), operations, 1e-9)
+
+	large, err := (&InterleavedMaskFactory{}).CreateMask(16, 9)
+	require.NoError(t, err)
+	_, err = ExpectedRecoveryOperations(large, model)
+	assert.Error(t, err)
+}
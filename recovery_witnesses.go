@@ -0,0 +1,83 @@
+package fecanalysis
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Pattern is a delivery pattern of a block of N media and K FEC packets, where bit i is set
+// if packet i (media packets first, then FEC packets) is delivered
+type Pattern struct {
+	Delivered uint64 `json:"delivered"`
+	N         int    `json:"n"`
+	K         int    `json:"k"`
+}
+
+// Lost returns the indices of the lost media packets and of the lost FEC packets
+func (p Pattern) Lost() (media, fec []int) {
+	for packet := 0; packet < p.N+p.K; packet++ {
+		if p.Delivered&(uint64(1)<<packet) != 0 {
+			continue
+		}
+		if packet < p.N {
+			media = append(media, packet)
+		} else {
+			fec = append(fec, packet-p.N)
+		}
+	}
+	return media, fec
+}
+
+// String formats the pattern as media|FEC bits, packet 0 first and 1 for delivered, followed
+// by the lost packets, e.g. "0110|01 (lost M:0,3 F:0)"
+func (p Pattern) String() string {
+	var text strings.Builder
+	for packet := 0; packet < p.N+p.K; packet++ {
+		if packet == p.N {
+			text.WriteByte('|')
+		}
+		text.WriteByte('0' + byte(p.Delivered>>packet&1))
+	}
+
+	media, fec := p.Lost()
+	var lost []string
+	if len(media) > 0 {
+		lost = append(lost, "M:"+joinInts(media))
+	}
+	if len(fec) > 0 {
+		lost = append(lost, "F:"+joinInts(fec))
+	}
+	if len(lost) == 0 {
+		lost = append(lost, "none")
+	}
+	fmt.Fprintf(&text, " (lost %s)", strings.Join(lost, " "))
+	return text.String()
+}
+
+// joinInts formats the values separated by commas
+func joinInts(values []int) string {
+	texts := make([]string, len(values))
+	for i, value := range values {
+		texts[i] = strconv.Itoa(value)
+	}
+	return strings.Join(texts, ",")
+}
+
+// RecoveryWitnesses holds an example non-recoverable pattern for each minimum loss count of
+// RecoveryCharacteristics, nil when the count is PerfectRecovery
+type RecoveryWitnesses struct {
+	MinLostPacketsForNonRecovery     *Pattern `json:"min_lost_packets_for_non_recovery,omitempty"`
+	MinConsecutiveLostForNonRecovery *Pattern `json:"min_consecutive_lost_for_non_recovery,omitempty"`
+	MinMediaBurstForNonRecovery      *Pattern `json:"min_media_burst_for_non_recovery,omitempty"`
+	MinFECBurstForNonRecovery        *Pattern `json:"min_fec_burst_for_non_recovery,omitempty"`
+}
+
+// CalculateRecoveryCharacteristicsWithWitnesses is CalculateRecoveryCharacteristicsFromSet
+// that also returns a concrete non-recoverable pattern reaching every minimum, so reports can
+// show counterexamples rather than only their size. Among the patterns of minimum size, the
+// witness is the first in the order the search tries them.
+func CalculateRecoveryCharacteristicsWithWitnesses(N, K int, reachableSet *ReachableSet) (RecoveryCharacteristics, RecoveryWitnesses) {
+	characteristics, witnesses, _ := calculateRecoveryCharacteristics(N, K, reachableSet, nil)
+	return characteristics, witnesses
+}
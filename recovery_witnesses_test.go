@@ -0,0 +1,59 @@
+package fecanalysis
+
+import (
+	"encoding/json"
+	"math/bits"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPattern(t *testing.T) {
+	pattern := Pattern{Delivered: 0b10_0110, N: 4, K: 2}
+	media, fec := pattern.Lost()
+	assert.Equal(t, []int{0, 3}, media)
+	assert.Equal(t, []int{0}, fec)
+	assert.Equal(t, "0110|01 (lost M:0,3 F:0)", pattern.String())
+	assert.Equal(t, "11|1 (lost none)", Pattern{Delivered: 0b111, N: 2, K: 1}.String())
+}
+
+func TestRecoveryCharacteristicsWithWitnesses(t *testing.T) {
+	for _, factory := range []MaskFactory{&GoogleBurstyMaskFactory{}, &GoogleRandomMaskFactory{}, &InterleavedMaskFactory{}} {
+		mask, err := factory.CreateMask(8, 4)
+		require.NoError(t, err)
+		set := RecoverableSet(mask, PeelingDecoding)
+		characteristics, witnesses := CalculateRecoveryCharacteristicsWithWitnesses(8, 4, set)
+		assert.Equal(t, CalculateRecoveryCharacteristicsFromSet(8, 4, set), characteristics)
+
+		// Every witness fails and has the size of its minimum
+		check := func(witness *Pattern, size LossCount, name string) {
+			if size == PerfectRecovery {
+				assert.Nil(t, witness, name)
+				return
+			}
+			require.NotNil(t, witness, name)
+			assert.False(t, set.Contains(witness.Delivered), name)
+			assert.Equal(t, size, LossCount(12-bits.OnesCount64(witness.Delivered)), name)
+		}
+		check(witnesses.MinLostPacketsForNonRecovery, characteristics.MinLostPacketsForNonRecovery, "min lost")
+		check(witnesses.MinConsecutiveLostForNonRecovery, characteristics.MinConsecutiveLostForNonRecovery, "consecutive")
+		check(witnesses.MinMediaBurstForNonRecovery, characteristics.MinMediaBurstForNonRecovery, "media burst")
+		fecBurst := characteristics.MinFECBurstForNonRecovery
+		if fecBurst != PerfectRecovery {
+			fecBurst++ // the burst plus one media packet
+		}
+		check(witnesses.MinFECBurstForNonRecovery, fecBurst, "FEC burst")
+	}
+
+	// Perfect recovery has no witnesses
+	set := NewReachableSet(8)
+	for pattern := uint64(0); pattern < 8; pattern++ {
+		set.Add(pattern)
+	}
+	_, witnesses := CalculateRecoveryCharacteristicsWithWitnesses(2, 1, set)
+	assert.Equal(t, RecoveryWitnesses{}, witnesses)
+	data, err := json.Marshal(witnesses)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(data))
+}
@@ -0,0 +1,71 @@
+package fecanalysis
+
+import "fmt"
+
+// RedundancyUtilization describes how much the FEC packets of a mask contribute to recovery
+// under a loss model, see CalculateRedundancyUtilization
+type RedundancyUtilization struct {
+	RowUsage    []float64 `json:"row_usage"`   // for every FEC packet, the probability that it is delivered and takes part in recovering lost media packets
+	RowPivotal  []float64 `json:"row_pivotal"` // for every FEC packet, the probability that it is delivered and recovers media packets the others cannot
+	Utilization float64   `json:"utilization"` // mean of RowUsage, the expected fraction of FEC packets that take part in recovery
+}
+
+// DeadRows returns the FEC packets whose usage is below threshold, the rows that are dead
+// weight at the operating point of the loss model
+func (u RedundancyUtilization) DeadRows(threshold float64) []int {
+	var dead []int
+	for fecIndex, usage := range u.RowUsage {
+		if usage < threshold {
+			dead = append(dead, fecIndex)
+		}
+	}
+	return dead
+}
+
+// CalculateRedundancyUtilization returns how often every FEC packet of the mask takes part in
+// recovery with the decoding mode under the loss model. A delivered packet takes part in
+// the recovery of a delivery pattern when it protects lost media packets and all of them
+// are recovered, so some decoding order repairs a packet with it. RowPivotal is stricter and
+// counts a packet only when losing it as well would leave more media packets missing, so
+// duplicate rows get credit only when the other copy is lost. A row with usage close to
+// zero costs overhead without improving recovery at the operating point.
+func CalculateRedundancyUtilization(mask Mask, model LossModel, mode DecodingMode) (RedundancyUtilization, error) {
+	N, K := mask.N(), mask.K()
+	if err := checkEnumerable(N, K); err != nil {
+		return RedundancyUtilization{}, err
+	}
+	if K == 0 {
+		return RedundancyUtilization{}, fmt.Errorf("redundancy utilization needs FEC packets")
+	}
+
+	recover := recoveryFunc(mask, mode)
+	rows := patternRows(mask)
+	allMedia := uint64(1)<<N - 1
+	usage := make([]float64, K)
+	pivotal := make([]float64, K)
+	VisitPatterns(model, N+K, func(pattern uint64, probability float64) {
+		if pattern&allMedia == allMedia {
+			return
+		}
+		recovered := recover(pattern) & allMedia
+		repaired := recovered &^ pattern
+		for fecIndex, row := range rows {
+			fecBit := uint64(1) << (N + fecIndex)
+			if pattern&fecBit == 0 {
+				continue
+			}
+			if lost := row &^ pattern; lost != 0 && lost&repaired == lost {
+				usage[fecIndex] += probability
+			}
+			if recover(pattern&^fecBit)&allMedia != recovered {
+				pivotal[fecIndex] += probability
+			}
+		}
+	})
+
+	utilization := 0.0
+	for _, rowUsage := range usage {
+		utilization += rowUsage
+	}
+	return RedundancyUtilization{RowUsage: usage, RowPivotal: pivotal, Utilization: utilization / float64(K)}, nil
+}
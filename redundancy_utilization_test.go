@@ -0,0 +1,49 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateRedundancyUtilization(t *testing.T) {
+	// The parity row of 2 media packets repairs exactly one lost media packet when it
+	// arrives, 2 * 0.1 * 0.9 * 0.9 = 0.162 under 10% random loss; the empty row never helps
+	mask, err := NewMatrixMask([][]bool{{true, true}, {false, false}})
+	require.NoError(t, err)
+	model := NewRandomLossModel(0.1)
+	for _, mode := range []DecodingMode{PeelingDecoding, MLDecoding} {
+		utilization, err := CalculateRedundancyUtilization(mask, model, mode)
+		require.NoError(t, err)
+		assert.InDeltaSlice(t, []float64{0.162, 0}, utilization.RowUsage, 1e-12, "%s", mode)
+		assert.InDeltaSlice(t, []float64{0.162, 0}, utilization.RowPivotal, 1e-12, "%s", mode)
+		assert.InDelta(t, 0.081, utilization.Utilization, 1e-12, "%s", mode)
+		assert.Equal(t, []int{1}, utilization.DeadRows(0.01), "%s", mode)
+	}
+
+	// Duplicate rows both take part in every repair they arrive for, but each only makes a
+	// difference when the other copy is lost
+	duplicate, err := NewMatrixMask([][]bool{{true, true}, {true, true}})
+	require.NoError(t, err)
+	for _, mode := range []DecodingMode{PeelingDecoding, MLDecoding} {
+		utilization, err := CalculateRedundancyUtilization(duplicate, model, mode)
+		require.NoError(t, err)
+		assert.InDeltaSlice(t, []float64{0.162, 0.162}, utilization.RowUsage, 1e-12, "%s", mode)
+		assert.InDeltaSlice(t, []float64{0.162 * 0.1, 0.162 * 0.1}, utilization.RowPivotal, 1e-12, "%s", mode)
+		assert.InDelta(t, 0.162, utilization.Utilization, 1e-12, "%s", mode)
+	}
+
+	// Every row of a WebRTC mask helps under a bursty channel
+	webrtc, err := (&GoogleBurstyMaskFactory{}).CreateMask(8, 4)
+	require.NoError(t, err)
+	utilization, err := CalculateRedundancyUtilization(webrtc, NewGilbertElliotLossModel(0.01, 0.5, 0.05, 0.3), PeelingDecoding)
+	require.NoError(t, err)
+	assert.Len(t, utilization.RowUsage, 4)
+	assert.Empty(t, utilization.DeadRows(1e-6))
+
+	big, err := (&InterleavedMaskFactory{}).CreateMask(20, 10)
+	require.NoError(t, err)
+	_, err = CalculateRedundancyUtilization(big, model, PeelingDecoding)
+	assert.Error(t, err)
+}
@@ -0,0 +1,102 @@
+package fecanalysis
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxReedSolomonShards is the largest total shard count of a GF(2^8) Reed-Solomon encoder,
+// the limit enforced by klauspost/reedsolomon.New for the default codec
+const maxReedSolomonShards = 256
+
+// ReedSolomonConfig describes a storage-style erasure code given as data/parity shard
+// counts, as passed to klauspost/reedsolomon.New(dataShards, parityShards). Reed-Solomon
+// codes are MDS, so the configuration evaluates as an ideal MDS code with N = DataShards
+// media packets and K = ParityShards FEC packets.
+type ReedSolomonConfig struct {
+	DataShards   int
+	ParityShards int
+}
+
+// NewReedSolomonConfig validates the shard counts the same way klauspost/reedsolomon does
+func NewReedSolomonConfig(dataShards, parityShards int) (ReedSolomonConfig, error) {
+	if dataShards <= 0 || parityShards < 0 {
+		return ReedSolomonConfig{}, fmt.Errorf("invalid shard counts %d+%d: need at least one data shard and no negative parity", dataShards, parityShards)
+	}
+	if dataShards+parityShards > maxReedSolomonShards {
+		return ReedSolomonConfig{}, fmt.Errorf("%d+%d shards exceed the maximum of %d", dataShards, parityShards, maxReedSolomonShards)
+	}
+	return ReedSolomonConfig{DataShards: dataShards, ParityShards: parityShards}, nil
+}
+
+// ParseReedSolomonConfig parses a "data+parity" shard specification such as "10+4"
+func ParseReedSolomonConfig(spec string) (ReedSolomonConfig, error) {
+	dataPart, parityPart, found := strings.Cut(strings.TrimSpace(spec), "+")
+	if !found {
+		return ReedSolomonConfig{}, fmt.Errorf("invalid Reed-Solomon config %q: expected data+parity", spec)
+	}
+	dataShards, err := strconv.Atoi(strings.TrimSpace(dataPart))
+	if err != nil {
+		return ReedSolomonConfig{}, fmt.Errorf("invalid data shard count in %q: %w", spec, err)
+	}
+	parityShards, err := strconv.Atoi(strings.TrimSpace(parityPart))
+	if err != nil {
+		return ReedSolomonConfig{}, fmt.Errorf("invalid parity shard count in %q: %w", spec, err)
+	}
+	return NewReedSolomonConfig(dataShards, parityShards)
+}
+
+// String formats the configuration as "data+parity"
+func (c ReedSolomonConfig) String() string {
+	return fmt.Sprintf("%d+%d", c.DataShards, c.ParityShards)
+}
+
+// N returns the number of media packets of the equivalent configuration
+func (c ReedSolomonConfig) N() int {
+	return c.DataShards
+}
+
+// K returns the number of FEC packets of the equivalent configuration
+func (c ReedSolomonConfig) K() int {
+	return c.ParityShards
+}
+
+// Overhead returns the parity overhead relative to the data (K/N)
+func (c ReedSolomonConfig) Overhead() float64 {
+	return float64(c.ParityShards) / float64(c.DataShards)
+}
+
+// Graph returns the MDS recovery graph of the configuration. Like RecoveryGraph it has
+// 2^(N+K) vertices, so it is only practical for small shard counts.
+func (c ReedSolomonConfig) Graph() *MDSGraph {
+	return NewMDSGraph(c.DataShards, c.ParityShards)
+}
+
+// RecoveryProbability returns the probability that all data shards are recovered when
+// the shards are sent consecutively over the loss model
+func (c ReedSolomonConfig) RecoveryProbability(model LossModel) float64 {
+	return MDSRecoveryProbability(model, c.DataShards, c.ParityShards)
+}
+
+// RecoveryCharacteristics returns the recovery characteristics of the configuration: any
+// ParityShards losses are recovered, and losing one more including a data shard is not.
+// Without parity shards there is no FEC burst, so MinFECBurstForNonRecovery is
+// PerfectRecovery as for masks without FEC packets.
+func (c ReedSolomonConfig) RecoveryCharacteristics() RecoveryCharacteristics {
+	minMediaBurst := LossCount(c.ParityShards + 1)
+	if c.ParityShards+1 > c.DataShards {
+		minMediaBurst = PerfectRecovery
+	}
+	minFECBurst := LossCount(c.ParityShards)
+	if minFECBurst == 0 {
+		minFECBurst = PerfectRecovery
+	}
+	return RecoveryCharacteristics{
+		MinLostPacketsForNonRecovery:     LossCount(c.ParityShards + 1),
+		MinConsecutiveLostForNonRecovery: LossCount(c.ParityShards + 1),
+		MinMediaBurstForNonRecovery:      minMediaBurst,
+		MinFECBurstForNonRecovery:        minFECBurst,
+		MaxGuaranteedLosses:              c.ParityShards,
+	}
+}
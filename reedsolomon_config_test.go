@@ -0,0 +1,59 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReedSolomonConfig(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    ReedSolomonConfig
+		wantErr bool
+	}{
+		{"10+4", ReedSolomonConfig{DataShards: 10, ParityShards: 4}, false},
+		{" 6 + 3 ", ReedSolomonConfig{DataShards: 6, ParityShards: 3}, false},
+		{"4+0", ReedSolomonConfig{DataShards: 4, ParityShards: 0}, false},
+		{"200+56", ReedSolomonConfig{DataShards: 200, ParityShards: 56}, false},
+		{"200+57", ReedSolomonConfig{}, true},
+		{"0+2", ReedSolomonConfig{}, true},
+		{"4+-1", ReedSolomonConfig{}, true},
+		{"4x2", ReedSolomonConfig{}, true},
+		{"a+2", ReedSolomonConfig{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			config, err := ParseReedSolomonConfig(tt.spec)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, config)
+		})
+	}
+}
+
+func TestReedSolomonConfigMatchesMDS(t *testing.T) {
+	config, err := NewReedSolomonConfig(4, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "4+2", config.String())
+	assert.Equal(t, 4, config.N())
+	assert.Equal(t, 2, config.K())
+	assert.InDelta(t, 0.5, config.Overhead(), 1e-12)
+
+	model := NewRandomLossModel(0.1)
+	assert.InDelta(t, MDSRecoveryProbability(model, 4, 2), config.RecoveryProbability(model), 1e-12)
+
+	// The closed-form characteristics agree with the BFS over the MDS graph, also without
+	// parity shards
+	for _, parityShards := range []int{2, 0} {
+		config, err := NewReedSolomonConfig(4, parityShards)
+		require.NoError(t, err)
+		reachable := BFS(config.Graph(), GoodVertices(4, parityShards))
+		assert.Equal(t, CalculateRecoveryCharacteristicsFromReachable(4, parityShards, reachable), config.RecoveryCharacteristics(), config.String())
+	}
+}
@@ -0,0 +1,51 @@
+package fecanalysis
+
+import "fmt"
+
+// sensitivityStep is the relative step of the finite differences of RecoverySensitivity
+const sensitivityStep = 1e-4
+
+// ChannelSensitivity holds the recovery probability of a mask at an operating point of a
+// bursty channel and its derivatives with respect to the channel parameters
+type ChannelSensitivity struct {
+	RecoveryProbability float64 `json:"recovery_probability"` // recovery probability at the operating point
+	PerLossProbability  float64 `json:"per_loss_probability"` // derivative with respect to the average loss probability, at a fixed mean burst length
+	PerBurstLength      float64 `json:"per_burst_length"`     // derivative with respect to the mean burst length in packets, at a fixed loss probability
+}
+
+// RecoverySensitivity returns the recovery probability of the mask on the GilbertBurstFamily
+// channel with the given loss probability and mean burst length, and how fast it changes with
+// either parameter, by central finite differences. Masks with small derivatives keep their
+// performance when the channel is estimated wrongly, rather than being optimal at one
+// operating point only. The recoverable set is computed once. The loss probability must be
+// below meanBurstLength/(meanBurstLength+1), where the family saturates.
+func RecoverySensitivity(mask Mask, lossProbability, meanBurstLength float64, mode DecodingMode) (ChannelSensitivity, error) {
+	N, K := mask.N(), mask.K()
+	if err := checkEnumerable(N, K); err != nil {
+		return ChannelSensitivity{}, err
+	}
+	if meanBurstLength < 1 {
+		return ChannelSensitivity{}, fmt.Errorf("mean burst length %g must be at least 1", meanBurstLength)
+	}
+	if lossProbability <= 0 || lossProbability >= meanBurstLength/(meanBurstLength+1) {
+		return ChannelSensitivity{}, fmt.Errorf("loss probability %g is not in (0, %g) for mean burst length %g",
+			lossProbability, meanBurstLength/(meanBurstLength+1), meanBurstLength)
+	}
+
+	set := RecoverableSet(mask, mode)
+	recoveryProbability := func(lossProbability, meanBurstLength float64) float64 {
+		return SumProbabilities(set, GilbertBurstFamily(meanBurstLength)(lossProbability), N+K, 0)
+	}
+
+	// Steps stay inside the valid ranges: a mean burst length of 1 is differenced forward
+	lossStep := sensitivityStep * min(lossProbability, meanBurstLength/(meanBurstLength+1)-lossProbability)
+	lengthStep := sensitivityStep * meanBurstLength
+	lengthLow := max(meanBurstLength-lengthStep, 1)
+	return ChannelSensitivity{
+		RecoveryProbability: recoveryProbability(lossProbability, meanBurstLength),
+		PerLossProbability: (recoveryProbability(lossProbability+lossStep, meanBurstLength) -
+			recoveryProbability(lossProbability-lossStep, meanBurstLength)) / (2 * lossStep),
+		PerBurstLength: (recoveryProbability(lossProbability, meanBurstLength+lengthStep) -
+			recoveryProbability(lossProbability, lengthLow)) / (meanBurstLength + lengthStep - lengthLow),
+	}, nil
+}
@@ -0,0 +1,35 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoverySensitivity(t *testing.T) {
+	mask, err := (&GoogleBurstyMaskFactory{}).CreateMask(8, 4)
+	require.NoError(t, err)
+	set := RecoverableSet(mask, PeelingDecoding)
+	recoveryProbability := func(lossProbability, meanBurstLength float64) float64 {
+		return SumProbabilities(set, GilbertBurstFamily(meanBurstLength)(lossProbability), 12, 0)
+	}
+
+	for _, meanBurstLength := range []float64{1, 2, 4} {
+		sensitivity, err := RecoverySensitivity(mask, 0.05, meanBurstLength, PeelingDecoding)
+		require.NoError(t, err)
+		assert.InDelta(t, recoveryProbability(0.05, meanBurstLength), sensitivity.RecoveryProbability, 1e-12)
+
+		// Coarser differences agree, and more losses only hurt
+		perLoss := (recoveryProbability(0.051, meanBurstLength) - recoveryProbability(0.049, meanBurstLength)) / 0.002
+		assert.InDelta(t, perLoss, sensitivity.PerLossProbability, 1e-3, "burst length %g", meanBurstLength)
+		assert.Negative(t, sensitivity.PerLossProbability)
+		perLength := (recoveryProbability(0.05, meanBurstLength+0.01) - recoveryProbability(0.05, meanBurstLength)) / 0.01
+		assert.InDelta(t, perLength, sensitivity.PerBurstLength, 1e-3, "burst length %g", meanBurstLength)
+	}
+
+	for _, invalid := range [][2]float64{{0, 2}, {0.7, 2}, {0.05, 0.5}} {
+		_, err := RecoverySensitivity(mask, invalid[0], invalid[1], PeelingDecoding)
+		assert.Error(t, err, "%v", invalid)
+	}
+}
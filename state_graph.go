@@ -0,0 +1,53 @@
+package fecanalysis
+
+import "iter"
+
+// StateGraph is a graph over arbitrary comparable states, for traversals of state spaces that
+// are not packet bitmasks, such as sliding-window decoder states. Graph is the bitmask
+// specialization and satisfies StateGraph[uint64]; unlike it, a StateGraph need not know its
+// number of vertices up front.
+type StateGraph[V comparable] interface {
+	// VisitEdges calls fn for every edge from the given vertex; it stops as soon as fn
+	// returns false
+	VisitEdges(vertex V, fn func(dst V) bool)
+}
+
+// StateGraphFunc adapts an edge visiting function to a StateGraph
+type StateGraphFunc[V comparable] func(vertex V, fn func(dst V) bool)
+
+// VisitEdges calls f(vertex, fn)
+func (f StateGraphFunc[V]) VisitEdges(vertex V, fn func(dst V) bool) {
+	f(vertex, fn)
+}
+
+// StateBFSIter returns an iterator over the states reachable from any of the sources, in BFS
+// order. It shares the traversal of BFSIter; visited states are kept in a map, except for a
+// Graph traversed from uint64 sources, which takes the BFSIter fast path with a bitset.
+func StateBFSIter[V comparable](graph StateGraph[V], sources []V) iter.Seq[V] {
+	if bitmaskGraph, ok := any(graph).(Graph); ok {
+		if bitmaskSources, ok := any(sources).([]uint64); ok {
+			return any(BFSIter(bitmaskGraph, bitmaskSources)).(iter.Seq[V])
+		}
+	}
+
+	return func(yield func(V) bool) {
+		visited := make(map[V]bool)
+		mark := func(vertex V) bool {
+			if visited[vertex] {
+				return false
+			}
+			visited[vertex] = true
+			return true
+		}
+		traverse(graph.VisitEdges, sources, mark, func(vertex V, _ int32) bool { return yield(vertex) }, nil)
+	}
+}
+
+// StateBFS returns the states reachable from any of the sources, in BFS order
+func StateBFS[V comparable](graph StateGraph[V], sources []V) []V {
+	var reachable []V
+	for vertex := range StateBFSIter(graph, sources) {
+		reachable = append(reachable, vertex)
+	}
+	return reachable
+}
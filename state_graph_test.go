@@ -0,0 +1,56 @@
+package fecanalysis
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// windowState is a toy sliding-window decoder state: the window start and the lost packets
+// in the window
+type windowState struct {
+	start int
+	lost  string
+}
+
+func TestStateBFS(t *testing.T) {
+	// The window slides until packet 3 and may recover one lost packet per step
+	graph := StateGraphFunc[windowState](func(state windowState, fn func(windowState) bool) {
+		if state.start < 3 && !fn(windowState{start: state.start + 1, lost: state.lost}) {
+			return
+		}
+		if len(state.lost) > 0 {
+			fn(windowState{start: state.start, lost: state.lost[1:]})
+		}
+	})
+
+	reachable := StateBFS[windowState](graph, []windowState{{start: 0, lost: "ab"}})
+	require.Len(t, reachable, 12)
+	assert.Equal(t, windowState{start: 0, lost: "ab"}, reachable[0])
+	assert.Contains(t, reachable, windowState{start: 3, lost: ""})
+
+	// Early termination stops the search
+	count := 0
+	for range StateBFSIter[windowState](graph, []windowState{{start: 0, lost: "ab"}}) {
+		count++
+		if count == 2 {
+			break
+		}
+	}
+	assert.Equal(t, 2, count)
+}
+
+func TestStateBFSMatchesBitmaskBFS(t *testing.T) {
+	mask, err := (&GoogleRandomMaskFactory{}).CreateMask(5, 3)
+	require.NoError(t, err)
+	graph := NewRecoveryGraph(mask)
+	sources := GoodVertices(5, 3)
+	expected := slices.Collect(BFSIter(graph, sources))
+
+	// The fast path and the generic map-based traversal agree
+	assert.Equal(t, expected, StateBFS[uint64](graph, sources))
+	generic := StateGraphFunc[uint64](graph.VisitEdges)
+	assert.Equal(t, expected, StateBFS[uint64](generic, sources))
+}
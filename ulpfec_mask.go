@@ -0,0 +1,132 @@
+package fecanalysis
+
+import "fmt"
+
+// ULPMask models RFC 5109 ULPFEC protection levels. Each FEC packet carries one
+// protection level per level mask: level 0 protects the first ProtectionLength(0) bytes of
+// the media packets in its level-0 mask, level 1 the next ProtectionLength(1) bytes of the
+// packets in its level-1 mask, and so on. Levels are recovered independently, so a lost
+// media packet may get its important leading bytes back even when later levels fail.
+type ULPMask struct {
+	levels  []Mask // protection mask of every level
+	lengths []int  // protection length in bytes of every level
+}
+
+// NewULPMask creates a multi-level ULPFEC mask from one mask per level and the byte
+// length protected by each level. All level masks must have the same N and K.
+func NewULPMask(levels []Mask, protectionLengths []int) (*ULPMask, error) {
+	if len(levels) == 0 {
+		return nil, fmt.Errorf("ULPFEC mask needs at least one protection level")
+	}
+	if len(levels) != len(protectionLengths) {
+		return nil, fmt.Errorf("got %d level masks but %d protection lengths", len(levels), len(protectionLengths))
+	}
+
+	N, K := levels[0].N(), levels[0].K()
+	for level, mask := range levels {
+		if mask.N() != N || mask.K() != K {
+			return nil, fmt.Errorf("level %d mask is %dx%d, expected %dx%d", level, mask.K(), mask.N(), K, N)
+		}
+		if protectionLengths[level] <= 0 {
+			return nil, fmt.Errorf("level %d protection length must be positive, got %d", level, protectionLengths[level])
+		}
+	}
+
+	return &ULPMask{
+		levels:  append([]Mask(nil), levels...),
+		lengths: append([]int(nil), protectionLengths...),
+	}, nil
+}
+
+// IsProtected returns true if the packet is protected by the FEC packet at any level
+func (m *ULPMask) IsProtected(packetIndex, fecIndex int) bool {
+	for _, level := range m.levels {
+		if level.IsProtected(packetIndex, fecIndex) {
+			return true
+		}
+	}
+	return false
+}
+
+// N returns the number of media packets
+func (m *ULPMask) N() int {
+	return m.levels[0].N()
+}
+
+// K returns the number of FEC packets
+func (m *ULPMask) K() int {
+	return m.levels[0].K()
+}
+
+// NumLevels returns the number of protection levels
+func (m *ULPMask) NumLevels() int {
+	return len(m.levels)
+}
+
+// Level returns the protection mask of the given level; NewRecoveryGraph(m.Level(i))
+// is the recovery graph for the bytes protected by that level
+func (m *ULPMask) Level(level int) Mask {
+	return m.levels[level]
+}
+
+// ProtectionLength returns the number of bytes protected by the given level
+func (m *ULPMask) ProtectionLength(level int) int {
+	return m.lengths[level]
+}
+
+// TotalProtectionLength returns the number of bytes protected by all levels together
+func (m *ULPMask) TotalProtectionLength() int {
+	total := 0
+	for _, length := range m.lengths {
+		total += length
+	}
+	return total
+}
+
+// RecoveredLevels returns, for every media packet, how many leading protection levels
+// are available after level-wise peeling recovery of the delivery pattern. Delivered
+// packets report NumLevels(). Only leading levels count, since the bytes of level i+1
+// are useless to a decoder without the bytes of level i before them.
+func (m *ULPMask) RecoveredLevels(pattern int) []int {
+	N := m.N()
+	recovered := make([]int, N)
+	available := uint64(1)<<N - 1 // packets whose leading levels are all available so far
+
+	for level, levelMask := range m.levels {
+		closure := peelingClosure(patternRows(levelMask), N, uint64(pattern))
+		available &= closure
+		for packetIndex := 0; packetIndex < N; packetIndex++ {
+			if available&(1<<packetIndex) != 0 {
+				recovered[packetIndex] = level + 1
+			}
+		}
+	}
+	return recovered
+}
+
+// RecoveredBytes returns, for every media packet, how many leading protected bytes are
+// available after recovery. Delivered packets report TotalProtectionLength().
+func (m *ULPMask) RecoveredBytes(pattern int) []int {
+	levels := m.RecoveredLevels(pattern)
+	recovered := make([]int, len(levels))
+	for packetIndex, count := range levels {
+		for level := 0; level < count; level++ {
+			recovered[packetIndex] += m.lengths[level]
+		}
+	}
+	return recovered
+}
+
+// ULPRecoverableSet returns the delivery patterns from which levels 0..maxLevel of every
+// media packet can be recovered, i.e. the intersection of the per-level recoverable sets
+func ULPRecoverableSet(mask *ULPMask, maxLevel int) *ReachableSet {
+	if maxLevel < 0 || maxLevel >= mask.NumLevels() {
+		return nil
+	}
+
+	set := recoverableSet(mask.Level(0))
+	for level := 1; level <= maxLevel; level++ {
+		set.Intersect(recoverableSet(mask.Level(level)))
+	}
+	return set
+}
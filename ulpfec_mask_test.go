@@ -0,0 +1,69 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestULPMaskLevelRecovery(t *testing.T) {
+	// Level 0 (first 20 bytes): FEC 0 protects packets 0,1 and FEC 1 protects packets 2,3
+	level0 := NewSimpleMask([][]bool{
+		{true, true, false, false},
+		{false, false, true, true},
+	}, 4, 2)
+	// Level 1 (next 100 bytes): FEC 0 protects all packets, FEC 1 nothing
+	level1 := NewSimpleMask([][]bool{
+		{true, true, true, true},
+		{false, false, false, false},
+	}, 4, 2)
+
+	mask, err := NewULPMask([]Mask{level0, level1}, []int{20, 100})
+	require.NoError(t, err)
+	assert.Equal(t, 4, mask.N())
+	assert.Equal(t, 2, mask.K())
+	assert.Equal(t, 2, mask.NumLevels())
+	assert.Equal(t, 120, mask.TotalProtectionLength())
+	assert.True(t, mask.IsProtected(3, 0), "packet 3 is protected by FEC 0 at level 1")
+
+	// Lose packets 0 and 2, both FEC delivered: level 0 recovers both, level 1 recovers neither
+	pattern := 0b1010 | 0b11<<4
+	assert.Equal(t, []int{1, 2, 1, 2}, mask.RecoveredLevels(pattern))
+	assert.Equal(t, []int{20, 120, 20, 120}, mask.RecoveredBytes(pattern))
+
+	// Lose only packet 3: both levels recover it
+	pattern = 0b0111 | 0b11<<4
+	assert.Equal(t, []int{2, 2, 2, 2}, mask.RecoveredLevels(pattern))
+}
+
+func TestULPRecoverableSet(t *testing.T) {
+	level0 := NewSimpleMask([][]bool{{true, true}}, 2, 1)
+	level1 := NewSimpleMask([][]bool{{true, false}}, 2, 1)
+	mask, err := NewULPMask([]Mask{level0, level1}, []int{10, 10})
+	require.NoError(t, err)
+
+	level0Set := ULPRecoverableSet(mask, 0)
+	bothSet := ULPRecoverableSet(mask, 1)
+
+	// Losing packet 1 with FEC delivered is recoverable at level 0 only
+	assert.True(t, level0Set.Contains(0b101))
+	assert.False(t, bothSet.Contains(0b101))
+	// Losing packet 0 with FEC delivered is recoverable at both levels
+	assert.True(t, bothSet.Contains(0b110))
+	assert.Nil(t, ULPRecoverableSet(mask, 2))
+}
+
+func TestNewULPMaskValidation(t *testing.T) {
+	a := NewSimpleMask([][]bool{{true, true}}, 2, 1)
+	b := NewSimpleMask([][]bool{{true, true, true}}, 3, 1)
+
+	_, err := NewULPMask(nil, nil)
+	assert.Error(t, err)
+	_, err = NewULPMask([]Mask{a, b}, []int{10, 10})
+	assert.Error(t, err)
+	_, err = NewULPMask([]Mask{a}, []int{0})
+	assert.Error(t, err)
+	_, err = NewULPMask([]Mask{a}, []int{10, 20})
+	assert.Error(t, err)
+}
@@ -0,0 +1,40 @@
+package fecanalysis
+
+// VisitedMarker records the vertices a traversal has reached, see BFSWithMarker. A ReachableSet
+// is a VisitedMarker; other implementations can trade speed for memory, e.g. compressed or
+// disk-backed bitmaps.
+type VisitedMarker interface {
+	// MarkReachable marks the vertex as reached
+	MarkReachable(vertex uint64)
+	// IsReachable returns true if the vertex has been marked
+	IsReachable(vertex uint64) bool
+}
+
+// BitsetVisitedMarker is a VisitedMarker that owns the storage of its ReachableSet, either in
+// memory or in a memory-mapped file for graphs whose bitset does not fit in RAM
+type BitsetVisitedMarker interface {
+	VisitedMarker
+	// Set returns the bitset; it is valid until Close
+	Set() *ReachableSet
+	// Close releases the storage
+	Close() error
+}
+
+// memoryVisitedMarker keeps the bitset on the heap
+type memoryVisitedMarker struct {
+	*ReachableSet
+}
+
+func (m memoryVisitedMarker) Set() *ReachableSet { return m.ReachableSet }
+
+func (m memoryVisitedMarker) Close() error { return nil }
+
+// NewVisitedMarker returns an in-memory marker for numVertices vertices when its bitset takes at
+// most memoryLimit bytes and a DiskVisitedMarker in dir otherwise; an empty dir selects the
+// default temporary directory
+func NewVisitedMarker(numVertices, memoryLimit uint64, dir string) (BitsetVisitedMarker, error) {
+	if (numVertices+63)/64*8 <= memoryLimit {
+		return memoryVisitedMarker{NewReachableSet(numVertices)}, nil
+	}
+	return NewDiskVisitedMarker(numVertices, dir)
+}
@@ -0,0 +1,32 @@
+//go:build !unix
+
+package fecanalysis
+
+import "fmt"
+
+// DiskVisitedMarker keeps the bitset in a memory-mapped temporary file; it is only available on
+// unix systems
+type DiskVisitedMarker struct{}
+
+// NewDiskVisitedMarker reports that disk-backed markers are not supported on this system
+func NewDiskVisitedMarker(numVertices uint64, dir string) (*DiskVisitedMarker, error) {
+	return nil, fmt.Errorf("disk-backed visited bitsets are only supported on unix systems")
+}
+
+// MarkReachable does nothing, as no marker can be created on this system
+func (m *DiskVisitedMarker) MarkReachable(vertex uint64) {}
+
+// IsReachable returns false, as no marker can be created on this system
+func (m *DiskVisitedMarker) IsReachable(vertex uint64) bool {
+	return false
+}
+
+// Set returns nil, as no marker can be created on this system
+func (m *DiskVisitedMarker) Set() *ReachableSet {
+	return nil
+}
+
+// Close does nothing
+func (m *DiskVisitedMarker) Close() error {
+	return nil
+}
@@ -0,0 +1,102 @@
+//go:build unix
+
+package fecanalysis
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskVisitedMarker(t *testing.T) {
+	dir := t.TempDir()
+	marker, err := NewDiskVisitedMarker(1000, dir)
+	require.NoError(t, err)
+
+	// The backing file is unlinked as soon as it is mapped
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	set := marker.Set()
+	assert.Equal(t, uint64(1000), set.NumVertices())
+	assert.True(t, set.Add(999))
+	assert.False(t, set.Add(999))
+	assert.True(t, set.Contains(999))
+	assert.False(t, set.Contains(998))
+	assert.Equal(t, []uint64{999}, set.Vertices())
+
+	require.NoError(t, marker.Close())
+	require.NoError(t, marker.Close())
+}
+
+func TestRecoverableSetIntoDisk(t *testing.T) {
+	mask, err := (&InterleavedMaskFactory{}).CreateMask(8, 4)
+	require.NoError(t, err)
+
+	marker, err := NewDiskVisitedMarker(1<<12, t.TempDir())
+	require.NoError(t, err)
+	defer marker.Close()
+
+	require.NoError(t, RecoverableSetInto(context.Background(), mask, PeelingDecoding, marker.Set(), nil))
+	expected := RecoverableSet(mask, PeelingDecoding)
+	assert.Equal(t, expected.Vertices(), marker.Set().Vertices())
+
+	// A used or mis-sized set is rejected
+	assert.Error(t, RecoverableSetInto(context.Background(), mask, PeelingDecoding, marker.Set(), nil))
+	assert.Error(t, RecoverableSetInto(context.Background(), mask, PeelingDecoding, NewReachableSet(1<<11), nil))
+}
+
+func TestNewVisitedMarker(t *testing.T) {
+	inMemory, err := NewVisitedMarker(1<<12, 1<<9, t.TempDir())
+	require.NoError(t, err)
+	assert.IsType(t, memoryVisitedMarker{}, inMemory)
+
+	onDisk, err := NewVisitedMarker(1<<12, 1<<8, t.TempDir())
+	require.NoError(t, err)
+	assert.IsType(t, &DiskVisitedMarker{}, onDisk)
+
+	for _, marker := range []BitsetVisitedMarker{inMemory, onDisk} {
+		assert.Equal(t, uint64(1<<12), marker.Set().NumVertices())
+		marker.MarkReachable(5)
+		assert.True(t, marker.IsReachable(5))
+		assert.True(t, marker.Set().Contains(5))
+		require.NoError(t, marker.Close())
+	}
+}
+
+func TestBFSWithDiskVisitedMarker(t *testing.T) {
+	mask, err := (&GoogleBurstyMaskFactory{}).CreateMask(8, 4)
+	require.NoError(t, err)
+	graph := NewRecoveryGraph(mask)
+
+	marker, err := NewDiskVisitedMarker(graph.NumVertices(), t.TempDir())
+	require.NoError(t, err)
+	defer marker.Close()
+
+	expected := ReachableBFS(graph, GoodVertices(8, 4))
+	assert.Equal(t, expected.Count(), BFSWithMarker(graph, GoodVertices(8, 4), marker))
+	assert.Equal(t, expected.Vertices(), marker.Set().Vertices())
+}
+
+func TestBFSWithFrontier(t *testing.T) {
+	mask, err := (&GoogleBurstyMaskFactory{}).CreateMask(8, 4)
+	require.NoError(t, err)
+	graph := NewRecoveryGraph(mask)
+
+	var markers [3]*DiskVisitedMarker
+	for i := range markers {
+		markers[i], err = NewDiskVisitedMarker(graph.NumVertices(), t.TempDir())
+		require.NoError(t, err)
+		defer markers[i].Close()
+	}
+	// Stale frontier contents are discarded
+	markers[1].MarkReachable(0)
+
+	expected := ReachableBFS(graph, GoodVertices(8, 4))
+	assert.Equal(t, expected.Count(), BFSWithFrontier(graph, GoodVertices(8, 4), markers[0], markers[1], markers[2]))
+	assert.Equal(t, expected.Vertices(), markers[0].Set().Vertices())
+}
@@ -0,0 +1,78 @@
+//go:build unix
+
+package fecanalysis
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// DiskVisitedMarker keeps the bitset in a memory-mapped temporary file, so the page cache rather
+// than the heap holds it. The 512 MiB bitset of N+K=32 then works on small machines at the cost
+// of page faults. The file is unlinked right after mapping and disappears with the mapping.
+type DiskVisitedMarker struct {
+	set  *ReachableSet
+	data []byte
+}
+
+// NewDiskVisitedMarker creates an empty disk-backed marker for the vertices 0..numVertices-1 in
+// dir; an empty dir selects the default temporary directory
+func NewDiskVisitedMarker(numVertices uint64, dir string) (*DiskVisitedMarker, error) {
+	size := max((numVertices+63)/64*8, 8)
+	if size > uint64(^uint(0)>>1) {
+		return nil, fmt.Errorf("visited bitset of %d vertices exceeds the address space", numVertices)
+	}
+
+	file, err := os.CreateTemp(dir, "fec-visited-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create visited bitset file: %w", err)
+	}
+	defer file.Close()
+	defer os.Remove(file.Name())
+
+	// Truncating extends the file with zeros, which is an empty set, without writing them
+	if err := file.Truncate(int64(size)); err != nil {
+		return nil, fmt.Errorf("failed to size visited bitset file: %w", err)
+	}
+	data, err := syscall.Mmap(int(file.Fd()), 0, int(size), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map visited bitset file: %w", err)
+	}
+
+	set := &ReachableSet{
+		words:       unsafe.Slice((*uint64)(unsafe.Pointer(&data[0])), (numVertices+63)/64),
+		numVertices: numVertices,
+	}
+	return &DiskVisitedMarker{set: set, data: data}, nil
+}
+
+// MarkReachable marks the vertex in the bitset
+func (m *DiskVisitedMarker) MarkReachable(vertex uint64) {
+	m.set.Add(vertex)
+}
+
+// IsReachable returns true if the vertex is marked in the bitset
+func (m *DiskVisitedMarker) IsReachable(vertex uint64) bool {
+	return m.set.Contains(vertex)
+}
+
+// Set returns the bitset; it is valid until Close
+func (m *DiskVisitedMarker) Set() *ReachableSet {
+	return m.set
+}
+
+// Close unmaps the bitset, which also frees its file
+func (m *DiskVisitedMarker) Close() error {
+	if m.data == nil {
+		return nil
+	}
+	m.set.words = nil
+	err := syscall.Munmap(m.data)
+	m.data = nil
+	if err != nil {
+		return fmt.Errorf("failed to unmap visited bitset file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package fecanalysis
+
+import "fmt"
+
+const (
+	webrtcShortMaskBytes = 2  // bytes per row of WebRTC masks for up to 16 media packets
+	webrtcLongMaskBytes  = 6  // bytes per row of WebRTC masks for up to 48 media packets
+	maxWebRTCMaskPackets = 48 // largest number of media packets a WebRTC mask can protect
+)
+
+// webrtcRowBytes returns the number of bytes per row WebRTC uses for N media packets
+func webrtcRowBytes(N int) int {
+	if N <= 8*webrtcShortMaskBytes {
+		return webrtcShortMaskBytes
+	}
+	return webrtcLongMaskBytes
+}
+
+// ExportWebRTCBytes encodes a mask in the WebRTC packet-mask table format: one row per FEC
+// packet, media packet i at bit 7-i%8 of byte i/8 (MSB first). Rows are 2 bytes for up to
+// 16 media packets and 6 bytes for up to 48, as in WebRTC's short and long masks, so
+// optimized masks can be dropped into a real encoder. Masks with more than 48 media
+// packets cannot be represented and yield nil.
+func ExportWebRTCBytes(mask Mask) []byte {
+	N, K := mask.N(), mask.K()
+	if N > maxWebRTCMaskPackets {
+		return nil
+	}
+
+	rowBytes := webrtcRowBytes(N)
+	data := make([]byte, K*rowBytes)
+	for fecIndex := 0; fecIndex < K; fecIndex++ {
+		for packetIndex := 0; packetIndex < N; packetIndex++ {
+			if mask.IsProtected(packetIndex, fecIndex) {
+				data[fecIndex*rowBytes+packetIndex/8] |= 1 << (7 - packetIndex%8)
+			}
+		}
+	}
+	return data
+}
+
+// NewMaskFromWebRTCBytes creates a mask from bytes in the WebRTC packet-mask table format,
+// the inverse of ExportWebRTCBytes
+func NewMaskFromWebRTCBytes(data []byte, N, K int) (Mask, error) {
+	if N <= 0 || K <= 0 || N > maxWebRTCMaskPackets {
+		return nil, fmt.Errorf("invalid parameters for WebRTC mask: N=%d, K=%d", N, K)
+	}
+
+	rowBytes := webrtcRowBytes(N)
+	if len(data) != K*rowBytes {
+		return nil, fmt.Errorf("WebRTC mask for N=%d, K=%d needs %d bytes, got %d", N, K, K*rowBytes, len(data))
+	}
+
+	return &bitMask{
+		data:     append([]byte(nil), data...),
+		n:        N,
+		k:        K,
+		rowBytes: rowBytes,
+	}, nil
+}
@@ -0,0 +1,54 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportWebRTCBytesMatchesBuiltinTables(t *testing.T) {
+	factories := map[string]interface {
+		MaskFactory
+		BuiltinTable() MaskTable
+	}{
+		"bursty": &GoogleBurstyMaskFactory{},
+		"random": &GoogleRandomMaskFactory{},
+	}
+
+	for name, factory := range factories {
+		for size, expected := range factory.BuiltinTable() {
+			mask, err := factory.CreateMask(size.N, size.K)
+			require.NoError(t, err)
+			assert.Equal(t, expected, ExportWebRTCBytes(mask), "%s N=%d K=%d", name, size.N, size.K)
+		}
+	}
+}
+
+func TestExportWebRTCBytesLongMask(t *testing.T) {
+	mask, err := (&InterleavedMaskFactory{}).CreateMask(20, 2)
+	require.NoError(t, err)
+
+	data := ExportWebRTCBytes(mask)
+	require.Len(t, data, 2*webrtcLongMaskBytes)
+	// FEC 0 protects even packets, FEC 1 odd packets
+	assert.Equal(t, []byte{0xaa, 0xaa, 0xa0, 0x00, 0x00, 0x00}, data[:6])
+	assert.Equal(t, []byte{0x55, 0x55, 0x50, 0x00, 0x00, 0x00}, data[6:])
+
+	decoded, err := NewMaskFromWebRTCBytes(data, 20, 2)
+	require.NoError(t, err)
+	for fecIndex := 0; fecIndex < 2; fecIndex++ {
+		for packetIndex := 0; packetIndex < 20; packetIndex++ {
+			assert.Equal(t, mask.IsProtected(packetIndex, fecIndex), decoded.IsProtected(packetIndex, fecIndex))
+		}
+	}
+}
+
+func TestExportWebRTCBytesLimits(t *testing.T) {
+	mask, err := (&InterleavedMaskFactory{}).CreateMask(49, 1)
+	require.NoError(t, err)
+	assert.Nil(t, ExportWebRTCBytes(mask))
+
+	_, err = NewMaskFromWebRTCBytes([]byte{0x80}, 1, 1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,62 @@
+package fecanalysis
+
+import "fmt"
+
+// maxWebRTCProtectionFactor is the largest WebRTC protection factor; the factor is the FEC
+// rate in Q8, so 255 asks for (almost) one FEC packet per media packet
+const maxWebRTCProtectionFactor = 255
+
+// WebRTCNumFECPackets returns the number of FEC packets WebRTC sends for a group of media
+// packets at the given protection factor (ForwardErrorCorrection::NumFecPackets): the
+// protection factor scaled by the group size with rounding, and at least one FEC packet
+// for any nonzero protection factor
+func WebRTCNumFECPackets(numMediaPackets, protectionFactor int) int {
+	numFECPackets := (numMediaPackets*protectionFactor + (1 << 7)) >> 8
+	if protectionFactor > 0 && numFECPackets == 0 {
+		numFECPackets = 1
+	}
+	return numFECPackets
+}
+
+// WebRTCProtection is the FEC configuration WebRTC selects for a media packet group
+type WebRTCProtection struct {
+	N    int  // media packets
+	K    int  // FEC packets
+	Mask Mask // packet mask the encoder uses
+}
+
+// SelectWebRTCProtection returns the configuration the WebRTC encoder uses for a group of
+// numMediaPackets media packets at the given protection factor (0..255), with masks from
+// the given table kind (WebRTCBurstyTable or WebRTCRandomTable) and numImportant packets
+// under unequal protection (0 for equal protection, WebRTC's default). It fails when the
+// protection factor yields no FEC packets.
+func SelectWebRTCProtection(numMediaPackets, protectionFactor int, kind string, numImportant int) (WebRTCProtection, error) {
+	if numMediaPackets <= 0 || numMediaPackets > maxWebRTCMaskPackets {
+		return WebRTCProtection{}, fmt.Errorf("number of media packets must be in 1..%d, got %d", maxWebRTCMaskPackets, numMediaPackets)
+	}
+	if protectionFactor < 0 || protectionFactor > maxWebRTCProtectionFactor {
+		return WebRTCProtection{}, fmt.Errorf("protection factor must be in 0..%d, got %d", maxWebRTCProtectionFactor, protectionFactor)
+	}
+
+	var factory interface {
+		CreateMaskUEP(N, K, numImportant int) (Mask, error)
+	}
+	switch kind {
+	case WebRTCBurstyTable:
+		factory = &GoogleBurstyMaskFactory{}
+	case WebRTCRandomTable:
+		factory = &GoogleRandomMaskFactory{}
+	default:
+		return WebRTCProtection{}, fmt.Errorf("unknown WebRTC mask table kind %q", kind)
+	}
+
+	K := WebRTCNumFECPackets(numMediaPackets, protectionFactor)
+	if K == 0 {
+		return WebRTCProtection{}, fmt.Errorf("protection factor %d sends no FEC packets", protectionFactor)
+	}
+	mask, err := factory.CreateMaskUEP(numMediaPackets, K, numImportant)
+	if err != nil {
+		return WebRTCProtection{}, err
+	}
+	return WebRTCProtection{N: numMediaPackets, K: K, Mask: mask}, nil
+}
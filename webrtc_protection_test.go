@@ -0,0 +1,66 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebRTCNumFECPackets(t *testing.T) {
+	tests := []struct {
+		numMediaPackets  int
+		protectionFactor int
+		expected         int
+	}{
+		{10, 0, 0},
+		{10, 1, 1},    // rounds to zero, but any protection sends one packet
+		{10, 64, 3},   // 2.5 rounds up
+		{10, 51, 2},   // 1.99
+		{4, 128, 2},   // 50%
+		{12, 255, 12}, // 11.95
+		{48, 255, 48},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, WebRTCNumFECPackets(tt.numMediaPackets, tt.protectionFactor),
+			"N=%d, factor=%d", tt.numMediaPackets, tt.protectionFactor)
+	}
+}
+
+func TestSelectWebRTCProtection(t *testing.T) {
+	protection, err := SelectWebRTCProtection(10, 64, WebRTCRandomTable, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 10, protection.N)
+	assert.Equal(t, 3, protection.K)
+	expected, err := (&GoogleRandomMaskFactory{}).CreateMask(10, 3)
+	require.NoError(t, err)
+	assert.Equal(t, maskMatrix(expected), maskMatrix(protection.Mask))
+
+	// Beyond the tables WebRTC generates interleaved masks
+	protection, err = SelectWebRTCProtection(20, 51, WebRTCBurstyTable, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 4, protection.K)
+	assert.Equal(t, maskMatrix(webrtcGeneratedMask(20, 4)), maskMatrix(protection.Mask))
+
+	// Unequal protection
+	protection, err = SelectWebRTCProtection(8, 128, WebRTCBurstyTable, 2)
+	require.NoError(t, err)
+	uep, err := (&GoogleBurstyMaskFactory{}).CreateMaskUEP(8, 4, 2)
+	require.NoError(t, err)
+	assert.Equal(t, maskMatrix(uep), maskMatrix(protection.Mask))
+
+	for _, tt := range []struct {
+		numMediaPackets, protectionFactor int
+		kind                              string
+	}{
+		{10, 0, WebRTCRandomTable},
+		{0, 64, WebRTCRandomTable},
+		{49, 64, WebRTCRandomTable},
+		{10, 256, WebRTCRandomTable},
+		{10, 64, "Other"},
+	} {
+		_, err := SelectWebRTCProtection(tt.numMediaPackets, tt.protectionFactor, tt.kind, 0)
+		assert.Error(t, err, "%+v", tt)
+	}
+}
@@ -0,0 +1,200 @@
+package fecanalysis
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// WebRTC mask table kinds, matching the kMaskBursty*/kMaskRandom* identifiers
+const (
+	WebRTCBurstyTable = "Bursty"
+	WebRTCRandomTable = "Random"
+)
+
+// maxGoogleTableSize is the largest N (and K) covered by the built-in Google tables
+const maxGoogleTableSize = 12
+
+// MaskSize identifies a mask by its number of media (N) and FEC (K) packets
+type MaskSize struct {
+	N int
+	K int
+}
+
+// MaskTable maps mask sizes to packed mask bytes (MSB first), 2 bytes per FEC row for up
+// to 16 media packets and 6 bytes for more, as in WebRTC's short and long masks
+type MaskTable map[MaskSize][]byte
+
+// Sizes returns the sizes present in the table sorted by (N, K)
+func (t MaskTable) Sizes() []MaskSize {
+	sizes := make([]MaskSize, 0, len(t))
+	for size := range t {
+		sizes = append(sizes, size)
+	}
+	sort.Slice(sizes, func(i, j int) bool {
+		if sizes[i].N != sizes[j].N {
+			return sizes[i].N < sizes[j].N
+		}
+		return sizes[i].K < sizes[j].K
+	})
+	return sizes
+}
+
+// MaskTableMismatch describes a mask that differs between two tables
+type MaskTableMismatch struct {
+	Size     MaskSize
+	Expected []byte // nil if the mask is missing from the expected table
+	Actual   []byte // nil if the mask is missing from the actual table
+}
+
+// String formats the mismatch for reports
+func (m MaskTableMismatch) String() string {
+	switch {
+	case m.Expected == nil:
+		return fmt.Sprintf("N=%d, K=%d: unexpected mask % x", m.Size.N, m.Size.K, m.Actual)
+	case m.Actual == nil:
+		return fmt.Sprintf("N=%d, K=%d: missing mask, expected % x", m.Size.N, m.Size.K, m.Expected)
+	default:
+		return fmt.Sprintf("N=%d, K=%d: expected % x, got % x", m.Size.N, m.Size.K, m.Expected, m.Actual)
+	}
+}
+
+// CompareMaskTables returns every size where the two tables disagree, sorted by (N, K)
+func CompareMaskTables(expected, actual MaskTable) []MaskTableMismatch {
+	all := MaskTable{}
+	for size := range expected {
+		all[size] = nil
+	}
+	for size := range actual {
+		all[size] = nil
+	}
+
+	var mismatches []MaskTableMismatch
+	for _, size := range all.Sizes() {
+		expectedMask, inExpected := expected[size]
+		actualMask, inActual := actual[size]
+		if inExpected && inActual && bytes.Equal(expectedMask, actualMask) {
+			continue
+		}
+		mismatches = append(mismatches, MaskTableMismatch{Size: size, Expected: expectedMask, Actual: actualMask})
+	}
+	return mismatches
+}
+
+// webrtcMaskDefinition matches the start of a mask definition in fec_private_tables_*.h,
+// either as an array ("const uint8_t kMaskBursty2_1[2] = {") or as a macro ("#define kMaskBursty2_1 \")
+var webrtcMaskDefinition = regexp.MustCompile(`(?m)(?:#define\s+|\bconst\s+uint8_t\s+)kMask(Bursty|Random)(\d+)_(\d+)\b(\s*\[[^\]]*\]\s*=\s*\{)?`)
+
+// webrtcHexByte matches a single hexadecimal byte literal
+var webrtcHexByte = regexp.MustCompile(`0x[0-9a-fA-F]{1,2}\b`)
+
+// LoadWebRTCMaskTable reads the masks of the given kind from a WebRTC fec_private_tables_*.h file
+func LoadWebRTCMaskTable(path, kind string) (MaskTable, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	table, err := ParseWebRTCMaskTable(file, kind)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return table, nil
+}
+
+// ParseWebRTCMaskTable parses the kMask<kind><N>_<K> definitions of a WebRTC
+// fec_private_tables_*.h source file. kind is WebRTCBurstyTable or WebRTCRandomTable.
+func ParseWebRTCMaskTable(r io.Reader, kind string) (MaskTable, error) {
+	if kind != WebRTCBurstyTable && kind != WebRTCRandomTable {
+		return nil, fmt.Errorf("unknown WebRTC mask table kind %q", kind)
+	}
+
+	source, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	table := MaskTable{}
+	matches := webrtcMaskDefinition.FindAllSubmatchIndex(source, -1)
+	for _, match := range matches {
+		if string(source[match[2]:match[3]]) != kind {
+			continue
+		}
+		N, _ := strconv.Atoi(string(source[match[4]:match[5]]))
+		K, _ := strconv.Atoi(string(source[match[6]:match[7]]))
+
+		body := definitionBody(source, match[1], match[8] >= 0)
+		var data []byte
+		for _, literal := range webrtcHexByte.FindAll(body, -1) {
+			value, _ := strconv.ParseUint(string(literal[2:]), 16, 8)
+			data = append(data, byte(value))
+		}
+
+		if expected := webrtcRowBytes(N) * K; len(data) != expected {
+			return nil, fmt.Errorf("kMask%s%d_%d has %d bytes, expected %d", kind, N, K, len(data), expected)
+		}
+		table[MaskSize{N: N, K: K}] = data
+	}
+
+	if len(table) == 0 {
+		return nil, fmt.Errorf("no kMask%s definitions found", kind)
+	}
+	return table, nil
+}
+
+// definitionBody returns the source text holding the bytes of a definition starting at
+// offset: up to the closing brace for arrays, or up to the first line without a trailing
+// backslash for macros
+func definitionBody(source []byte, offset int, isArray bool) []byte {
+	rest := source[offset:]
+	if isArray {
+		if end := bytes.IndexByte(rest, '}'); end >= 0 {
+			return rest[:end]
+		}
+		return rest
+	}
+
+	end := 0
+	for end < len(rest) {
+		lineEnd := bytes.IndexByte(rest[end:], '\n')
+		if lineEnd < 0 {
+			return rest
+		}
+		line := bytes.TrimRight(rest[end:end+lineEnd], " \t\r")
+		end += lineEnd + 1
+		if !bytes.HasSuffix(line, []byte{'\\'}) {
+			break
+		}
+	}
+	return rest[:end]
+}
+
+// builtinSizes returns the part of the table the built-in patterns cover, N up to
+// maxGoogleTableSize, so that Verify does not report longer upstream masks as unexpected
+func (t MaskTable) builtinSizes() MaskTable {
+	covered := MaskTable{}
+	for size, data := range t {
+		if size.N <= maxGoogleTableSize {
+			covered[size] = data
+		}
+	}
+	return covered
+}
+
+// builtinMaskTable collects all masks a table lookup function knows about
+func builtinMaskTable(lookup func(N, K int) ([]byte, error)) MaskTable {
+	table := MaskTable{}
+	for N := 1; N <= maxGoogleTableSize; N++ {
+		for K := 1; K <= N; K++ {
+			if data, err := lookup(N, K); err == nil {
+				table[MaskSize{N: N, K: K}] = data
+			}
+		}
+	}
+	return table
+}
@@ -0,0 +1,125 @@
+package fecanalysis
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const webrtcArrayHeader = `
+namespace webrtc {
+namespace fec_private_tables {
+
+const uint8_t kMaskBursty1_1[2] = {
+  0x80, 0x00
+};
+
+const uint8_t kMaskBursty2_1[2] = {
+  0xc0, 0x00
+};
+
+const uint8_t kMaskBursty2_2[4] = {
+  0x80, 0x00,
+  0xc0, 0x00
+};
+
+}  // namespace fec_private_tables
+}  // namespace webrtc
+`
+
+const webrtcMacroHeader = `
+#define kMaskRandom1_1 \
+  0x80, 0x00
+
+#define kMaskRandom2_2 \
+  0xc0, 0x00, \
+  0x80, 0x00
+
+#define kMaskRandom3_1 \
+  0xe0, 0x00
+`
+
+func TestParseWebRTCMaskTableArrays(t *testing.T) {
+	table, err := ParseWebRTCMaskTable(strings.NewReader(webrtcArrayHeader), WebRTCBurstyTable)
+	require.NoError(t, err)
+	assert.Equal(t, []MaskSize{{1, 1}, {2, 1}, {2, 2}}, table.Sizes())
+	assert.Equal(t, []byte{0x80, 0x00, 0xc0, 0x00}, table[MaskSize{2, 2}])
+
+	// The parsed entries agree with the built-in tables
+	factory := &GoogleBurstyMaskFactory{}
+	builtin := factory.BuiltinTable()
+	for size, data := range table {
+		assert.Equal(t, builtin[size], data, "N=%d, K=%d", size.N, size.K)
+	}
+}
+
+func TestParseWebRTCMaskTableMacros(t *testing.T) {
+	table, err := ParseWebRTCMaskTable(strings.NewReader(webrtcMacroHeader), WebRTCRandomTable)
+	require.NoError(t, err)
+	assert.Equal(t, []MaskSize{{1, 1}, {2, 2}, {3, 1}}, table.Sizes())
+	assert.Equal(t, []byte{0xc0, 0x00, 0x80, 0x00}, table[MaskSize{2, 2}])
+
+	_, err = ParseWebRTCMaskTable(strings.NewReader(webrtcMacroHeader), WebRTCBurstyTable)
+	assert.Error(t, err, "header has no bursty masks")
+}
+
+func TestParseWebRTCMaskTableLongMasks(t *testing.T) {
+	const header = `
+#define kMaskRandom17_1 \
+  0xff, 0xff, 0x80, 0x00, 0x00, 0x00
+
+#define kMaskRandom17_2 \
+  0xaa, 0xaa, 0x80, 0x00, 0x00, 0x00, \
+  0x55, 0x55, 0x00, 0x00, 0x00, 0x00
+`
+	table, err := ParseWebRTCMaskTable(strings.NewReader(header), WebRTCRandomTable)
+	require.NoError(t, err)
+	assert.Equal(t, []MaskSize{{17, 1}, {17, 2}}, table.Sizes())
+
+	factory := &GoogleRandomMaskFactory{Table: table}
+	mask, err := factory.CreateMask(17, 2)
+	require.NoError(t, err)
+	assert.True(t, mask.IsProtected(16, 0))
+	assert.False(t, mask.IsProtected(16, 1))
+	assert.True(t, mask.IsProtected(15, 1))
+	assert.Equal(t, table[MaskSize{17, 2}], ExportWebRTCBytes(mask))
+
+	// Long masks are beyond the built-in tables and are not reported by Verify
+	builtin := &GoogleRandomMaskFactory{}
+	upstream := builtin.BuiltinTable()
+	for size, data := range table {
+		upstream[size] = data
+	}
+	assert.Empty(t, builtin.Verify(upstream))
+
+	_, err = ParseWebRTCMaskTable(strings.NewReader("#define kMaskRandom17_1 \\\n  0xff, 0xff\n"), WebRTCRandomTable)
+	assert.Error(t, err, "long masks need 6 bytes per row")
+}
+
+func TestVerifyDetectsMismatches(t *testing.T) {
+	factory := &GoogleRandomMaskFactory{}
+	upstream := factory.BuiltinTable()
+	assert.Empty(t, factory.Verify(upstream))
+
+	upstream[MaskSize{3, 1}] = []byte{0xc0, 0x00}
+	delete(upstream, MaskSize{4, 4})
+	mismatches := factory.Verify(upstream)
+	require.Len(t, mismatches, 2)
+	assert.Equal(t, MaskSize{3, 1}, mismatches[0].Size)
+	assert.Nil(t, mismatches[1].Expected)
+	assert.Contains(t, mismatches[1].String(), "unexpected mask")
+}
+
+func TestFactoryUsesCustomTable(t *testing.T) {
+	factory := &GoogleBurstyMaskFactory{Table: MaskTable{{2, 1}: {0x80, 0x00}}}
+
+	mask, err := factory.CreateMask(2, 1)
+	require.NoError(t, err)
+	assert.True(t, mask.IsProtected(0, 0))
+	assert.False(t, mask.IsProtected(1, 0))
+
+	_, err = factory.CreateMask(3, 1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,118 @@
+package fecanalysis
+
+import "fmt"
+
+// UEPMode selects how WebRTC protects the non-important packets in unequal protection mode
+type UEPMode int
+
+const (
+	// UEPModeOverlap protects all media packets with the remaining FEC packets; this is
+	// the mode the WebRTC encoder uses
+	UEPModeOverlap UEPMode = iota
+	// UEPModeNoOverlap protects only the packets after the important ones with the remaining FEC packets
+	UEPModeNoOverlap
+	// UEPModeBiasFirstPacket spends no FEC packets on the important packets and instead adds
+	// the first media packet to every FEC packet of the (N, K) table mask
+	UEPModeBiasFirstPacket
+)
+
+// uepImportantAllocation is the largest fraction of FEC packets WebRTC spends on the important packets
+const uepImportantAllocation = 0.5
+
+// webrtcUEPMask builds the packet mask the WebRTC encoder uses when unequal protection
+// is enabled (UnequalProtectionMask in forward_error_correction_internal.cc). The first
+// numFECForImportant rows protect the numImportant first media packets with the
+// (numImportant, numFECForImportant) table mask, the remaining rows are filled according
+// to the mode; UEPModeBiasFirstPacket skips the important rows, like WebRTC skips
+// SetProtectionAllocation for it. lookup returns the table pattern for an (N, K) combination.
+func webrtcUEPMask(lookup func(N, K int) ([]byte, error), N, K, numImportant int, mode UEPMode) (Mask, error) {
+	if N <= 0 || K <= 0 || K > N || N > maxWebRTCMaskPackets {
+		return nil, fmt.Errorf("invalid parameters for UEP mask: N=%d, K=%d", N, K)
+	}
+	if numImportant < 0 || numImportant > N {
+		return nil, fmt.Errorf("number of important packets must be in 0..%d, got %d", N, numImportant)
+	}
+
+	numFECForImportant := 0
+	if mode != UEPModeBiasFirstPacket {
+		numFECForImportant = min(numImportant, int(uepImportantAllocation*float64(K)))
+		if K == 1 && N > 2*numImportant {
+			numFECForImportant = 0 // Fall back to equal protection
+		}
+	}
+	if numImportant == 0 {
+		return webrtcTableMask(lookup, N, K)
+	}
+
+	rows := make([][]bool, 0, K)
+	if numFECForImportant > 0 {
+		important, err := webrtcTableMask(lookup, numImportant, numFECForImportant)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, fitRows(important, N, 0)...)
+	}
+
+	remaining := K - numFECForImportant
+	if remaining > 0 {
+		switch mode {
+		case UEPModeOverlap, UEPModeBiasFirstPacket:
+			rest, err := webrtcTableMask(lookup, N, remaining)
+			if err != nil {
+				return nil, err
+			}
+			restRows := fitRows(rest, N, 0)
+			if mode == UEPModeBiasFirstPacket {
+				for _, row := range restRows {
+					row[0] = true
+				}
+			}
+			rows = append(rows, restRows...)
+		case UEPModeNoOverlap:
+			// WebRTC shifts the remaining mask by the number of FEC packets spent on the
+			// important packets, not by the number of important packets
+			rest, err := webrtcTableMask(lookup, N-numFECForImportant, remaining)
+			if err != nil {
+				return nil, err
+			}
+			rows = append(rows, fitRows(rest, N, numFECForImportant)...)
+		default:
+			return nil, fmt.Errorf("unknown UEP mode %d", mode)
+		}
+	}
+
+	return NewMatrixMask(rows)
+}
+
+// webrtcTableMask returns the table mask for (N, K), or the generated interleaved mask
+// WebRTC uses beyond the table size
+func webrtcTableMask(lookup func(N, K int) ([]byte, error), N, K int) (Mask, error) {
+	if N > maxGoogleTableSize {
+		return webrtcGeneratedMask(N, K), nil
+	}
+	pattern, err := lookup(N, K)
+	if err != nil {
+		return nil, err
+	}
+	return &bitMask{data: pattern, n: N, k: K, rowBytes: webrtcRowBytes(N)}, nil
+}
+
+// fitRows places the rows of a mask into rows of width N, starting at column shift
+func fitRows(mask Mask, N, shift int) [][]bool {
+	rows := make([][]bool, mask.K())
+	for fecIndex := range rows {
+		rows[fecIndex] = make([]bool, N)
+		for packetIndex := 0; packetIndex < mask.N() && packetIndex+shift < N; packetIndex++ {
+			rows[fecIndex][packetIndex+shift] = mask.IsProtected(packetIndex, fecIndex)
+		}
+	}
+	return rows
+}
+
+// rawMaskTable returns the table a factory looks patterns up in
+func rawMaskTable(table MaskTable, builtin func() MaskTable) MaskTable {
+	if table != nil {
+		return table
+	}
+	return builtin()
+}
@@ -0,0 +1,70 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateMaskUEPOverlap(t *testing.T) {
+	factory := &GoogleRandomMaskFactory{}
+
+	// Half of the 4 FEC packets go to the 2 important packets
+	mask, err := factory.CreateMaskUEP(6, 4, 2)
+	require.NoError(t, err)
+
+	important, err := factory.CreateMask(2, 2)
+	require.NoError(t, err)
+	rest, err := factory.CreateMask(6, 2)
+	require.NoError(t, err)
+
+	rows := maskMatrix(mask)
+	assert.Equal(t, fitRows(important, 6, 0), rows[:2])
+	assert.Equal(t, maskMatrix(rest), rows[2:])
+}
+
+func TestCreateMaskUEPModes(t *testing.T) {
+	factory := &GoogleBurstyMaskFactory{}
+
+	noOverlap, err := factory.CreateMaskUEPMode(8, 4, 3, UEPModeNoOverlap)
+	require.NoError(t, err)
+	rest, err := factory.CreateMask(6, 2)
+	require.NoError(t, err)
+	assert.Equal(t, fitRows(rest, 8, 2), maskMatrix(noOverlap)[2:], "remaining rows are shifted by the FEC count")
+
+	// No FEC packets go to the important packets, the first packet is added to every row
+	biased, err := factory.CreateMaskUEPMode(8, 4, 3, UEPModeBiasFirstPacket)
+	require.NoError(t, err)
+	plain, err := factory.CreateMask(8, 4)
+	require.NoError(t, err)
+	expected := maskMatrix(plain)
+	for _, row := range expected {
+		row[0] = true
+	}
+	assert.Equal(t, expected, maskMatrix(biased))
+}
+
+func TestCreateMaskUEPFallsBackToEqualProtection(t *testing.T) {
+	factory := &GoogleRandomMaskFactory{}
+	equal, err := factory.CreateMask(5, 1)
+	require.NoError(t, err)
+
+	for _, numImportant := range []int{0, 2} {
+		mask, err := factory.CreateMaskUEP(5, 1, numImportant)
+		require.NoError(t, err)
+		assert.Equal(t, maskMatrix(equal), maskMatrix(mask), "important packets: %d", numImportant)
+	}
+
+	_, err = factory.CreateMaskUEP(5, 2, 6)
+	assert.Error(t, err)
+}
+
+func TestRawTable(t *testing.T) {
+	factory := &GoogleBurstyMaskFactory{}
+	assert.Equal(t, factory.BuiltinTable(), factory.RawTable())
+
+	custom := MaskTable{MaskSize{N: 1, K: 1}: {0x80, 0x00}}
+	factory.Table = custom
+	assert.Equal(t, custom, factory.RawTable())
+}
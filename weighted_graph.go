@@ -0,0 +1,123 @@
+package fecanalysis
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// WeightedGraph is a Graph whose edges have non-negative costs
+type WeightedGraph interface {
+	Graph
+
+	// EdgeWeight returns the cost of the edge from src to dst, +Inf if there is none
+	EdgeWeight(src, dst uint64) float64
+}
+
+// WeightedRecoveryGraph is a peeling RecoveryGraph whose edges cost the XOR work of the
+// recovery they represent. Recovering a media packet from a FEC packet XORs the FEC payload,
+// as long as the largest packet it protects, with every other protected media payload, so
+// the cost is the number of payload bytes read.
+type WeightedRecoveryGraph struct {
+	*RecoveryGraph
+	packetSizes []int // payload size of every media packet
+	fecSizes    []int // payload size of every FEC packet
+	rowSizes    []int // total payload size of the media packets protected by every FEC packet
+}
+
+// NewWeightedRecoveryGraph creates a weighted peeling recovery graph of the mask, with the
+// payload size in bytes of every media packet
+func NewWeightedRecoveryGraph(mask Mask, packetSizes []int) (*WeightedRecoveryGraph, error) {
+	if len(packetSizes) != mask.N() {
+		return nil, fmt.Errorf("got %d packet sizes for %d media packets", len(packetSizes), mask.N())
+	}
+
+	graph := &WeightedRecoveryGraph{RecoveryGraph: NewRecoveryGraph(mask), packetSizes: packetSizes}
+	for _, row := range graph.rows {
+		total, largest := 0, 0
+		for protected := row; protected != 0; protected &= protected - 1 {
+			size := packetSizes[bits.TrailingZeros64(protected)]
+			total += size
+			largest = max(largest, size)
+		}
+		graph.fecSizes = append(graph.fecSizes, largest)
+		graph.rowSizes = append(graph.rowSizes, total)
+	}
+	return graph, nil
+}
+
+// EdgeWeight returns the cheapest XOR cost of recovering the media packet that src has and dst
+// lacks, over the FEC packets usable at src
+func (g *WeightedRecoveryGraph) EdgeWeight(src, dst uint64) float64 {
+	recovered := src &^ dst
+	if dst&^src != 0 || recovered == 0 || recovered&(recovered-1) != 0 {
+		return math.Inf(1)
+	}
+
+	packetIndex := bits.TrailingZeros64(recovered)
+	weight := math.Inf(1)
+	if packetIndex >= g.N {
+		return weight
+	}
+	for fecIndex, row := range g.rows {
+		if row&recovered != 0 && g.canUseFECPacket(src, fecIndex) {
+			cost := g.fecSizes[fecIndex] + g.rowSizes[fecIndex] - g.packetSizes[packetIndex]
+			weight = min(weight, float64(cost))
+		}
+	}
+	return weight
+}
+
+// dijkstraItem is a vertex with its tentative distance
+type dijkstraItem struct {
+	vertex   uint64
+	distance float64
+}
+
+// dijkstraHeap is a min-heap of vertices ordered by tentative distance
+type dijkstraHeap []dijkstraItem
+
+func (h dijkstraHeap) Len() int           { return len(h) }
+func (h dijkstraHeap) Less(i, j int) bool { return h[i].distance < h[j].distance }
+func (h dijkstraHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *dijkstraHeap) Push(x any)        { *h = append(*h, x.(dijkstraItem)) }
+func (h *dijkstraHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// Dijkstra returns the cost of the cheapest path from any of the source vertices to every
+// vertex, +Inf for unreachable vertices. On a WeightedRecoveryGraph from GoodVertices this is
+// the least XOR work that recovers every media packet of a delivery pattern.
+func Dijkstra(graph WeightedGraph, sources []uint64) []float64 {
+	distances := make([]float64, graph.NumVertices())
+	for vertex := range distances {
+		distances[vertex] = math.Inf(1)
+	}
+
+	frontier := &dijkstraHeap{}
+	for _, source := range sources {
+		if source < graph.NumVertices() && distances[source] != 0 {
+			distances[source] = 0
+			heap.Push(frontier, dijkstraItem{vertex: source})
+		}
+	}
+
+	for frontier.Len() > 0 {
+		current := heap.Pop(frontier).(dijkstraItem)
+		if current.distance > distances[current.vertex] {
+			continue // stale entry superseded by a shorter path
+		}
+		graph.VisitEdges(current.vertex, func(dst uint64) bool {
+			if distance := current.distance + graph.EdgeWeight(current.vertex, dst); distance < distances[dst] {
+				distances[dst] = distance
+				heap.Push(frontier, dijkstraItem{vertex: dst, distance: distance})
+			}
+			return true
+		})
+	}
+	return distances
+}
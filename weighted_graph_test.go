@@ -0,0 +1,70 @@
+package fecanalysis
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// weightedSimpleGraph is a SimpleGraph with explicit edge weights
+type weightedSimpleGraph struct {
+	*SimpleGraph
+	weights map[[2]uint64]float64
+}
+
+func (g *weightedSimpleGraph) addEdge(source, destination int, weight float64) {
+	g.AddEdge(source, destination)
+	g.weights[[2]uint64{uint64(source), uint64(destination)}] = weight
+}
+
+func (g *weightedSimpleGraph) EdgeWeight(src, dst uint64) float64 {
+	if weight, ok := g.weights[[2]uint64{src, dst}]; ok {
+		return weight
+	}
+	return math.Inf(1)
+}
+
+func TestDijkstra(t *testing.T) {
+	graph := &weightedSimpleGraph{SimpleGraph: NewSimpleGraph(5), weights: map[[2]uint64]float64{}}
+	graph.addEdge(0, 1, 5)
+	graph.addEdge(0, 2, 1)
+	graph.addEdge(2, 1, 2)
+	graph.addEdge(1, 3, 1)
+	graph.addEdge(4, 0, 1)
+
+	assert.Equal(t, []float64{0, 3, 1, 4, math.Inf(1)}, Dijkstra(graph, []uint64{0}))
+	assert.Equal(t, []float64{0, 2, 0, 3, math.Inf(1)}, Dijkstra(graph, []uint64{0, 2}))
+}
+
+func TestWeightedRecoveryGraph(t *testing.T) {
+	mask, err := (&SingleParityMaskFactory{}).CreateMask(3, 1)
+	require.NoError(t, err)
+	graph, err := NewWeightedRecoveryGraph(mask, []int{100, 200, 300})
+	require.NoError(t, err)
+
+	distances := Dijkstra(graph, GoodVertices(3, 1))
+	assert.Equal(t, 0.0, distances[0b0111])
+	// Recovering packet 0 reads the 300-byte FEC payload and packets 1 and 2
+	assert.Equal(t, 800.0, distances[0b1110])
+	// Recovering packet 2 reads the FEC payload and packets 0 and 1
+	assert.Equal(t, 600.0, distances[0b1011])
+	assert.True(t, math.IsInf(distances[0b1100], 1))
+	assert.True(t, math.IsInf(graph.EdgeWeight(0b1111, 0b1100), 1))
+
+	_, err = NewWeightedRecoveryGraph(mask, []int{100})
+	assert.Error(t, err)
+}
+
+func TestDijkstraMatchesReachability(t *testing.T) {
+	mask, err := (&GoogleBurstyMaskFactory{}).CreateMask(6, 3)
+	require.NoError(t, err)
+	graph, err := NewWeightedRecoveryGraph(mask, []int{1, 1, 1, 1, 1, 1})
+	require.NoError(t, err)
+
+	reachable := ReachableBFS(graph, GoodVertices(6, 3))
+	for vertex, distance := range Dijkstra(graph, GoodVertices(6, 3)) {
+		assert.Equal(t, reachable.Contains(uint64(vertex)), !math.IsInf(distance, 1), "vertex %09b", vertex)
+	}
+}
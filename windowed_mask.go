@@ -0,0 +1,135 @@
+package fecanalysis
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// WindowedMask describes a sliding-window (convolutional) FEC scheme over an unbounded
+// media stream: after every RepairInterval media packets one FEC packet is sent that
+// protects the last Window media packets. Unlike block masks, protection windows overlap,
+// so a FEC packet can repair losses left over from the previous window.
+type WindowedMask struct {
+	Window         int // number of most recent media packets protected by each FEC packet
+	RepairInterval int // number of media packets between consecutive FEC packets
+}
+
+// NewWindowedMask creates a sliding-window mask
+func NewWindowedMask(window, repairInterval int) (*WindowedMask, error) {
+	if window <= 0 || repairInterval <= 0 {
+		return nil, fmt.Errorf("invalid parameters for windowed mask: window=%d, repair interval=%d", window, repairInterval)
+	}
+	return &WindowedMask{Window: window, RepairInterval: repairInterval}, nil
+}
+
+// Overhead returns the ratio of FEC packets to media packets
+func (m *WindowedMask) Overhead() float64 {
+	return 1.0 / float64(m.RepairInterval)
+}
+
+// Unroll returns the block mask covering the first periods repair intervals of the
+// stream: periods*RepairInterval media packets and periods FEC packets. The first
+// windows are truncated at the start of the stream.
+func (m *WindowedMask) Unroll(periods int) (Mask, error) {
+	if periods <= 0 {
+		return nil, fmt.Errorf("number of periods must be positive, got %d", periods)
+	}
+	return &unrolledWindowedMask{windowed: m, periods: periods}, nil
+}
+
+// warmupPeriods returns the number of repair intervals a window spans; periods closer
+// than that to either end of the unrolled stream see truncated protection
+func (m *WindowedMask) warmupPeriods() int {
+	return (m.Window + m.RepairInterval - 1) / m.RepairInterval
+}
+
+// transmissionIndexToVertexBit maps a position in the transmitted stream (media packets
+// with a FEC packet after every RepairInterval of them) to the vertex bit of the
+// unrolled block mask, where media packets come first and FEC packets after them
+func (m *WindowedMask) transmissionIndexToVertexBit(index, periods int) int {
+	period, offset := index/(m.RepairInterval+1), index%(m.RepairInterval+1)
+	if offset == m.RepairInterval {
+		return periods*m.RepairInterval + period
+	}
+	return period*m.RepairInterval + offset
+}
+
+// SteadyStateResidualLoss computes the fraction of media packets that remain lost after
+// peeling recovery once the stream has reached steady state. The stream is unrolled for
+// the given number of periods, the loss model is applied in transmission order, and only
+// media packets of the middle periods are counted, so that protection truncated at the
+// start of the stream and repair arriving after its end do not distort the result.
+func SteadyStateResidualLoss(mask *WindowedMask, model LossModel, periods int) (float64, error) {
+	warmup := mask.warmupPeriods()
+	if periods <= 2*warmup {
+		return 0, fmt.Errorf("need more than %d periods to reach steady state, got %d", 2*warmup, periods)
+	}
+
+	length := periods * (mask.RepairInterval + 1)
+	if length > MaxEnumerablePackets {
+		return 0, fmt.Errorf("unrolled stream of %d packets exceeds the limit of %d", length, MaxEnumerablePackets)
+	}
+
+	unrolled, err := mask.Unroll(periods)
+	if err != nil {
+		return 0, err
+	}
+	N := unrolled.N()
+	rows := patternRows(unrolled)
+
+	vertexBits := make([]int, length)
+	for index := range vertexBits {
+		vertexBits[index] = mask.transmissionIndexToVertexBit(index, periods)
+	}
+
+	// Media packets of the middle periods
+	measured := uint64(0)
+	for period := warmup; period < periods-warmup; period++ {
+		for offset := 0; offset < mask.RepairInterval; offset++ {
+			measured |= 1 << (period*mask.RepairInterval + offset)
+		}
+	}
+	measuredCount := bits.OnesCount64(measured)
+
+	expectedLost := 0.0
+	for pattern := 0; pattern < (1 << length); pattern++ {
+		vertex := uint64(0)
+		for index, bit := range vertexBits {
+			if pattern&(1<<index) != 0 {
+				vertex |= 1 << bit
+			}
+		}
+		recovered := peelingClosure(rows, N, vertex)
+		lost := bits.OnesCount64(measured &^ recovered)
+		if lost > 0 {
+			expectedLost += model.CalculateProbability(pattern, length) * float64(lost)
+		}
+	}
+
+	return expectedLost / float64(measuredCount), nil
+}
+
+// unrolledWindowedMask is the block mask of a windowed mask over a finite number of periods
+type unrolledWindowedMask struct {
+	windowed *WindowedMask
+	periods  int
+}
+
+// IsProtected returns true if the packet at packetIndex is within the window of the FEC packet at fecIndex
+func (m *unrolledWindowedMask) IsProtected(packetIndex, fecIndex int) bool {
+	if packetIndex < 0 || packetIndex >= m.N() || fecIndex < 0 || fecIndex >= m.K() {
+		return false
+	}
+	end := (fecIndex + 1) * m.windowed.RepairInterval // exclusive end of the window
+	return packetIndex < end && packetIndex >= end-m.windowed.Window
+}
+
+// N returns the number of media packets
+func (m *unrolledWindowedMask) N() int {
+	return m.periods * m.windowed.RepairInterval
+}
+
+// K returns the number of FEC packets
+func (m *unrolledWindowedMask) K() int {
+	return m.periods
+}
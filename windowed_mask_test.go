@@ -0,0 +1,63 @@
+package fecanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWindowedMaskUnroll(t *testing.T) {
+	mask, err := NewWindowedMask(4, 2)
+	require.NoError(t, err)
+
+	unrolled, err := mask.Unroll(3)
+	require.NoError(t, err)
+	assert.Equal(t, 6, unrolled.N())
+	assert.Equal(t, 3, unrolled.K())
+
+	expected := [][]bool{
+		{true, true, false, false, false, false},
+		{true, true, true, true, false, false},
+		{false, false, true, true, true, true},
+	}
+	for fecIndex, row := range expected {
+		for packetIndex, protected := range row {
+			assert.Equal(t, protected, unrolled.IsProtected(packetIndex, fecIndex), "packet %d, FEC %d", packetIndex, fecIndex)
+		}
+	}
+}
+
+func TestSteadyStateResidualLoss(t *testing.T) {
+	model := NewRandomLossModel(0.1)
+
+	// Window equal to the repair interval is plain block parity: a media packet is lost
+	// when it and at least one of the other R packets of its block (including FEC) is lost
+	block, err := NewWindowedMask(2, 2)
+	require.NoError(t, err)
+	residual, err := SteadyStateResidualLoss(block, model, 5)
+	require.NoError(t, err)
+	p := 0.1
+	assert.InDelta(t, p*(1-(1-p)*(1-p)), residual, 1e-9)
+
+	// Overlapping windows can only help compared to the block code
+	sliding, err := NewWindowedMask(4, 2)
+	require.NoError(t, err)
+	slidingResidual, err := SteadyStateResidualLoss(sliding, model, 5)
+	require.NoError(t, err)
+	assert.Less(t, slidingResidual, residual)
+	assert.Greater(t, slidingResidual, 0.0)
+}
+
+func TestSteadyStateResidualLossValidation(t *testing.T) {
+	mask, err := NewWindowedMask(4, 2)
+	require.NoError(t, err)
+
+	_, err = SteadyStateResidualLoss(mask, NewRandomLossModel(0.1), 4)
+	assert.Error(t, err, "not enough periods for steady state")
+	_, err = SteadyStateResidualLoss(mask, NewRandomLossModel(0.1), 9)
+	assert.Error(t, err, "unrolled stream too long")
+
+	_, err = NewWindowedMask(0, 2)
+	assert.Error(t, err)
+}
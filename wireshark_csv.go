@@ -0,0 +1,164 @@
+package fecanalysis
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// sequenceColumnNames are header names Wireshark uses for the RTP sequence number
+// in "RTP Stream Analysis" exports and in custom packet-list columns
+var sequenceColumnNames = []string{"sequence", "seq", "rtp.seq", "sequence number"}
+
+// arrivalColumnNames are optional header names carrying an explicit per-packet arrival flag
+var arrivalColumnNames = []string{"received", "arrived", "delivered", "lost"}
+
+// infoSequencePattern extracts the sequence number from the packet-list "Info" column
+var infoSequencePattern = regexp.MustCompile(`Seq=(\d+)`)
+
+// LoadWiresharkRTPCSV reads a loss trace from a Wireshark RTP stream CSV export file
+func LoadWiresharkRTPCSV(path string) (LossTrace, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	trace, err := ReadWiresharkRTPCSV(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return trace, nil
+}
+
+// ReadWiresharkRTPCSV reads a loss trace from the CSV Wireshark produces for an RTP stream.
+// The sequence number is taken from a "Sequence"/"Seq" column or, for packet-list exports,
+// parsed from "Seq=" in the "Info" column. Sequence numbers are unwrapped across the 16-bit
+// boundary, and every number missing between the first and last packet is a lost packet.
+// If an explicit "Received"/"Lost" column is present, it overrides the arrival of listed rows.
+func ReadWiresharkRTPCSV(r io.Reader) (LossTrace, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV header: %w", err)
+	}
+
+	sequenceColumn := findColumn(header, sequenceColumnNames)
+	infoColumn := findColumn(header, []string{"info"})
+	if sequenceColumn < 0 && infoColumn < 0 {
+		return nil, fmt.Errorf("CSV has no sequence number or Info column (header: %v)", header)
+	}
+	arrivalColumn := findColumn(header, arrivalColumnNames)
+	arrivalIsLoss := arrivalColumn >= 0 && strings.EqualFold(strings.TrimSpace(header[arrivalColumn]), "lost")
+
+	delivered := make(map[int64]bool)
+	var extended int64
+	var previous uint16
+	first := true
+	line := 1
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		seq, err := parseSequenceNumber(record, sequenceColumn, infoColumn)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		// Unwrap the 16-bit sequence number relative to the previous packet
+		if first {
+			first = false
+		} else {
+			extended += int64(int16(seq - previous))
+		}
+		previous = seq
+
+		arrived := true
+		if arrivalColumn >= 0 && arrivalColumn < len(record) {
+			flag, err := parseFlag(record[arrivalColumn])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			arrived = flag != arrivalIsLoss
+		}
+		delivered[extended] = delivered[extended] || arrived
+	}
+
+	if len(delivered) == 0 {
+		return nil, fmt.Errorf("CSV contains no RTP packets")
+	}
+
+	sequenceNumbers := make([]int64, 0, len(delivered))
+	for seq := range delivered {
+		sequenceNumbers = append(sequenceNumbers, seq)
+	}
+	sort.Slice(sequenceNumbers, func(i, j int) bool { return sequenceNumbers[i] < sequenceNumbers[j] })
+
+	lowest := sequenceNumbers[0]
+	highest := sequenceNumbers[len(sequenceNumbers)-1]
+	trace := make(LossTrace, highest-lowest+1)
+	for seq, arrived := range delivered {
+		trace[seq-lowest] = arrived
+	}
+	return trace, nil
+}
+
+// findColumn returns the index of the first header matching one of the names (case-insensitive)
+func findColumn(header []string, names []string) int {
+	for _, name := range names {
+		for i, column := range header {
+			if strings.EqualFold(strings.TrimSpace(column), name) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// parseSequenceNumber extracts the RTP sequence number from a CSV record
+func parseSequenceNumber(record []string, sequenceColumn, infoColumn int) (uint16, error) {
+	var text string
+	if sequenceColumn >= 0 && sequenceColumn < len(record) {
+		text = strings.TrimSpace(record[sequenceColumn])
+	} else if infoColumn >= 0 && infoColumn < len(record) {
+		match := infoSequencePattern.FindStringSubmatch(record[infoColumn])
+		if match == nil {
+			return 0, fmt.Errorf("no Seq= field in Info column %q", record[infoColumn])
+		}
+		text = match[1]
+	} else {
+		return 0, fmt.Errorf("record has too few fields: %v", record)
+	}
+
+	value, err := strconv.ParseUint(text, 10, 16)
+	if err != nil {
+		return 0, fmt.Errorf("invalid sequence number %q: %w", text, err)
+	}
+	return uint16(value), nil
+}
+
+// parseFlag parses an arrival flag written as 1/0, true/false or yes/no
+func parseFlag(text string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(text)) {
+	case "1", "true", "yes", "y":
+		return true, nil
+	case "0", "false", "no", "n", "":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid arrival flag %q", text)
+}
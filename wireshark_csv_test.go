@@ -0,0 +1,74 @@
+package fecanalysis
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadWiresharkRTPStreamAnalysisCSV(t *testing.T) {
+	csvData := `"Packet","Sequence","Delta (ms)","Filtered Jitter (ms)","Skew (ms)","IP BW (kbps)","Marker","Status"
+"1","65533","0.00","0.00","0.00","1.60","","[ Ok ]"
+"2","65534","20.00","0.00","0.00","3.20","","[ Ok ]"
+"3","0","40.00","1.25","-20.00","4.80","","Wrong sequence number"
+"4","1","20.00","1.17","-20.00","6.40","","[ Ok ]"
+"5","4","60.00","2.34","-60.00","8.00","","Wrong sequence number"
+`
+	trace, err := ReadWiresharkRTPCSV(strings.NewReader(csvData))
+	require.NoError(t, err)
+
+	// 65533, 65534, [65535 lost], 0, 1, [2, 3 lost], 4
+	expected := LossTrace{true, true, false, true, true, false, false, true}
+	assert.Equal(t, expected, trace)
+	assert.Equal(t, 3, trace.LostCount())
+	assert.InDelta(t, 3.0/8.0, trace.LossRate(), 1e-12)
+}
+
+func TestReadWiresharkPacketListCSV(t *testing.T) {
+	csvData := `"No.","Time","Source","Destination","Protocol","Length","Info"
+"1","0.000","10.0.0.1","10.0.0.2","RTP","214","PT=DynamicRTP-Type-96, SSRC=0x1234, Seq=10, Time=160"
+"2","0.020","10.0.0.1","10.0.0.2","RTP","214","PT=DynamicRTP-Type-96, SSRC=0x1234, Seq=12, Time=480"
+"3","0.030","10.0.0.1","10.0.0.2","RTP","214","PT=DynamicRTP-Type-96, SSRC=0x1234, Seq=11, Time=320"
+`
+	trace, err := ReadWiresharkRTPCSV(strings.NewReader(csvData))
+	require.NoError(t, err)
+
+	// Reordered packets still count as delivered
+	assert.Equal(t, LossTrace{true, true, true}, trace)
+}
+
+func TestReadWiresharkCSVWithArrivalFlags(t *testing.T) {
+	csvData := "Sequence,Lost\n100,0\n101,1\n102,0\n"
+	trace, err := ReadWiresharkRTPCSV(strings.NewReader(csvData))
+	require.NoError(t, err)
+	assert.Equal(t, LossTrace{true, false, true}, trace)
+}
+
+func TestReadWiresharkCSVErrors(t *testing.T) {
+	_, err := ReadWiresharkRTPCSV(strings.NewReader("A,B\n1,2\n"))
+	assert.Error(t, err)
+
+	_, err = ReadWiresharkRTPCSV(strings.NewReader("Sequence\nabc\n"))
+	assert.Error(t, err)
+
+	_, err = ReadWiresharkRTPCSV(strings.NewReader("Sequence\n"))
+	assert.Error(t, err)
+}
+
+func TestLossTraceModels(t *testing.T) {
+	trace := LossTrace{true, true, false, false, true, true, true, false, true, true}
+
+	histogram, err := trace.WindowHistogram(5, 5)
+	require.NoError(t, err)
+	assert.Equal(t, map[int]int{0b10011: 1, 0b11011: 1}, histogram)
+
+	gilbert := trace.FitGilbert()
+	assert.InDelta(t, 2.0/6.0, gilbert.P01, 1e-12) // 2 of 6 delivered->next transitions lose
+	assert.InDelta(t, 2.0/3.0, gilbert.P10, 1e-12) // 2 of 3 lost->next transitions recover
+
+	model, err := trace.EmpiricalLossModel(3, 1.0)
+	require.NoError(t, err)
+	assert.Equal(t, 8, model.TotalWindows())
+}